@@ -0,0 +1,95 @@
+package notation
+
+// SignatureAlgorithmInfo describes a signature algorithm and its
+// identifiers in the signature envelope formats.
+type SignatureAlgorithmInfo struct {
+	// Algorithm is the signature algorithm.
+	Algorithm SignatureAlgorithm
+
+	// KeySpec is the key spec signing with Algorithm.
+	KeySpec KeySpec
+
+	// Hash is the hash algorithm of Algorithm.
+	Hash HashAlgorithm
+
+	// JWS is the JWS algorithm name as defined in RFC 7518 and RFC 8037.
+	JWS string
+
+	// COSE is the COSE algorithm identifier as registered in the IANA COSE
+	// Algorithms registry.
+	COSE int
+}
+
+// signatureAlgorithms is the table of the supported signature algorithms.
+// Adding an algorithm here makes it available to all envelope formats.
+var signatureAlgorithms = []SignatureAlgorithmInfo{
+	{Algorithm: RSASSA_PSS_SHA_256, KeySpec: RSA_2048, Hash: SHA256, JWS: "PS256", COSE: -37},
+	{Algorithm: RSASSA_PSS_SHA_384, KeySpec: RSA_3072, Hash: SHA384, JWS: "PS384", COSE: -38},
+	{Algorithm: RSASSA_PSS_SHA_512, KeySpec: RSA_4096, Hash: SHA512, JWS: "PS512", COSE: -39},
+	{Algorithm: ECDSA_SHA_256, KeySpec: EC_256, Hash: SHA256, JWS: "ES256", COSE: -7},
+	{Algorithm: ECDSA_SHA_384, KeySpec: EC_384, Hash: SHA384, JWS: "ES384", COSE: -35},
+	{Algorithm: ECDSA_SHA_512, KeySpec: EC_512, Hash: SHA512, JWS: "ES512", COSE: -36},
+	{Algorithm: EDDSA_ED25519, KeySpec: ED25519, Hash: SHA512, JWS: "EdDSA", COSE: -8},
+}
+
+// SignatureAlgorithms returns the supported signature algorithms.
+func SignatureAlgorithms() []SignatureAlgorithmInfo {
+	return append([]SignatureAlgorithmInfo(nil), signatureAlgorithms...)
+}
+
+// LookupSignatureAlgorithm returns the description of alg, and whether alg
+// is supported.
+func LookupSignatureAlgorithm(alg SignatureAlgorithm) (SignatureAlgorithmInfo, bool) {
+	return lookupSignatureAlgorithm(func(info SignatureAlgorithmInfo) bool {
+		return info.Algorithm == alg
+	})
+}
+
+// lookupSignatureAlgorithm returns the first supported algorithm matching
+// match.
+func lookupSignatureAlgorithm(match func(SignatureAlgorithmInfo) bool) (SignatureAlgorithmInfo, bool) {
+	for _, info := range signatureAlgorithms {
+		if match(info) {
+			return info, true
+		}
+	}
+	return SignatureAlgorithmInfo{}, false
+}
+
+// Hash returns the Hash associated s.
+func (s SignatureAlgorithm) Hash() HashAlgorithm {
+	info, _ := LookupSignatureAlgorithm(s)
+	return info.Hash
+}
+
+// JWS returns the JWS algorithm name.
+func (s SignatureAlgorithm) JWS() string {
+	info, _ := LookupSignatureAlgorithm(s)
+	return info.JWS
+}
+
+// COSE returns the COSE algorithm identifier.
+// It returns 0, which is reserved by COSE, if s is not supported.
+func (s SignatureAlgorithm) COSE() int {
+	info, _ := LookupSignatureAlgorithm(s)
+	return info.COSE
+}
+
+// NewSignatureAlgorithmJWS returns the algorithm associated to alg.
+// It returns an empty string if alg is not supported.
+func NewSignatureAlgorithmJWS(alg string) SignatureAlgorithm {
+	info, _ := lookupSignatureAlgorithm(func(info SignatureAlgorithmInfo) bool {
+		return info.JWS == alg
+	})
+	return info.Algorithm
+}
+
+// NewSignatureAlgorithmCOSE returns the algorithm associated to the COSE
+// algorithm identifier alg.
+// It returns an empty string if alg is not supported.
+func NewSignatureAlgorithmCOSE(alg int) SignatureAlgorithm {
+	info, _ := lookupSignatureAlgorithm(func(info SignatureAlgorithmInfo) bool {
+		return info.COSE == alg
+	})
+	return info.Algorithm
+}
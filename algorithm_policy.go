@@ -0,0 +1,113 @@
+package notation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"time"
+)
+
+// AlgorithmPolicy rejects signatures made with algorithms or keys weaker
+// than the thresholds of a deployment, e.g. to forbid SHA-1 anywhere in the
+// certificate chain or to reject RSA-2048 keys from a given date.
+// A nil or zero policy rejects nothing.
+type AlgorithmPolicy struct {
+	// ForbiddenSignatureAlgorithms are the signature algorithms of the
+	// signatures rejected, e.g. RSASSA_PSS_SHA_256.
+	ForbiddenSignatureAlgorithms []SignatureAlgorithm
+
+	// ForbiddenHashes are the hash functions rejected in the signature
+	// algorithm of the signature and of any certificate of its chain, e.g.
+	// crypto.SHA1. Unlike the FIPS mode, the signatures of self-issued
+	// certificates are checked too.
+	ForbiddenHashes []crypto.Hash
+
+	// KeySizeRules are the minimum key sizes of the certificates of the
+	// chain, each effective from a date.
+	KeySizeRules []KeySizeRule
+}
+
+// KeySizeRule requires minimum key sizes from a date.
+type KeySizeRule struct {
+	// Since is the time from which the rule is effective.
+	// The rule is always effective if zero.
+	Since time.Time
+
+	// MinRSAKeySize is the minimum size in bits of RSA keys, e.g. 3072.
+	// RSA keys are not checked if zero.
+	MinRSAKeySize int
+
+	// MinECDSAKeySize is the minimum size in bits of ECDSA keys, e.g. 384.
+	// ECDSA keys are not checked if zero.
+	MinECDSAKeySize int
+}
+
+// Check returns an ErrorCodeWeakAlgorithm error if a signature of alg with
+// the certificate chain certs is rejected by the policy at time at, i.e.
+// if alg or the signature algorithm of a certificate is forbidden, or if a
+// key of a certificate is smaller than required by the rules effective at
+// time at.
+func (p *AlgorithmPolicy) Check(alg SignatureAlgorithm, certs []*x509.Certificate, at time.Time) error {
+	if p == nil {
+		return nil
+	}
+	for _, forbidden := range p.ForbiddenSignatureAlgorithms {
+		if alg == forbidden {
+			return Errorf(ErrorCodeWeakAlgorithm, "signature algorithm %s is forbidden", alg)
+		}
+	}
+	if p.forbidsHash(alg.Hash().HashFunc()) {
+		return Errorf(ErrorCodeWeakAlgorithm, "signature algorithm %s uses forbidden hash %v", alg, alg.Hash().HashFunc())
+	}
+	for _, cert := range certs {
+		if hash, ok := certificateSignatureHashes[cert.SignatureAlgorithm]; ok && p.forbidsHash(hash) {
+			return Errorf(ErrorCodeWeakAlgorithm, "certificate %q is signed with %s, which uses forbidden hash %v", cert.Subject, cert.SignatureAlgorithm, hash)
+		}
+		for _, rule := range p.KeySizeRules {
+			if !rule.Since.IsZero() && at.Before(rule.Since) {
+				continue
+			}
+			switch key := cert.PublicKey.(type) {
+			case *rsa.PublicKey:
+				if rule.MinRSAKeySize > 0 && key.N.BitLen() < rule.MinRSAKeySize {
+					return Errorf(ErrorCodeWeakAlgorithm, "certificate %q has an RSA key of %d bits, less than the %d bits required", cert.Subject, key.N.BitLen(), rule.MinRSAKeySize)
+				}
+			case *ecdsa.PublicKey:
+				if size := key.Params().BitSize; rule.MinECDSAKeySize > 0 && size < rule.MinECDSAKeySize {
+					return Errorf(ErrorCodeWeakAlgorithm, "certificate %q has an ECDSA key of %d bits, less than the %d bits required", cert.Subject, size, rule.MinECDSAKeySize)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *AlgorithmPolicy) forbidsHash(hash crypto.Hash) bool {
+	for _, forbidden := range p.ForbiddenHashes {
+		if hash == forbidden {
+			return true
+		}
+	}
+	return false
+}
+
+// certificateSignatureHashes maps the signature algorithms of certificates
+// to the hash functions they use.
+var certificateSignatureHashes = map[x509.SignatureAlgorithm]crypto.Hash{
+	x509.MD5WithRSA:       crypto.MD5,
+	x509.SHA1WithRSA:      crypto.SHA1,
+	x509.DSAWithSHA1:      crypto.SHA1,
+	x509.ECDSAWithSHA1:    crypto.SHA1,
+	x509.SHA256WithRSA:    crypto.SHA256,
+	x509.SHA256WithRSAPSS: crypto.SHA256,
+	x509.DSAWithSHA256:    crypto.SHA256,
+	x509.ECDSAWithSHA256:  crypto.SHA256,
+	x509.SHA384WithRSA:    crypto.SHA384,
+	x509.SHA384WithRSAPSS: crypto.SHA384,
+	x509.ECDSAWithSHA384:  crypto.SHA384,
+	x509.SHA512WithRSA:    crypto.SHA512,
+	x509.SHA512WithRSAPSS: crypto.SHA512,
+	x509.ECDSAWithSHA512:  crypto.SHA512,
+	x509.PureEd25519:      crypto.SHA512,
+}
@@ -0,0 +1,61 @@
+package notation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAlgorithmPolicy_Check(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "leaf"}, SignatureAlgorithm: x509.SHA256WithRSA, PublicKey: &rsaKey.PublicKey}
+	ecLeaf := &x509.Certificate{Subject: pkix.Name{CommonName: "leaf"}, SignatureAlgorithm: x509.ECDSAWithSHA256, PublicKey: &ecKey.PublicKey}
+	sha1Root := &x509.Certificate{Subject: pkix.Name{CommonName: "root"}, SignatureAlgorithm: x509.SHA1WithRSA, PublicKey: &rsaKey.PublicKey}
+	cutoff := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rsa3072Since := &AlgorithmPolicy{KeySizeRules: []KeySizeRule{{Since: cutoff, MinRSAKeySize: 3072}}}
+
+	tests := []struct {
+		name    string
+		policy  *AlgorithmPolicy
+		alg     SignatureAlgorithm
+		certs   []*x509.Certificate
+		at      time.Time
+		wantErr bool
+	}{
+		{name: "nil policy", alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf, sha1Root}},
+		{name: "zero policy", policy: &AlgorithmPolicy{}, alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf, sha1Root}},
+		{name: "forbidden signature algorithm", policy: &AlgorithmPolicy{ForbiddenSignatureAlgorithms: []SignatureAlgorithm{RSASSA_PSS_SHA_256}}, alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf}, wantErr: true},
+		{name: "forbidden signature hash", policy: &AlgorithmPolicy{ForbiddenHashes: []crypto.Hash{crypto.SHA256}}, alg: ECDSA_SHA_256, certs: []*x509.Certificate{ecLeaf}, wantErr: true},
+		{name: "SHA-1 in chain", policy: &AlgorithmPolicy{ForbiddenHashes: []crypto.Hash{crypto.SHA1}}, alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf, sha1Root}, wantErr: true},
+		{name: "no SHA-1", policy: &AlgorithmPolicy{ForbiddenHashes: []crypto.Hash{crypto.SHA1}}, alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf}},
+		{name: "RSA-2048 before cutoff", policy: rsa3072Since, alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf}, at: cutoff.Add(-time.Second)},
+		{name: "RSA-2048 after cutoff", policy: rsa3072Since, alg: RSASSA_PSS_SHA_256, certs: []*x509.Certificate{leaf}, at: cutoff, wantErr: true},
+		{name: "ECDSA after RSA cutoff", policy: rsa3072Since, alg: ECDSA_SHA_256, certs: []*x509.Certificate{ecLeaf}, at: cutoff},
+		{name: "ECDSA P-256", policy: &AlgorithmPolicy{KeySizeRules: []KeySizeRule{{MinECDSAKeySize: 384}}}, alg: ECDSA_SHA_256, certs: []*x509.Certificate{ecLeaf}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.alg, tt.certs, tt.at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrWeakAlgorithm) {
+				t.Errorf("Check() error = %v, want %v", err, ErrWeakAlgorithm)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package notation
+
+import (
+	"crypto"
+	"testing"
+)
+
+func TestSignatureAlgorithms(t *testing.T) {
+	tests := []struct {
+		alg     SignatureAlgorithm
+		keySpec KeySpec
+		hash    crypto.Hash
+		jws     string
+		cose    int
+	}{
+		{alg: RSASSA_PSS_SHA_256, keySpec: RSA_2048, hash: crypto.SHA256, jws: "PS256", cose: -37},
+		{alg: RSASSA_PSS_SHA_384, keySpec: RSA_3072, hash: crypto.SHA384, jws: "PS384", cose: -38},
+		{alg: RSASSA_PSS_SHA_512, keySpec: RSA_4096, hash: crypto.SHA512, jws: "PS512", cose: -39},
+		{alg: ECDSA_SHA_256, keySpec: EC_256, hash: crypto.SHA256, jws: "ES256", cose: -7},
+		{alg: ECDSA_SHA_384, keySpec: EC_384, hash: crypto.SHA384, jws: "ES384", cose: -35},
+		{alg: ECDSA_SHA_512, keySpec: EC_512, hash: crypto.SHA512, jws: "ES512", cose: -36},
+		{alg: EDDSA_ED25519, keySpec: ED25519, hash: crypto.SHA512, jws: "EdDSA", cose: -8},
+	}
+	if got := len(SignatureAlgorithms()); got != len(tests) {
+		t.Errorf("len(SignatureAlgorithms()) = %d, want %d", got, len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.alg), func(t *testing.T) {
+			if got := tt.keySpec.SignatureAlgorithm(); got != tt.alg {
+				t.Errorf("KeySpec.SignatureAlgorithm() = %v, want %v", got, tt.alg)
+			}
+			if got := tt.alg.Hash().HashFunc(); got != tt.hash {
+				t.Errorf("Hash() = %v, want %v", got, tt.hash)
+			}
+			if got := tt.alg.JWS(); got != tt.jws {
+				t.Errorf("JWS() = %v, want %v", got, tt.jws)
+			}
+			if got := tt.alg.COSE(); got != tt.cose {
+				t.Errorf("COSE() = %v, want %v", got, tt.cose)
+			}
+			if got := NewSignatureAlgorithmJWS(tt.jws); got != tt.alg {
+				t.Errorf("NewSignatureAlgorithmJWS() = %v, want %v", got, tt.alg)
+			}
+			if got := NewSignatureAlgorithmCOSE(tt.cose); got != tt.alg {
+				t.Errorf("NewSignatureAlgorithmCOSE() = %v, want %v", got, tt.alg)
+			}
+		})
+	}
+}
+
+func TestSignatureAlgorithms_Unsupported(t *testing.T) {
+	const alg SignatureAlgorithm = "RSASSA_PKCS1_V1_5_SHA_256"
+	if _, ok := LookupSignatureAlgorithm(alg); ok {
+		t.Errorf("LookupSignatureAlgorithm() ok = true, want false")
+	}
+	if got := alg.Hash(); got != "" {
+		t.Errorf("Hash() = %v, want empty", got)
+	}
+	if got := alg.JWS(); got != "" {
+		t.Errorf("JWS() = %v, want empty", got)
+	}
+	if got := alg.COSE(); got != 0 {
+		t.Errorf("COSE() = %v, want 0", got)
+	}
+	if got := KeySpec("RSA_1024").SignatureAlgorithm(); got != "" {
+		t.Errorf("KeySpec.SignatureAlgorithm() = %v, want empty", got)
+	}
+	if got := NewSignatureAlgorithmJWS("RS256"); got != "" {
+		t.Errorf("NewSignatureAlgorithmJWS() = %v, want empty", got)
+	}
+	if got := NewSignatureAlgorithmCOSE(-257); got != "" {
+		t.Errorf("NewSignatureAlgorithmCOSE() = %v, want empty", got)
+	}
+
+	// the table cannot be modified through the returned algorithms
+	SignatureAlgorithms()[0].JWS = "RS256"
+	if got := RSASSA_PSS_SHA_256.JWS(); got != "PS256" {
+		t.Errorf("JWS() = %v, want PS256", got)
+	}
+}
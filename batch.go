@@ -0,0 +1,136 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBatchParallelism is the number of artifacts signed concurrently
+// by SignBatch if SignBatchOptions.Parallelism is not set.
+const DefaultBatchParallelism = 4
+
+// SignBatchOptions contains parameters for SignBatch.
+type SignBatchOptions struct {
+	SignOptions
+
+	// Resolve resolves an artifact reference to the descriptor to be signed,
+	// e.g. registry.RepositoryClient.GetManifestDescriptor.
+	Resolve func(ctx context.Context, reference string) (Descriptor, error)
+
+	// Store stores the signature of the artifact if present,
+	// e.g. by pushing it to the registry and linking it to the artifact.
+	Store func(ctx context.Context, reference string, desc Descriptor, signature []byte) error
+
+	// Parallelism is the maximum number of artifacts signed concurrently.
+	// DefaultBatchParallelism is used if not positive.
+	Parallelism int
+}
+
+// SignBatchResult is the result of signing a single artifact with SignBatch.
+type SignBatchResult struct {
+	// Reference is the reference of the artifact.
+	Reference string
+
+	// Descriptor is the descriptor of the artifact.
+	Descriptor Descriptor
+
+	// Signature is the signature of the artifact.
+	Signature []byte
+
+	// Err is non-nil if the artifact failed to be signed.
+	Err error
+}
+
+// BatchError is returned by SignBatch if any artifact failed to be signed.
+type BatchError struct {
+	// Errs contains the error of each failed artifact.
+	Errs []error
+
+	// Total is the number of artifacts of the batch.
+	Total int
+}
+
+// Error returns the number of failures and the first error.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("failed to sign %d of %d artifacts: %v", len(e.Errs), e.Total, e.Errs[0])
+}
+
+// Unwrap returns the first error.
+func (e *BatchError) Unwrap() error {
+	return e.Errs[0]
+}
+
+// SignBatch resolves and signs the artifacts referenced by refs concurrently,
+// with at most opts.Parallelism artifacts in flight.
+//
+// A result is returned for each reference, in the order of refs.
+// The returned error is a *BatchError aggregating the per-artifact errors
+// if any artifact failed to be signed.
+func SignBatch(ctx context.Context, signer Signer, refs []string, opts SignBatchOptions) ([]SignBatchResult, error) {
+	if signer == nil {
+		return nil, errors.New("nil signer")
+	}
+	if opts.Resolve == nil {
+		return nil, errors.New("nil artifact resolver")
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultBatchParallelism
+	}
+
+	results := make([]SignBatchResult, len(refs))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		results[i].Reference = ref
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(result *SignBatchResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result.Descriptor, result.Signature, result.Err = signArtifact(ctx, signer, result.Reference, opts)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Reference, result.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return results, &BatchError{Errs: errs, Total: len(refs)}
+	}
+	return results, nil
+}
+
+// signArtifact resolves, signs and stores a single artifact of a batch.
+func signArtifact(ctx context.Context, signer Signer, ref string, opts SignBatchOptions) (Descriptor, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return Descriptor{}, nil, err
+	}
+	desc, err := opts.Resolve(ctx, ref)
+	if err != nil {
+		return Descriptor{}, nil, fmt.Errorf("failed to resolve artifact: %w", err)
+	}
+	sig, err := signer.Sign(ctx, desc, opts.SignOptions)
+	if err != nil {
+		return desc, nil, fmt.Errorf("failed to sign artifact: %w", err)
+	}
+	if opts.Store != nil && !opts.DryRun {
+		if err := opts.Store(ctx, ref, desc, sig); err != nil {
+			return desc, sig, fmt.Errorf("failed to store signature: %w", err)
+		}
+	}
+	return desc, sig, nil
+}
@@ -0,0 +1,111 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+type mockSigner struct {
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (s *mockSigner) Sign(ctx context.Context, desc Descriptor, opts SignOptions) ([]byte, error) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&s.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&s.maxInFlight, max, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if desc.Size < 0 {
+		return nil, errors.New("sign failed")
+	}
+	return []byte(desc.Digest), nil
+}
+
+func resolve(ctx context.Context, ref string) (Descriptor, error) {
+	switch ref {
+	case "missing":
+		return Descriptor{}, errors.New("not found")
+	case "unsignable":
+		return Descriptor{Size: -1}, nil
+	}
+	return Descriptor{Digest: digest.FromString(ref)}, nil
+}
+
+func TestSignBatch(t *testing.T) {
+	refs := []string{"a", "missing", "b", "unsignable", "c", "d", "e", "f"}
+	signer := &mockSigner{}
+	var stored int32
+	results, err := SignBatch(context.Background(), signer, refs, SignBatchOptions{
+		Resolve: resolve,
+		Store: func(ctx context.Context, ref string, desc Descriptor, sig []byte) error {
+			atomic.AddInt32(&stored, 1)
+			return nil
+		},
+		Parallelism: 2,
+	})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("SignBatch() error = %v, want *BatchError", err)
+	}
+	if len(batchErr.Errs) != 2 || batchErr.Total != len(refs) {
+		t.Errorf("SignBatch() error = %v, want 2 of %d failures", err, len(refs))
+	}
+	if len(results) != len(refs) {
+		t.Fatalf("SignBatch() returned %d results, want %d", len(results), len(refs))
+	}
+	for i, result := range results {
+		if result.Reference != refs[i] {
+			t.Errorf("results[%d].Reference = %v, want %v", i, result.Reference, refs[i])
+		}
+		wantErr := refs[i] == "missing" || refs[i] == "unsignable"
+		if (result.Err != nil) != wantErr {
+			t.Errorf("results[%d].Err = %v, wantErr %v", i, result.Err, wantErr)
+		}
+		if !wantErr && string(result.Signature) != string(digest.FromString(refs[i])) {
+			t.Errorf("results[%d].Signature = %s, want signature of %s", i, result.Signature, refs[i])
+		}
+	}
+	if stored != 6 {
+		t.Errorf("Store() called %d times, want 6", stored)
+	}
+	if signer.maxInFlight > 2 {
+		t.Errorf("SignBatch() signed %d artifacts concurrently, want at most 2", signer.maxInFlight)
+	}
+}
+
+func TestSignBatch_StoreFailed(t *testing.T) {
+	_, err := SignBatch(context.Background(), &mockSigner{}, []string{"a"}, SignBatchOptions{
+		Resolve: resolve,
+		Store: func(ctx context.Context, ref string, desc Descriptor, sig []byte) error {
+			return errors.New("push failed")
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "push failed") {
+		t.Fatalf("SignBatch() error = %v, want push failed", err)
+	}
+}
+
+func TestSignBatch_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := SignBatch(ctx, &mockSigner{}, []string{"a", "b"}, SignBatchOptions{Resolve: resolve})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SignBatch() error = %v, want %v", err, context.Canceled)
+	}
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want %v", i, result.Err, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SignBlob signs the content read from reader, e.g. a file, as a blob of
+// media type mediaType.
+// The content is hashed with SHA-256 into the descriptor signed by signer.
+// Use DescriptorFromReader to pick another digest algorithm or to report
+// the progress of large blobs.
+// It returns the descriptor of the blob and the signature envelope.
+func SignBlob(ctx context.Context, signer Signer, reader io.Reader, mediaType string, opts SignOptions) (Descriptor, []byte, error) {
+	if signer == nil {
+		return Descriptor{}, nil, errors.New("nil signer")
+	}
+	if mediaType == "" {
+		return Descriptor{}, nil, errors.New("missing blob media type")
+	}
+	desc, err := DescriptorFromReader(reader, mediaType, DigestOptions{})
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	sig, err := signer.Sign(ctx, desc, opts)
+	if err != nil {
+		return desc, nil, err
+	}
+	return desc, sig, nil
+}
+
+// VerifyBlob verifies the content read from reader against the signature
+// envelope signature.
+// The signature is verified by verifier, and the digest and the size of the
+// content must match the descriptor signed.
+func VerifyBlob(ctx context.Context, verifier Verifier, reader io.Reader, signature []byte, opts VerifyOptions) (*VerificationOutcome, error) {
+	if verifier == nil {
+		return nil, errors.New("nil verifier")
+	}
+	outcome, err := verifier.Verify(ctx, signature, opts)
+	if err != nil {
+		return outcome, err
+	}
+	desc := outcome.Descriptor
+	dgst, size, err := digestReader(reader, desc.Digest.Algorithm(), nil)
+	if err != nil {
+		return outcome, outcome.Fail(CheckIntegrity, err)
+	}
+	if dgst != desc.Digest || size != desc.Size {
+		return outcome, outcome.Fail(CheckIntegrity, fmt.Errorf("blob of digest %s and size %d does not match the signed content of digest %s and size %d", dgst, size, desc.Digest, desc.Size))
+	}
+	return outcome, nil
+}
@@ -0,0 +1,68 @@
+package notation
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// descriptorSigner signs a descriptor by encoding it in JSON.
+type descriptorSigner struct{}
+
+func (descriptorSigner) Sign(ctx context.Context, desc Descriptor, opts SignOptions) ([]byte, error) {
+	return json.Marshal(desc)
+}
+
+// descriptorVerifier verifies the signatures of descriptorSigner.
+type descriptorVerifier struct{}
+
+func (descriptorVerifier) Verify(ctx context.Context, signature []byte, opts VerifyOptions) (*VerificationOutcome, error) {
+	outcome := &VerificationOutcome{}
+	if err := json.Unmarshal(signature, &outcome.Descriptor); err != nil {
+		return outcome, outcome.Fail(CheckIntegrity, err)
+	}
+	outcome.Pass(CheckIntegrity)
+	return outcome, nil
+}
+
+func TestSignVerifyBlob(t *testing.T) {
+	ctx := context.Background()
+	const content = "release tarball"
+	desc, sig, err := SignBlob(ctx, descriptorSigner{}, strings.NewReader(content), "application/gzip", SignOptions{})
+	if err != nil {
+		t.Fatalf("SignBlob() error = %v", err)
+	}
+	want := Descriptor{
+		MediaType: "application/gzip",
+		Digest:    digest.FromString(content),
+		Size:      int64(len(content)),
+	}
+	if !desc.Equal(want) {
+		t.Errorf("SignBlob() descriptor = %v, want %v", desc, want)
+	}
+
+	outcome, err := VerifyBlob(ctx, descriptorVerifier{}, strings.NewReader(content), sig, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyBlob() error = %v", err)
+	}
+	if !outcome.Descriptor.Equal(want) {
+		t.Errorf("VerifyBlob() descriptor = %v, want %v", outcome.Descriptor, want)
+	}
+
+	outcome, err = VerifyBlob(ctx, descriptorVerifier{}, strings.NewReader("tampered"), sig, VerifyOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not match the signed content") {
+		t.Fatalf("VerifyBlob() error = %v, want content mismatch", err)
+	}
+	if result := outcome.Result(CheckIntegrity); result.Status != VerificationFailed {
+		t.Errorf("VerifyBlob() integrity = %v, want failed", result)
+	}
+}
+
+func TestSignBlob_MissingMediaType(t *testing.T) {
+	if _, _, err := SignBlob(context.Background(), descriptorSigner{}, strings.NewReader("blob"), "", SignOptions{}); err == nil {
+		t.Fatalf("SignBlob() without media type succeeded, want error")
+	}
+}
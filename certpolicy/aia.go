@@ -0,0 +1,152 @@
+package certpolicy
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/notaryproject/notation-go/log"
+)
+
+// DefaultAIAMaxDepth is the maximum number of certificates fetched to
+// complete a chain if AIAFetcher.MaxDepth is not set.
+const DefaultAIAMaxDepth = 5
+
+// maxAIACertificateSize is the maximum size of a fetched certificate.
+const maxAIACertificateSize = 1 << 20
+
+// maxAIACacheSize is the maximum number of fetched certificates cached.
+const maxAIACacheSize = 256
+
+// AIAFetcher completes certificate chains consisting of only a leaf, or
+// missing intermediates, by fetching the issuer certificates from the
+// Authority Information Access URLs of the certificates.
+// Fetched certificates are cached by URL, up to maxAIACacheSize certificates,
+// for the lifetime of the fetcher.
+//
+// A nil fetcher fetches nothing, which disables AIA fetching.
+type AIAFetcher struct {
+	// Client is the HTTP client used to fetch certificates.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// MaxDepth is the maximum number of certificates fetched per chain.
+	// DefaultAIAMaxDepth is used if not positive.
+	MaxDepth int
+
+	mu    sync.Mutex
+	cache map[string]*x509.Certificate
+}
+
+// NewAIAFetcher creates an AIA fetcher fetching certificates with client.
+func NewAIAFetcher(client *http.Client) *AIAFetcher {
+	return &AIAFetcher{Client: client}
+}
+
+// Complete returns chain with the issuer certificates fetched from the AIA
+// URLs of its last certificate appended, until a self-issued certificate
+// is reached. Fetching is best effort: certificates which cannot be fetched
+// or do not sign the chain are skipped, and the chain is completed as far as
+// possible. chain is not modified.
+func (f *AIAFetcher) Complete(ctx context.Context, chain []*x509.Certificate) []*x509.Certificate {
+	if f == nil || len(chain) == 0 {
+		return chain
+	}
+	// cap the capacity so that appending never writes to the array of the
+	// caller
+	chain = chain[:len(chain):len(chain)]
+	maxDepth := f.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultAIAMaxDepth
+	}
+	logger := log.GetLogger(ctx)
+	for depth := 0; depth < maxDepth; depth++ {
+		child := chain[len(chain)-1]
+		if isSelfIssued(child) {
+			break
+		}
+		issuer := f.fetchIssuer(ctx, child)
+		if issuer == nil {
+			logger.Debugf("no issuer of certificate %q could be fetched from %v", child.Subject, child.IssuingCertificateURL)
+			break
+		}
+		chain = append(chain, issuer)
+	}
+	return chain
+}
+
+// fetchIssuer returns the first certificate fetched from the AIA URLs of
+// child which signed child, or nil if none.
+func (f *AIAFetcher) fetchIssuer(ctx context.Context, child *x509.Certificate) *x509.Certificate {
+	for _, url := range child.IssuingCertificateURL {
+		cert, err := f.fetch(ctx, url)
+		if err != nil {
+			log.GetLogger(ctx).Debugf("failed to fetch the issuer of certificate %q from %q: %v", child.Subject, url, err)
+			continue
+		}
+		if err := child.CheckSignatureFrom(cert); err != nil {
+			log.GetLogger(ctx).Debugf("certificate %q fetched from %q did not sign certificate %q: %v", cert.Subject, url, child.Subject, err)
+			continue
+		}
+		return cert
+	}
+	return nil
+}
+
+// fetch fetches the certificate at url, from the cache if fetched already.
+func (f *AIAFetcher) fetch(ctx context.Context, url string) (*x509.Certificate, error) {
+	f.mu.Lock()
+	cert, ok := f.cache[url]
+	f.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAIACertificateSize))
+	if err != nil {
+		return nil, err
+	}
+	// AIA certificates are DER encoded, though some servers serve PEM.
+	if block, _ := pem.Decode(data); block != nil && block.Type == "CERTIFICATE" {
+		data = block.Bytes
+	}
+	cert, err = x509.ParseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	if f.cache == nil {
+		f.cache = make(map[string]*x509.Certificate)
+	}
+	if len(f.cache) >= maxAIACacheSize {
+		// evict an arbitrary certificate
+		for cached := range f.cache {
+			delete(f.cache, cached)
+			break
+		}
+	}
+	f.cache[url] = cert
+	f.mu.Unlock()
+	return cert, nil
+}
@@ -0,0 +1,114 @@
+package certpolicy
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// issueWithAIA issues a certificate as issue, with the AIA URL aia.
+func issueWithAIA(t *testing.T, name string, isCA bool, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer, aia string) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		IssuingCertificateURL: []string{aia},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestAIAFetcher_Complete(t *testing.T) {
+	rootKey, interKey, leafKey := newKey(t), newKey(t), newKey(t)
+	root := issue(t, "root", true, rootKey, nil, nil)
+	var inter *x509.Certificate
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/intermediate.crt":
+			w.Write(inter.Raw)
+		case "/root.pem":
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: root.Raw})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	inter = issueWithAIA(t, "intermediate", true, interKey, root, rootKey, server.URL+"/root.pem")
+	leaf := issueWithAIA(t, "leaf", false, leafKey, inter, interKey, server.URL+"/intermediate.crt")
+	broken := issueWithAIA(t, "broken", false, leafKey, inter, interKey, server.URL+"/missing.crt")
+
+	ctx := context.Background()
+	var disabled *AIAFetcher
+	if got := disabled.Complete(ctx, []*x509.Certificate{leaf}); len(got) != 1 {
+		t.Errorf("Complete() with a nil fetcher = %v, want the leaf only", subjects(got))
+	}
+
+	fetcher := NewAIAFetcher(server.Client())
+	want := []*x509.Certificate{leaf, inter, root}
+	for i := 0; i < 2; i++ {
+		if got := fetcher.Complete(ctx, []*x509.Certificate{leaf}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Complete() = %v, want %v", subjects(got), subjects(want))
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Complete() sent %d requests, want 2 with caching", requests)
+	}
+
+	if got := fetcher.Complete(ctx, []*x509.Certificate{broken}); len(got) != 1 {
+		t.Errorf("Complete() = %v, want the unresolvable leaf only", subjects(got))
+	}
+
+	// the array of the caller is not written to
+	chain := make([]*x509.Certificate, 1, 3)
+	chain[0] = leaf
+	if got := fetcher.Complete(ctx, chain); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Complete() = %v, want %v", subjects(got), subjects(want))
+	}
+	if extra := chain[:cap(chain)]; extra[1] != nil || extra[2] != nil {
+		t.Errorf("Complete() wrote %v to the array of the chain", subjects(extra))
+	}
+}
+
+func TestAIAFetcher_CacheSize(t *testing.T) {
+	key := newKey(t)
+	root := issue(t, "root", true, key, nil, nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(root.Raw)
+	}))
+	defer server.Close()
+
+	fetcher := NewAIAFetcher(server.Client())
+	for i := 0; i < maxAIACacheSize+10; i++ {
+		if _, err := fetcher.fetch(context.Background(), fmt.Sprintf("%s/%d.crt", server.URL, i)); err != nil {
+			t.Fatalf("fetch() error = %v", err)
+		}
+	}
+	if len(fetcher.cache) != maxAIACacheSize {
+		t.Errorf("fetcher cached %d certificates, want %d", len(fetcher.cache), maxAIACacheSize)
+	}
+}
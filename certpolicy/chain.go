@@ -0,0 +1,109 @@
+package certpolicy
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// BuildChain orders certs, as returned by plugins, from the signing
+// certificate to the root, dropping duplicates and certificates which are
+// not part of the chain of the signing certificate.
+//
+// The signing certificate is the first certificate of certs which does not
+// issue any other certificate of certs. Each certificate of the returned
+// chain is verified to be signed by the next one, so that a broken chain
+// fails with the offending certificates rather than with an unknown
+// authority error at verification time.
+func BuildChain(certs []*x509.Certificate) ([]*x509.Certificate, error) {
+	certs = dedupe(certs)
+	if len(certs) == 0 {
+		return nil, errors.New("certificate chain is empty")
+	}
+	leaf, err := findLeaf(certs)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make([]bool, len(certs))
+	used[leaf] = true
+	chain := []*x509.Certificate{certs[leaf]}
+	for child := certs[leaf]; !isSelfIssued(child); {
+		parent, err := findParent(child, certs, used)
+		if err != nil {
+			return nil, err
+		}
+		if parent < 0 {
+			// the chain ends without its root, which is resolved from the
+			// trust store at verification time.
+			break
+		}
+		used[parent] = true
+		child = certs[parent]
+		chain = append(chain, child)
+	}
+	return chain, nil
+}
+
+// dedupe returns certs without duplicates, in their original order.
+func dedupe(certs []*x509.Certificate) []*x509.Certificate {
+	var unique []*x509.Certificate
+	for _, cert := range certs {
+		duplicate := false
+		for _, u := range unique {
+			if bytes.Equal(u.Raw, cert.Raw) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, cert)
+		}
+	}
+	return unique
+}
+
+// findLeaf returns the index of the first certificate which does not issue
+// any other certificate.
+func findLeaf(certs []*x509.Certificate) (int, error) {
+	for i, cert := range certs {
+		issuer := false
+		for j, other := range certs {
+			if i != j && !isSelfIssued(other) && bytes.Equal(other.RawIssuer, cert.RawSubject) {
+				issuer = true
+				break
+			}
+		}
+		if !issuer {
+			return i, nil
+		}
+	}
+	return -1, errors.New("certificate chain has no signing certificate: every certificate issues another one")
+}
+
+// findParent returns the index of the unused certificate which signed child,
+// or -1 if no certificate of certs is named as the issuer of child.
+func findParent(child *x509.Certificate, certs []*x509.Certificate, used []bool) (int, error) {
+	var lastErr error
+	var issuer *x509.Certificate
+	for i, cert := range certs {
+		if used[i] || !bytes.Equal(child.RawIssuer, cert.RawSubject) {
+			continue
+		}
+		if err := child.CheckSignatureFrom(cert); err != nil {
+			lastErr, issuer = err, cert
+			continue
+		}
+		return i, nil
+	}
+	if lastErr != nil {
+		return -1, fmt.Errorf("certificate %q is issued by %q, but is not signed by it: %w", child.Subject, issuer.Subject, lastErr)
+	}
+	return -1, nil
+}
+
+// isSelfIssued reports whether the issuer of cert is its subject, e.g. a root.
+func isSelfIssued(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject)
+}
@@ -0,0 +1,104 @@
+package certpolicy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issue issues a certificate named name for key, signed by the parent
+// certificate and key, or self-signed if parent is nil.
+func issue(t *testing.T, name string, isCA bool, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func newKey(t *testing.T) crypto.Signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestBuildChain(t *testing.T) {
+	rootKey, interKey, leafKey := newKey(t), newKey(t), newKey(t)
+	root := issue(t, "root", true, rootKey, nil, nil)
+	inter := issue(t, "intermediate", true, interKey, root, rootKey)
+	leaf := issue(t, "leaf", false, leafKey, inter, interKey)
+	unrelated := issue(t, "unrelated", true, newKey(t), nil, nil)
+	// an intermediate named as the issuer of the leaf, with another key
+	impostor := issue(t, "intermediate", true, newKey(t), root, rootKey)
+
+	tests := []struct {
+		name    string
+		certs   []*x509.Certificate
+		want    []*x509.Certificate
+		wantErr string
+	}{
+		{name: "ordered", certs: []*x509.Certificate{leaf, inter, root}, want: []*x509.Certificate{leaf, inter, root}},
+		{name: "unordered", certs: []*x509.Certificate{root, leaf, inter}, want: []*x509.Certificate{leaf, inter, root}},
+		{name: "duplicates and extra", certs: []*x509.Certificate{inter, leaf, root, inter, leaf, unrelated}, want: []*x509.Certificate{leaf, inter, root}},
+		{name: "without root", certs: []*x509.Certificate{inter, leaf}, want: []*x509.Certificate{leaf, inter}},
+		{name: "self-signed", certs: []*x509.Certificate{unrelated}, want: []*x509.Certificate{unrelated}},
+		{name: "empty", wantErr: "empty"},
+		{name: "wrong issuer", certs: []*x509.Certificate{leaf, impostor, root}, wantErr: `certificate "CN=leaf" is issued by "CN=intermediate", but is not signed by it`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildChain(tt.certs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BuildChain() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildChain() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildChain() = %v, want %v", subjects(got), subjects(tt.want))
+			}
+		})
+	}
+}
+
+func subjects(certs []*x509.Certificate) []string {
+	var names []string
+	for _, cert := range certs {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return names
+}
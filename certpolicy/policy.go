@@ -0,0 +1,166 @@
+// Package certpolicy defines the requirements on the certificate chains of
+// signatures, as defined in
+// https://github.com/notaryproject/notaryproject/blob/main/signature-specification.md#certificate-requirements,
+// with additional knobs for stricter deployments.
+//
+// The same policy is enforced when signing and when verifying.
+package certpolicy
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// Default key sizes of the specification.
+const (
+	DefaultMinRSAKeySize   = 2048
+	DefaultMinECDSAKeySize = 256
+)
+
+var oidExtensionKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 15}
+
+// Policy is a certificate chain validation policy.
+// The zero value enforces the requirements of the specification.
+type Policy struct {
+	// KeyUsage are the key usage bits required in the signing certificate.
+	// x509.KeyUsageDigitalSignature is required if zero.
+	KeyUsage x509.KeyUsage
+
+	// ExtKeyUsages are the extended key usages required in the signing
+	// certificate. x509.ExtKeyUsageCodeSigning is required if empty.
+	ExtKeyUsages []x509.ExtKeyUsage
+
+	// MinRSAKeySize is the minimum size in bits of RSA keys of the signing
+	// certificate. DefaultMinRSAKeySize is used if zero.
+	MinRSAKeySize int
+
+	// MinECDSAKeySize is the minimum size in bits of ECDSA keys of the
+	// signing certificate. DefaultMinECDSAKeySize is used if zero.
+	MinECDSAKeySize int
+
+	// SignatureAlgorithms are the signature algorithms allowed on the
+	// certificates of the chain. Any algorithm is allowed if empty.
+	SignatureAlgorithms []x509.SignatureAlgorithm
+
+	// MaxChainLength is the maximum number of certificates in the chain.
+	// The length is not limited if zero.
+	MaxChainLength int
+}
+
+// Default is the policy enforcing the requirements of the specification.
+var Default = &Policy{}
+
+// ValidateChain validates the certificate chain, whose first certificate is
+// the signing certificate. A nil policy is the Default policy.
+func (p *Policy) ValidateChain(certs []*x509.Certificate) error {
+	if p == nil {
+		p = Default
+	}
+	if len(certs) == 0 {
+		return errors.New("certificate chain is empty")
+	}
+	if p.MaxChainLength > 0 && len(certs) > p.MaxChainLength {
+		return fmt.Errorf("certificate chain has %d certificates, exceeding the maximum of %d", len(certs), p.MaxChainLength)
+	}
+	if len(p.SignatureAlgorithms) > 0 {
+		for _, cert := range certs {
+			if !p.allowsSignatureAlgorithm(cert.SignatureAlgorithm) {
+				return fmt.Errorf("certificate %q is signed with disallowed signature algorithm %v", cert.Subject, cert.SignatureAlgorithm)
+			}
+		}
+	}
+	return p.ValidateSigningCertificate(certs[0])
+}
+
+// ValidateSigningCertificate validates the signing certificate.
+// A nil policy is the Default policy.
+func (p *Policy) ValidateSigningCertificate(cert *x509.Certificate) error {
+	if p == nil {
+		p = Default
+	}
+	if keyUsage := p.keyUsage(); cert.KeyUsage&keyUsage != keyUsage {
+		if keyUsage == x509.KeyUsageDigitalSignature {
+			return errors.New("keyUsage must have the bit positions for digitalSignature set")
+		}
+		return fmt.Errorf("keyUsage must have the bit positions %b set", keyUsage)
+	}
+	for _, required := range p.RequiredExtKeyUsages() {
+		if !hasExtKeyUsage(cert, required) {
+			return fmt.Errorf("extKeyUsage must contain %d", required)
+		}
+	}
+	for _, e := range cert.Extensions {
+		if e.Id.Equal(oidExtensionKeyUsage) {
+			if !e.Critical {
+				return errors.New("the keyUsage extension must be marked critical")
+			}
+			break
+		}
+	}
+	if cert.BasicConstraintsValid && cert.IsCA {
+		return errors.New("if the basicConstraints extension is present, the CA field MUST be set false")
+	}
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if min := p.minRSAKeySize(); key.N.BitLen() < min {
+			return fmt.Errorf("RSA public key length must be %d bits or higher", min)
+		}
+	case *ecdsa.PublicKey:
+		if min := p.minECDSAKeySize(); key.Params().N.BitLen() < min {
+			return fmt.Errorf("ECDSA public key length must be %d bits or higher", min)
+		}
+	}
+	return nil
+}
+
+// RequiredExtKeyUsages returns the extended key usages required in the
+// signing certificate. A nil policy is the Default policy.
+func (p *Policy) RequiredExtKeyUsages() []x509.ExtKeyUsage {
+	if p == nil || len(p.ExtKeyUsages) == 0 {
+		return []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+	}
+	return p.ExtKeyUsages
+}
+
+func (p *Policy) keyUsage() x509.KeyUsage {
+	if p.KeyUsage == 0 {
+		return x509.KeyUsageDigitalSignature
+	}
+	return p.KeyUsage
+}
+
+func (p *Policy) minRSAKeySize() int {
+	if p.MinRSAKeySize == 0 {
+		return DefaultMinRSAKeySize
+	}
+	return p.MinRSAKeySize
+}
+
+func (p *Policy) minECDSAKeySize() int {
+	if p.MinECDSAKeySize == 0 {
+		return DefaultMinECDSAKeySize
+	}
+	return p.MinECDSAKeySize
+}
+
+func (p *Policy) allowsSignatureAlgorithm(alg x509.SignatureAlgorithm) bool {
+	for _, allowed := range p.SignatureAlgorithms {
+		if allowed == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func hasExtKeyUsage(cert *x509.Certificate, extKeyUsage x509.ExtKeyUsage) bool {
+	for _, ext := range cert.ExtKeyUsage {
+		if ext == extKeyUsage {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package certpolicy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newCert(t *testing.T, template *x509.Certificate, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+	template.SerialNumber = big.NewInt(1)
+	template.Subject = pkix.Name{CommonName: "test"}
+	template.NotBefore = time.Now()
+	template.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestPolicy_ValidateChain(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := func() *x509.Certificate {
+		return newCert(t, &x509.Certificate{
+			KeyUsage:    x509.KeyUsageDigitalSignature,
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		}, rsaKey)
+	}
+	rsaCert := valid()
+	ecCert := newCert(t, &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}, ecKey)
+	noCodeSigning := newCert(t, &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, rsaKey)
+	ca := newCert(t, &x509.Certificate{
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, rsaKey)
+
+	tests := []struct {
+		name    string
+		policy  *Policy
+		certs   []*x509.Certificate
+		wantErr bool
+	}{
+		{name: "default rsa", certs: []*x509.Certificate{rsaCert}},
+		{name: "default ecdsa", policy: Default, certs: []*x509.Certificate{ecCert}},
+		{name: "empty chain", wantErr: true},
+		{name: "no code signing", certs: []*x509.Certificate{noCodeSigning}, wantErr: true},
+		{name: "ca", certs: []*x509.Certificate{ca}, wantErr: true},
+		{name: "custom eku", policy: &Policy{ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, certs: []*x509.Certificate{noCodeSigning}},
+		{name: "rsa key size", policy: &Policy{MinRSAKeySize: 3072}, certs: []*x509.Certificate{rsaCert}, wantErr: true},
+		{name: "ecdsa key size", policy: &Policy{MinECDSAKeySize: 384}, certs: []*x509.Certificate{ecCert}, wantErr: true},
+		{name: "chain length", policy: &Policy{MaxChainLength: 1}, certs: []*x509.Certificate{rsaCert, ca}, wantErr: true},
+		{name: "signature algorithm", policy: &Policy{SignatureAlgorithms: []x509.SignatureAlgorithm{x509.SHA384WithRSA}}, certs: []*x509.Certificate{rsaCert}, wantErr: true},
+		{name: "allowed signature algorithm", policy: &Policy{SignatureAlgorithms: []x509.SignatureAlgorithm{x509.SHA256WithRSA}}, certs: []*x509.Certificate{rsaCert}},
+		{name: "key usage", policy: &Policy{KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment}, certs: []*x509.Certificate{rsaCert}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.ValidateChain(tt.certs); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/dir"
+)
+
+// Signature formats of the config file.
+const (
+	// SignatureFormatJWS is the JWS signature format.
+	SignatureFormatJWS = "jws"
+)
+
+// Environment variables overriding the config file.
+const (
+	// InsecureRegistriesEnv overrides Config.InsecureRegistries with a
+	// comma-separated list of registries.
+	InsecureRegistriesEnv = "NOTATION_INSECURE_REGISTRIES"
+
+	// CredentialsStoreEnv overrides Config.CredentialsStore.
+	CredentialsStoreEnv = "NOTATION_CREDENTIALS_STORE"
+
+	// SignatureFormatEnv overrides Config.SignatureFormat.
+	SignatureFormatEnv = "NOTATION_SIGNATURE_FORMAT"
+)
+
+// Config is the content of the config file config.json.
+type Config struct {
+	// InsecureRegistries are the registries, i.e. host:port, accessed over
+	// plain HTTP.
+	InsecureRegistries []string `json:"insecureRegistries"`
+
+	// CredentialsStore is the credential helper used for all registries.
+	CredentialsStore string `json:"credsStore,omitempty"`
+
+	// CredentialHelpers are the credential helpers of registries.
+	CredentialHelpers map[string]string `json:"credHelpers,omitempty"`
+
+	// SignatureFormat is the default format of the generated signatures.
+	// SignatureFormatJWS is used if empty.
+	SignatureFormat string `json:"signatureFormat,omitempty"`
+
+	// RegistryMirrors maps registries, i.e. host:port, to the mirrors the
+	// signatures of their artifacts are fetched from for verification.
+	// A mirror is a registry host optionally followed by the repository
+	// path prefix of a pull-through cache, e.g. mirror.example.com/dockerhub.
+	RegistryMirrors map[string]string `json:"registryMirrors,omitempty"`
+}
+
+// LoadConfig loads the config file at path, applies the environment variable
+// overrides, and validates the result.
+// If path is empty, the config file of notation, as returned by
+// dir.ConfigPath, is loaded. A missing file results in the default config.
+func LoadConfig(path string) (*Config, error) {
+	path, err := configFilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return parseConfig(data, path)
+}
+
+// LoadConfigFS loads the config file at path in fsys, applies the
+// environment variable overrides, and validates the result.
+// If path is empty, the config file of a notation config directory, i.e.
+// dir.ConfigFileName, is loaded, e.g. from the file system returned by
+// dir.ConfigFS. A missing file results in the default config.
+func LoadConfigFS(fsys fs.FS, path string) (*Config, error) {
+	if path == "" {
+		path = dir.ConfigFileName
+	}
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return parseConfig(data, path)
+}
+
+// parseConfig parses the config read from path, which is the default config
+// if data is nil, applies the environment variable overrides, and validates
+// the result.
+func parseConfig(data []byte, path string) (*Config, error) {
+	cfg := &Config{}
+	if data != nil {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config %q: %w", path, err)
+		}
+	}
+	cfg.applyEnv()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %q: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the config to the file at path, replacing it atomically.
+// If path is empty, the config file of notation is written.
+func (c *Config) Save(path string) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	path, err := configFilePath(path)
+	if err != nil {
+		return err
+	}
+	if c.InsecureRegistries == nil {
+		c.InsecureRegistries = []string{}
+	}
+	return saveJSON(path, c)
+}
+
+// Validate checks that the registries, the mirrors and the signature format
+// are valid.
+func (c *Config) Validate() error {
+	for _, registry := range c.InsecureRegistries {
+		if registry == "" || strings.Contains(registry, "://") || strings.Contains(registry, "/") {
+			return fmt.Errorf("insecure registry %q is not a registry host, e.g. localhost:5000", registry)
+		}
+	}
+	for registry, mirror := range c.RegistryMirrors {
+		if registry == "" || strings.Contains(registry, "://") || strings.Contains(registry, "/") {
+			return fmt.Errorf("mirrored registry %q is not a registry host, e.g. docker.io", registry)
+		}
+		if mirror == "" || strings.HasPrefix(mirror, "/") || strings.HasSuffix(mirror, "/") || strings.Contains(mirror, "://") {
+			return fmt.Errorf("mirror %q of registry %q is not a registry host optionally followed by a repository path prefix, e.g. mirror.example.com/dockerhub", mirror, registry)
+		}
+	}
+	if _, err := c.SignatureMediaType(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsRegistryInsecure reports whether registry, i.e. host:port, is accessed
+// over plain HTTP.
+func (c *Config) IsRegistryInsecure(registry string) bool {
+	for _, insecure := range c.InsecureRegistries {
+		if insecure == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// RegistryMirror returns the mirror of registry, i.e. host:port, and
+// whether it is mirrored.
+func (c *Config) RegistryMirror(registry string) (string, bool) {
+	mirror, ok := c.RegistryMirrors[registry]
+	return mirror, ok
+}
+
+// SignatureMediaType returns the envelope media type of the signature format.
+func (c *Config) SignatureMediaType() (string, error) {
+	switch c.SignatureFormat {
+	case "", SignatureFormatJWS:
+		return notation.MediaTypeJWSEnvelope, nil
+	}
+	return "", fmt.Errorf("unsupported signature format %q", c.SignatureFormat)
+}
+
+// applyEnv applies the environment variable overrides.
+func (c *Config) applyEnv() {
+	if value, ok := os.LookupEnv(InsecureRegistriesEnv); ok {
+		c.InsecureRegistries = nil
+		for _, registry := range strings.Split(value, ",") {
+			if registry = strings.TrimSpace(registry); registry != "" {
+				c.InsecureRegistries = append(c.InsecureRegistries, registry)
+			}
+		}
+	}
+	if value, ok := os.LookupEnv(CredentialsStoreEnv); ok {
+		c.CredentialsStore = value
+	}
+	if value, ok := os.LookupEnv(SignatureFormatEnv); ok {
+		c.SignatureFormat = value
+	}
+}
+
+// configFilePath returns path, or the config file of notation if path is
+// empty.
+func configFilePath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return dir.ConfigPath()
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/dir"
+)
+
+func TestLoadConfig(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(dir.ConfigEnv, configDir)
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if mediaType, _ := cfg.SignatureMediaType(); mediaType != notation.MediaTypeJWSEnvelope {
+		t.Errorf("SignatureMediaType() = %q, want %q", mediaType, notation.MediaTypeJWSEnvelope)
+	}
+
+	cfg.InsecureRegistries = []string{"localhost:5000"}
+	cfg.CredentialHelpers = map[string]string{"registry.example.com": "test"}
+	if err := cfg.Save(""); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("LoadConfig() = %+v, want %+v", got, cfg)
+	}
+	if !got.IsRegistryInsecure("localhost:5000") || got.IsRegistryInsecure("registry.example.com") {
+		t.Errorf("IsRegistryInsecure() does not match InsecureRegistries %v", got.InsecureRegistries)
+	}
+}
+
+func TestLoadConfigFS(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(dir.ConfigEnv, configDir)
+	fsys, err := dir.ConfigFS()
+	if err != nil {
+		t.Fatalf("ConfigFS() error = %v", err)
+	}
+
+	cfg, err := LoadConfigFS(fsys, "")
+	if err != nil {
+		t.Fatalf("LoadConfigFS() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("LoadConfigFS() = %+v, want the default config", cfg)
+	}
+	cfg.InsecureRegistries = []string{"localhost:5000"}
+	if err := cfg.Save(""); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadConfigFS(fsys, "")
+	if err != nil {
+		t.Fatalf("LoadConfigFS() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("LoadConfigFS() = %+v, want %+v", got, cfg)
+	}
+	keys, err := LoadSigningKeysFS(fsys, "")
+	if err != nil || len(keys.Keys) != 0 {
+		t.Errorf("LoadSigningKeysFS() = %+v, %v, want no keys", keys, err)
+	}
+}
+
+func TestLoadConfig_Env(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"insecureRegistries": ["localhost:5000"], "credsStore": "file"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(InsecureRegistriesEnv, "a.example.com, b.example.com:5000")
+	t.Setenv(CredentialsStoreEnv, "env")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := &Config{
+		InsecureRegistries: []string{"a.example.com", "b.example.com:5000"},
+		CredentialsStore:   "env",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", cfg, want)
+	}
+
+	t.Setenv(SignatureFormatEnv, "unknown")
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig() error = nil, want error for unsupported signature format")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "empty", cfg: Config{}},
+		{name: "jws", cfg: Config{SignatureFormat: SignatureFormatJWS, InsecureRegistries: []string{"localhost:5000"}}},
+		{name: "scheme", cfg: Config{InsecureRegistries: []string{"http://localhost:5000"}}, wantErr: true},
+		{name: "repository", cfg: Config{InsecureRegistries: []string{"localhost:5000/repo"}}, wantErr: true},
+		{name: "format", cfg: Config{SignatureFormat: "cose"}, wantErr: true},
+		{name: "mirror", cfg: Config{RegistryMirrors: map[string]string{"docker.io": "mirror.example.com/dockerhub"}}},
+		{name: "mirror repository", cfg: Config{RegistryMirrors: map[string]string{"docker.io/library": "mirror.example.com"}}, wantErr: true},
+		{name: "mirror scheme", cfg: Config{RegistryMirrors: map[string]string{"docker.io": "https://mirror.example.com"}}, wantErr: true},
+		{name: "empty mirror", cfg: Config{RegistryMirrors: map[string]string{"docker.io": ""}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
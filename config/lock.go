@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultLockTimeout is the time to wait for a configuration file locked by
+// another update.
+const DefaultLockTimeout = 10 * time.Second
+
+// lockRetryInterval is the interval between attempts to lock a file.
+const lockRetryInterval = 20 * time.Millisecond
+
+// errLocked is returned by tryLock if the file is locked by another open
+// file.
+var errLocked = errors.New("file is locked")
+
+// lockFile locks the file at path with an advisory lock of the operating
+// system on the lock file {path}.lock, waiting up to timeout for a
+// concurrent lock to be released. The lock is released by the operating
+// system if the process exits while holding it, so that a crashed update
+// does not leave a stale lock behind. The returned function releases the
+// lock.
+func lockFile(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %q: %w", path, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			return func() {
+				unlock(f)
+				f.Close()
+			}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %q: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %q: timed out waiting for %q to be released", path, lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// tryLock fails as file locking is not supported on this platform.
+func tryLock(f *os.File) error {
+	return fmt.Errorf("file locking is not supported on %s", runtime.GOOS)
+}
+
+// unlock fails as file locking is not supported on this platform.
+func unlock(f *os.File) error {
+	return fmt.Errorf("file locking is not supported on %s", runtime.GOOS)
+}
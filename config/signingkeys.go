@@ -0,0 +1,241 @@
+// Package config manages the configuration files of notation, such as the
+// signing keys file signingkeys.json.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/notaryproject/notation-go/dir"
+)
+
+// Signing keys errors
+var (
+	ErrKeyNotFound      = errors.New("signing key not found")
+	ErrKeyAlreadyExists = errors.New("signing key already exists")
+)
+
+// KeySuite is a named signing key, which is either a local key and
+// certificate pair, or a key of a signing plugin.
+type KeySuite struct {
+	// Name is the name of the key.
+	Name string `json:"name"`
+
+	// KeyPath is the path of the private key of a local key.
+	KeyPath string `json:"keyPath,omitempty"`
+
+	// CertificatePath is the path of the certificate chain of a local key.
+	CertificatePath string `json:"certPath,omitempty"`
+
+	// ID is the key ID of a plugin key.
+	ID string `json:"id,omitempty"`
+
+	// PluginName is the name of the plugin of a plugin key.
+	PluginName string `json:"pluginName,omitempty"`
+
+	// PluginConfig is the configuration passed to the plugin of a plugin key.
+	PluginConfig map[string]string `json:"pluginConfig,omitempty"`
+}
+
+// IsPlugin reports whether the key is a plugin key.
+func (k KeySuite) IsPlugin() bool {
+	return k.PluginName != ""
+}
+
+// Validate checks that the key is either a complete local key or a complete
+// plugin key.
+func (k KeySuite) Validate() error {
+	if k.Name == "" {
+		return errors.New("signing key name is empty")
+	}
+	local := k.KeyPath != "" || k.CertificatePath != ""
+	if k.IsPlugin() || k.ID != "" {
+		if local {
+			return fmt.Errorf("signing key %q cannot be both a local key and a plugin key", k.Name)
+		}
+		if k.PluginName == "" || k.ID == "" {
+			return fmt.Errorf("signing key %q requires both a plugin name and a key ID", k.Name)
+		}
+		return nil
+	}
+	if k.KeyPath == "" || k.CertificatePath == "" {
+		return fmt.Errorf("signing key %q requires both a key path and a certificate path", k.Name)
+	}
+	return nil
+}
+
+// SigningKeys is the content of the signing keys file.
+type SigningKeys struct {
+	// Default is the name of the default key.
+	Default string `json:"default,omitempty"`
+
+	// Keys are the signing keys.
+	Keys []KeySuite `json:"keys"`
+}
+
+// LoadSigningKeys loads the signing keys file at path.
+// If path is empty, the signing keys file of notation, as returned by
+// dir.SigningKeysPath, is loaded. A missing file results in no keys.
+func LoadSigningKeys(path string) (*SigningKeys, error) {
+	path, err := signingKeysPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &SigningKeys{}, nil
+		}
+		return nil, err
+	}
+	return parseSigningKeys(data, path)
+}
+
+// LoadSigningKeysFS loads the signing keys file at path in fsys.
+// If path is empty, the signing keys file of a notation config directory,
+// i.e. dir.SigningKeysFileName, is loaded, e.g. from the file system
+// returned by dir.ConfigFS. A missing file results in no keys.
+func LoadSigningKeysFS(fsys fs.FS, path string) (*SigningKeys, error) {
+	if path == "" {
+		path = dir.SigningKeysFileName
+	}
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &SigningKeys{}, nil
+		}
+		return nil, err
+	}
+	return parseSigningKeys(data, path)
+}
+
+// parseSigningKeys parses the signing keys read from path.
+func parseSigningKeys(data []byte, path string) (*SigningKeys, error) {
+	var keys SigningKeys
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse signing keys %q: %w", path, err)
+	}
+	return &keys, nil
+}
+
+// Save writes the signing keys to the file at path, replacing it atomically.
+// If path is empty, the signing keys file of notation is written.
+func (s *SigningKeys) Save(path string) error {
+	path, err := signingKeysPath(path)
+	if err != nil {
+		return err
+	}
+	if s.Keys == nil {
+		s.Keys = []KeySuite{}
+	}
+	return saveJSON(path, s)
+}
+
+// Get returns the key named name, or the default key if name is empty.
+// If the key is not found, the error is ErrKeyNotFound.
+func (s *SigningKeys) Get(name string) (KeySuite, error) {
+	if name == "" {
+		if s.Default == "" {
+			return KeySuite{}, fmt.Errorf("no default signing key: %w", ErrKeyNotFound)
+		}
+		name = s.Default
+	}
+	if i := s.index(name); i >= 0 {
+		return s.Keys[i], nil
+	}
+	return KeySuite{}, fmt.Errorf("%q: %w", name, ErrKeyNotFound)
+}
+
+// Add adds key, and sets it as the default key if markDefault is true.
+// If a key of the same name exists, the error is ErrKeyAlreadyExists.
+func (s *SigningKeys) Add(key KeySuite, markDefault bool) error {
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	if s.index(key.Name) >= 0 {
+		return fmt.Errorf("%q: %w", key.Name, ErrKeyAlreadyExists)
+	}
+	s.Keys = append(s.Keys, key)
+	if markDefault {
+		s.Default = key.Name
+	}
+	return nil
+}
+
+// Remove removes the key named name, and unsets the default key if it is
+// the removed key.
+// If the key is not found, the error is ErrKeyNotFound.
+func (s *SigningKeys) Remove(name string) error {
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("%q: %w", name, ErrKeyNotFound)
+	}
+	s.Keys = append(s.Keys[:i], s.Keys[i+1:]...)
+	if s.Default == name {
+		s.Default = ""
+	}
+	return nil
+}
+
+// SetDefault sets the key named name as the default key.
+// If the key is not found, the error is ErrKeyNotFound.
+func (s *SigningKeys) SetDefault(name string) error {
+	if s.index(name) < 0 {
+		return fmt.Errorf("%q: %w", name, ErrKeyNotFound)
+	}
+	s.Default = name
+	return nil
+}
+
+// index returns the index of the key named name, or -1 if not found.
+func (s *SigningKeys) index(name string) int {
+	for i, key := range s.Keys {
+		if key.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// UpdateSigningKeys loads the signing keys file at path, updates the keys
+// with fn, and saves them if fn succeeds.
+// If path is empty, the signing keys file of notation is updated.
+//
+// The file is locked for the whole update, so that concurrent updates,
+// including by other processes, do not overwrite each other.
+func UpdateSigningKeys(path string, fn func(keys *SigningKeys) error) error {
+	path, err := signingKeysPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path, DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	keys, err := LoadSigningKeys(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(keys); err != nil {
+		return err
+	}
+	return keys.Save(path)
+}
+
+// signingKeysPath returns path, or the signing keys file of notation if path
+// is empty.
+func signingKeysPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return dir.SigningKeysPath()
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go/dir"
+)
+
+var (
+	localKey = KeySuite{
+		Name:            "local",
+		KeyPath:         "/keys/local.key",
+		CertificatePath: "/keys/local.crt",
+	}
+	pluginKey = KeySuite{
+		Name:         "remote",
+		ID:           "key-id",
+		PluginName:   "kms",
+		PluginConfig: map[string]string{"region": "us-west-2"},
+	}
+)
+
+func TestKeySuite_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     KeySuite
+		wantErr bool
+	}{
+		{name: "local", key: localKey},
+		{name: "plugin", key: pluginKey},
+		{name: "no name", key: KeySuite{KeyPath: "k", CertificatePath: "c"}, wantErr: true},
+		{name: "no cert", key: KeySuite{Name: "k", KeyPath: "k"}, wantErr: true},
+		{name: "no key id", key: KeySuite{Name: "k", PluginName: "p"}, wantErr: true},
+		{name: "both", key: KeySuite{Name: "k", KeyPath: "k", CertificatePath: "c", ID: "id", PluginName: "p"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSigningKeys(t *testing.T) {
+	t.Setenv(dir.ConfigEnv, t.TempDir())
+	keys, err := LoadSigningKeys("")
+	if err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+	if len(keys.Keys) != 0 {
+		t.Fatalf("LoadSigningKeys() = %v, want no keys", keys.Keys)
+	}
+	if err := keys.Add(localKey, false); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := keys.Add(pluginKey, true); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := keys.Add(localKey, false); !errors.Is(err, ErrKeyAlreadyExists) {
+		t.Errorf("Add() error = %v, want ErrKeyAlreadyExists", err)
+	}
+	if err := keys.Save(""); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	keys, err = LoadSigningKeys("")
+	if err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+	if got, err := keys.Get(""); err != nil || got.Name != pluginKey.Name || got.PluginConfig["region"] != "us-west-2" {
+		t.Errorf("Get() = %+v, %v, want default key %q", got, err, pluginKey.Name)
+	}
+	if err := keys.SetDefault(localKey.Name); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	if err := keys.SetDefault("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("SetDefault() error = %v, want ErrKeyNotFound", err)
+	}
+	if err := keys.Remove(localKey.Name); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if keys.Default != "" {
+		t.Errorf("Remove() kept the removed default key %q", keys.Default)
+	}
+	if _, err := keys.Get(localKey.Name); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Get() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestUpdateSigningKeys_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signingkeys.json")
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- UpdateSigningKeys(path, func(keys *SigningKeys) error {
+				key := localKey
+				key.Name = fmt.Sprintf("key-%d", i)
+				return keys.Add(key, false)
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateSigningKeys() error = %v", err)
+		}
+	}
+	keys, err := LoadSigningKeys(path)
+	if err != nil {
+		t.Fatalf("LoadSigningKeys() error = %v", err)
+	}
+	if len(keys.Keys) != n {
+		t.Errorf("UpdateSigningKeys() saved %d keys, want %d", len(keys.Keys), n)
+	}
+}
+
+func TestLockFile_Timeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signingkeys.json")
+	unlock, err := lockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+	if _, err := lockFile(path, 50*time.Millisecond); err == nil {
+		t.Fatalf("lockFile() should time out while locked")
+	}
+	unlock()
+	unlock, err = lockFile(path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v after unlock", err)
+	}
+	unlock()
+}
+
+func TestLockFile_Stale(t *testing.T) {
+	// a lock file left behind by a crashed process does not hold the lock
+	path := filepath.Join(t.TempDir(), "signingkeys.json")
+	if err := os.WriteFile(path+".lock", nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := lockFile(path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,85 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	// DetachedSignatureExtension is the file extension of detached signatures.
+	DetachedSignatureExtension = ".sig"
+
+	// detachedMediaTypeExtension is the file extension of the file next to
+	// a detached signature containing its envelope media type.
+	detachedMediaTypeExtension = ".mediatype"
+)
+
+// DetachedSignaturePath returns the path of the detached signature of the
+// content of digest dgst in dir, i.e. <algorithm>-<encoded>.sig.
+// The algorithm and the encoded digest are separated by a dash since colons
+// are not allowed in file names on all platforms.
+func DetachedSignaturePath(dir string, dgst digest.Digest) string {
+	name := dgst.Algorithm().String() + "-" + dgst.Encoded() + DetachedSignatureExtension
+	return filepath.Join(dir, name)
+}
+
+// WriteDetachedSignature writes the signature envelope of desc to dir, along
+// with its envelope media type, e.g. MediaTypeJWSEnvelope.
+// It returns the path of the detached signature.
+func WriteDetachedSignature(dir string, desc Descriptor, envelope []byte, mediaType string) (string, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("invalid descriptor digest: %w", err)
+	}
+	if mediaType == "" {
+		return "", errors.New("missing envelope media type")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := DetachedSignaturePath(dir, desc.Digest)
+	if err := os.WriteFile(path, envelope, 0644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path+detachedMediaTypeExtension, []byte(mediaType), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadDetachedSignature reads the detached signature at path, and returns
+// the signature envelope with its media type.
+// MediaTypeJWSEnvelope is assumed if the media type is not recorded.
+func ReadDetachedSignature(path string) ([]byte, string, error) {
+	envelope, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType, err := os.ReadFile(path + detachedMediaTypeExtension)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return envelope, MediaTypeJWSEnvelope, nil
+		}
+		return nil, "", err
+	}
+	return envelope, strings.TrimSpace(string(mediaType)), nil
+}
+
+// VerifyDetached verifies the content read from payload against the detached
+// signature at path, e.g. to verify a release tarball, with VerifyBlob.
+func VerifyDetached(ctx context.Context, verifier Verifier, payload io.Reader, path string, opts VerifyOptions) (*VerificationOutcome, error) {
+	envelope, mediaType, err := ReadDetachedSignature(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read detached signature: %w", err)
+	}
+	if mediaType != MediaTypeJWSEnvelope {
+		return nil, fmt.Errorf("unsupported signature envelope media type %q", mediaType)
+	}
+	return VerifyBlob(ctx, verifier, payload, envelope, opts)
+}
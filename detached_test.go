@@ -0,0 +1,115 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// mockVerifier returns the descriptor encoded as the signature envelope.
+type mockVerifier struct{}
+
+func (mockVerifier) Verify(ctx context.Context, signature []byte, opts VerifyOptions) (*VerificationOutcome, error) {
+	if string(signature) == "invalid" {
+		return nil, errors.New("invalid signature")
+	}
+	content := strings.TrimPrefix(string(signature), "signature of ")
+	return &VerificationOutcome{
+		Descriptor: Descriptor{
+			Digest: digest.FromString(content),
+			Size:   int64(len(content)),
+		},
+	}, nil
+}
+
+func TestWriteReadDetachedSignature(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "signatures")
+	desc := Descriptor{Digest: digest.FromString("release"), Size: 7}
+	path, err := WriteDetachedSignature(dir, desc, []byte("signature of release"), MediaTypeJWSEnvelope)
+	if err != nil {
+		t.Fatalf("WriteDetachedSignature() error = %v", err)
+	}
+	want := filepath.Join(dir, "sha256-"+desc.Digest.Encoded()+".sig")
+	if path != want {
+		t.Errorf("WriteDetachedSignature() = %v, want %v", path, want)
+	}
+	envelope, mediaType, err := ReadDetachedSignature(path)
+	if err != nil {
+		t.Fatalf("ReadDetachedSignature() error = %v", err)
+	}
+	if string(envelope) != "signature of release" || mediaType != MediaTypeJWSEnvelope {
+		t.Errorf("ReadDetachedSignature() = %q, %v, want signature of release, %v", envelope, mediaType, MediaTypeJWSEnvelope)
+	}
+
+	if _, err := WriteDetachedSignature(dir, Descriptor{Digest: "invalid"}, nil, MediaTypeJWSEnvelope); err == nil {
+		t.Errorf("WriteDetachedSignature() with invalid digest succeeded, want error")
+	}
+}
+
+func TestVerifyDetached(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content, envelope, mediaType string) string {
+		path, err := WriteDetachedSignature(dir, Descriptor{Digest: digest.FromString(content)}, []byte(envelope), mediaType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		payload string
+		path    string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			payload: "release",
+			path:    write("release", "signature of release", MediaTypeJWSEnvelope),
+		},
+		{
+			name:    "payload mismatch",
+			payload: "tampered",
+			path:    write("release", "signature of release", MediaTypeJWSEnvelope),
+			wantErr: "does not match the signed content",
+		},
+		{
+			name:    "invalid signature",
+			payload: "invalid",
+			path:    write("invalid", "invalid", MediaTypeJWSEnvelope),
+			wantErr: "invalid signature",
+		},
+		{
+			name:    "unsupported media type",
+			payload: "cose",
+			path:    write("cose", "signature of cose", "application/cose"),
+			wantErr: "unsupported signature envelope media type",
+		},
+		{
+			name:    "missing signature",
+			payload: "release",
+			path:    filepath.Join(dir, "missing.sig"),
+			wantErr: "failed to read detached signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, err := VerifyDetached(context.Background(), mockVerifier{}, strings.NewReader(tt.payload), tt.path, VerifyOptions{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyDetached() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyDetached() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.name == "payload mismatch" && outcome.Result(CheckIntegrity).Status != VerificationFailed {
+				t.Errorf("VerifyDetached() integrity = %v, want failed", outcome.Result(CheckIntegrity))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package notation
+
+import (
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// DigestOptions contains parameters for DescriptorFromReader.
+type DigestOptions struct {
+	// Algorithm is the digest algorithm, one of sha256, sha384 and sha512.
+	// digest.SHA256 is used if empty.
+	Algorithm digest.Algorithm
+
+	// Progress is called with the number of bytes read so far each time
+	// content is read, e.g. to report the progress of multi-GB artifacts.
+	Progress func(read int64)
+}
+
+// DescriptorFromReader returns the descriptor of media type mediaType of the
+// content read from reader.
+// The content is digested as it is read, without being held in memory.
+func DescriptorFromReader(reader io.Reader, mediaType string, opts DigestOptions) (Descriptor, error) {
+	alg := opts.Algorithm
+	if alg == "" {
+		alg = digest.SHA256
+	}
+	dgst, size, err := digestReader(reader, alg, opts.Progress)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    dgst,
+		Size:      size,
+	}, nil
+}
+
+// digestReader digests the content read from reader with alg, and returns
+// the digest with the size of the content.
+func digestReader(reader io.Reader, alg digest.Algorithm, progress func(read int64)) (digest.Digest, int64, error) {
+	switch alg {
+	case digest.SHA256, digest.SHA384, digest.SHA512:
+	default:
+		return "", 0, fmt.Errorf("unsupported digest algorithm %q", alg)
+	}
+	if progress != nil {
+		reader = &progressReader{reader: reader, progress: progress}
+	}
+	digester := alg.Digester()
+	size, err := io.Copy(digester.Hash(), reader)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read content: %w", err)
+	}
+	return digester.Digest(), size, nil
+}
+
+// progressReader reports the number of bytes read from reader.
+type progressReader struct {
+	reader   io.Reader
+	read     int64
+	progress func(read int64)
+}
+
+// Read reads from the underlying reader and reports the progress.
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress(r.read)
+	}
+	return n, err
+}
@@ -0,0 +1,51 @@
+package notation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestDescriptorFromReader(t *testing.T) {
+	content := strings.Repeat("a", 100*1024)
+	tests := []struct {
+		name    string
+		alg     digest.Algorithm
+		want    digest.Digest
+		wantErr bool
+	}{
+		{name: "default", want: digest.SHA256.FromString(content)},
+		{name: "sha384", alg: digest.SHA384, want: digest.SHA384.FromString(content)},
+		{name: "sha512", alg: digest.SHA512, want: digest.SHA512.FromString(content)},
+		{name: "unsupported", alg: "sha1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var progress []int64
+			desc, err := DescriptorFromReader(strings.NewReader(content), "application/octet-stream", DigestOptions{
+				Algorithm: tt.alg,
+				Progress: func(read int64) {
+					progress = append(progress, read)
+				},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DescriptorFromReader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			want := Descriptor{
+				MediaType: "application/octet-stream",
+				Digest:    tt.want,
+				Size:      int64(len(content)),
+			}
+			if !desc.Equal(want) {
+				t.Errorf("DescriptorFromReader() = %v, want %v", desc, want)
+			}
+			if len(progress) < 2 || progress[len(progress)-1] != int64(len(content)) {
+				t.Errorf("DescriptorFromReader() reported progress %v, want increments up to %d", progress, len(content))
+			}
+		})
+	}
+}
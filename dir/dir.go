@@ -0,0 +1,151 @@
+// Package dir computes the paths of the configuration, plugins, trust stores,
+// config file, trust policy, signing keys and cache of notation.
+//
+// The user configuration directory is resolved per OS by os.UserConfigDir,
+// i.e. $XDG_CONFIG_HOME or ~/.config on Linux, %AppData% on Windows and
+// ~/Library/Application Support on macOS, and can be overridden by the
+// NOTATION_CONFIG environment variable. The cache directory is resolved
+// likewise by os.UserCacheDir, and can be overridden by NOTATION_CACHE.
+//
+// The files of the config directory can also be read from any fs.FS, e.g.
+// an embed.FS or an in-memory file system, at the slash-separated paths
+// relative to the config directory returned by the functions ending in FS.
+package dir
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Environment variables overriding the directories of notation.
+const (
+	// ConfigEnv is the environment variable overriding the config directory.
+	ConfigEnv = "NOTATION_CONFIG"
+
+	// CacheEnv is the environment variable overriding the cache directory.
+	CacheEnv = "NOTATION_CACHE"
+)
+
+// Names of the directories and files of notation.
+const (
+	// Notation is the name of the notation directories under the user
+	// config and cache directories.
+	Notation = "notation"
+
+	// PluginsDirName is the name of the plugins directory in the config
+	// directory.
+	PluginsDirName = "plugins"
+
+	// TrustStoreDirName is the name of the trust store directory in the
+	// config directory.
+	TrustStoreDirName = "truststore"
+
+	// TrustPolicyFileName is the name of the trust policy file in the config
+	// directory.
+	TrustPolicyFileName = "trustpolicy.json"
+
+	// ConfigFileName is the name of the config file in the config directory.
+	ConfigFileName = "config.json"
+
+	// SigningKeysFileName is the name of the signing keys file in the config
+	// directory.
+	SigningKeysFileName = "signingkeys.json"
+
+	// SignaturesDirName is the name of the signatures directory in the cache
+	// directory.
+	SignaturesDirName = "signatures"
+)
+
+// ConfigDir returns the config directory of notation.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(ConfigEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, Notation), nil
+}
+
+// CacheDir returns the cache directory of notation.
+func CacheDir() (string, error) {
+	if dir := os.Getenv(CacheEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, Notation), nil
+}
+
+// PluginDir returns the directory where plugins are installed following the
+// {dir}/{plugin-name}/notation-{plugin-name}[.exe] pattern.
+func PluginDir() (string, error) {
+	return configPath(PluginsDirName)
+}
+
+// X509TrustStoreDir returns the directory of the x509 trust store of type
+// storeType, e.g. ca, named name.
+func X509TrustStoreDir(storeType, name string) (string, error) {
+	return configPath(TrustStoreDirName, "x509", storeType, name)
+}
+
+// TrustPolicyPath returns the path of the trust policy file.
+func TrustPolicyPath() (string, error) {
+	return configPath(TrustPolicyFileName)
+}
+
+// ConfigPath returns the path of the config file.
+func ConfigPath() (string, error) {
+	return configPath(ConfigFileName)
+}
+
+// SigningKeysPath returns the path of the signing keys file.
+func SigningKeysPath() (string, error) {
+	return configPath(SigningKeysFileName)
+}
+
+// SignatureCacheDir returns the directory where signatures are cached.
+func SignatureCacheDir() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SignaturesDirName), nil
+}
+
+// configPath joins elem to the config directory.
+func configPath(elem ...string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// ConfigFS returns the config directory of notation as a read-only file
+// system.
+func ConfigFS() (fs.FS, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return os.DirFS(dir), nil
+}
+
+// PluginDirFS returns the path of the plugin directory relative to the
+// config directory.
+func PluginDirFS() string {
+	return PluginsDirName
+}
+
+// X509TrustStoreDirFS returns the path of the directory of the x509 trust
+// store of type storeType, e.g. ca, named name, relative to the config
+// directory.
+func X509TrustStoreDirFS(storeType, name string) string {
+	return path.Join(TrustStoreDirName, "x509", storeType, name)
+}
@@ -0,0 +1,59 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirs_EnvOverride(t *testing.T) {
+	configDir := t.TempDir()
+	cacheDir := t.TempDir()
+	t.Setenv(ConfigEnv, configDir)
+	t.Setenv(CacheEnv, cacheDir)
+
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{name: "config", fn: ConfigDir, want: configDir},
+		{name: "cache", fn: CacheDir, want: cacheDir},
+		{name: "plugins", fn: PluginDir, want: filepath.Join(configDir, "plugins")},
+		{name: "trust policy", fn: TrustPolicyPath, want: filepath.Join(configDir, "trustpolicy.json")},
+		{name: "config file", fn: ConfigPath, want: filepath.Join(configDir, "config.json")},
+		{name: "signing keys", fn: SigningKeysPath, want: filepath.Join(configDir, "signingkeys.json")},
+		{name: "signatures", fn: SignatureCacheDir, want: filepath.Join(cacheDir, "signatures")},
+		{
+			name: "trust store",
+			fn:   func() (string, error) { return X509TrustStoreDir("ca", "acme") },
+			want: filepath.Join(configDir, "truststore", "x509", "ca", "acme"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigDir_Default(t *testing.T) {
+	t.Setenv(ConfigEnv, "")
+	userDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Skipf("no user config directory: %v", err)
+	}
+	got, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir() error = %v", err)
+	}
+	if want := filepath.Join(userDir, "notation"); got != want {
+		t.Errorf("ConfigDir() = %q, want %q", got, want)
+	}
+}
@@ -1,8 +1,116 @@
 package notation
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // SignOptions errors
 var (
 	ErrExpiryNotSpecified = errors.New("expiry not specified")
 )
+
+// ErrorCode identifies the kind of an error returned by signers and verifiers.
+type ErrorCode string
+
+// Error codes of the errors returned by signers and verifiers.
+const (
+	ErrorCodeInvalidArgument              ErrorCode = "INVALID_ARGUMENT"
+	ErrorCodeEmptyCertificateChain        ErrorCode = "EMPTY_CERTIFICATE_CHAIN"
+	ErrorCodeInvalidCertificate           ErrorCode = "INVALID_CERTIFICATE"
+	ErrorCodeKeyIDMismatch                ErrorCode = "KEY_ID_MISMATCH"
+	ErrorCodeKeySpecMismatch              ErrorCode = "KEY_SPEC_MISMATCH"
+	ErrorCodeUnsupportedKeySpec           ErrorCode = "UNSUPPORTED_KEY_SPEC"
+	ErrorCodeUnsupportedSigningAlgorithm  ErrorCode = "UNSUPPORTED_SIGNING_ALGORITHM"
+	ErrorCodeMalformedSignature           ErrorCode = "MALFORMED_SIGNATURE"
+	ErrorCodeInvalidSignature             ErrorCode = "INVALID_SIGNATURE"
+	ErrorCodeUntrustedSigner              ErrorCode = "UNTRUSTED_SIGNER"
+	ErrorCodeSignatureExpired             ErrorCode = "SIGNATURE_EXPIRED"
+	ErrorCodeUnsupportedCriticalAttribute ErrorCode = "UNSUPPORTED_CRITICAL_ATTRIBUTE"
+	ErrorCodeUserMetadataMismatch         ErrorCode = "USER_METADATA_MISMATCH"
+	ErrorCodePluginFailed                 ErrorCode = "PLUGIN_FAILED"
+	ErrorCodeFIPSViolation                ErrorCode = "FIPS_VIOLATION"
+	ErrorCodePolicyDenied                 ErrorCode = "POLICY_DENIED"
+	ErrorCodeAnnotationsMismatch          ErrorCode = "ANNOTATIONS_MISMATCH"
+	ErrorCodeWeakAlgorithm                ErrorCode = "WEAK_ALGORITHM"
+	ErrorCodeLimitExceeded                ErrorCode = "LIMIT_EXCEEDED"
+	ErrorCodeHashMismatch                 ErrorCode = "HASH_MISMATCH"
+)
+
+// Errors of each error code, to be tested with errors.Is.
+var (
+	ErrInvalidArgument              = &Error{Code: ErrorCodeInvalidArgument, Err: errors.New("invalid argument")}
+	ErrEmptyCertificateChain        = &Error{Code: ErrorCodeEmptyCertificateChain, Err: errors.New("empty certificate chain")}
+	ErrInvalidCertificate           = &Error{Code: ErrorCodeInvalidCertificate, Err: errors.New("invalid certificate")}
+	ErrKeyIDMismatch                = &Error{Code: ErrorCodeKeyIDMismatch, Err: errors.New("key ID mismatch")}
+	ErrKeySpecMismatch              = &Error{Code: ErrorCodeKeySpecMismatch, Err: errors.New("key spec mismatch")}
+	ErrUnsupportedKeySpec           = &Error{Code: ErrorCodeUnsupportedKeySpec, Err: errors.New("unsupported key spec")}
+	ErrUnsupportedSigningAlgorithm  = &Error{Code: ErrorCodeUnsupportedSigningAlgorithm, Err: errors.New("unsupported signing algorithm")}
+	ErrMalformedSignature           = &Error{Code: ErrorCodeMalformedSignature, Err: errors.New("malformed signature")}
+	ErrInvalidSignature             = &Error{Code: ErrorCodeInvalidSignature, Err: errors.New("invalid signature")}
+	ErrUntrustedSigner              = &Error{Code: ErrorCodeUntrustedSigner, Err: errors.New("untrusted signer")}
+	ErrSignatureExpired             = &Error{Code: ErrorCodeSignatureExpired, Err: errors.New("signature expired")}
+	ErrUnsupportedCriticalAttribute = &Error{Code: ErrorCodeUnsupportedCriticalAttribute, Err: errors.New("unsupported critical attribute")}
+	ErrUserMetadataMismatch         = &Error{Code: ErrorCodeUserMetadataMismatch, Err: errors.New("user metadata mismatch")}
+	ErrPluginFailed                 = &Error{Code: ErrorCodePluginFailed, Err: errors.New("plugin failed")}
+	ErrFIPSViolation                = &Error{Code: ErrorCodeFIPSViolation, Err: errors.New("FIPS violation")}
+	ErrPolicyDenied                 = &Error{Code: ErrorCodePolicyDenied, Err: errors.New("policy denied")}
+	ErrAnnotationsMismatch          = &Error{Code: ErrorCodeAnnotationsMismatch, Err: errors.New("annotations mismatch")}
+	ErrWeakAlgorithm                = &Error{Code: ErrorCodeWeakAlgorithm, Err: errors.New("weak algorithm")}
+	ErrLimitExceeded                = &Error{Code: ErrorCodeLimitExceeded, Err: errors.New("limit exceeded")}
+	ErrHashMismatch                 = &Error{Code: ErrorCodeHashMismatch, Err: errors.New("hash mismatch")}
+)
+
+// Error is an error with an error code.
+// Use errors.As to get the code of an error, or errors.Is with the Err
+// variable of a code, e.g. ErrKeyIDMismatch.
+type Error struct {
+	// Code is the error code.
+	Code ErrorCode
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Errorf formats an error of code as fmt.Errorf.
+func Errorf(code ErrorCode, format string, a ...interface{}) error {
+	return &Error{
+		Code: code,
+		Err:  fmt.Errorf(format, a...),
+	}
+}
+
+// WrapError returns err with code, or nil if err is nil.
+// err is returned as is if it already has the code.
+func WrapError(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) && e.Code == code {
+		return err
+	}
+	return &Error{
+		Code: code,
+		Err:  err,
+	}
+}
+
+// Error returns the message of the underlying error.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
@@ -0,0 +1,39 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	err := Errorf(ErrorCodeKeyIDMismatch, "keyID %q does not match request %q", "2", "1")
+	if got, want := err.Error(), `keyID "2" does not match request "1"`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	wrapped := fmt.Errorf("sign failed: %w", err)
+	if !errors.Is(wrapped, ErrKeyIDMismatch) {
+		t.Errorf("errors.Is(%v, ErrKeyIDMismatch) = false, want true", wrapped)
+	}
+	if errors.Is(wrapped, ErrInvalidSignature) {
+		t.Errorf("errors.Is(%v, ErrInvalidSignature) = true, want false", wrapped)
+	}
+	var e *Error
+	if !errors.As(wrapped, &e) || e.Code != ErrorCodeKeyIDMismatch {
+		t.Errorf("errors.As(%v) code = %v, want %v", wrapped, e, ErrorCodeKeyIDMismatch)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	if err := WrapError(ErrorCodeInvalidSignature, nil); err != nil {
+		t.Errorf("WrapError(nil) = %v, want nil", err)
+	}
+	cause := errors.New("crypto/rsa: verification error")
+	err := WrapError(ErrorCodeInvalidSignature, cause)
+	if !errors.Is(err, ErrInvalidSignature) || !errors.Is(err, cause) {
+		t.Errorf("WrapError() = %v, want %v wrapping %v", err, ErrInvalidSignature, cause)
+	}
+	if again := WrapError(ErrorCodeInvalidSignature, err); again != err {
+		t.Errorf("WrapError() rewrapped an error of the same code")
+	}
+}
@@ -0,0 +1,116 @@
+// Package event provides the event hooks of notation-go.
+//
+// Handlers are injected through the context with WithHandler. The library
+// reports the progress of long operations, such as pushing and fetching
+// signatures, checking revocation and invoking plugins, to the handler of
+// the context, if any, e.g. to drive progress bars or to write structured
+// audit logs.
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Type is the type of an event.
+type Type string
+
+// Types of events.
+const (
+	// SignaturePushed is emitted once a signature is pushed and linked to
+	// the artifact it signs.
+	SignaturePushed Type = "signaturePushed"
+
+	// SignatureFetched is emitted once a signature of an artifact is
+	// fetched for verification.
+	SignatureFetched Type = "signatureFetched"
+
+	// RevocationCheckStarted is emitted before the revocation of the
+	// certificate chain of a signature is checked.
+	RevocationCheckStarted Type = "revocationCheckStarted"
+
+	// RevocationCheckFinished is emitted once the revocation of the
+	// certificate chain of a signature is checked.
+	RevocationCheckFinished Type = "revocationCheckFinished"
+
+	// PluginInvoked is emitted once a command of a plugin completes.
+	PluginInvoked Type = "pluginInvoked"
+)
+
+// Event is an event of a long operation.
+type Event struct {
+	// Type is the type of the event.
+	Type Type
+
+	// Time is the time the event is emitted at.
+	Time time.Time
+
+	// Artifact is the digest of the artifact the event is about, if known.
+	Artifact digest.Digest
+
+	// Signature is the digest of the signature the event is about, if known.
+	Signature digest.Digest
+
+	// Plugin is the name of the plugin invoked, if any.
+	Plugin string
+
+	// Command is the command of the plugin invoked, if any.
+	Command string
+
+	// Duration is the duration of the operation, set on the events
+	// reporting its completion.
+	Duration time.Duration
+
+	// Err is the error the operation failed with, if any.
+	Err error
+}
+
+// Handler handles the events of long operations.
+// Implementations must be safe for concurrent use, and should return
+// promptly since events are handled synchronously.
+type Handler interface {
+	// HandleEvent handles the event e.
+	HandleEvent(e Event)
+}
+
+// HandlerFunc is an adapter to use ordinary functions as handlers.
+type HandlerFunc func(e Event)
+
+// HandleEvent calls f(e).
+func (f HandlerFunc) HandleEvent(e Event) {
+	f(e)
+}
+
+// Discard is a handler discarding all events.
+var Discard Handler = discardHandler{}
+
+// contextKey is the context key of the handler.
+type contextKey struct{}
+
+// WithHandler returns a context carrying handler.
+func WithHandler(ctx context.Context, handler Handler) context.Context {
+	return context.WithValue(ctx, contextKey{}, handler)
+}
+
+// GetHandler returns the handler of ctx, or Discard if ctx carries no handler.
+func GetHandler(ctx context.Context) Handler {
+	if handler, ok := ctx.Value(contextKey{}).(Handler); ok && handler != nil {
+		return handler
+	}
+	return Discard
+}
+
+// Emit emits e to the handler of ctx, setting its time if not set.
+func Emit(ctx context.Context, e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	GetHandler(ctx).HandleEvent(e)
+}
+
+// discardHandler discards all events.
+type discardHandler struct{}
+
+func (discardHandler) HandleEvent(e Event) {}
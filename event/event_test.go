@@ -0,0 +1,26 @@
+package event
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	ctx := context.Background()
+	if got := GetHandler(ctx); got != Discard {
+		t.Errorf("GetHandler() = %v, want Discard", got)
+	}
+	Emit(ctx, Event{Type: SignaturePushed})
+
+	var events []Event
+	ctx = WithHandler(ctx, HandlerFunc(func(e Event) {
+		events = append(events, e)
+	}))
+	Emit(ctx, Event{Type: PluginInvoked, Plugin: "plugin", Command: "generate-signature"})
+	if len(events) != 1 {
+		t.Fatalf("Emit() emitted %d events, want 1", len(events))
+	}
+	if e := events[0]; e.Type != PluginInvoked || e.Plugin != "plugin" || e.Time.IsZero() {
+		t.Errorf("Emit() emitted %+v, want a timed plugin invoked event", e)
+	}
+}
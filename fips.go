@@ -0,0 +1,102 @@
+package notation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"sync/atomic"
+)
+
+// fipsMode is 1 if the FIPS mode is enabled.
+var fipsMode int32
+
+func init() {
+	if fipsDefault {
+		fipsMode = 1
+	}
+}
+
+// SetFIPSMode enables or disables the FIPS mode. In FIPS mode, signers and
+// verifiers only accept the key specs, certificates and signature algorithms
+// approved by FIPS 186-4, and fail with ErrorCodeFIPSViolation otherwise.
+//
+// The FIPS mode is disabled by default, unless built with the notation_fips
+// build tag.
+func SetFIPSMode(enabled bool) {
+	var mode int32
+	if enabled {
+		mode = 1
+	}
+	atomic.StoreInt32(&fipsMode, mode)
+}
+
+// FIPSMode reports whether the FIPS mode is enabled.
+func FIPSMode() bool {
+	return atomic.LoadInt32(&fipsMode) == 1
+}
+
+// FIPSApproved reports whether the key spec is approved by FIPS 186-4, i.e.
+// an RSA or a NIST P-curve key spec. Ed25519 is not approved.
+func (k KeySpec) FIPSApproved() bool {
+	switch k {
+	case RSA_2048, RSA_3072, RSA_4096, EC_256, EC_384, EC_512:
+		return true
+	}
+	return false
+}
+
+// CheckFIPSKeySpec returns an ErrorCodeFIPSViolation error if the FIPS mode
+// is enabled and keySpec is not approved.
+func CheckFIPSKeySpec(keySpec KeySpec) error {
+	if FIPSMode() && !keySpec.FIPSApproved() {
+		return Errorf(ErrorCodeFIPSViolation, "keySpec %q is not FIPS approved", keySpec)
+	}
+	return nil
+}
+
+// fipsSignatureAlgorithms are the FIPS approved signature algorithms of
+// certificates.
+var fipsSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.SHA256WithRSA:    true,
+	x509.SHA384WithRSA:    true,
+	x509.SHA512WithRSA:    true,
+	x509.SHA256WithRSAPSS: true,
+	x509.SHA384WithRSAPSS: true,
+	x509.SHA512WithRSAPSS: true,
+	x509.ECDSAWithSHA256:  true,
+	x509.ECDSAWithSHA384:  true,
+	x509.ECDSAWithSHA512:  true,
+}
+
+// CheckFIPSCertificateChain returns an ErrorCodeFIPSViolation error if the
+// FIPS mode is enabled and a certificate of certs has a key, or is signed with
+// an algorithm, which is not approved, e.g. an RSA key of less than 2048
+// bits or a SHA-1 signature. The signatures of self-issued certificates,
+// i.e. roots trusted as such, are not checked.
+func CheckFIPSCertificateChain(certs []*x509.Certificate) error {
+	if !FIPSMode() {
+		return nil
+	}
+	for _, cert := range certs {
+		switch key := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			if key.N.BitLen() < 2048 {
+				return Errorf(ErrorCodeFIPSViolation, "certificate %q has an RSA key of %d bits, which is not FIPS approved", cert.Subject, key.N.BitLen())
+			}
+		case *ecdsa.PublicKey:
+			switch key.Curve {
+			case elliptic.P256(), elliptic.P384(), elliptic.P521():
+			default:
+				return Errorf(ErrorCodeFIPSViolation, "certificate %q has an EC key on curve %s, which is not FIPS approved", cert.Subject, key.Curve.Params().Name)
+			}
+		default:
+			return Errorf(ErrorCodeFIPSViolation, "certificate %q has a %s key, which is not FIPS approved", cert.Subject, cert.PublicKeyAlgorithm)
+		}
+		if !bytes.Equal(cert.RawIssuer, cert.RawSubject) && !fipsSignatureAlgorithms[cert.SignatureAlgorithm] {
+			return Errorf(ErrorCodeFIPSViolation, "certificate %q is signed with %s, which is not FIPS approved", cert.Subject, cert.SignatureAlgorithm)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !notation_fips
+// +build !notation_fips
+
+package notation
+
+// fipsDefault is the FIPS mode of builds without the notation_fips tag.
+const fipsDefault = false
@@ -0,0 +1,101 @@
+package notation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestKeySpec_FIPSApproved(t *testing.T) {
+	for _, k := range []KeySpec{RSA_2048, RSA_3072, RSA_4096, EC_256, EC_384, EC_512} {
+		if !k.FIPSApproved() {
+			t.Errorf("%s.FIPSApproved() = false, want true", k)
+		}
+	}
+	if ED25519.FIPSApproved() {
+		t.Errorf("%s.FIPSApproved() = true, want false", ED25519)
+	}
+}
+
+func TestCheckFIPSCertificateChain(t *testing.T) {
+	createCert := func(t *testing.T, key crypto.Signer, sigAlg x509.SignatureAlgorithm, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+		t.Helper()
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: "leaf"},
+			NotBefore:             time.Now().Add(-time.Minute),
+			NotAfter:              time.Now().Add(time.Hour),
+			SignatureAlgorithm:    sigAlg,
+			BasicConstraintsValid: true,
+		}
+		if parent == nil {
+			template.Subject.CommonName = "root"
+			template.IsCA = true
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// self-signed roots are trusted as such, whatever their signature
+	root := createCert(t, rootKey, x509.SHA1WithRSA, nil, nil)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224Key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		leaf    *x509.Certificate
+		wantErr bool
+	}{
+		{name: "approved", leaf: createCert(t, ecKey, x509.SHA256WithRSA, root, rootKey)},
+		{name: "SHA-1 signature", leaf: createCert(t, ecKey, x509.SHA1WithRSA, root, rootKey), wantErr: true},
+		{name: "P-224 key", leaf: createCert(t, p224Key, x509.SHA256WithRSA, root, rootKey), wantErr: true},
+		{name: "RSA 1024 key", leaf: createCert(t, weakKey, x509.SHA256WithRSA, root, rootKey), wantErr: true},
+	}
+
+	SetFIPSMode(true)
+	defer SetFIPSMode(false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckFIPSCertificateChain([]*x509.Certificate{tt.leaf, root})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckFIPSCertificateChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrFIPSViolation) {
+				t.Errorf("CheckFIPSCertificateChain() error = %v, want ErrFIPSViolation", err)
+			}
+		})
+	}
+
+	SetFIPSMode(false)
+	if err := CheckFIPSCertificateChain([]*x509.Certificate{tests[1].leaf, root}); err != nil {
+		t.Errorf("CheckFIPSCertificateChain() without FIPS mode error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,142 @@
+package notation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// HTTPClientOptions configures the HTTP clients used to access registries,
+// revocation endpoints and timestamping authorities.
+//
+// The resulted transport can be passed to registry.NewAuthClient,
+// timestamp.NewHTTPTimestamper and the plugin manager install options.
+type HTTPClientOptions struct {
+	// RoundTripper is the base transport.
+	// http.DefaultTransport is used if nil.
+	// The proxy and TLS options can only be applied if it is an *http.Transport.
+	RoundTripper http.RoundTripper
+
+	// Proxy returns the proxy to use for a request.
+	// The proxy of the base transport is used if nil.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// CABundlePath is the path to a PEM file of CA certificates trusted
+	// in addition to the system roots.
+	CABundlePath string
+
+	// InsecureSkipVerify disables the verification of the TLS certificates
+	// of all hosts.
+	InsecureSkipVerify bool
+
+	// InsecureRegistries lists the hosts, i.e. host:port, whose TLS
+	// certificates are not verified.
+	InsecureRegistries []string
+
+	// RateLimit, if not nil, limits the rate of the requests sent to each
+	// host, such as the requests listing and fetching signatures, or
+	// fetching certificates and revocation information.
+	RateLimit *RateLimit
+}
+
+// Transport returns the transport configured by opts.
+func (opts HTTPClientOptions) Transport() (http.RoundTripper, error) {
+	transport, err := opts.transport()
+	if err != nil {
+		return nil, err
+	}
+	if opts.RateLimit != nil {
+		transport = NewRateLimitTransport(transport, *opts.RateLimit)
+	}
+	return transport, nil
+}
+
+// transport returns the transport configured by the proxy and TLS options.
+func (opts HTTPClientOptions) transport() (http.RoundTripper, error) {
+	base := opts.RoundTripper
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.Proxy == nil && opts.CABundlePath == "" && !opts.InsecureSkipVerify && len(opts.InsecureRegistries) == 0 {
+		return base, nil
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("proxy and TLS options cannot be applied to transport of type %T", base)
+	}
+	transport = transport.Clone()
+	if opts.Proxy != nil {
+		transport.Proxy = opts.Proxy
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if opts.CABundlePath != "" {
+		roots, err := loadCABundle(opts.CABundlePath)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.RootCAs = roots
+	}
+	if opts.InsecureSkipVerify {
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+	if len(opts.InsecureRegistries) == 0 || opts.InsecureSkipVerify {
+		return transport, nil
+	}
+	insecure := transport.Clone()
+	insecure.TLSClientConfig.InsecureSkipVerify = true
+	hosts := make(map[string]bool, len(opts.InsecureRegistries))
+	for _, host := range opts.InsecureRegistries {
+		hosts[host] = true
+	}
+	return &hostTransport{
+		secure:   transport,
+		insecure: insecure,
+		hosts:    hosts,
+	}, nil
+}
+
+// Client returns an HTTP client using the transport configured by opts.
+func (opts HTTPClientOptions) Client() (*http.Client, error) {
+	transport, err := opts.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// hostTransport skips TLS verification for the insecure hosts.
+type hostTransport struct {
+	secure   http.RoundTripper
+	insecure http.RoundTripper
+	hosts    map[string]bool
+}
+
+// RoundTrip routes the request to the insecure transport if its host is insecure.
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hosts[req.URL.Host] {
+		return t.insecure.RoundTrip(req)
+	}
+	return t.secure.RoundTrip(req)
+}
+
+// loadCABundle returns the system roots with the certificates of the PEM
+// file at path added.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in CA bundle %q", path)
+	}
+	return roots, nil
+}
@@ -0,0 +1,88 @@
+package notation
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHTTPClientOptions_Client(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host := server.Listener.Addr().String()
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    HTTPClientOptions
+		wantErr bool
+	}{
+		{"default", HTTPClientOptions{}, true},
+		{"CA bundle", HTTPClientOptions{CABundlePath: bundle}, false},
+		{"insecure", HTTPClientOptions{InsecureSkipVerify: true}, false},
+		{"insecure registry", HTTPClientOptions{InsecureRegistries: []string{host}}, false},
+		{"other insecure registry", HTTPClientOptions{InsecureRegistries: []string{"registry.example.com"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.opts.Client()
+			if err != nil {
+				t.Fatalf("Client() error = %v", err)
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPClientOptions_Transport(t *testing.T) {
+	var proxied bool
+	transport, err := HTTPClientOptions{
+		Proxy: func(r *http.Request) (*url.URL, error) {
+			proxied = true
+			return nil, nil
+		},
+	}.Transport()
+	if err != nil {
+		t.Fatalf("Transport() error = %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if !proxied {
+		t.Error("Proxy was not called")
+	}
+
+	if _, err := (HTTPClientOptions{
+		RoundTripper:       roundTripperFunc(nil),
+		InsecureSkipVerify: true,
+	}).Transport(); err == nil {
+		t.Error("Transport() error = nil, want error for custom round tripper with TLS options")
+	}
+	if _, err := (HTTPClientOptions{CABundlePath: filepath.Join(t.TempDir(), "missing.pem")}).Transport(); err == nil {
+		t.Error("Transport() error = nil, want error for missing CA bundle")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,178 @@
+// Package cosign discovers and verifies cosign signatures against notation
+// trust policies and trust stores, to ease the migration of artifacts signed
+// with cosign.
+//
+// The package is read-only: it does not produce cosign signatures.
+// Only the signatures whose signing certificate is attached, e.g. keyless
+// signatures, can be verified against trust stores; key-based signatures
+// fail the integrity check.
+package cosign
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// MediaTypeSimpleSigning is the media type of the layers of the cosign
+// signature manifests, whose content is a simple signing payload.
+const MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// Annotations of the layers of the cosign signature manifests.
+const (
+	// AnnotationSignature is the base64 encoded signature of the payload.
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+
+	// AnnotationCertificate is the PEM encoded signing certificate.
+	AnnotationCertificate = "dev.sigstore.cosign/certificate"
+
+	// AnnotationChain is the PEM encoded certificate chain of the signing
+	// certificate, excluding the signing certificate.
+	AnnotationChain = "dev.sigstore.cosign/chain"
+)
+
+const (
+	maxManifestSizeLimit = 4 * 1024 * 1024 // 4 MiB
+	maxPayloadSizeLimit  = 4 * 1024 * 1024 // 4 MiB
+)
+
+// Signature is a cosign signature of an artifact.
+type Signature struct {
+	// Descriptor is the descriptor of the simple signing payload, as listed
+	// in the signature manifest.
+	Descriptor notation.Descriptor
+
+	// Payload is the signed simple signing payload.
+	Payload []byte
+
+	// Signature is the signature of the payload.
+	Signature []byte
+
+	// CertificateChain is the certificate chain of the signature, starting
+	// with the signing certificate. It is empty for key-based signatures.
+	CertificateChain []*x509.Certificate
+}
+
+// SignatureTag returns the tag of the cosign signature manifest of the
+// artifact of digest artifactDigest, e.g. sha256-<hex>.sig.
+func SignatureTag(artifactDigest digest.Digest) string {
+	return fmt.Sprintf("%s-%s.sig", artifactDigest.Algorithm(), artifactDigest.Encoded())
+}
+
+// FetchSignatures fetches the cosign signatures of the artifact of digest
+// artifactDigest from repo. No signature is returned if the artifact has no
+// cosign signature manifest.
+func FetchSignatures(ctx context.Context, repo *registry.RepositoryClient, artifactDigest digest.Digest) ([]Signature, error) {
+	if err := artifactDigest.Validate(); err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	r := repo.Repository
+	r.ManifestMediaTypes = []string{ocispec.MediaTypeImageManifest}
+	store := r.Manifests()
+	tag := SignatureTag(artifactDigest)
+	desc, err := store.Resolve(ctx, tag)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve cosign signature manifest %q: %w", tag, err)
+	}
+	if desc.Size > maxManifestSizeLimit {
+		return nil, fmt.Errorf("cosign signature manifest too large: %d", desc.Size)
+	}
+	manifestJSON, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cosign signature manifest %q: %w", tag, err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid cosign signature manifest %q: %w", tag, err)
+	}
+
+	var sigs []Signature
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != MediaTypeSimpleSigning {
+			continue
+		}
+		if layer.Size > maxPayloadSizeLimit {
+			return nil, fmt.Errorf("cosign signature payload %s too large: %d", layer.Digest, layer.Size)
+		}
+		sig, err := parseSignature(layer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cosign signature %s: %w", layer.Digest, err)
+		}
+		if sig.Payload, err = content.FetchAll(ctx, r.Blobs(), layer); err != nil {
+			return nil, fmt.Errorf("failed to fetch cosign signature payload %s: %w", layer.Digest, err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// parseSignature parses the signature and the certificate chain annotating
+// the payload layer.
+func parseSignature(layer ocispec.Descriptor) (Signature, error) {
+	sig := Signature{
+		Descriptor: notation.Descriptor{
+			MediaType: layer.MediaType,
+			Digest:    layer.Digest,
+			Size:      layer.Size,
+		},
+	}
+	encoded, ok := layer.Annotations[AnnotationSignature]
+	if !ok {
+		return Signature{}, errors.New("missing signature annotation")
+	}
+	var err error
+	if sig.Signature, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+		return Signature{}, fmt.Errorf("invalid signature annotation: %w", err)
+	}
+	if cert := layer.Annotations[AnnotationCertificate]; cert != "" {
+		if sig.CertificateChain, err = parseCertificates(cert); err != nil {
+			return Signature{}, fmt.Errorf("invalid certificate annotation: %w", err)
+		}
+		if len(sig.CertificateChain) != 1 {
+			return Signature{}, fmt.Errorf("certificate annotation has %d certificates", len(sig.CertificateChain))
+		}
+		chain, err := parseCertificates(layer.Annotations[AnnotationChain])
+		if err != nil {
+			return Signature{}, fmt.Errorf("invalid chain annotation: %w", err)
+		}
+		sig.CertificateChain = append(sig.CertificateChain, chain...)
+	}
+	return sig, nil
+}
+
+// parseCertificates parses PEM encoded certificates.
+func parseCertificates(data string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(strings.TrimSpace(data))
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("invalid PEM data")
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
@@ -0,0 +1,358 @@
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/verification"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orasregistry "oras.land/oras-go/v2/registry"
+)
+
+const testRepository = "software/net-monitor"
+
+var testArtifactDigest = digest.FromString("net-monitor")
+
+// testRegistry serves the cosign signature manifests of a repository.
+type testRegistry struct {
+	manifests map[string][]byte // by tag and by digest
+	blobs     map[digest.Digest][]byte
+	requests  int
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests++
+	prefix := "/v2/" + testRepository
+	switch {
+	case strings.HasPrefix(req.URL.Path, prefix+"/manifests/"):
+		manifest, ok := r.manifests[strings.TrimPrefix(req.URL.Path, prefix+"/manifests/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+		w.Header().Set("Content-Length", fmt.Sprint(len(manifest)))
+		if req.Method == http.MethodGet {
+			w.Write(manifest)
+		}
+	case strings.HasPrefix(req.URL.Path, prefix+"/blobs/"):
+		blob, ok := r.blobs[digest.Digest(strings.TrimPrefix(req.URL.Path, prefix+"/blobs/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(blob)))
+		w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// testCA is a certificate authority issuing signing certificates.
+type testCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA", Organization: []string{"Acme"}},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{key: key, cert: cert}
+}
+
+// issue issues a signing certificate valid until notAfter.
+func (ca *testCA) issue(t *testing.T, notAfter time.Time) (crypto.Signer, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}, CommonName: "signer"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+// signLayer returns the payload layer of a cosign signature of the artifact
+// of digest signed, and the payload.
+func signLayer(t *testing.T, key crypto.Signer, certs []*x509.Certificate, signed digest.Digest) (ocispec.Descriptor, []byte) {
+	t.Helper()
+	p := map[string]interface{}{
+		"critical": map[string]interface{}{
+			"identity": map[string]string{"docker-reference": "registry.acme-rockets.io/" + testRepository},
+			"image":    map[string]string{"docker-manifest-digest": signed.String()},
+			"type":     signatureType,
+		},
+		"optional": map[string]interface{}{"buildId": "101", "attempt": 1},
+	}
+	payload, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(payload)
+	sig, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := ocispec.Descriptor{
+		MediaType:   MediaTypeSimpleSigning,
+		Digest:      digest.FromBytes(payload),
+		Size:        int64(len(payload)),
+		Annotations: map[string]string{AnnotationSignature: base64.StdEncoding.EncodeToString(sig)},
+	}
+	if len(certs) > 0 {
+		layer.Annotations[AnnotationCertificate] = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certs[0].Raw}))
+		var chain []byte
+		for _, cert := range certs[1:] {
+			chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		layer.Annotations[AnnotationChain] = string(chain)
+	}
+	return layer, payload
+}
+
+// newTestRepository serves the cosign signature manifest of the payload
+// layers of the test artifact, if any.
+func newTestRepository(t *testing.T, layers []ocispec.Descriptor, payloads [][]byte) (*testRegistry, *registry.RepositoryClient) {
+	t.Helper()
+	r := &testRegistry{manifests: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+	if len(layers) > 0 {
+		manifest, err := json.Marshal(ocispec.Manifest{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    ocispec.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: digest.FromString("{}"), Size: 2},
+			Layers:    layers,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.manifests[SignatureTag(testArtifactDigest)] = manifest
+		r.manifests[digest.FromBytes(manifest).String()] = manifest
+		for i, layer := range layers {
+			r.blobs[layer.Digest] = payloads[i]
+		}
+	}
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, registry.NewRepositoryClient(ts.Client(), orasregistry.Reference{Registry: u.Host, Repository: testRepository}, true)
+}
+
+func newTestVerifier(ca *testCA, level string) *verification.Verifier {
+	return verification.NewVerifier(&verification.PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []verification.TrustPolicy{{
+			Name:                  "test-statement-name",
+			RegistryScopes:        []string{"*"},
+			SignatureVerification: level,
+			TrustStore:            "ca:test-store",
+			TrustedIdentities:     []string{"x509.subject:C=US,ST=WA,O=Acme"},
+		}},
+	}, []*verification.X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{ca.cert}}})
+}
+
+func TestSignatureTag(t *testing.T) {
+	want := "sha256-" + testArtifactDigest.Encoded() + ".sig"
+	if got := SignatureTag(testArtifactDigest); got != want {
+		t.Errorf("SignatureTag() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyArtifact(t *testing.T) {
+	trusted := newTestCA(t)
+	untrusted := newTestCA(t)
+	key, cert := trusted.issue(t, time.Now().Add(time.Hour))
+	expiredKey, expiredCert := trusted.issue(t, time.Now().Add(-time.Hour))
+	untrustedKey, untrustedCert := untrusted.issue(t, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name        string
+		level       string
+		key         crypto.Signer
+		certs       []*x509.Certificate
+		signed      digest.Digest
+		tamper      bool
+		noSignature bool
+		wantErr     string
+		wantFailed  notation.VerificationCheck
+	}{
+		{
+			name:  "valid",
+			level: "strict",
+			key:   key,
+			certs: []*x509.Certificate{cert, trusted.cert},
+		},
+		{
+			name:  "leaf only",
+			level: "strict",
+			key:   key,
+			certs: []*x509.Certificate{cert},
+		},
+		{
+			name:    "untrusted",
+			level:   "strict",
+			key:     untrustedKey,
+			certs:   []*x509.Certificate{untrustedCert, untrusted.cert},
+			wantErr: "x509",
+		},
+		{
+			name:    "other artifact",
+			level:   "strict",
+			key:     key,
+			certs:   []*x509.Certificate{cert},
+			signed:  digest.FromString("other"),
+			wantErr: "is signed for artifact",
+		},
+		{
+			name:    "tampered",
+			level:   "strict",
+			key:     key,
+			certs:   []*x509.Certificate{cert},
+			tamper:  true,
+			wantErr: "signature verification failed",
+		},
+		{
+			name:    "key-based",
+			level:   "strict",
+			key:     key,
+			wantErr: "key-based signatures are not supported",
+		},
+		{
+			name:    "expired",
+			level:   "strict",
+			key:     expiredKey,
+			certs:   []*x509.Certificate{expiredCert},
+			wantErr: "signing certificate expired",
+		},
+		{
+			name:       "expired logged",
+			level:      "permissive",
+			key:        expiredKey,
+			certs:      []*x509.Certificate{expiredCert},
+			wantFailed: notation.CheckExpiry,
+		},
+		{
+			name:        "no signature",
+			level:       "strict",
+			noSignature: true,
+			wantErr:     "no cosign signature is associated",
+		},
+		{
+			name:  "skip",
+			level: "skip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var layers []ocispec.Descriptor
+			var payloads [][]byte
+			if !tt.noSignature && tt.key != nil {
+				signed := tt.signed
+				if signed == "" {
+					signed = testArtifactDigest
+				}
+				layer, payload := signLayer(t, tt.key, tt.certs, signed)
+				if tt.tamper {
+					payload = []byte(strings.Replace(string(payload), "101", "102", 1))
+					layer.Digest = digest.FromBytes(payload)
+				}
+				layers, payloads = append(layers, layer), append(payloads, payload)
+			}
+			r, repo := newTestRepository(t, layers, payloads)
+			v := newTestVerifier(trusted, tt.level)
+			artifactUri := repo.Reference.Registry + "/" + testRepository + "@" + testArtifactDigest.String()
+
+			outcome, err := VerifyArtifact(context.Background(), v, repo, artifactUri)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifyArtifact() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyArtifact() error = %v", err)
+			}
+			if tt.level == "skip" {
+				if !outcome.Skipped || r.requests != 0 {
+					t.Errorf("VerifyArtifact() outcome = %+v with %d requests, want skipped outcome without requests", outcome, r.requests)
+				}
+				return
+			}
+			if outcome.Descriptor.Digest != testArtifactDigest {
+				t.Errorf("VerifyArtifact() Descriptor.Digest = %v, want %v", outcome.Descriptor.Digest, testArtifactDigest)
+			}
+			if got := outcome.UserMetadata; len(got) != 1 || got["buildId"] != "101" {
+				t.Errorf("VerifyArtifact() UserMetadata = %v, want map[buildId:101]", got)
+			}
+			for _, check := range []notation.VerificationCheck{notation.CheckIntegrity, notation.CheckAuthenticity, notation.CheckTrustedIdentity} {
+				if result := outcome.Result(check); result == nil || result.Status != notation.VerificationPassed {
+					t.Errorf("VerifyArtifact() %s result = %v, want passed", check, result)
+				}
+			}
+			failures := outcome.Failures()
+			if tt.wantFailed == "" {
+				if len(failures) != 0 {
+					t.Errorf("VerifyArtifact() failures = %v, want none", failures)
+				}
+			} else if len(failures) != 1 || failures[0].Check != tt.wantFailed || failures[0].Severity != notation.SeverityWarning {
+				t.Errorf("VerifyArtifact() failures = %v, want %s warning", failures, tt.wantFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/verification"
+	"github.com/opencontainers/go-digest"
+)
+
+// signatureType is the type of the simple signing payloads of cosign.
+const signatureType = "cosign container image signature"
+
+// payload is a simple signing payload.
+type payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// Verify verifies the cosign signature sig of the artifact referenced by
+// artifactUri, e.g. domain.com/my/repository@sha256:digest, against the
+// applicable trust policy of v.
+//
+// The outcome is reported with the checks of notation signatures: the
+// integrity check verifies the signature of the payload with the signing
+// certificate, and the payload pins the artifact. The string values of the
+// optional section of the payload are reported as user metadata.
+func Verify(ctx context.Context, v *verification.Verifier, artifactUri string, sig Signature) (*notation.VerificationOutcome, error) {
+	outcome := &notation.VerificationOutcome{SignatureDigest: sig.Descriptor.Digest}
+	if err := verifyIntegrity(sig, outcome); err != nil {
+		outcome.Fail(notation.CheckIntegrity, err)
+	} else {
+		outcome.Pass(notation.CheckIntegrity)
+	}
+	err := v.VerifyExternal(ctx, artifactUri, outcome)
+	return outcome, err
+}
+
+// VerifyArtifact fetches the cosign signatures of the artifact referenced by
+// artifactUri from repo, and returns the outcome of the first signature
+// satisfying the applicable trust policy of v.
+// If the trust policy skips verification, no signature is fetched and the
+// outcome is marked as Skipped.
+func VerifyArtifact(ctx context.Context, v *verification.Verifier, repo *registry.RepositoryClient, artifactUri string) (*notation.VerificationOutcome, error) {
+	trustPolicy, err := v.PolicyDocument.ApplicableTrustPolicy(artifactUri)
+	if err != nil {
+		return nil, err
+	}
+	level, err := trustPolicy.VerificationLevel()
+	if err != nil {
+		return nil, err
+	}
+	artifactDigest, err := artifactDigestFromUri(artifactUri)
+	if level.Name == verification.Skip.Name {
+		outcome := &notation.VerificationOutcome{Skipped: true}
+		if err == nil {
+			outcome.Descriptor.Digest = artifactDigest
+		}
+		return outcome, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sigs, err := FetchSignatures(ctx, repo, artifactDigest)
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("no cosign signature is associated with %q", artifactUri)
+	}
+
+	logger := log.GetLogger(ctx)
+	var errs []string
+	for _, sig := range sigs {
+		outcome, err := Verify(ctx, v, artifactUri, sig)
+		if err == nil {
+			logger.Debugf("cosign signature %s satisfies trust policy %q", sig.Descriptor.Digest, trustPolicy.Name)
+			return outcome, nil
+		}
+		errs = append(errs, fmt.Sprintf("signature %s: %v", sig.Descriptor.Digest, err))
+	}
+	sort.Strings(errs)
+	return nil, fmt.Errorf("no cosign signature associated with %q satisfies the trust policy %q: %s", artifactUri, trustPolicy.Name, strings.Join(errs, "; "))
+}
+
+// verifyIntegrity verifies the signature of the payload with the signing
+// certificate, and records the signed descriptor, the user metadata and the
+// certificate chain in outcome.
+func verifyIntegrity(sig Signature, outcome *notation.VerificationOutcome) error {
+	if len(sig.CertificateChain) == 0 {
+		return notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "cosign signature has no signing certificate, key-based signatures are not supported")
+	}
+	if err := verifyPayloadSignature(sig.CertificateChain[0].PublicKey, sig.Payload, sig.Signature); err != nil {
+		return notation.WrapError(notation.ErrorCodeInvalidSignature, err)
+	}
+	var p payload
+	if err := json.Unmarshal(sig.Payload, &p); err != nil {
+		return notation.Errorf(notation.ErrorCodeMalformedSignature, "invalid simple signing payload: %w", err)
+	}
+	if p.Critical.Type != signatureType {
+		return notation.Errorf(notation.ErrorCodeMalformedSignature, "unsupported simple signing payload type %q", p.Critical.Type)
+	}
+	signedDigest, err := digest.Parse(p.Critical.Image.DockerManifestDigest)
+	if err != nil {
+		return notation.Errorf(notation.ErrorCodeMalformedSignature, "invalid signed digest: %w", err)
+	}
+	outcome.Descriptor = notation.Descriptor{Digest: signedDigest}
+	for k, v := range p.Optional {
+		if s, ok := v.(string); ok {
+			if outcome.UserMetadata == nil {
+				outcome.UserMetadata = make(map[string]string)
+			}
+			outcome.UserMetadata[k] = s
+		}
+	}
+	outcome.CertificateChain = sig.CertificateChain
+	outcome.SelfSigned = notation.IsSelfSigned(sig.CertificateChain[0])
+	return nil
+}
+
+// verifyPayloadSignature verifies the signature of payload with key, as
+// produced by cosign: ECDSA and RSA PKCS #1 v1.5 signatures of the SHA-256
+// digest of the payload, and Ed25519 signatures of the payload.
+func verifyPayloadSignature(key crypto.PublicKey, payload, sig []byte) error {
+	hash := sha256.Sum256(payload)
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], sig) {
+			return errors.New("signature verification failed")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, sig) {
+			return errors.New("signature verification failed")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
+
+// artifactDigestFromUri returns the digest of the artifact referenced by an
+// artifact URI such as domain.com/repository@sha256:digest
+func artifactDigestFromUri(artifactUri string) (digest.Digest, error) {
+	i := strings.LastIndex(artifactUri, "@")
+	if i < 0 {
+		return "", fmt.Errorf("artifact URI %q does not reference the artifact by digest, e.g domain.com/my/repository@sha256:digest", artifactUri)
+	}
+	d, err := digest.Parse(artifactUri[i+1:])
+	if err != nil {
+		return "", fmt.Errorf("artifact URI %q has an invalid digest: %w", artifactUri, err)
+	}
+	return d, nil
+}
@@ -1,5 +1,7 @@
 package notation
 
+import "time"
+
 const (
 	// MediaTypeJWSEnvelope describes the media type of the JWS envelope.
 	MediaTypeJWSEnvelope = "application/vnd.cncf.notary.v2.jws.v1"
@@ -24,6 +26,21 @@ type JWSProtectedHeader struct {
 
 	// Media type of the secured content (the payload).
 	ContentType string `json:"cty"`
+
+	// Signing scheme of the signature.
+	// It is mandatory for verification, and must be listed in Critical.
+	SigningScheme SigningScheme `json:"io.cncf.notary.signingScheme,omitempty"`
+
+	// Time at which the signature was generated, as claimed by the signer.
+	// It is mandatory for SigningSchemeX509.
+	SigningTime *time.Time `json:"io.cncf.notary.signingTime,omitempty"`
+
+	// Time after which the signature must not be considered valid.
+	// It must be listed in Critical if present.
+	Expiry *time.Time `json:"io.cncf.notary.expiry,omitempty"`
+
+	// List of headers that must be understood and processed.
+	Critical []string `json:"crit,omitempty"`
 }
 
 // JWSUnprotectedHeader contains the set of unprotected headers.
@@ -34,6 +51,10 @@ type JWSUnprotectedHeader struct {
 	// List of X.509 Base64-DER-encoded certificates
 	// as defined at https://datatracker.ietf.org/doc/html/rfc7515#section-4.1.6.
 	CertChain [][]byte `json:"x5c"`
+
+	// Identifier of the software which produced the signature,
+	// e.g. "notation/1.0.0". It is informational only.
+	SigningAgent string `json:"io.cncf.notary.signingAgent,omitempty"`
 }
 
 // JWSEnvelope is the final signature envelope.
@@ -50,42 +71,3 @@ type JWSEnvelope struct {
 	// Base64URL-encoded signature.
 	Signature string `json:"signature"`
 }
-
-// JWS returns the JWS algorithm name.
-func (s SignatureAlgorithm) JWS() string {
-	switch s {
-	case RSASSA_PSS_SHA_256:
-		return "PS256"
-	case RSASSA_PSS_SHA_384:
-		return "PS384"
-	case RSASSA_PSS_SHA_512:
-		return "PS512"
-	case ECDSA_SHA_256:
-		return "ES256"
-	case ECDSA_SHA_384:
-		return "ES384"
-	case ECDSA_SHA_512:
-		return "ES512"
-	}
-	return ""
-}
-
-// NewSignatureAlgorithmJWS returns the algorithm associated to alg.
-// It returns an empty string if alg is not supported.
-func NewSignatureAlgorithmJWS(alg string) SignatureAlgorithm {
-	switch alg {
-	case "PS256":
-		return RSASSA_PSS_SHA_256
-	case "PS384":
-		return RSASSA_PSS_SHA_384
-	case "PS512":
-		return RSASSA_PSS_SHA_512
-	case "ES256":
-		return ECDSA_SHA_256
-	case "ES384":
-		return ECDSA_SHA_384
-	case "ES512":
-		return ECDSA_SHA_512
-	}
-	return ""
-}
@@ -0,0 +1,65 @@
+package notation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+)
+
+// KeySpecFromKey returns the key spec of a public key, or of the public key
+// of a private key or a crypto.Signer, e.g. to describe the key of a plugin
+// or a custom signer.
+// RSA keys of 2048, 3072 and 4096 bits, ECDSA keys on the P-256, P-384 and
+// P-521 curves, and Ed25519 keys are supported. The returned error has the
+// ErrorCodeUnsupportedKeySpec code if the key is not supported.
+func KeySpecFromKey(key interface{}) (KeySpec, error) {
+	if k, ok := key.(interface {
+		Public() crypto.PublicKey
+	}); ok {
+		key = k.Public()
+	}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch size := key.Size(); size {
+		case 256:
+			return RSA_2048, nil
+		case 384:
+			return RSA_3072, nil
+		case 512:
+			return RSA_4096, nil
+		default:
+			return "", Errorf(ErrorCodeUnsupportedKeySpec, "RSA key of size %d bits is not supported", key.N.BitLen())
+		}
+	case *ecdsa.PublicKey:
+		params := key.Curve.Params()
+		switch size := params.N.BitLen(); size {
+		case 256:
+			return EC_256, nil
+		case 384:
+			return EC_384, nil
+		case 521:
+			return EC_512, nil
+		default:
+			return "", Errorf(ErrorCodeUnsupportedKeySpec, "EC key %q of size %d bits is not supported", params.Name, size)
+		}
+	case ed25519.PublicKey:
+		return ED25519, nil
+	}
+	return "", Errorf(ErrorCodeUnsupportedKeySpec, "unsupported key type %T, only RSA, EC and Ed25519 keys are supported", key)
+}
+
+// KeySpecFromCertificate returns the key spec of the public key of cert, as
+// KeySpecFromKey does.
+func KeySpecFromCertificate(cert *x509.Certificate) (KeySpec, error) {
+	if cert == nil {
+		return "", Errorf(ErrorCodeInvalidArgument, "nil certificate")
+	}
+	keySpec, err := KeySpecFromKey(cert.PublicKey)
+	if err != nil {
+		return "", Errorf(ErrorCodeUnsupportedKeySpec, "certificate %q: %w", cert.Subject, err)
+	}
+	return keySpec, nil
+}
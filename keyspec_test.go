@@ -0,0 +1,107 @@
+package notation
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestKeySpecFromKey(t *testing.T) {
+	rsaKey := func(bits int) *rsa.PrivateKey {
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	ecKey := func(curve elliptic.Curve) *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa2048 := rsaKey(2048)
+	ec256 := ecKey(elliptic.P256())
+	tests := []struct {
+		name    string
+		key     interface{}
+		want    KeySpec
+		wantErr bool
+	}{
+		{name: "RSA 2048", key: rsa2048, want: RSA_2048},
+		{name: "RSA 2048 public key", key: &rsa2048.PublicKey, want: RSA_2048},
+		{name: "RSA 3072", key: rsaKey(3072), want: RSA_3072},
+		{name: "RSA 4096", key: rsaKey(4096), want: RSA_4096},
+		{name: "RSA 1024", key: rsaKey(1024), wantErr: true},
+		{name: "EC P-256", key: ec256, want: EC_256},
+		{name: "EC P-256 public key", key: &ec256.PublicKey, want: EC_256},
+		{name: "EC P-384", key: ecKey(elliptic.P384()), want: EC_384},
+		{name: "EC P-521", key: ecKey(elliptic.P521()), want: EC_512},
+		{name: "EC P-224", key: ecKey(elliptic.P224()), wantErr: true},
+		{name: "Ed25519", key: edKey, want: ED25519},
+		{name: "Ed25519 public key", key: edPublic, want: ED25519},
+		{name: "unsupported", key: "key", wantErr: true},
+		{name: "nil", key: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KeySpecFromKey(tt.key)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedKeySpec) {
+					t.Errorf("KeySpecFromKey() error = %v, want %v", err, ErrUnsupportedKeySpec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("KeySpecFromKey() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("KeySpecFromKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeySpecFromCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := KeySpecFromCertificate(cert)
+	if err != nil {
+		t.Fatalf("KeySpecFromCertificate() error = %v", err)
+	}
+	if got != EC_384 {
+		t.Errorf("KeySpecFromCertificate() = %v, want %v", got, EC_384)
+	}
+	if _, err := KeySpecFromCertificate(nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("KeySpecFromCertificate() error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,80 @@
+// Package log provides the logging interface of notation-go.
+//
+// Loggers are injected through the context with WithLogger, and the library
+// emits debug-level traces of plugin executions, registry calls and
+// verification steps to the logger of the context, if any.
+//
+// *logrus.Logger and *logrus.Entry implement Logger as is.
+// Loggers of log/slog can be adapted with NewSlogLogger.
+package log
+
+import "context"
+
+// Logger is a leveled logger.
+type Logger interface {
+	// Debug logs a message at debug level.
+	Debug(args ...interface{})
+
+	// Debugf logs a formatted message at debug level.
+	Debugf(format string, args ...interface{})
+
+	// Info logs a message at info level.
+	Info(args ...interface{})
+
+	// Infof logs a formatted message at info level.
+	Infof(format string, args ...interface{})
+
+	// Warn logs a message at warn level.
+	Warn(args ...interface{})
+
+	// Warnf logs a formatted message at warn level.
+	Warnf(format string, args ...interface{})
+
+	// Error logs a message at error level.
+	Error(args ...interface{})
+
+	// Errorf logs a formatted message at error level.
+	Errorf(format string, args ...interface{})
+}
+
+// Discard is a logger discarding all messages.
+var Discard Logger = discardLogger{}
+
+// contextKey is the context key of the logger.
+type contextKey struct{}
+
+// WithLogger returns a context carrying logger.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// GetLogger returns the logger of ctx, or Discard if ctx carries no logger.
+// Loggers taking a context, e.g. those of NewSlogLogger, log with ctx.
+func GetLogger(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(contextKey{}).(Logger); ok && logger != nil {
+		if logger, ok := logger.(contextLogger); ok {
+			return logger.withContext(ctx)
+		}
+		return logger
+	}
+	return Discard
+}
+
+// contextLogger is a logger logging with a context, e.g. so that handlers
+// can correlate the messages with the trace of the context.
+type contextLogger interface {
+	// withContext returns the logger logging with ctx.
+	withContext(ctx context.Context) Logger
+}
+
+// discardLogger discards all messages.
+type discardLogger struct{}
+
+func (discardLogger) Debug(args ...interface{})                 {}
+func (discardLogger) Debugf(format string, args ...interface{}) {}
+func (discardLogger) Info(args ...interface{})                  {}
+func (discardLogger) Infof(format string, args ...interface{})  {}
+func (discardLogger) Warn(args ...interface{})                  {}
+func (discardLogger) Warnf(format string, args ...interface{})  {}
+func (discardLogger) Error(args ...interface{})                 {}
+func (discardLogger) Errorf(format string, args ...interface{}) {}
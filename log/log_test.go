@@ -0,0 +1,30 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// recordingLogger records the formatted debug messages.
+type recordingLogger struct {
+	discardLogger
+	messages []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestGetLogger(t *testing.T) {
+	if logger := GetLogger(context.Background()); logger != Discard {
+		t.Errorf("GetLogger() = %v, want Discard", logger)
+	}
+
+	logger := &recordingLogger{}
+	ctx := WithLogger(context.Background(), logger)
+	GetLogger(ctx).Debugf("running %s", "sign")
+	if len(logger.messages) != 1 || logger.messages[0] != "running sign" {
+		t.Errorf("GetLogger() logged %v, want [running sign]", logger.messages)
+	}
+}
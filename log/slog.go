@@ -0,0 +1,63 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// NewSlogLogger adapts a slog.Logger to Logger. Messages are logged with the
+// context the logger is retrieved from by GetLogger, so that slog handlers
+// can read its values.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger, ctx: context.Background()}
+}
+
+// slogLogger logs to a slog.Logger with ctx.
+type slogLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+func (l *slogLogger) withContext(ctx context.Context) Logger {
+	return &slogLogger{logger: l.logger, ctx: ctx}
+}
+
+func (l *slogLogger) log(level slog.Level, msg string) {
+	l.logger.Log(l.ctx, level, msg)
+}
+
+func (l *slogLogger) Debug(args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprint(args...))
+}
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Info(args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Warn(args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Error(args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprint(args...))
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
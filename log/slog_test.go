@@ -0,0 +1,50 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	logger.Debugf("hidden %d", 1)
+	logger.Warnf("plugin %q is slow", "kms")
+	got := buf.String()
+	if strings.Contains(got, "hidden") {
+		t.Errorf("NewSlogLogger() logged %q below the handler level", got)
+	}
+	if !strings.Contains(got, "level=WARN") || !strings.Contains(got, `plugin \"kms\" is slow`) {
+		t.Errorf("NewSlogLogger() logged %q, want the warning", got)
+	}
+}
+
+// ctxHandler records the request IDs of the contexts of the records.
+type ctxHandler struct {
+	slog.Handler
+	requestIDs []interface{}
+}
+
+type requestIDKey struct{}
+
+func (h *ctxHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.requestIDs = append(h.requestIDs, ctx.Value(requestIDKey{}))
+	return nil
+}
+
+func TestNewSlogLogger_Context(t *testing.T) {
+	h := &ctxHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+	ctx := WithLogger(context.Background(), NewSlogLogger(slog.New(h)))
+	ctx = context.WithValue(ctx, requestIDKey{}, "42")
+	GetLogger(ctx).Info("verifying")
+	if len(h.requestIDs) != 1 || h.requestIDs[0] != "42" {
+		t.Errorf("NewSlogLogger() logged with request IDs %v, want [42]", h.requestIDs)
+	}
+}
@@ -0,0 +1,58 @@
+// Package metrics provides the metrics hooks of notation-go.
+//
+// Recorders are injected through the context with WithRecorder. The library
+// reports signing and verification results, and the latency of plugin
+// commands and registry operations to the recorder of the context, if any,
+// e.g. to be exported as Prometheus counters and histograms.
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// Recorder records metrics of signing and verification.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	// RecordSign records a signing, which failed if err is not nil.
+	RecordSign(err error)
+
+	// RecordVerify records a signature verification, which failed if err
+	// is not nil.
+	RecordVerify(err error)
+
+	// RecordPluginCommand records the latency of a command of a plugin.
+	RecordPluginCommand(plugin, command string, latency time.Duration, err error)
+
+	// RecordRegistryOperation records the latency of a registry operation,
+	// e.g. Resolve, ListSignatures, Get, Put or Link.
+	RecordRegistryOperation(operation string, latency time.Duration, err error)
+}
+
+// Discard is a recorder discarding all metrics.
+var Discard Recorder = discardRecorder{}
+
+// contextKey is the context key of the recorder.
+type contextKey struct{}
+
+// WithRecorder returns a context carrying recorder.
+func WithRecorder(ctx context.Context, recorder Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, recorder)
+}
+
+// GetRecorder returns the recorder of ctx, or Discard if ctx carries no recorder.
+func GetRecorder(ctx context.Context) Recorder {
+	if recorder, ok := ctx.Value(contextKey{}).(Recorder); ok && recorder != nil {
+		return recorder
+	}
+	return Discard
+}
+
+// discardRecorder discards all metrics.
+type discardRecorder struct{}
+
+func (discardRecorder) RecordSign(err error)   {}
+func (discardRecorder) RecordVerify(err error) {}
+func (discardRecorder) RecordPluginCommand(plugin, command string, latency time.Duration, err error) {
+}
+func (discardRecorder) RecordRegistryOperation(operation string, latency time.Duration, err error) {}
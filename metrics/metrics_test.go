@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingRecorder struct {
+	Recorder
+	signs int
+}
+
+func (r *countingRecorder) RecordSign(err error) { r.signs++ }
+
+func TestGetRecorder(t *testing.T) {
+	ctx := context.Background()
+	if got := GetRecorder(ctx); got != Discard {
+		t.Errorf("GetRecorder() = %v, want Discard", got)
+	}
+	Discard.RecordPluginCommand("plugin", "generate-signature", time.Second, nil)
+
+	recorder := &countingRecorder{Recorder: Discard}
+	ctx = WithRecorder(ctx, recorder)
+	GetRecorder(ctx).RecordSign(nil)
+	if recorder.signs != 1 {
+		t.Errorf("GetRecorder() recorded %d signs, want 1", recorder.signs)
+	}
+}
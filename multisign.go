@@ -0,0 +1,133 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MultiSignOptions contains parameters for SignMulti.
+type MultiSignOptions struct {
+	SignOptions
+
+	// Store stores a signature of the artifact, e.g. by pushing it to the
+	// registry and linking it to the artifact, and returns the descriptor
+	// of the stored signature, e.g. of its signature manifest.
+	Store func(ctx context.Context, desc Descriptor, signature []byte) (Descriptor, error)
+
+	// DeleteSignature deletes a signature stored by Store, described by
+	// sigDesc, to roll back the signatures stored before a storage failure.
+	// The stored signatures are kept if nil.
+	DeleteSignature func(ctx context.Context, sigDesc Descriptor) error
+}
+
+// MultiSignResult is the result of signing with a single signer with
+// SignMulti.
+type MultiSignResult struct {
+	// Signature is the signature of the signer.
+	Signature []byte
+
+	// SignatureDescriptor is the descriptor of the stored signature, as
+	// returned by MultiSignOptions.Store. It is empty if the signature is
+	// not stored, or if its storage was rolled back.
+	SignatureDescriptor Descriptor
+
+	// Err is non-nil if the signer failed to sign, or if the signature
+	// failed to be stored.
+	Err error
+}
+
+// MultiSignError is returned by SignMulti if any signer failed.
+type MultiSignError struct {
+	// Errs contains the error of each failed signer.
+	Errs []error
+
+	// Total is the number of signers.
+	Total int
+}
+
+// Error returns the number of failures and the first error.
+func (e *MultiSignError) Error() string {
+	return fmt.Sprintf("failed to sign with %d of %d signers: %v", len(e.Errs), e.Total, e.Errs[0])
+}
+
+// Unwrap returns the first error.
+func (e *MultiSignError) Unwrap() error {
+	return e.Errs[0]
+}
+
+// SignMulti signs the artifact described by desc with each of signers
+// concurrently, e.g. with an RSA organization key and an EC team key, and
+// stores all the signatures with opts.Store.
+//
+// The signatures are attached atomically: none is stored unless all the
+// signers succeed, and the signatures stored before a storage failure are
+// deleted with opts.DeleteSignature.
+//
+// A result is returned for each signer, in the order of signers.
+// The returned error is a *MultiSignError aggregating the per-signer errors
+// if any signer failed.
+func SignMulti(ctx context.Context, signers []Signer, desc Descriptor, opts MultiSignOptions) ([]MultiSignResult, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("no signers")
+	}
+	for i, signer := range signers {
+		if signer == nil {
+			return nil, fmt.Errorf("nil signer %d", i)
+		}
+	}
+
+	results := make([]MultiSignResult, len(signers))
+	var wg sync.WaitGroup
+	for i, signer := range signers {
+		wg.Add(1)
+		go func(signer Signer, result *MultiSignResult) {
+			defer wg.Done()
+			result.Signature, result.Err = signer.Sign(ctx, desc, opts.SignOptions)
+		}(signer, &results[i])
+	}
+	wg.Wait()
+	if err := multiSignError(results); err != nil {
+		return results, err
+	}
+	if opts.Store == nil || opts.DryRun {
+		return results, nil
+	}
+
+	// store the signatures, rolling back on failure
+	for i := range results {
+		result := &results[i]
+		result.SignatureDescriptor, result.Err = opts.Store(ctx, desc, result.Signature)
+		if result.Err == nil {
+			continue
+		}
+		result.Err = fmt.Errorf("failed to store signature: %w", result.Err)
+		if opts.DeleteSignature != nil {
+			for j := 0; j < i; j++ {
+				stored := &results[j]
+				if err := opts.DeleteSignature(ctx, stored.SignatureDescriptor); err != nil {
+					stored.Err = fmt.Errorf("failed to roll back stored signature %s: %w", stored.SignatureDescriptor.Digest, err)
+				} else {
+					stored.SignatureDescriptor = Descriptor{}
+				}
+			}
+		}
+		break
+	}
+	return results, multiSignError(results)
+}
+
+// multiSignError aggregates the errors of results, if any.
+func multiSignError(results []MultiSignResult) error {
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("signer %d: %w", i, result.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return &MultiSignError{Errs: errs, Total: len(results)}
+	}
+	return nil
+}
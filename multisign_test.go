@@ -0,0 +1,72 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// keySigner signs with the key it is named after, and fails if named "fail".
+type keySigner string
+
+func (s keySigner) Sign(ctx context.Context, desc Descriptor, opts SignOptions) ([]byte, error) {
+	if s == "fail" {
+		return nil, errors.New("sign failed")
+	}
+	return []byte(string(s) + ":" + string(desc.Digest)), nil
+}
+
+func TestSignMulti(t *testing.T) {
+	desc := Descriptor{Digest: digest.FromString("artifact")}
+	tests := []struct {
+		name       string
+		signers    []Signer
+		storeFails string
+		wantStored int
+		wantErrs   int
+	}{
+		{"all signed", []Signer{keySigner("rsa"), keySigner("ec")}, "", 2, 0},
+		{"signer failure", []Signer{keySigner("rsa"), keySigner("fail"), keySigner("ec")}, "", 0, 1},
+		{"storage failure rolled back", []Signer{keySigner("rsa"), keySigner("ec"), keySigner("ed")}, "ec", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := make(map[digest.Digest]bool)
+			results, err := SignMulti(context.Background(), tt.signers, desc, MultiSignOptions{
+				Store: func(ctx context.Context, desc Descriptor, signature []byte) (Descriptor, error) {
+					if string(signature) == tt.storeFails+":"+string(desc.Digest) {
+						return Descriptor{}, errors.New("store failed")
+					}
+					sigDesc := Descriptor{Digest: digest.FromBytes(signature)}
+					stored[sigDesc.Digest] = true
+					return sigDesc, nil
+				},
+				DeleteSignature: func(ctx context.Context, sigDesc Descriptor) error {
+					delete(stored, sigDesc.Digest)
+					return nil
+				},
+			})
+			var multiErr *MultiSignError
+			if tt.wantErrs == 0 && err != nil || tt.wantErrs > 0 && (!errors.As(err, &multiErr) || len(multiErr.Errs) != tt.wantErrs) {
+				t.Fatalf("SignMulti() error = %v, want %d failures", err, tt.wantErrs)
+			}
+			if len(results) != len(tt.signers) {
+				t.Fatalf("SignMulti() returned %d results, want %d", len(results), len(tt.signers))
+			}
+			for i, result := range results {
+				if s := tt.signers[i].(keySigner); s != "fail" && string(result.Signature) != string(s)+":"+string(desc.Digest) {
+					t.Errorf("results[%d].Signature = %s, want signature of %s", i, result.Signature, s)
+				}
+			}
+			if len(stored) != tt.wantStored {
+				t.Errorf("SignMulti() stored %d signatures, want %d", len(stored), tt.wantStored)
+			}
+		})
+	}
+
+	if _, err := SignMulti(context.Background(), nil, desc, MultiSignOptions{}); err == nil {
+		t.Error("SignMulti() error = nil, want error without signers")
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
 	"time"
 
 	"github.com/notaryproject/notation-go/crypto/timestamp"
@@ -13,6 +17,9 @@ import (
 // Media type for Notary payload for OCI artifacts, which contains an artifact descriptor.
 const MediaTypePayload = "application/vnd.cncf.notary.payload.v1+json"
 
+// MediaTypeCOSEEnvelope describes the media type of the COSE envelope.
+const MediaTypeCOSEEnvelope = "application/cose"
+
 // Descriptor describes the content signed or to be signed.
 type Descriptor struct {
 	// The media type of the targeted content.
@@ -26,6 +33,78 @@ type Descriptor struct {
 
 	// Contains optional user defined attributes.
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ArtifactType is the type of the artifact of the targeted content,
+	// e.g. the artifact type of an artifact manifest, if any.
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// DefaultDigestAlgorithms are the digest algorithms allowed by
+// Descriptor.Validate if no digest algorithm policy is set.
+var DefaultDigestAlgorithms = []digest.Algorithm{
+	digest.SHA256,
+	digest.SHA384,
+	digest.SHA512,
+}
+
+// Validate reports whether the descriptor is well-formed, and whether its
+// digest algorithm is one of DefaultDigestAlgorithms.
+func (d Descriptor) Validate() error {
+	return d.ValidateDigestAlgorithms(nil)
+}
+
+// ValidateDigestAlgorithms reports whether the descriptor is well-formed,
+// and whether its digest algorithm is one of allowed.
+// DefaultDigestAlgorithms is used if allowed is empty.
+func (d Descriptor) ValidateDigestAlgorithms(allowed []digest.Algorithm) error {
+	if d.MediaType == "" {
+		return errors.New("descriptor media type cannot be empty")
+	}
+	if d.Size < 0 {
+		return fmt.Errorf("invalid descriptor size %d", d.Size)
+	}
+	if len(allowed) == 0 {
+		allowed = DefaultDigestAlgorithms
+	}
+	alg := d.Digest.Algorithm()
+	var ok bool
+	for _, a := range allowed {
+		if a == alg {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("descriptor digest algorithm %q is not allowed, allowed algorithms are %v", alg, allowed)
+	}
+	if err := d.Digest.Validate(); err != nil {
+		return fmt.Errorf("invalid descriptor digest: %w", err)
+	}
+	return nil
+}
+
+// ValidateMediaTypes reports whether the media type of the descriptor is
+// one of mediaTypes, and whether its artifact type is one of artifactTypes.
+// Any media type, respectively artifact type, is allowed if the list is
+// empty.
+func (d Descriptor) ValidateMediaTypes(mediaTypes, artifactTypes []string) error {
+	if len(mediaTypes) > 0 && !isPresent(d.MediaType, mediaTypes) {
+		return fmt.Errorf("descriptor media type %q is not allowed, allowed media types are %q", d.MediaType, mediaTypes)
+	}
+	if len(artifactTypes) > 0 && !isPresent(d.ArtifactType, artifactTypes) {
+		return fmt.Errorf("descriptor artifact type %q is not allowed, allowed artifact types are %q", d.ArtifactType, artifactTypes)
+	}
+	return nil
+}
+
+// isPresent reports whether val is in values.
+func isPresent(val string, values []string) bool {
+	for _, v := range values {
+		if v == val {
+			return true
+		}
+	}
+	return false
 }
 
 // Equal reports whether d and t points to the same content.
@@ -47,8 +126,214 @@ type SignOptions struct {
 	// An empty list of `KeyUsages` in the verify options implies ExtKeyUsageTimeStamping.
 	TSAVerifyOptions x509.VerifyOptions
 
-	// Sets or overrides the plugin configuration.
+	// SigningScheme is the signing scheme of the resulted signature.
+	// SigningSchemeX509 is used if empty.
+	SigningScheme SigningScheme
+
+	// PayloadContentType is the content type of the signed payload, written
+	// to the cty protected header, e.g. a successor of MediaTypePayload or a
+	// vendor media type. Verifiers only accept it if listed in
+	// VerifyOptions.AllowedPayloadContentTypes. MediaTypePayload is used if
+	// empty.
+	PayloadContentType string
+
+	// EnvelopeType is the media type of the resulted signature envelope,
+	// either MediaTypeJWSEnvelope or MediaTypeCOSEEnvelope.
+	// MediaTypeJWSEnvelope is used if empty. COSE envelopes are assembled
+	// from raw signatures, so that they are not supported by plugins only
+	// generating envelopes, and are not timestamped.
+	EnvelopeType string
+
+	// PluginConfig sets or overrides the plugin configuration passed to the
+	// describe-key, generate-signature and generate-envelope commands,
+	// e.g. the region, profile or endpoint used by a KMS plugin.
+	// Entries take precedence over the configuration the signer was created with.
 	PluginConfig map[string]string
+
+	// UserMetadata contains user defined key/value pairs, e.g. build provenance
+	// labels, signed as annotations of the descriptor in the payload.
+	// Keys must not start with ReservedAnnotationPrefix nor collide with
+	// the annotations of the descriptor.
+	UserMetadata map[string]string
+
+	// SignedAnnotations restricts the annotations of the descriptor signed
+	// to the listed keys, e.g. org.opencontainers.image.revision. The keys
+	// are recorded in the signed descriptor under AnnotationSignedAnnotations,
+	// so that verifiers can check the current annotations of the artifact
+	// still match, including that the listed annotations missing at signing
+	// time are still missing. All the annotations are signed if empty.
+	SignedAnnotations []string
+
+	// ExtendedSignedAttributes are added to the signed attributes of the
+	// resulted signature, e.g. to declare the verification plugin.
+	ExtendedSignedAttributes []SignedAttribute
+
+	// AllowedDigestAlgorithms restricts the digest algorithms of the
+	// descriptors signed. DefaultDigestAlgorithms is used if empty.
+	AllowedDigestAlgorithms []digest.Algorithm
+
+	// SigningAgent identifies the software producing the signature,
+	// e.g. "notation/1.0.0", and is written to the unprotected header of
+	// the envelope. Since it is not signed, it is informational only.
+	SigningAgent string
+
+	// Clock returns the signing time of the resulted signature.
+	// time.Now is used if nil.
+	Clock func() time.Time
+
+	// Deterministic makes the resulted signature envelope reproducible, e.g.
+	// for reproducible builds. The signing time is taken from Clock, which is
+	// then required, the JSON keys and the critical headers are ordered, and
+	// the unprotected headers which do not only depend on the inputs, i.e. the
+	// timestamp countersignature and the signing agent, are omitted even if
+	// TSA or SigningAgent is set.
+	// Two signings of the same payload with the same key at the same time
+	// then produce byte-identical envelopes, provided the signature algorithm
+	// is deterministic, i.e. Ed25519. RSASSA-PSS and ECDSA signatures are
+	// randomized, so that only the protected header and the payload are
+	// reproduced.
+	Deterministic bool
+
+	// DryRun validates the signing request without signing, e.g. to check a
+	// pipeline before it runs: the plugin metadata and the key are checked,
+	// the certificate chain is validated against the certificate policy if
+	// it is known without signing, and the signature envelope is built up to
+	// its signing input. Sign then returns a nil signature, and SignBatch and
+	// Resign store and delete nothing.
+	DryRun bool
+
+	// OnDryRun is called with the report of what would be signed when
+	// DryRun is set.
+	OnDryRun func(report DryRunReport)
+}
+
+// DryRunReport reports what a signer would sign, as found by a dry run.
+type DryRunReport struct {
+	// Descriptor is the descriptor which would be signed, including the
+	// user metadata.
+	Descriptor Descriptor
+
+	// KeyID is the ID of the signing key.
+	KeyID string
+
+	// KeySpec is the key spec of the signing key, if known without signing.
+	KeySpec KeySpec
+
+	// SignatureAlgorithm is the signature algorithm of the signing key, if
+	// known without signing.
+	SignatureAlgorithm SignatureAlgorithm
+
+	// SigningScheme is the signing scheme of the signature.
+	SigningScheme SigningScheme
+
+	// SigningTime is the signing time of the signature.
+	SigningTime time.Time
+
+	// Expiry is the expiry of the signature, if any.
+	Expiry time.Time
+
+	// SigningInput is the input of the signature algorithm, i.e. the
+	// encoded protected header and payload of the JWS envelope, if built
+	// without signing.
+	SigningInput []byte
+
+	// CertificateChain is the certificate chain of the signing key, if
+	// known without signing.
+	CertificateChain []*x509.Certificate
+}
+
+// SignedAttribute is an extended attribute signed along with the payload.
+type SignedAttribute struct {
+	// Key is the name of the attribute.
+	Key string
+
+	// Value is the value of the attribute, which must be JSON serializable.
+	Value interface{}
+
+	// Critical requires verifiers to understand and process the attribute,
+	// and to reject the signature otherwise.
+	Critical bool
+}
+
+// ReservedAnnotationPrefix is the prefix of the annotation keys reserved
+// for Notary, which cannot be used as user metadata keys.
+const ReservedAnnotationPrefix = "io.cncf.notary."
+
+// AnnotationSignedAnnotations is the annotation of the signed descriptor
+// listing the keys of SignOptions.SignedAnnotations, comma separated.
+const AnnotationSignedAnnotations = ReservedAnnotationPrefix + "signedAnnotations"
+
+// Validate does basic validation on SignOptions.
+func (opts SignOptions) Validate() error {
+	if opts.SigningScheme != "" {
+		if err := opts.SigningScheme.Validate(); err != nil {
+			return err
+		}
+	}
+	if opts.PayloadContentType != "" {
+		if _, _, err := mime.ParseMediaType(opts.PayloadContentType); err != nil {
+			return fmt.Errorf("invalid payload content type %q: %w", opts.PayloadContentType, err)
+		}
+	}
+	switch opts.EnvelopeType {
+	case "", MediaTypeJWSEnvelope, MediaTypeCOSEEnvelope:
+	default:
+		return fmt.Errorf("unsupported envelope type %q", opts.EnvelopeType)
+	}
+	if opts.Deterministic && opts.Clock == nil {
+		return errors.New("deterministic signing requires a clock")
+	}
+	keys := make(map[string]bool, len(opts.ExtendedSignedAttributes))
+	for _, attr := range opts.ExtendedSignedAttributes {
+		if attr.Key == "" {
+			return errors.New("extended signed attribute key cannot be empty")
+		}
+		if keys[attr.Key] {
+			return fmt.Errorf("duplicate extended signed attribute %q", attr.Key)
+		}
+		keys[attr.Key] = true
+	}
+	for k := range opts.UserMetadata {
+		if k == "" {
+			return errors.New("user metadata key cannot be empty")
+		}
+		if strings.HasPrefix(k, ReservedAnnotationPrefix) {
+			return fmt.Errorf("user metadata key %q is reserved", k)
+		}
+	}
+	for _, k := range opts.SignedAnnotations {
+		if k == "" || strings.Contains(k, ",") {
+			return fmt.Errorf("invalid signed annotation key %q", k)
+		}
+		if strings.HasPrefix(k, ReservedAnnotationPrefix) {
+			return fmt.Errorf("signed annotation key %q is reserved", k)
+		}
+	}
+	return nil
+}
+
+// SigningScheme identifies the trust model of a signature, as defined in
+// https://github.com/notaryproject/notaryproject/blob/main/signing-scheme.md.
+type SigningScheme string
+
+const (
+	// SigningSchemeX509 identifies signatures whose signing certificate
+	// chains to a trusted CA. A timestamp countersignature may be used
+	// to verify signatures after the signing certificate expired.
+	SigningSchemeX509 SigningScheme = "notary.x509"
+
+	// SigningSchemeX509SigningAuthority identifies signatures produced by
+	// a signing authority, which attests the signing time of the signature.
+	SigningSchemeX509SigningAuthority SigningScheme = "notary.x509.signingAuthority"
+)
+
+// Validate returns an error if the signing scheme is not supported.
+func (s SigningScheme) Validate() error {
+	switch s {
+	case SigningSchemeX509, SigningSchemeX509SigningAuthority:
+		return nil
+	}
+	return fmt.Errorf("signing scheme %q is not supported", s)
 }
 
 // Signer is a generic interface for signing an artifact.
@@ -61,18 +346,81 @@ type Signer interface {
 }
 
 // VerifyOptions contains parameters for Verifier.Verify.
-type VerifyOptions struct{}
+type VerifyOptions struct {
+	// UserMetadata contains the key/value pairs the signed user metadata
+	// must contain. Verification fails if any of them is missing or differs.
+	UserMetadata map[string]string
+
+	// ArtifactAnnotations are the current annotations of the artifact, e.g.
+	// of its manifest. If not nil, they must match the annotations signed
+	// by signatures restricting their signed annotations with
+	// SignOptions.SignedAnnotations.
+	ArtifactAnnotations map[string]string
+
+	// ExpiryWarningPeriod enables warnings for signatures about to expire.
+	// If positive, OnExpiryWarning is called when a valid signature expires
+	// within this period, e.g. 30 * 24 * time.Hour to be warned 30 days ahead.
+	ExpiryWarningPeriod time.Duration
+
+	// OnExpiryWarning is called with the descriptor of the signed artifact
+	// and the expiry of its signature when the signature expires within
+	// ExpiryWarningPeriod. It does not affect the verification result.
+	OnExpiryWarning func(desc Descriptor, expiry time.Time)
+
+	// AllowedDigestAlgorithms restricts the digest algorithms of the
+	// descriptors signed, e.g. to require sha384 or stronger.
+	// DefaultDigestAlgorithms is used if empty.
+	AllowedDigestAlgorithms []digest.Algorithm
+
+	// AllowedMediaTypes restricts the media types of the descriptors
+	// signed, e.g. to OCI image manifests. Any media type is allowed if
+	// empty.
+	AllowedMediaTypes []string
+
+	// AllowedArtifactTypes restricts the artifact types of the descriptors
+	// signed. Descriptors without artifact type are only allowed if the
+	// empty artifact type is listed. Any artifact type is allowed if empty.
+	AllowedArtifactTypes []string
+
+	// AllowedPayloadContentTypes are the content types of the signed
+	// payloads accepted, as set by SignOptions.PayloadContentType.
+	// Only MediaTypePayload is accepted if empty.
+	AllowedPayloadContentTypes []string
+
+	// Clock returns the time at which the signature is verified, i.e. the
+	// time at which its expiry and the validity of its certificates are
+	// evaluated, e.g. to replay past verifications for auditing.
+	// time.Now is used if nil.
+	Clock func() time.Time
+}
 
 // Validate does basic validation on VerifyOptions.
 func (opts VerifyOptions) Validate() error {
+	if opts.ExpiryWarningPeriod < 0 {
+		return errors.New("expiry warning period cannot be negative")
+	}
 	return nil
 }
 
+// WarnExpiry calls OnExpiryWarning if expiry is non-zero and within
+// ExpiryWarningPeriod of now.
+func (opts VerifyOptions) WarnExpiry(desc Descriptor, expiry, now time.Time) {
+	if opts.OnExpiryWarning == nil || opts.ExpiryWarningPeriod <= 0 || expiry.IsZero() {
+		return
+	}
+	if expiry.Sub(now) <= opts.ExpiryWarningPeriod {
+		opts.OnExpiryWarning(desc, expiry)
+	}
+}
+
 // Verifier is a generic interface for verifying an artifact.
 type Verifier interface {
-	// Verify verifies the signature and returns the verified descriptor and
-	// metadata of the signed artifact.
-	Verify(ctx context.Context, signature []byte, opts VerifyOptions) (Descriptor, error)
+	// Verify verifies the signature and returns the outcome of the verification,
+	// including the verified descriptor of the signed artifact.
+	//
+	// The returned error is non-nil if any check failed, in which case
+	// the outcome is still returned and reports the result of every check.
+	Verify(ctx context.Context, signature []byte, opts VerifyOptions) (*VerificationOutcome, error)
 }
 
 // Service combines the signing and verification services.
@@ -93,25 +441,18 @@ const (
 	EC_256   KeySpec = "EC_256"
 	EC_384   KeySpec = "EC_384"
 	EC_512   KeySpec = "EC_512"
+
+	// ED25519 is not defined by the Notary V2 specification and is only
+	// supported for keys which are not available in any other spec.
+	ED25519 KeySpec = "ED25519"
 )
 
 // SignatureAlgorithm returns the signing algorithm associated with KeyType k.
 func (k KeySpec) SignatureAlgorithm() SignatureAlgorithm {
-	switch k {
-	case RSA_2048:
-		return RSASSA_PSS_SHA_256
-	case RSA_3072:
-		return RSASSA_PSS_SHA_384
-	case RSA_4096:
-		return RSASSA_PSS_SHA_512
-	case EC_256:
-		return ECDSA_SHA_256
-	case EC_384:
-		return ECDSA_SHA_384
-	case EC_512:
-		return ECDSA_SHA_512
-	}
-	return ""
+	info, _ := lookupSignatureAlgorithm(func(info SignatureAlgorithmInfo) bool {
+		return info.KeySpec == k
+	})
+	return info.Algorithm
 }
 
 // HashAlgorithm algorithm associated with the key spec.
@@ -146,17 +487,8 @@ const (
 	ECDSA_SHA_256      SignatureAlgorithm = "ECDSA_SHA_256"
 	ECDSA_SHA_384      SignatureAlgorithm = "ECDSA_SHA_384"
 	ECDSA_SHA_512      SignatureAlgorithm = "ECDSA_SHA_512"
-)
 
-// Hash returns the Hash associated s.
-func (s SignatureAlgorithm) Hash() HashAlgorithm {
-	switch s {
-	case RSASSA_PSS_SHA_256, ECDSA_SHA_256:
-		return SHA256
-	case RSASSA_PSS_SHA_384, ECDSA_SHA_384:
-		return SHA384
-	case RSASSA_PSS_SHA_512, ECDSA_SHA_512:
-		return SHA512
-	}
-	return ""
-}
+	// EDDSA_ED25519 signs the payload itself rather than its digest,
+	// hashing it internally with SHA-512 as defined in RFC 8032.
+	EDDSA_ED25519 SignatureAlgorithm = "EDDSA_ED25519"
+)
@@ -0,0 +1,104 @@
+package notation
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestDescriptor_ValidateDigestAlgorithms(t *testing.T) {
+	valid := Descriptor{
+		MediaType: MediaTypePayload,
+		Digest:    digest.FromString("content"),
+		Size:      7,
+	}
+	tests := []struct {
+		name    string
+		desc    func(d Descriptor) Descriptor
+		allowed []digest.Algorithm
+		wantErr bool
+	}{
+		{name: "valid", desc: func(d Descriptor) Descriptor { return d }},
+		{
+			name: "sha512",
+			desc: func(d Descriptor) Descriptor {
+				d.Digest = digest.SHA512.FromString("content")
+				return d
+			},
+		},
+		{
+			name: "missing media type",
+			desc: func(d Descriptor) Descriptor {
+				d.MediaType = ""
+				return d
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative size",
+			desc: func(d Descriptor) Descriptor {
+				d.Size = -1
+				return d
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed digest",
+			desc: func(d Descriptor) Descriptor {
+				d.Digest = "sha256:invalid"
+				return d
+			},
+			wantErr: true,
+		},
+		{
+			name: "sha1",
+			desc: func(d Descriptor) Descriptor {
+				d.Digest = "sha1:0a0a9f2a6772942557ab5355d76af442f8f65e01"
+				return d
+			},
+			wantErr: true,
+		},
+		{
+			name:    "not allowed by policy",
+			desc:    func(d Descriptor) Descriptor { return d },
+			allowed: []digest.Algorithm{digest.SHA384, digest.SHA512},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.desc(valid).ValidateDigestAlgorithms(tt.allowed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDigestAlgorithms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDescriptor_ValidateMediaTypes(t *testing.T) {
+	const artifactManifest = "application/vnd.cncf.oras.artifact.manifest.v1+json"
+	image := Descriptor{MediaType: "application/vnd.oci.image.manifest.v1+json"}
+	sbom := Descriptor{MediaType: artifactManifest, ArtifactType: "application/spdx+json"}
+	tests := []struct {
+		name          string
+		desc          Descriptor
+		mediaTypes    []string
+		artifactTypes []string
+		wantErr       bool
+	}{
+		{name: "no allowlist", desc: sbom},
+		{name: "allowed media type", desc: image, mediaTypes: []string{image.MediaType}},
+		{name: "media type not allowed", desc: sbom, mediaTypes: []string{image.MediaType}, wantErr: true},
+		{name: "allowed artifact type", desc: sbom, mediaTypes: []string{image.MediaType, artifactManifest}, artifactTypes: []string{"application/spdx+json"}},
+		{name: "artifact type not allowed", desc: sbom, artifactTypes: []string{"application/vnd.cyclonedx+json"}, wantErr: true},
+		{name: "no artifact type", desc: image, artifactTypes: []string{"application/spdx+json"}, wantErr: true},
+		{name: "empty artifact type allowed", desc: image, artifactTypes: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.desc.ValidateMediaTypes(tt.mediaTypes, tt.artifactTypes); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMediaTypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package notation
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// VerificationCheck is a check performed while verifying a signature.
+type VerificationCheck string
+
+const (
+	// CheckIntegrity checks the signature was produced by the key of the
+	// signing certificate and the envelope has not been tampered with.
+	CheckIntegrity VerificationCheck = "integrity"
+
+	// CheckAuthenticity checks the certificate chain of the signature
+	// chains up to a trusted root.
+	CheckAuthenticity VerificationCheck = "authenticity"
+
+	// CheckTrustedIdentity checks the signing identity is trusted.
+	CheckTrustedIdentity VerificationCheck = "trustedIdentity"
+
+	// CheckExpiry checks the signature has not expired.
+	CheckExpiry VerificationCheck = "expiry"
+
+	// CheckRevocation checks the certificate chain has not been revoked.
+	CheckRevocation VerificationCheck = "revocation"
+
+	// CheckTimestamp checks the timestamp countersignature of the signature.
+	CheckTimestamp VerificationCheck = "timestamp"
+
+	// CheckUserMetadata checks the signed user metadata contains the
+	// key/value pairs required by VerifyOptions.UserMetadata.
+	// It is only performed if VerifyOptions.UserMetadata is not empty.
+	CheckUserMetadata VerificationCheck = "userMetadata"
+
+	// CheckAnnotations checks the current annotations of the artifact,
+	// provided by VerifyOptions.ArtifactAnnotations, match the annotations
+	// listed as signed by the signature. It is only performed if
+	// VerifyOptions.ArtifactAnnotations is not nil and the signature lists
+	// its signed annotations under AnnotationSignedAnnotations.
+	CheckAnnotations VerificationCheck = "annotations"
+
+	// CheckPolicy checks the policy evaluator of the verifier admits the
+	// artifact once the signature satisfies the trust policy. It is only
+	// performed if a policy evaluator is configured.
+	CheckPolicy VerificationCheck = "policy"
+)
+
+// VerificationStatus is the status of a VerificationCheck.
+type VerificationStatus string
+
+const (
+	VerificationPassed  VerificationStatus = "passed"
+	VerificationFailed  VerificationStatus = "failed"
+	VerificationSkipped VerificationStatus = "skipped"
+)
+
+// VerificationSeverity is the severity of a failed VerificationCheck, as
+// determined by the verification level of the applicable trust policy.
+type VerificationSeverity string
+
+const (
+	// SeverityError is the severity of a failed check enforced by the
+	// verification level, which fails the verification.
+	SeverityError VerificationSeverity = "error"
+
+	// SeverityWarning is the severity of a failed check logged by the
+	// verification level, which does not fail the verification.
+	SeverityWarning VerificationSeverity = "warning"
+
+	// SeverityInfo is the severity of a failed check skipped by the
+	// verification level, which is ignored.
+	SeverityInfo VerificationSeverity = "info"
+)
+
+// VerificationResult is the result of a single VerificationCheck.
+type VerificationResult struct {
+	Check  VerificationCheck
+	Status VerificationStatus
+
+	// Error is non-nil if Status is VerificationFailed.
+	Error error
+
+	// Severity is the severity of the failure if Status is
+	// VerificationFailed. It is only set when verifying against a trust
+	// policy, and is empty otherwise.
+	Severity VerificationSeverity
+}
+
+// VerificationOutcome reports the result of each check performed
+// while verifying a signature.
+type VerificationOutcome struct {
+	// SignatureDigest is the digest of the signature envelope, if the
+	// signature was fetched from a repository.
+	SignatureDigest digest.Digest
+
+	// Descriptor is the descriptor of the signed artifact.
+	// It is only populated if the integrity check passed.
+	Descriptor Descriptor
+
+	// UserMetadata contains the signed annotations of the descriptor,
+	// including the user metadata provided at signing time.
+	UserMetadata map[string]string
+
+	// ExtendedSignedAttributes contains the extended signed attributes
+	// of the signature.
+	ExtendedSignedAttributes []SignedAttribute
+
+	// SigningScheme is the signing scheme of the signature.
+	// It is empty if the integrity check failed.
+	SigningScheme SigningScheme
+
+	// SigningTime is the signing time claimed by the signer, which is not
+	// attested. It is only populated for SigningSchemeX509.
+	SigningTime time.Time
+
+	// AuthenticSigningTime is the signing time attested by the signing
+	// authority. It is only populated for SigningSchemeX509SigningAuthority.
+	AuthenticSigningTime time.Time
+
+	// Expiry is the expiry of the signature declared in the envelope.
+	// It is zero if the signature never expires or if the integrity
+	// check failed.
+	Expiry time.Time
+
+	// SigningAgent identifies the software which produced the signature,
+	// as declared in the unprotected header of the envelope. It is not
+	// signed, so it must only be used for informational purposes.
+	SigningAgent string
+
+	// CertificateChain is the certificate chain of the signature,
+	// starting with the signing certificate.
+	// It is only populated if the integrity check passed.
+	CertificateChain []*x509.Certificate
+
+	// VerifiedChains are the certificate chains built from the signing
+	// certificate to a trust anchor when verifying the signing certificate,
+	// starting with the signing certificate and ending with the trust
+	// anchor. Unlike CertificateChain, which is read from the envelope,
+	// they only hold verified certificates. They are only populated if the
+	// authenticity check passed.
+	VerifiedChains [][]*x509.Certificate
+
+	// SelfSigned reports whether the signing certificate is self-signed,
+	// rather than issued by a CA. It is only populated if the integrity
+	// check passed.
+	SelfSigned bool
+
+	// Results contains the results of the checks in the order they were
+	// first performed, with at most one result per check.
+	Results []*VerificationResult
+
+	// Skipped reports that signature verification was skipped by the trust
+	// policy. No signature is fetched and no check is performed in that
+	// case, so Results is empty and Descriptor only carries the digest of
+	// the artifact if it is referenced by digest.
+	Skipped bool
+}
+
+// IsSelfSigned reports whether cert is self-signed, i.e. issued by itself
+// and signed by its own key.
+func IsSelfSigned(cert *x509.Certificate) bool {
+	// CheckSignatureFrom would require cert to be a CA certificate
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// Pass records check as passed.
+func (o *VerificationOutcome) Pass(check VerificationCheck) {
+	o.set(&VerificationResult{Check: check, Status: VerificationPassed})
+}
+
+// Skip records check as skipped.
+func (o *VerificationOutcome) Skip(check VerificationCheck) {
+	o.set(&VerificationResult{Check: check, Status: VerificationSkipped})
+}
+
+// Fail records check as failed with err and returns err.
+func (o *VerificationOutcome) Fail(check VerificationCheck, err error) error {
+	o.set(&VerificationResult{Check: check, Status: VerificationFailed, Error: err})
+	return err
+}
+
+// set records result, replacing the previous result of the same check if any.
+func (o *VerificationOutcome) set(result *VerificationResult) {
+	for i, r := range o.Results {
+		if r.Check == result.Check {
+			o.Results[i] = result
+			return
+		}
+	}
+	o.Results = append(o.Results, result)
+}
+
+// Result returns the result of check, or nil if check was not performed.
+func (o *VerificationOutcome) Result(check VerificationCheck) *VerificationResult {
+	for _, r := range o.Results {
+		if r.Check == check {
+			return r
+		}
+	}
+	return nil
+}
+
+// Failures returns the results of the failed checks, in the order they were
+// first performed.
+func (o *VerificationOutcome) Failures() []*VerificationResult {
+	var failures []*VerificationResult
+	for _, r := range o.Results {
+		if r.Status == VerificationFailed {
+			failures = append(failures, r)
+		}
+	}
+	return failures
+}
+
+// Err returns the error of the first failed check, or nil if no check failed.
+func (o *VerificationOutcome) Err() error {
+	for _, r := range o.Results {
+		if r.Status == VerificationFailed {
+			return r.Error
+		}
+	}
+	return nil
+}
+
+// String returns a one-line summary of the check results.
+func (r *VerificationResult) String() string {
+	if r.Error != nil {
+		if r.Severity != "" {
+			return fmt.Sprintf("%s: %s (%s): %v", r.Check, r.Status, r.Severity, r.Error)
+		}
+		return fmt.Sprintf("%s: %s: %v", r.Check, r.Status, r.Error)
+	}
+	return fmt.Sprintf("%s: %s", r.Check, r.Status)
+}
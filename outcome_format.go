@@ -0,0 +1,182 @@
+package notation
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// outcomeJSON is the JSON representation of a VerificationOutcome.
+// Its fields are only ever added, so that the representation is stable.
+type outcomeJSON struct {
+	SignatureDigest          digest.Digest            `json:"signatureDigest,omitempty"`
+	Descriptor               *Descriptor              `json:"descriptor,omitempty"`
+	Skipped                  bool                     `json:"skipped,omitempty"`
+	SigningScheme            SigningScheme            `json:"signingScheme,omitempty"`
+	SigningTime              *time.Time               `json:"signingTime,omitempty"`
+	AuthenticSigningTime     *time.Time               `json:"authenticSigningTime,omitempty"`
+	Expiry                   *time.Time               `json:"expiry,omitempty"`
+	SigningAgent             string                   `json:"signingAgent,omitempty"`
+	UserMetadata             map[string]string        `json:"userMetadata,omitempty"`
+	ExtendedSignedAttributes []signedAttributeJSON    `json:"extendedSignedAttributes,omitempty"`
+	CertificateChain         []certificateJSON        `json:"certificateChain,omitempty"`
+	SelfSigned               *bool                    `json:"selfSigned,omitempty"`
+	Results                  []verificationResultJSON `json:"results"`
+}
+
+// signedAttributeJSON is the JSON representation of a SignedAttribute.
+type signedAttributeJSON struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Critical bool        `json:"critical,omitempty"`
+}
+
+// certificateJSON describes a certificate of the certificate chain.
+type certificateJSON struct {
+	Subject           string    `json:"subject"`
+	Issuer            string    `json:"issuer"`
+	SerialNumber      string    `json:"serialNumber"`
+	NotBefore         time.Time `json:"notBefore"`
+	NotAfter          time.Time `json:"notAfter"`
+	SHA256Fingerprint string    `json:"sha256Fingerprint"`
+}
+
+// verificationResultJSON is the JSON representation of a
+// VerificationResult.
+type verificationResultJSON struct {
+	Check     VerificationCheck    `json:"check"`
+	Status    VerificationStatus   `json:"status"`
+	Severity  VerificationSeverity `json:"severity,omitempty"`
+	ErrorCode ErrorCode            `json:"errorCode,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes the outcome as JSON with stable field names, e.g. to
+// report verifications in CI logs. Certificates are described by their
+// subject, issuer, serial number, validity and SHA-256 fingerprint, and the
+// errors of the results by their message and error code, if any.
+func (o *VerificationOutcome) MarshalJSON() ([]byte, error) {
+	out := outcomeJSON{
+		SignatureDigest: o.SignatureDigest,
+		Skipped:         o.Skipped,
+		SigningScheme:   o.SigningScheme,
+		SigningAgent:    o.SigningAgent,
+		UserMetadata:    o.UserMetadata,
+		Results:         make([]verificationResultJSON, len(o.Results)),
+	}
+	if o.Descriptor.Digest != "" {
+		desc := o.Descriptor
+		out.Descriptor = &desc
+	}
+	out.SigningTime = optionalTime(o.SigningTime)
+	out.AuthenticSigningTime = optionalTime(o.AuthenticSigningTime)
+	out.Expiry = optionalTime(o.Expiry)
+	for _, attr := range o.ExtendedSignedAttributes {
+		out.ExtendedSignedAttributes = append(out.ExtendedSignedAttributes, signedAttributeJSON(attr))
+	}
+	for _, cert := range o.CertificateChain {
+		out.CertificateChain = append(out.CertificateChain, describeCertificate(cert))
+	}
+	if len(o.CertificateChain) > 0 {
+		// reported whenever known, so that CA-issued is not mistaken for unknown
+		selfSigned := o.SelfSigned
+		out.SelfSigned = &selfSigned
+	}
+	for i, r := range o.Results {
+		result := verificationResultJSON{
+			Check:    r.Check,
+			Status:   r.Status,
+			Severity: r.Severity,
+		}
+		if r.Error != nil {
+			result.Error = r.Error.Error()
+			var e *Error
+			if errors.As(r.Error, &e) {
+				result.ErrorCode = e.Code
+			}
+		}
+		out.Results[i] = result
+	}
+	return json.Marshal(out)
+}
+
+// optionalTime returns a pointer to t in UTC, or nil if t is zero.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// describeCertificate describes cert for reports.
+func describeCertificate(cert *x509.Certificate) certificateJSON {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return certificateJSON{
+		Subject:           cert.Subject.String(),
+		Issuer:            cert.Issuer.String(),
+		SerialNumber:      cert.SerialNumber.Text(16),
+		NotBefore:         cert.NotBefore.UTC(),
+		NotAfter:          cert.NotAfter.UTC(),
+		SHA256Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// Summary returns a human readable summary of the outcome, with one line
+// per check, e.g. for CLIs:
+//
+//	signature sha256:4d3e... of sha256:7f1a... (application/vnd.oci.image.manifest.v1+json)
+//	  signed by "CN=release,O=Acme" (CA-issued) with signing scheme notary.x509 at 2022-07-01T10:00:00Z
+//	  integrity: passed
+//	  authenticity: failed (error): x509: certificate signed by unknown authority
+func (o *VerificationOutcome) Summary() string {
+	var b strings.Builder
+	if o.Skipped {
+		b.WriteString("signature verification skipped")
+		if o.Descriptor.Digest != "" {
+			fmt.Fprintf(&b, " for %s", o.Descriptor.Digest)
+		}
+		b.WriteString("\n")
+		return b.String()
+	}
+	b.WriteString("signature")
+	if o.SignatureDigest != "" {
+		fmt.Fprintf(&b, " %s", o.SignatureDigest)
+	}
+	if o.Descriptor.Digest != "" {
+		fmt.Fprintf(&b, " of %s (%s)", o.Descriptor.Digest, o.Descriptor.MediaType)
+	}
+	b.WriteString("\n")
+	if len(o.CertificateChain) > 0 {
+		fmt.Fprintf(&b, "  signed by %q", o.CertificateChain[0].Subject.String())
+		if o.SelfSigned {
+			b.WriteString(" (self-signed)")
+		} else {
+			b.WriteString(" (CA-issued)")
+		}
+		if o.SigningScheme != "" {
+			fmt.Fprintf(&b, " with signing scheme %s", o.SigningScheme)
+		}
+		switch {
+		case !o.AuthenticSigningTime.IsZero():
+			fmt.Fprintf(&b, " at %s (authentic)", o.AuthenticSigningTime.UTC().Format(time.RFC3339))
+		case !o.SigningTime.IsZero():
+			fmt.Fprintf(&b, " at %s", o.SigningTime.UTC().Format(time.RFC3339))
+		}
+		b.WriteString("\n")
+	}
+	if !o.Expiry.IsZero() {
+		fmt.Fprintf(&b, "  expires at %s\n", o.Expiry.UTC().Format(time.RFC3339))
+	}
+	for _, r := range o.Results {
+		fmt.Fprintf(&b, "  %s\n", r)
+	}
+	return b.String()
+}
@@ -0,0 +1,131 @@
+package notation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func testOutcome(t *testing.T) *VerificationOutcome {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(0x2a),
+		Subject:      pkix.Name{CommonName: "release", Organization: []string{"Acme"}},
+		NotBefore:    time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcome := &VerificationOutcome{
+		SignatureDigest: digest.FromString("signature"),
+		Descriptor: Descriptor{
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Digest:    digest.FromString("net-monitor"),
+			Size:      11,
+		},
+		UserMetadata:             map[string]string{"buildId": "42"},
+		ExtendedSignedAttributes: []SignedAttribute{{Key: "io.acme.team", Value: "net-monitor", Critical: true}},
+		SigningScheme:            SigningSchemeX509,
+		SigningTime:              time.Date(2022, 7, 1, 10, 0, 0, 0, time.FixedZone("PDT", -7*3600)),
+		CertificateChain:         []*x509.Certificate{cert},
+		SelfSigned:               IsSelfSigned(cert),
+	}
+	outcome.Pass(CheckIntegrity)
+	outcome.Fail(CheckAuthenticity, Errorf(ErrorCodeUntrustedSigner, "x509: certificate signed by unknown authority"))
+	outcome.Results[1].Severity = SeverityError
+	outcome.Skip(CheckRevocation)
+	return outcome
+}
+
+func TestVerificationOutcome_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(testOutcome(t))
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	var got struct {
+		SignatureDigest  string            `json:"signatureDigest"`
+		Descriptor       Descriptor        `json:"descriptor"`
+		SigningTime      string            `json:"signingTime"`
+		Expiry           *string           `json:"expiry"`
+		UserMetadata     map[string]string `json:"userMetadata"`
+		CertificateChain []struct {
+			Subject      string `json:"subject"`
+			SerialNumber string `json:"serialNumber"`
+			Fingerprint  string `json:"sha256Fingerprint"`
+		} `json:"certificateChain"`
+		SelfSigned *bool `json:"selfSigned"`
+		Results    []struct {
+			Check     string `json:"check"`
+			Status    string `json:"status"`
+			Severity  string `json:"severity"`
+			ErrorCode string `json:"errorCode"`
+			Error     string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.SignatureDigest != digest.FromString("signature").String() || got.Descriptor.Digest != digest.FromString("net-monitor") || got.UserMetadata["buildId"] != "42" {
+		t.Errorf("MarshalJSON() = %s, want the signature, the descriptor and the user metadata", data)
+	}
+	if got.SigningTime != "2022-07-01T17:00:00Z" || got.Expiry != nil {
+		t.Errorf("MarshalJSON() signingTime, expiry = %q, %v, want UTC signing time and no expiry", got.SigningTime, got.Expiry)
+	}
+	if len(got.CertificateChain) != 1 || got.CertificateChain[0].Subject != "CN=release,O=Acme" || got.CertificateChain[0].SerialNumber != "2a" || len(got.CertificateChain[0].Fingerprint) != 64 {
+		t.Errorf("MarshalJSON() certificateChain = %+v, want the signing certificate", got.CertificateChain)
+	}
+	if got.SelfSigned == nil || !*got.SelfSigned {
+		t.Errorf("MarshalJSON() selfSigned = %v, want true", got.SelfSigned)
+	}
+	if len(got.Results) != 3 {
+		t.Fatalf("MarshalJSON() results = %+v, want 3 results", got.Results)
+	}
+	if r := got.Results[1]; r.Check != "authenticity" || r.Status != "failed" || r.Severity != "error" || r.ErrorCode != string(ErrorCodeUntrustedSigner) || !strings.Contains(r.Error, "unknown authority") {
+		t.Errorf("MarshalJSON() results[1] = %+v, want the failed authenticity check", r)
+	}
+	if r := got.Results[0]; r.Error != "" || r.ErrorCode != "" {
+		t.Errorf("MarshalJSON() results[0] = %+v, want no error", r)
+	}
+
+	skipped, err := json.Marshal(&VerificationOutcome{Skipped: true})
+	if err != nil || string(skipped) != `{"skipped":true,"results":[]}` {
+		t.Errorf("MarshalJSON() = %s, %v, want the skipped outcome", skipped, err)
+	}
+}
+
+func TestVerificationOutcome_Summary(t *testing.T) {
+	want := strings.Join([]string{
+		"signature " + digest.FromString("signature").String() + " of " + digest.FromString("net-monitor").String() + " (application/vnd.oci.image.manifest.v1+json)",
+		`  signed by "CN=release,O=Acme" (self-signed) with signing scheme notary.x509 at 2022-07-01T17:00:00Z`,
+		"  integrity: passed",
+		"  authenticity: failed (error): x509: certificate signed by unknown authority",
+		"  revocation: skipped",
+		"",
+	}, "\n")
+	if got := testOutcome(t).Summary(); got != want {
+		t.Errorf("Summary() =\n%s\nwant\n%s", got, want)
+	}
+	skipped := &VerificationOutcome{Skipped: true, Descriptor: Descriptor{Digest: digest.FromString("net-monitor")}}
+	if got := skipped.Summary(); got != "signature verification skipped for "+digest.FromString("net-monitor").String()+"\n" {
+		t.Errorf("Summary() = %q, want the skipped verification", got)
+	}
+}
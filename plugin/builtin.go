@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	builtInsMu sync.RWMutex
+	builtIns   = make(map[string]Runner)
+)
+
+// RegisterBuiltIn registers an in-process implementation of the plugin
+// contract under name, so that embedding applications can provide
+// plugins without spawning child processes.
+//
+// Built-in plugins are resolved by the plugin manager before any plugin
+// installed on the system with the same name.
+// It returns an error if a built-in plugin is already registered with name.
+func RegisterBuiltIn(name string, runner Runner) error {
+	if name == "" {
+		return errors.New("empty plugin name")
+	}
+	if runner == nil {
+		return errors.New("nil plugin runner")
+	}
+	builtInsMu.Lock()
+	defer builtInsMu.Unlock()
+	if _, ok := builtIns[name]; ok {
+		return fmt.Errorf("built-in plugin %q already registered", name)
+	}
+	builtIns[name] = runner
+	return nil
+}
+
+// UnregisterBuiltIn removes the built-in plugin registered under name, if any.
+func UnregisterBuiltIn(name string) {
+	builtInsMu.Lock()
+	defer builtInsMu.Unlock()
+	delete(builtIns, name)
+}
+
+// BuiltIn returns the built-in plugin registered under name.
+func BuiltIn(name string) (Runner, bool) {
+	builtInsMu.RLock()
+	defer builtInsMu.RUnlock()
+	runner, ok := builtIns[name]
+	return runner, ok
+}
+
+// BuiltIns returns the sorted names of the registered built-in plugins.
+func BuiltIns() []string {
+	builtInsMu.RLock()
+	defer builtInsMu.RUnlock()
+	names := make([]string, 0, len(builtIns))
+	for name := range builtIns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
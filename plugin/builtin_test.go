@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type nopRunner struct{}
+
+func (nopRunner) Run(ctx context.Context, req Request) (interface{}, error) {
+	return nil, nil
+}
+
+func TestRegisterBuiltIn(t *testing.T) {
+	if err := RegisterBuiltIn("", nopRunner{}); err == nil {
+		t.Error("RegisterBuiltIn() expected error for empty name")
+	}
+	if err := RegisterBuiltIn("foo", nil); err == nil {
+		t.Error("RegisterBuiltIn() expected error for nil runner")
+	}
+	if err := RegisterBuiltIn("foo", nopRunner{}); err != nil {
+		t.Fatalf("RegisterBuiltIn() error = %v", err)
+	}
+	defer UnregisterBuiltIn("foo")
+	if err := RegisterBuiltIn("foo", nopRunner{}); err == nil {
+		t.Error("RegisterBuiltIn() expected error for duplicated name")
+	}
+	if _, ok := BuiltIn("foo"); !ok {
+		t.Error("BuiltIn() = false, want true")
+	}
+	if got, want := BuiltIns(), []string{"foo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BuiltIns() = %v, want %v", got, want)
+	}
+	UnregisterBuiltIn("foo")
+	if _, ok := BuiltIn("foo"); ok {
+		t.Error("BuiltIn() = true, want false")
+	}
+}
@@ -31,6 +31,27 @@ const (
 	ErrorCodeGeneric ErrorCode = "ERROR"
 )
 
+// Errors which can be matched against a RequestError using errors.Is,
+// based on the RequestError code.
+var (
+	ErrValidation                 = errors.New("validation error")
+	ErrUnsupportedContractVersion = errors.New("unsupported contract version")
+	ErrAccessDenied               = errors.New("access denied")
+	ErrTimeout                    = errors.New("timeout")
+	ErrThrottled                  = errors.New("throttled")
+	ErrGeneric                    = errors.New("generic error")
+)
+
+// codeErrors maps error codes to their matching sentinel errors.
+var codeErrors = map[ErrorCode]error{
+	ErrorCodeValidation:                 ErrValidation,
+	ErrorCodeUnsupportedContractVersion: ErrUnsupportedContractVersion,
+	ErrorCodeAccessDenied:               ErrAccessDenied,
+	ErrorCodeTimeout:                    ErrTimeout,
+	ErrorCodeThrottled:                  ErrThrottled,
+	ErrorCodeGeneric:                    ErrGeneric,
+}
+
 type jsonErr struct {
 	Code     ErrorCode         `json:"errorCode"`
 	Message  string            `json:"errorMessage,omitempty"`
@@ -52,7 +73,12 @@ func (e RequestError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is a RequestError with the same code and message,
+// or the sentinel error associated to the code of e, such as ErrTimeout.
 func (e RequestError) Is(target error) bool {
+	if sentinel, ok := codeErrors[e.Code]; ok && target == sentinel {
+		return true
+	}
 	if et, ok := target.(RequestError); ok {
 		if e.Code != et.Code {
 			return false
@@ -3,6 +3,7 @@ package plugin
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -105,6 +106,9 @@ func TestRequestError_Is(t *testing.T) {
 		{"only same message", RequestError{Code: ErrorCodeTimeout, Err: errors.New("foo")}, args{RequestError{Code: ErrorCodeGeneric, Err: errors.New("foo")}}, false},
 		{"same with nil message", RequestError{Code: ErrorCodeGeneric}, args{RequestError{Code: ErrorCodeGeneric}}, true},
 		{"same", RequestError{Code: ErrorCodeGeneric, Err: errors.New("foo")}, args{RequestError{Code: ErrorCodeGeneric, Err: errors.New("foo")}}, true},
+		{"sentinel", RequestError{Code: ErrorCodeTimeout, Err: errors.New("foo")}, args{ErrTimeout}, true},
+		{"other sentinel", RequestError{Code: ErrorCodeTimeout, Err: errors.New("foo")}, args{ErrAccessDenied}, false},
+		{"unknown code", RequestError{Code: "OTHER", Err: errors.New("foo")}, args{ErrGeneric}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -114,3 +118,17 @@ func TestRequestError_Is(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestError_Is_Decoded(t *testing.T) {
+	var err RequestError
+	if e := json.Unmarshal([]byte("{\"errorCode\":\"ACCESS_DENIED\",\"errorMessage\":\"expired token\"}"), &err); e != nil {
+		t.Fatal(e)
+	}
+	wrapped := fmt.Errorf("describe-key command failed: %w", err)
+	if !errors.Is(wrapped, ErrAccessDenied) {
+		t.Errorf("errors.Is(%v, ErrAccessDenied) = false, want true", wrapped)
+	}
+	if errors.Is(wrapped, ErrValidation) {
+		t.Errorf("errors.Is(%v, ErrValidation) = true, want false", wrapped)
+	}
+}
@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// metadataCache caches the get-plugin-metadata responses of plugins keyed by
+// the path of the plugin binary.
+// An entry is invalidated as soon as the modification time or the size of
+// the binary changes, e.g. when the plugin is upgraded.
+//
+// A nil cache caches nothing.
+type metadataCache struct {
+	mu      sync.Mutex
+	entries map[string]metadataCacheEntry
+}
+
+// metadataCacheEntry is the cached metadata of a plugin binary.
+type metadataCacheEntry struct {
+	modTime  time.Time
+	size     int64
+	metadata plugin.Metadata
+}
+
+// newMetadataCache returns an empty metadata cache.
+func newMetadataCache() *metadataCache {
+	return &metadataCache{entries: make(map[string]metadataCacheEntry)}
+}
+
+// load returns the cached metadata of the binary at pluginPath, if the
+// binary is unchanged according to fi.
+func (c *metadataCache) load(pluginPath string, fi fs.FileInfo) (*plugin.Metadata, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[pluginPath]
+	if !ok {
+		return nil, false
+	}
+	if !entry.modTime.Equal(fi.ModTime()) || entry.size != fi.Size() {
+		delete(c.entries, pluginPath)
+		return nil, false
+	}
+	metadata := entry.metadata
+	return &metadata, true
+}
+
+// store caches the metadata of the binary at pluginPath described by fi.
+func (c *metadataCache) store(pluginPath string, fi fs.FileInfo, metadata *plugin.Metadata) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[pluginPath] = metadataCacheEntry{
+		modTime:  fi.ModTime(),
+		size:     fi.Size(),
+		metadata: *metadata,
+	}
+}
@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// Names of the health checks run by Manager.Check.
+const (
+	HealthCheckMetadata        = "metadata"
+	HealthCheckContractVersion = "contract-version"
+	HealthCheckDescribeKey     = "describe-key"
+)
+
+// CheckOptions contains optional parameters for Manager.Check.
+type CheckOptions struct {
+	// KeyID is the key of the describe-key self-test.
+	// The self-test is skipped if empty.
+	KeyID string
+
+	// PluginConfig is the plugin config passed to the describe-key
+	// self-test.
+	PluginConfig map[string]string
+}
+
+// HealthResult is the health of a plugin, as reported by Manager.Check.
+type HealthResult struct {
+	// Name is the name of the plugin.
+	Name string
+
+	// Path is the path of the plugin binary, empty for built-in plugins.
+	Path string `json:",omitempty"`
+
+	// Metadata is the metadata of the plugin, if it could be fetched.
+	Metadata *plugin.Metadata `json:",omitempty"`
+
+	// ContractVersion is the contract version negotiated with the plugin.
+	ContractVersion string `json:",omitempty"`
+
+	// Checks are the checks run, in order. Checks are not run once one
+	// fails.
+	Checks []HealthCheck
+
+	// Healthy is true if all the checks succeeded.
+	Healthy bool
+}
+
+// HealthCheck is the result of a health check of a plugin.
+type HealthCheck struct {
+	// Name is the name of the check, e.g. HealthCheckMetadata.
+	Name string
+
+	// Duration is the duration of the check.
+	Duration time.Duration
+
+	// Err is non-nil if the check failed.
+	Err error `json:",omitempty"`
+}
+
+// Check checks the health of the named plugin: its metadata is fetched and
+// validated, and the contract version is negotiated.
+// If opts.KeyID is set, the describe-key command of the plugin is also run
+// against that key as a self-test.
+//
+// The error is only non-nil if the plugin is not found, in which case it is
+// of type ErrNotFound; failed checks are reported in the result.
+func (mgr *Manager) Check(ctx context.Context, name string, opts CheckOptions) (*HealthResult, error) {
+	runner, err := mgr.Runner(name)
+	if err != nil {
+		return nil, err
+	}
+	result := &HealthResult{Name: name}
+	if r, ok := runner.(pluginRunner); ok {
+		result.Path = r.path
+	}
+	check := func(checkName string, fn func() error) bool {
+		start := time.Now()
+		err := fn()
+		result.Checks = append(result.Checks, HealthCheck{Name: checkName, Duration: time.Since(start), Err: err})
+		return err == nil
+	}
+
+	ok := check(HealthCheckMetadata, func() error {
+		out, err := runner.Run(ctx, new(plugin.GetMetadataRequest))
+		if err != nil {
+			return err
+		}
+		metadata, ok := out.(*plugin.Metadata)
+		if !ok {
+			return fmt.Errorf("plugin runner returned incorrect get-plugin-metadata response type '%T'", out)
+		}
+		result.Metadata = metadata
+		if metadata.Name != name {
+			return fmt.Errorf("plugin %q reports name %q", name, metadata.Name)
+		}
+		if err := metadata.Validate(); err != nil {
+			return fmt.Errorf("invalid metadata: %w", err)
+		}
+		return nil
+	})
+	ok = ok && check(HealthCheckContractVersion, func() (err error) {
+		result.ContractVersion, err = result.Metadata.NegotiateContractVersion()
+		return err
+	})
+	if opts.KeyID != "" {
+		ok = ok && check(HealthCheckDescribeKey, func() error {
+			if !result.Metadata.HasCapability(plugin.CapabilitySignatureGenerator) {
+				return errors.New("plugin does not have the SIGNATURE_GENERATOR capability")
+			}
+			out, err := runner.Run(ctx, &plugin.DescribeKeyRequest{
+				ContractVersion: result.ContractVersion,
+				KeyID:           opts.KeyID,
+				PluginConfig:    opts.PluginConfig,
+			})
+			if err != nil {
+				return err
+			}
+			key, ok := out.(*plugin.DescribeKeyResponse)
+			if !ok {
+				return fmt.Errorf("plugin runner returned incorrect describe-key response type '%T'", out)
+			}
+			if key.KeyID != opts.KeyID {
+				return fmt.Errorf("keyID in describeKey response %q does not match request %q", key.KeyID, opts.KeyID)
+			}
+			if key.KeySpec.SignatureAlgorithm() == "" {
+				return fmt.Errorf("key spec %q is not supported", key.KeySpec)
+			}
+			return nil
+		})
+	}
+	result.Healthy = ok
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/notaryproject/notation-go"
+)
+
+// commandCommander runs the testCommander of each command.
+type commandCommander map[string]testCommander
+
+func (c commandCommander) Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	return c[command].Run(ctx, path, command, req, stdout, stderr)
+}
+
+func TestManager_Check(t *testing.T) {
+	fsys := fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+	}
+	metadata := testCommander{metadataJSON(validMetadata), true, nil}
+	unsupported := validMetadata
+	unsupported.SupportedContractVersions = []string{"2.0"}
+	key := func(keyID string, keySpec notation.KeySpec) testCommander {
+		return testCommander{[]byte(`{"keyId": "` + keyID + `", "keySpec": "` + string(keySpec) + `"}`), true, nil}
+	}
+	tests := []struct {
+		name   string
+		cmder  commandCommander
+		keyID  string
+		checks int
+		failed string
+	}{
+		{"healthy", commandCommander{"get-plugin-metadata": metadata}, "", 2, ""},
+		{"healthy key", commandCommander{"get-plugin-metadata": metadata, "describe-key": key("key", notation.RSA_2048)}, "key", 3, ""},
+		{"metadata failure", commandCommander{"get-plugin-metadata": testCommander{nil, false, errors.New("exec failed")}}, "key", 1, HealthCheckMetadata},
+		{"unsupported contract", commandCommander{"get-plugin-metadata": testCommander{metadataJSON(unsupported), true, nil}}, "", 2, HealthCheckContractVersion},
+		{"describe-key failure", commandCommander{"get-plugin-metadata": metadata, "describe-key": testCommander{[]byte(`{"errorCode": "ACCESS_DENIED"}`), false, nil}}, "key", 3, HealthCheckDescribeKey},
+		{"key mismatch", commandCommander{"get-plugin-metadata": metadata, "describe-key": key("other", notation.RSA_2048)}, "key", 3, HealthCheckDescribeKey},
+		{"unsupported key spec", commandCommander{"get-plugin-metadata": metadata, "describe-key": key("key", "custom")}, "key", 3, HealthCheckDescribeKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := &Manager{fsys: fsys, cmder: tt.cmder}
+			got, err := mgr.Check(context.Background(), "foo", CheckOptions{KeyID: tt.keyID})
+			if err != nil {
+				t.Fatalf("Manager.Check() error = %v", err)
+			}
+			if got.Healthy != (tt.failed == "") || len(got.Checks) != tt.checks {
+				t.Fatalf("Manager.Check() = %+v, want %d checks, healthy %v", got, tt.checks, tt.failed == "")
+			}
+			last := got.Checks[len(got.Checks)-1]
+			if tt.failed != "" && (last.Name != tt.failed || last.Err == nil) {
+				t.Errorf("Manager.Check() last check = %+v, want %s failure", last, tt.failed)
+			}
+		})
+	}
+
+	mgr := &Manager{fsys: fsys}
+	if _, err := mgr.Check(context.Background(), "bar", CheckOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Manager.Check() error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// maxPluginSize specifies the max size of a plugin binary that can be
+// downloaded from a remote source.
+const maxPluginSize = 256 * 1024 * 1024 // 256 MiB
+
+// ErrAlreadyInstalled is returned by Manager.Install when a plugin with the
+// same name is already installed and overwriting is not allowed.
+var ErrAlreadyInstalled = errors.New("plugin already installed")
+
+// ErrDowngrade is returned by Manager.Install when the plugin being installed
+// has a lower version than the installed one and downgrading is not allowed.
+var ErrDowngrade = errors.New("plugin downgrade not allowed")
+
+// ErrChecksumMismatch is returned by Manager.Install when the SHA-256 checksum
+// of the plugin binary does not match the expected one.
+var ErrChecksumMismatch = errors.New("plugin checksum mismatch")
+
+// InstallOptions contains parameters for Manager.Install.
+type InstallOptions struct {
+	// Checksum is the hex-encoded SHA-256 checksum of the plugin binary.
+	// It is required when installing from a URL.
+	Checksum string
+
+	// Overwrite allows replacing an installed plugin with the same name.
+	Overwrite bool
+
+	// AllowDowngrade allows replacing an installed plugin with a lower version.
+	// It is only honored if Overwrite is also set.
+	AllowDowngrade bool
+
+	// Client is the HTTP client used to download plugins from a URL.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+// Install installs the plugin binary located at source, which is either
+// a local file path or an http(s) URL.
+//
+// The binary is copied into a staging file, its checksum is verified,
+// its metadata is fetched and validated, and it is then moved to
+// {root}/{plugin-name}/notation-{plugin-name}[.exe].
+func (mgr *Manager) Install(ctx context.Context, source string, opts InstallOptions) (*Plugin, error) {
+	root, ok := mgr.root()
+	if !ok {
+		return nil, errors.New("plugin installation is only supported by managers created with New")
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	src, remote, err := openSource(ctx, source, opts.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	if remote && opts.Checksum == "" {
+		return nil, fmt.Errorf("checksum is required to install plugin from %q", source)
+	}
+
+	// Stage the binary next to its final destination
+	// so it can be moved atomically.
+	tmp, err := os.CreateTemp(root, addExeSuffix(".install-*"))
+	if err != nil {
+		return nil, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy plugin binary: %w", err)
+	}
+	if opts.Checksum != "" {
+		if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, opts.Checksum) {
+			return nil, fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, got, opts.Checksum)
+		}
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return nil, err
+	}
+
+	// Validate the plugin before installing it.
+	out, err := run(ctx, mgr.cmder, mgr.opts, tmpPath, plugin.CommandGetMetadata, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+	metadata := out.(*plugin.Metadata)
+	if err := metadata.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	name := metadata.Name
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid plugin name %q", name)
+	}
+
+	if existing, err := mgr.newPlugin(ctx, name); err == nil {
+		if !opts.Overwrite {
+			return nil, fmt.Errorf("%w: %s", ErrAlreadyInstalled, name)
+		}
+		if existing.Err == nil && !opts.AllowDowngrade && plugin.CompareVersion(metadata.Version, existing.Version) < 0 {
+			return nil, fmt.Errorf("%w: %s version %s is lower than installed version %s", ErrDowngrade, name, metadata.Version, existing.Version)
+		}
+	}
+
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	dst := filepath.Join(dir, binName(name))
+	if err := os.Rename(tmpPath, dst); err != nil {
+		return nil, err
+	}
+	return &Plugin{Metadata: *metadata, Path: dst}, nil
+}
+
+// Uninstall removes the named plugin and its directory.
+//
+// If the plugin is not found, the error is of type ErrNotFound.
+func (mgr *Manager) Uninstall(name string) error {
+	root, ok := mgr.root()
+	if !ok {
+		return errors.New("plugin uninstallation is only supported by managers created with New")
+	}
+	if name != filepath.Base(name) || !isCandidate(mgr.fsys, name) {
+		return ErrNotFound
+	}
+	return os.RemoveAll(filepath.Join(root, name))
+}
+
+// root returns the plugin directory on the local file system.
+func (mgr *Manager) root() (string, bool) {
+	fsys, ok := mgr.fsys.(rootedFS)
+	if !ok || fsys.readOnly {
+		return "", false
+	}
+	return fsys.root, true
+}
+
+// openSource opens a local file or downloads a remote one.
+// remote reports whether source is a URL.
+func openSource(ctx context.Context, source string, client *http.Client) (rc io.ReadCloser, remote bool, err error) {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, false, err
+		}
+		return f, false, nil
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, true, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, true, fmt.Errorf("failed to download plugin from %q: %s", source, resp.Status)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(resp.Body, maxPluginSize), resp.Body}, true, nil
+}
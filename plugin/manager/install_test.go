@@ -0,0 +1,153 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// versionCommander returns metadata with a version depending on the binary path.
+type versionCommander map[string]string
+
+func (c versionCommander) Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	m := validMetadata
+	m.Version = c[filepath.Base(filepath.Dir(path))]
+	if m.Version == "" {
+		m.Version = c["new"]
+	}
+	_, err := stdout.Write(metadataJSON(m))
+	return true, err
+}
+
+func writeSource(t *testing.T, content string) (string, string) {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "notation-foo")
+	if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return src, hex.EncodeToString(sum[:])
+}
+
+func TestManager_Install(t *testing.T) {
+	root := t.TempDir()
+	mgr := &Manager{fsys: rootedFS{FS: os.DirFS(root), root: root}, cmder: testCommander{metadataJSON(validMetadata), true, nil}}
+	src, checksum := writeSource(t, "binary")
+
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{Checksum: "00"}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Manager.Install() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+	p, err := mgr.Install(context.Background(), src, InstallOptions{Checksum: checksum})
+	if err != nil {
+		t.Fatalf("Manager.Install() error = %v", err)
+	}
+	want := filepath.Join(root, "foo", binName("foo"))
+	if p.Path != want {
+		t.Errorf("Manager.Install() Path = %v, want %v", p.Path, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Fatalf("plugin binary not installed: %v", err)
+	}
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{}); !errors.Is(err, ErrAlreadyInstalled) {
+		t.Fatalf("Manager.Install() error = %v, want %v", err, ErrAlreadyInstalled)
+	}
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{Overwrite: true}); err != nil {
+		t.Fatalf("Manager.Install() overwrite error = %v", err)
+	}
+
+	if err := mgr.Uninstall("foo"); err != nil {
+		t.Fatalf("Manager.Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "foo")); !os.IsNotExist(err) {
+		t.Errorf("plugin directory not removed: %v", err)
+	}
+	if err := mgr.Uninstall("foo"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Manager.Uninstall() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestManager_Install_Downgrade(t *testing.T) {
+	root := t.TempDir()
+	cmder := versionCommander{"foo": "1.2.0", "new": "1.10.0"}
+	mgr := &Manager{fsys: rootedFS{FS: os.DirFS(root), root: root}, cmder: cmder}
+	src, _ := writeSource(t, "binary")
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{}); err != nil {
+		t.Fatalf("Manager.Install() error = %v", err)
+	}
+	cmder["foo"] = "1.10.0"
+	cmder["new"] = "1.2.0"
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{Overwrite: true}); !errors.Is(err, ErrDowngrade) {
+		t.Fatalf("Manager.Install() error = %v, want %v", err, ErrDowngrade)
+	}
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{Overwrite: true, AllowDowngrade: true}); err != nil {
+		t.Fatalf("Manager.Install() error = %v", err)
+	}
+}
+
+func TestManager_Install_URL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("binary"))
+	}))
+	defer ts.Close()
+	root := t.TempDir()
+	mgr := &Manager{fsys: rootedFS{FS: os.DirFS(root), root: root}, cmder: testCommander{metadataJSON(validMetadata), true, nil}}
+	if _, err := mgr.Install(context.Background(), ts.URL, InstallOptions{}); err == nil {
+		t.Fatal("Manager.Install() expected error for missing checksum")
+	}
+	_, checksum := writeSource(t, "binary")
+	if _, err := mgr.Install(context.Background(), ts.URL, InstallOptions{Checksum: checksum}); err != nil {
+		t.Fatalf("Manager.Install() error = %v", err)
+	}
+}
+
+func TestManager_Install_InvalidMetadata(t *testing.T) {
+	root := t.TempDir()
+	mgr := &Manager{fsys: rootedFS{FS: os.DirFS(root), root: root}, cmder: testCommander{metadataJSON(plugin.Metadata{Name: "foo"}), true, nil}}
+	src, _ := writeSource(t, "binary")
+	if _, err := mgr.Install(context.Background(), src, InstallOptions{}); err == nil {
+		t.Fatal("Manager.Install() expected error for invalid metadata")
+	}
+	if _, err := os.Stat(filepath.Join(root, "foo")); !os.IsNotExist(err) {
+		t.Errorf("invalid plugin should not be installed: %v", err)
+	}
+}
+
+func TestManager_Install_NotRooted(t *testing.T) {
+	mgr := &Manager{fsys: fstest.MapFS{}, cmder: nil}
+	if _, err := mgr.Install(context.Background(), "foo", InstallOptions{}); err == nil {
+		t.Error("Manager.Install() expected error")
+	}
+	if err := mgr.Uninstall("foo"); err == nil {
+		t.Error("Manager.Uninstall() expected error")
+	}
+}
+
+func TestNewFS(t *testing.T) {
+	root := filepath.FromSlash("/opt/notation/plugins")
+	mgr := NewFS(fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+	}, root, Options{})
+	mgr.cmder = testCommander{metadataJSON(validMetadata), true, nil}
+	p, err := mgr.Get(context.Background(), "foo")
+	if err != nil {
+		t.Fatalf("Manager.Get() error = %v", err)
+	}
+	if want := filepath.Join(root, "foo", binName("foo")); p.Path != want {
+		t.Errorf("Manager.Get() Path = %v, want %v", p.Path, want)
+	}
+	if err := mgr.Uninstall("foo"); err == nil {
+		t.Error("Manager.Uninstall() error = nil, want read-only manager error")
+	}
+}
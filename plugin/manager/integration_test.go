@@ -2,6 +2,7 @@ package manager_test
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
@@ -9,6 +10,7 @@ import (
 	"reflect"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/notaryproject/notation-go/plugin"
 	"github.com/notaryproject/notation-go/plugin/manager"
@@ -83,9 +85,61 @@ func TestIntegration(t *testing.T) {
 	}
 }
 
+func TestIntegration_ResponseTooLarge(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip()
+	}
+	root := preparePlugin(t)
+	mgr := manager.NewWithOptions(root, manager.Options{MaxResponseSize: 1 << 16})
+	r, err := mgr.Runner("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Run(context.Background(), &plugin.DescribeKeyRequest{ContractVersion: plugin.ContractVersion, KeyID: "key"})
+	var tooLarge *manager.ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 1<<16 {
+		t.Errorf("Runner.Run() error = %v, want ResponseTooLargeError", err)
+	}
+}
+
 func addExeSuffix(s string) string {
 	if runtime.GOOS == "windows" {
 		s += ".exe"
 	}
 	return s
 }
+
+func TestIntegration_Sandbox(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip()
+	}
+	root := preparePlugin(t)
+	t.Setenv("NOTATION_TEST_SECRET", "secret")
+	t.Setenv("NOTATION_TEST_ALLOWED", "allowed")
+	sandbox := &manager.SandboxOptions{Env: []string{"NOTATION_TEST_ALLOWED"}}
+	if runtime.GOOS == "linux" {
+		sandbox.Limits = manager.ResourceLimits{MaxOpenFiles: 64, MaxCPUTime: time.Minute}
+	}
+	mgr := manager.NewWithOptions(root, manager.Options{Sandbox: sandbox})
+	r, err := mgr.Runner("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Run(context.Background(), &plugin.GenerateEnvelopeRequest{ContractVersion: plugin.ContractVersion})
+	if err != nil {
+		t.Fatalf("Runner.Run() error = %v", err)
+	}
+	annotations := out.(*plugin.GenerateEnvelopeResponse).Annotations
+	if env := annotations["env"]; env != "NOTATION_TEST_ALLOWED=allowed" {
+		t.Errorf("plugin environment = %q, want NOTATION_TEST_ALLOWED only", env)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := annotations["wd"]; got == "" || got == wd {
+		t.Errorf("plugin working directory = %q, want a private directory", got)
+	} else if _, err := os.Stat(got); !os.IsNotExist(err) {
+		t.Errorf("plugin working directory %q was not removed", got)
+	}
+}
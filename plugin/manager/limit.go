@@ -0,0 +1,59 @@
+package manager
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// DefaultMaxRequestSize is the maximum size in bytes of the requests passed
+// to the plugins on stdin, unless overridden by Options.MaxRequestSize.
+const DefaultMaxRequestSize = 64 << 20
+
+// DefaultMaxResponseSize is the maximum size in bytes of the responses read
+// from the stdout and the stderr of the plugins, unless overridden by
+// Options.MaxResponseSize.
+const DefaultMaxResponseSize = 64 << 20
+
+// ResponseTooLargeError is returned by Runner.Run when a plugin writes more
+// than the maximum response size to stdout or stderr. The plugin process is
+// killed as soon as the limit is exceeded.
+type ResponseTooLargeError struct {
+	// Command is the plugin command.
+	Command plugin.Command
+
+	// Limit is the maximum response size in bytes.
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s command response exceeds the maximum size of %d bytes", e.Command, e.Limit)
+}
+
+// maxSize returns the size limit, given the configured limit and its
+// default. Negative limits disable the limit.
+func maxSize(limit, def int64) int64 {
+	if limit == 0 {
+		return def
+	}
+	return limit
+}
+
+// limitedWriter writes to w until n bytes are written, and fails with err
+// afterwards. A negative n writes without limit.
+type limitedWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n >= 0 {
+		if int64(len(p)) > l.n {
+			return 0, l.err
+		}
+		l.n -= int64(len(p))
+	}
+	return l.w.Write(p)
+}
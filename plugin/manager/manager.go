@@ -6,14 +6,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/notaryproject/notation-go/dir"
+	"github.com/notaryproject/notation-go/event"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/metrics"
 	"github.com/notaryproject/notation-go/plugin"
+	"github.com/notaryproject/notation-go/trace"
 )
 
 // Plugin represents a potential plugin with all it's metadata.
@@ -34,42 +43,160 @@ var ErrNotCompliant = errors.New("plugin not compliant")
 
 // commander is defined for mocking purposes.
 type commander interface {
-	// Output runs the command, passing req to the its stdin.
-	// It only returns an error if the binary can't be executed.
-	// Returns stdout if success is true, stderr if success is false.
-	Output(ctx context.Context, path string, command string, req []byte) (out []byte, success bool, err error)
+	// Run runs the command, passing req to the its stdin, and copies its
+	// stdout and stderr to stdout and stderr as they are produced.
+	// It only returns an error if the binary can't be executed or if its
+	// output is rejected by stdout or stderr, in which case the process
+	// is killed.
+	// Returns whether the command exits with a zero exit status.
+	Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (success bool, err error)
 }
 
 // execCommander implements the commander interface using exec.Command().
-type execCommander struct{}
+type execCommander struct {
+	// sandbox restricts the plugin processes, if not nil.
+	sandbox *SandboxOptions
+}
 
-func (c execCommander) Output(ctx context.Context, name string, command string, req []byte) ([]byte, bool, error) {
-	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, name, command)
+func (c execCommander) Run(ctx context.Context, name string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	// The process is killed as soon as ctx is done,
+	// or as soon as its output is rejected.
+	cmdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var writeErr error
+	abort := func(err error) {
+		once.Do(func() {
+			writeErr = err
+			cancel()
+		})
+	}
+	cmd := exec.CommandContext(cmdCtx, name, command)
 	cmd.Stdin = bytes.NewReader(req)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
+	cmd.Stdout = &abortWriter{w: stdout, abort: abort}
+	cmd.Stderr = &abortWriter{w: stderr, abort: abort}
+	if c.sandbox != nil {
+		cleanup, err := c.sandbox.apply(cmd)
+		if err != nil {
+			return false, err
+		}
+		defer cleanup()
+	}
+	err := cmd.Start()
+	if err == nil && c.sandbox != nil && !c.sandbox.Limits.isZero() {
+		if limitErr := setLimits(cmd.Process.Pid, c.sandbox.Limits); limitErr != nil {
+			cancel()
+			cmd.Wait()
+			return false, limitErr
+		}
+	}
+	if err == nil {
+		err = cmd.Wait()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return false, ctxErr
+	}
+	if writeErr != nil {
+		return false, writeErr
+	}
 	if _, ok := err.(*exec.ExitError); err != nil && !ok {
-		return nil, false, err
+		return false, err
 	}
-	if !cmd.ProcessState.Success() {
-		return stderr.Bytes(), false, nil
+	return cmd.ProcessState.Success(), nil
+}
+
+// abortWriter writes to w, calling abort with the first write error.
+type abortWriter struct {
+	w     io.Writer
+	abort func(error)
+}
+
+func (a *abortWriter) Write(p []byte) (int, error) {
+	n, err := a.w.Write(p)
+	if err != nil {
+		a.abort(err)
 	}
-	return stdout.Bytes(), true, nil
+	return n, err
 }
 
-// rootedFS is io.FS implementation used in New.
-// root is the root of the file system tree passed to os.DirFS.
+// rootedFS is io.FS implementation used in New and NewFS.
+// root is the directory of the local file system where the plugins of the
+// file system tree are executed from, which is only writable by the manager
+// if the tree is os.DirFS(root) as created by New.
 type rootedFS struct {
 	fs.FS
-	root string
+	root     string
+	readOnly bool
+}
+
+// Options contains optional parameters for NewWithOptions.
+type Options struct {
+	// Timeout is the maximum duration of any plugin command.
+	// No timeout is applied if zero, other than the context deadline.
+	Timeout time.Duration
+
+	// CommandTimeouts overrides Timeout for specific commands,
+	// e.g. a short timeout for get-plugin-metadata and a longer one
+	// for generate-signature.
+	CommandTimeouts map[plugin.Command]time.Duration
+
+	// Sandbox restricts the environment, the working directory and the
+	// resources of the plugin processes.
+	// Plugins inherit the environment and the working directory of the
+	// caller if nil.
+	Sandbox *SandboxOptions
+
+	// MaxRequestSize is the maximum size in bytes of the requests passed to
+	// the plugins. DefaultMaxRequestSize is used if zero, and no limit is
+	// applied if negative.
+	MaxRequestSize int64
+
+	// MaxResponseSize is the maximum size in bytes of the output of the
+	// plugins on stdout and on stderr. A plugin exceeding it is killed and
+	// the command fails with a *ResponseTooLargeError.
+	// DefaultMaxResponseSize is used if zero, and no limit is applied if
+	// negative.
+	MaxResponseSize int64
+
+	// MaxConcurrency is the maximum number of concurrent commands of any
+	// plugin, e.g. for plugins backed by HSMs which cannot handle parallel
+	// invocations. Commands exceeding it wait in order for a running
+	// command to complete, or for their context to be done.
+	// No limit is applied if zero.
+	MaxConcurrency int
+
+	// PluginConcurrency overrides MaxConcurrency for specific plugins,
+	// keyed by plugin name. No limit is applied to a plugin mapped to zero.
+	PluginConcurrency map[string]int
+}
+
+// timeout returns the timeout applicable to cmd.
+func (opts Options) timeout(cmd plugin.Command) time.Duration {
+	if d, ok := opts.CommandTimeouts[cmd]; ok {
+		return d
+	}
+	return opts.Timeout
+}
+
+// concurrency returns the maximum number of concurrent commands of the
+// named plugin.
+func (opts Options) concurrency(name string) int {
+	if n, ok := opts.PluginConcurrency[name]; ok {
+		return n
+	}
+	return opts.MaxConcurrency
 }
 
 // Manager manages plugins installed on the system.
+//
+// A Manager and the runners it returns are safe for concurrent use.
+// Built-in plugins are not subject to the concurrency limits of the manager.
 type Manager struct {
 	fsys  fs.FS
 	cmder commander
+	opts  Options
+	cache *metadataCache
+	pools *executionPools
 }
 
 // New returns a new manager rooted at root.
@@ -77,7 +204,32 @@ type Manager struct {
 // root is the path of the directory where plugins are stored
 // following the {root}/{plugin-name}/notation-{plugin-name}[.exe] pattern.
 func New(root string) *Manager {
-	return &Manager{rootedFS{os.DirFS(root), root}, execCommander{}}
+	return NewWithOptions(root, Options{})
+}
+
+// NewDefault returns a new manager rooted at the plugin directory of notation,
+// as returned by dir.PluginDir.
+func NewDefault() (*Manager, error) {
+	root, err := dir.PluginDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(root), nil
+}
+
+// NewWithOptions returns a new manager rooted at root configured with opts.
+func NewWithOptions(root string, opts Options) *Manager {
+	return &Manager{fsys: rootedFS{FS: os.DirFS(root), root: root}, cmder: execCommander{sandbox: opts.Sandbox}, opts: opts, cache: newMetadataCache(), pools: newExecutionPools(opts.concurrency)}
+}
+
+// NewFS returns a new manager discovering plugins in fsys, e.g. a read-only
+// or virtual file system, following the {plugin-name}/notation-{plugin-name}[.exe]
+// pattern. The plugins are executed from the same pattern under the local
+// directory root, which fsys is expected to mirror.
+//
+// Plugins cannot be installed nor uninstalled by the manager.
+func NewFS(fsys fs.FS, root string, opts Options) *Manager {
+	return &Manager{fsys: rootedFS{FS: fsys, root: root, readOnly: true}, cmder: execCommander{sandbox: opts.Sandbox}, opts: opts, cache: newMetadataCache(), pools: newExecutionPools(opts.concurrency)}
 }
 
 // Get returns a plugin on the system by its name.
@@ -103,36 +255,54 @@ func (mgr *Manager) List(ctx context.Context) ([]*Plugin, error) {
 			// Ignore non-directories and symlinked directories.
 			return nil
 		}
+		if _, ok := plugin.BuiltIn(d.Name()); ok {
+			// Built-in plugins take precedence.
+			return fs.SkipDir
+		}
 		p, err := mgr.newPlugin(ctx, d.Name())
 		if err == nil {
 			plugins = append(plugins, p)
 		}
 		return fs.SkipDir
 	})
+	for _, name := range plugin.BuiltIns() {
+		p, err := mgr.newPlugin(ctx, name)
+		if err == nil {
+			plugins = append(plugins, p)
+		}
+	}
 	return plugins, nil
 }
 
 // Runner returns a plugin.Runner.
 //
+// Plugins registered with plugin.RegisterBuiltIn are returned as is.
 // If the plugin is not found or is not a valid candidate, the error is of type ErrNotFound.
 func (mgr *Manager) Runner(name string) (plugin.Runner, error) {
+	if runner, ok := plugin.BuiltIn(name); ok {
+		return runner, nil
+	}
 	ok := isCandidate(mgr.fsys, name)
 	if !ok {
 		return nil, ErrNotFound
 	}
 
-	return pluginRunner{name: name, path: binPath(mgr.fsys, name), cmder: mgr.cmder}, nil
+	return pluginRunner{name: name, path: binPath(mgr.fsys, name), fsys: mgr.fsys, cmder: mgr.cmder, opts: mgr.opts, cache: mgr.cache, pools: mgr.pools}, nil
 }
 
 // newPlugin determines if the given candidate is valid and returns a Plugin.
 func (mgr *Manager) newPlugin(ctx context.Context, name string) (*Plugin, error) {
+	if runner, ok := plugin.BuiltIn(name); ok {
+		return newBuiltInPlugin(ctx, name, runner), nil
+	}
 	ok := isCandidate(mgr.fsys, name)
 	if !ok {
 		return nil, ErrNotFound
 	}
 
 	p := &Plugin{Path: binPath(mgr.fsys, name)}
-	out, err := run(ctx, mgr.cmder, p.Path, plugin.CommandGetMetadata, nil)
+	runner := pluginRunner{name: name, path: p.Path, fsys: mgr.fsys, cmder: mgr.cmder, opts: mgr.opts, cache: mgr.cache, pools: mgr.pools}
+	out, err := runner.run(ctx, plugin.CommandGetMetadata, nil)
 	if err != nil {
 		p.Err = fmt.Errorf("failed to fetch metadata: %w", err)
 		return p, nil
@@ -146,13 +316,45 @@ func (mgr *Manager) newPlugin(ctx context.Context, name string) (*Plugin, error)
 	return p, nil
 }
 
+// newBuiltInPlugin returns a Plugin for a built-in plugin.
+// Built-in plugins have no Path.
+func newBuiltInPlugin(ctx context.Context, name string, runner plugin.Runner) *Plugin {
+	p := new(Plugin)
+	out, err := runner.Run(ctx, new(plugin.GetMetadataRequest))
+	if err != nil {
+		p.Err = fmt.Errorf("failed to fetch metadata: %w", err)
+		return p
+	}
+	metadata, ok := out.(*plugin.Metadata)
+	if !ok {
+		p.Err = fmt.Errorf("failed to fetch metadata: plugin runner returned incorrect get-plugin-metadata response type '%T'", out)
+		return p
+	}
+	p.Metadata = *metadata
+	if p.Name != name {
+		p.Err = fmt.Errorf("built-in plugin registered as %q reports name %q", name, p.Name)
+	} else if err := p.Metadata.Validate(); err != nil {
+		p.Err = fmt.Errorf("invalid metadata: %w", err)
+	}
+	return p
+}
+
 type pluginRunner struct {
 	name  string
 	path  string
+	fsys  fs.FS
 	cmder commander
+	opts  Options
+	cache *metadataCache
+	pools *executionPools
 }
 
-func (p pluginRunner) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+func (p pluginRunner) Run(ctx context.Context, req plugin.Request) (_ interface{}, err error) {
+	ctx, span := trace.StartSpan(ctx, "notation.plugin.Run",
+		trace.Attribute{Key: "notation.plugin.name", Value: p.name},
+		trace.Attribute{Key: "notation.plugin.command", Value: string(req.Command())},
+	)
+	defer func() { trace.EndSpan(span, err) }()
 	var data []byte
 	if req != nil {
 		var err error
@@ -161,26 +363,65 @@ func (p pluginRunner) Run(ctx context.Context, req plugin.Request) (interface{},
 			return nil, pluginErr(p.name, fmt.Errorf("failed to marshal request object: %w", err))
 		}
 	}
-	resp, err := run(ctx, p.cmder, p.path, req.Command(), data)
+	logger := log.GetLogger(ctx)
+	logger.Debugf("running %s command of plugin %q", req.Command(), p.name)
+	start := time.Now()
+	resp, err := p.run(ctx, req.Command(), data)
+	metrics.GetRecorder(ctx).RecordPluginCommand(p.name, string(req.Command()), time.Since(start), err)
+	event.Emit(ctx, event.Event{Type: event.PluginInvoked, Plugin: p.name, Command: string(req.Command()), Duration: time.Since(start), Err: err})
 	if err != nil {
+		logger.Debugf("%s command of plugin %q failed after %v: %v", req.Command(), p.name, time.Since(start), err)
 		return nil, pluginErr(p.name, err)
 	}
+	logger.Debugf("%s command of plugin %q completed in %v", req.Command(), p.name, time.Since(start))
 	return resp, nil
 }
 
-// run executes the command and decodes the response.
-func run(ctx context.Context, cmder commander, pluginPath string, cmd plugin.Command, req []byte) (interface{}, error) {
-	out, ok, err := cmder.Output(ctx, pluginPath, string(cmd), req)
-	if err != nil {
-		return nil, fmt.Errorf("failed running the plugin: %w", err)
+// run executes the command, serving get-plugin-metadata from the metadata
+// cache while the plugin binary is unchanged.
+func (p pluginRunner) run(ctx context.Context, cmd plugin.Command, req []byte) (interface{}, error) {
+	if cmd != plugin.CommandGetMetadata || p.fsys == nil {
+		return p.exec(ctx, cmd, req)
 	}
-	if !ok {
-		var re plugin.RequestError
-		err = json.Unmarshal(out, &re)
-		if err != nil {
-			return nil, plugin.RequestError{Code: plugin.ErrorCodeGeneric, Err: fmt.Errorf("failed to decode json response: %w", ErrNotCompliant)}
+	fi, statErr := fs.Stat(p.fsys, path.Join(p.name, binName(p.name)))
+	if statErr == nil {
+		if metadata, ok := p.cache.load(p.path, fi); ok {
+			return metadata, nil
 		}
-		return nil, re
+	}
+	resp, err := p.exec(ctx, cmd, req)
+	if err != nil {
+		return nil, err
+	}
+	if statErr == nil {
+		p.cache.store(p.path, fi, resp.(*plugin.Metadata))
+	}
+	return resp, nil
+}
+
+// exec executes the command once an execution slot of the plugin is
+// available.
+func (p pluginRunner) exec(ctx context.Context, cmd plugin.Command, req []byte) (interface{}, error) {
+	release, err := p.pools.acquire(ctx, p.name)
+	if err != nil {
+		return nil, fmt.Errorf("%s command did not start: %w", cmd, err)
+	}
+	defer release()
+	return run(ctx, p.cmder, p.opts, p.path, cmd, req)
+}
+
+// run executes the command and decodes the response.
+//
+// The response is decoded as it is written by the plugin, and the command
+// fails with a *ResponseTooLargeError as soon as the plugin output exceeds
+// the maximum response size.
+//
+// If the command does not complete before the applicable timeout or the
+// ctx deadline, the plugin process is killed and the error is a
+// RequestError with code ErrorCodeTimeout wrapping context.DeadlineExceeded.
+func run(ctx context.Context, cmder commander, opts Options, pluginPath string, cmd plugin.Command, req []byte) (interface{}, error) {
+	if limit := maxSize(opts.MaxRequestSize, DefaultMaxRequestSize); limit >= 0 && int64(len(req)) > limit {
+		return nil, fmt.Errorf("%s command request exceeds the maximum size of %d bytes", cmd, limit)
 	}
 	var resp interface{}
 	switch cmd {
@@ -192,16 +433,91 @@ func run(ctx context.Context, cmder commander, pluginPath string, cmd plugin.Com
 		resp = new(plugin.GenerateEnvelopeResponse)
 	case plugin.CommandDescribeKey:
 		resp = new(plugin.DescribeKeyResponse)
+	case plugin.CommandVerifySignature:
+		resp = new(plugin.VerifySignatureResponse)
 	default:
 		return nil, fmt.Errorf("unsupported command: %s", cmd)
 	}
-	err = json.Unmarshal(out, resp)
+	if timeout := opts.timeout(cmd); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// decode stdout while the plugin writes it
+	pr, pw := io.Pipe()
+	decoded := make(chan error, 1)
+	go func() {
+		dec := json.NewDecoder(pr)
+		err := dec.Decode(resp)
+		if err == nil && dec.More() {
+			err = errors.New("trailing data after json response")
+		}
+		// drain the remaining output so that the plugin never blocks
+		io.Copy(io.Discard, pr)
+		decoded <- err
+	}()
+	limit := maxSize(opts.MaxResponseSize, DefaultMaxResponseSize)
+	tooLarge := &ResponseTooLargeError{Command: cmd, Limit: limit}
+	var stderr bytes.Buffer
+	ok, err := cmder.Run(ctx, pluginPath, string(cmd), req,
+		&limitedWriter{w: pw, n: limit, err: tooLarge},
+		&limitedWriter{w: &stderr, n: limit, err: tooLarge},
+	)
+	pw.Close()
+	decodeErr := <-decoded
+	diag := diagnostic(stderr.Bytes())
+	if diag != "" {
+		log.GetLogger(ctx).Debugf("%s command of plugin %q wrote to stderr: %s", cmd, pluginPath, diag)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode json response: %w", ErrNotCompliant)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, plugin.RequestError{Code: plugin.ErrorCodeTimeout, Err: fmt.Errorf("%s command timed out%s: %w", cmd, diagnosticSuffix(diag), err)}
+		}
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed running the plugin: %w", err)
+	}
+	if !ok {
+		var re plugin.RequestError
+		err = json.Unmarshal(stderr.Bytes(), &re)
+		if err != nil {
+			return nil, plugin.RequestError{Code: plugin.ErrorCodeGeneric, Err: fmt.Errorf("failed to decode json response%s: %w", diagnosticSuffix(diag), ErrNotCompliant)}
+		}
+		return nil, re
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode json response%s: %w", diagnosticSuffix(diag), ErrNotCompliant)
 	}
 	return resp, nil
 }
 
+// maxDiagnosticSize is the maximum size in bytes of the plugin stderr
+// output attached to errors and logs.
+const maxDiagnosticSize = 4096
+
+// diagnostic returns the stderr output of a plugin, trimmed to its last
+// maxDiagnosticSize bytes, which hold the final diagnostic.
+func diagnostic(stderr []byte) string {
+	stderr = bytes.TrimSpace(stderr)
+	var prefix string
+	if len(stderr) > maxDiagnosticSize {
+		stderr = stderr[len(stderr)-maxDiagnosticSize:]
+		prefix = "..."
+	}
+	return prefix + strings.ToValidUTF8(string(stderr), "\uFFFD")
+}
+
+// diagnosticSuffix formats a non-empty diagnostic to be appended to an
+// error message.
+func diagnosticSuffix(diag string) string {
+	if diag == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (plugin stderr: %q)", diag)
+}
+
 func pluginErr(name string, err error) error {
 	return fmt.Errorf("%s: %w", name, err)
 }
@@ -228,7 +544,7 @@ func binName(name string) string {
 
 func binPath(fsys fs.FS, name string) string {
 	base := binName(name)
-	// New() always instantiate a rootedFS.
+	// New() and NewFS() always instantiate a rootedFS.
 	// Other fs.FS implementations are only supported for testing purposes.
 	if fsys, ok := fsys.(rootedFS); ok {
 		return filepath.Join(fsys.root, name, base)
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"io/fs"
 	"reflect"
 	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/notaryproject/notation-go/plugin"
 )
@@ -19,8 +21,16 @@ type testCommander struct {
 	err     error
 }
 
-func (t testCommander) Output(ctx context.Context, path string, command string, req []byte) (out []byte, success bool, err error) {
-	return t.output, t.success, t.err
+func (t testCommander) Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	if t.err != nil {
+		return false, t.err
+	}
+	if t.success {
+		_, err := stdout.Write(t.output)
+		return true, err
+	}
+	_, err := stderr.Write(t.output)
+	return false, err
 }
 
 var validMetadata = plugin.Metadata{
@@ -29,7 +39,7 @@ var validMetadata = plugin.Metadata{
 }
 
 func TestManager_Get_Empty(t *testing.T) {
-	mgr := &Manager{fstest.MapFS{}, nil}
+	mgr := &Manager{fsys: fstest.MapFS{}, cmder: nil}
 	got, err := mgr.Get(context.Background(), "foo")
 	if !errors.Is(err, ErrNotFound) {
 		t.Errorf("Manager.Get() error = %v, want %v", got, ErrNotFound)
@@ -52,27 +62,27 @@ func TestManager_Get_NotFound(t *testing.T) {
 	ctx := context.Background()
 
 	// empty fsys.
-	mgr := Manager{fstest.MapFS{}, nil}
+	mgr := Manager{fsys: fstest.MapFS{}, cmder: nil}
 	check(mgr.Get(ctx, "foo"))
 
 	// plugin directory exists without executable.
-	mgr = Manager{fstest.MapFS{
+	mgr = Manager{fsys: fstest.MapFS{
 		"foo": &fstest.MapFile{Mode: fs.ModeDir},
-	}, nil}
+	}, cmder: nil}
 	check(mgr.Get(ctx, "foo"))
 
 	// plugin directory exists with symlinked executable.
-	mgr = Manager{fstest.MapFS{
+	mgr = Manager{fsys: fstest.MapFS{
 		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 		addExeSuffix("foo/notation-foo"): &fstest.MapFile{Mode: fs.ModeSymlink},
-	}, nil}
+	}, cmder: nil}
 	check(mgr.Get(ctx, "foo"))
 
 	// valid plugin exists but is not the target.
-	mgr = Manager{fstest.MapFS{
+	mgr = Manager{fsys: fstest.MapFS{
 		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-	}, testCommander{metadataJSON(validMetadata), true, nil}}
+	}, cmder: testCommander{metadataJSON(validMetadata), true, nil}}
 	check(mgr.Get(ctx, "baz"))
 }
 
@@ -89,50 +99,50 @@ func TestManager_Get(t *testing.T) {
 	}{
 		{
 			"command error",
-			&Manager{fstest.MapFS{
+			&Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, testCommander{nil, false, errors.New("failed")}},
+			}, cmder: testCommander{nil, false, errors.New("failed")}},
 			args{"foo"},
 			&Plugin{Path: addExeSuffix("foo/notation-foo")},
 			"failed to fetch metadata",
 		},
 		{
 			"invalid json",
-			&Manager{fstest.MapFS{
+			&Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, testCommander{[]byte("content"), true, nil}},
+			}, cmder: testCommander{[]byte("content"), true, nil}},
 			args{"foo"},
 			&Plugin{Path: addExeSuffix("foo/notation-foo")},
 			"failed to fetch metadata",
 		},
 		{
 			"invalid metadata name",
-			&Manager{fstest.MapFS{
+			&Manager{fsys: fstest.MapFS{
 				"baz":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("baz/notation-baz"): new(fstest.MapFile),
-			}, testCommander{metadataJSON(validMetadata), true, nil}},
+			}, cmder: testCommander{metadataJSON(validMetadata), true, nil}},
 			args{"baz"},
 			&Plugin{Metadata: validMetadata, Path: addExeSuffix("baz/notation-baz")},
 			"executable name must be",
 		},
 		{
 			"invalid metadata content",
-			&Manager{fstest.MapFS{
+			&Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, testCommander{metadataJSON(plugin.Metadata{Name: "foo"}), true, nil}},
+			}, cmder: testCommander{metadataJSON(plugin.Metadata{Name: "foo"}), true, nil}},
 			args{"foo"},
 			&Plugin{Metadata: plugin.Metadata{Name: "foo"}, Path: addExeSuffix("foo/notation-foo")},
 			"invalid metadata",
 		},
 		{
 			"valid",
-			&Manager{fstest.MapFS{
+			&Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, testCommander{metadataJSON(validMetadata), true, nil}},
+			}, cmder: testCommander{metadataJSON(validMetadata), true, nil}},
 			args{"foo"},
 			&Plugin{Metadata: validMetadata, Path: addExeSuffix("foo/notation-foo")}, "",
 		},
@@ -173,32 +183,32 @@ func TestManager_List(t *testing.T) {
 		mgr  *Manager
 		want []*Plugin
 	}{
-		{"empty fsys", &Manager{fstest.MapFS{}, nil}, nil},
-		{"fsys without plugins", &Manager{fstest.MapFS{"a.go": &fstest.MapFile{}}, nil}, nil},
+		{"empty fsys", &Manager{fsys: fstest.MapFS{}, cmder: nil}, nil},
+		{"fsys without plugins", &Manager{fsys: fstest.MapFS{"a.go": &fstest.MapFile{}}, cmder: nil}, nil},
 		{
 			"fsys with plugins but symlinked", &Manager{
-				fstest.MapFS{
+				fsys: fstest.MapFS{
 					"foo":                            &fstest.MapFile{Mode: fs.ModeDir | fs.ModeSymlink},
 					addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
 					"baz":                            &fstest.MapFile{Mode: fs.ModeDir},
-				}, testCommander{metadataJSON(validMetadata), true, nil}},
+				}, cmder: testCommander{metadataJSON(validMetadata), true, nil}},
 			nil,
 		},
 		{
 			"fsys with some invalid plugins", &Manager{
-				fstest.MapFS{
+				fsys: fstest.MapFS{
 					"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 					addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-				}, testCommander{metadataJSON(validMetadata), true, nil}},
+				}, cmder: testCommander{metadataJSON(validMetadata), true, nil}},
 			[]*Plugin{{Metadata: validMetadata}},
 		},
 		{
 			"fsys with plugins", &Manager{
-				fstest.MapFS{
+				fsys: fstest.MapFS{
 					"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 					addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
 					"baz":                            &fstest.MapFile{Mode: fs.ModeDir},
-				}, testCommander{metadataJSON(validMetadata), true, nil}},
+				}, cmder: testCommander{metadataJSON(validMetadata), true, nil}},
 			[]*Plugin{{Metadata: validMetadata}},
 		},
 	}
@@ -218,7 +228,7 @@ func TestManager_List(t *testing.T) {
 }
 
 func TestManager_Runner_Run_NotFound(t *testing.T) {
-	mgr := &Manager{fstest.MapFS{}, nil}
+	mgr := &Manager{fsys: fstest.MapFS{}, cmder: nil}
 	_, err := mgr.Runner("foo")
 	if !errors.Is(err, ErrNotFound) {
 		t.Fatalf("Manager.Runner() error = %v, want %v", err, ErrNotFound)
@@ -238,24 +248,31 @@ func TestManager_Runner_Run(t *testing.T) {
 		err  error
 	}{
 		{
-			"exec error", &Manager{fstest.MapFS{
+			"exec error", &Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, &testCommander{nil, false, errExec}},
+			}, cmder: &testCommander{nil, false, errExec}},
 			args{"foo", plugin.CommandGenerateSignature}, errExec,
 		},
 		{
-			"request error", &Manager{fstest.MapFS{
+			"request error", &Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, &testCommander{[]byte("{\"errorCode\": \"ERROR\"}"), false, nil}},
+			}, cmder: &testCommander{[]byte("{\"errorCode\": \"ERROR\"}"), false, nil}},
 			args{"foo", plugin.CommandGenerateSignature}, plugin.RequestError{Code: plugin.ErrorCodeGeneric},
 		},
 		{
-			"valid", &Manager{fstest.MapFS{
+			"typed request error", &Manager{fsys: fstest.MapFS{
+				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+			}, cmder: &testCommander{[]byte("{\"errorCode\": \"ACCESS_DENIED\", \"errorMessage\": \"denied\"}"), false, nil}},
+			args{"foo", plugin.CommandGenerateSignature}, plugin.ErrAccessDenied,
+		},
+		{
+			"valid", &Manager{fsys: fstest.MapFS{
 				"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
 				addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
-			}, testCommander{metadataJSON(validMetadata), true, nil}},
+			}, cmder: testCommander{metadataJSON(validMetadata), true, nil}},
 			args{"foo", plugin.CommandGenerateSignature}, nil,
 		},
 	}
@@ -293,3 +310,206 @@ func TestNew(t *testing.T) {
 		t.Error("New() = nil")
 	}
 }
+
+// blockingCommander blocks until the context is done.
+type blockingCommander struct{}
+
+func (blockingCommander) Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestManager_Runner_Run_Timeout(t *testing.T) {
+	mgr := &Manager{fsys: fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+	}, cmder: blockingCommander{}, opts: Options{
+		Timeout:         time.Hour,
+		CommandTimeouts: map[plugin.Command]time.Duration{plugin.CommandGenerateSignature: time.Millisecond},
+	}}
+	runner, err := mgr.Runner("foo")
+	if err != nil {
+		t.Fatalf("Manager.Runner() error = %v, want nil", err)
+	}
+	_, err = runner.Run(context.Background(), requester(plugin.CommandGenerateSignature))
+	var re plugin.RequestError
+	if !errors.As(err, &re) || re.Code != plugin.ErrorCodeTimeout {
+		t.Fatalf("Runner.Run() error = %v, want code %v", err, plugin.ErrorCodeTimeout)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Runner.Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = runner.Run(ctx, requester(plugin.CommandDescribeKey))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Runner.Run() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestManager_Runner_Run_SizeLimits(t *testing.T) {
+	fsys := fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+	}
+	output := metadataJSON(validMetadata)
+	tests := []struct {
+		name     string
+		cmder    commander
+		opts     Options
+		req      plugin.Request
+		wantErr  bool
+		tooLarge bool
+	}{
+		{"within limits", testCommander{output, true, nil}, Options{MaxResponseSize: int64(len(output))}, requester(plugin.CommandGetMetadata), false, false},
+		{"unlimited", testCommander{output, true, nil}, Options{MaxResponseSize: -1}, requester(plugin.CommandGetMetadata), false, false},
+		{"stdout too large", testCommander{output, true, nil}, Options{MaxResponseSize: 10}, requester(plugin.CommandGetMetadata), true, true},
+		{"stderr too large", testCommander{[]byte(`{"errorCode": "ERROR"}`), false, nil}, Options{MaxResponseSize: 10}, requester(plugin.CommandGetMetadata), true, true},
+		{"request too large", testCommander{output, true, nil}, Options{MaxRequestSize: 10}, &plugin.DescribeKeyRequest{KeyID: "key"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := &Manager{fsys: fsys, cmder: tt.cmder, opts: tt.opts}
+			runner, err := mgr.Runner("foo")
+			if err != nil {
+				t.Fatalf("Manager.Runner() error = %v", err)
+			}
+			_, err = runner.Run(context.Background(), tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Runner.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var tooLarge *ResponseTooLargeError
+			if errors.As(err, &tooLarge) != tt.tooLarge {
+				t.Errorf("Runner.Run() error = %v, want ResponseTooLargeError %v", err, tt.tooLarge)
+			}
+		})
+	}
+}
+
+func TestManager_Runner_Run_Stderr(t *testing.T) {
+	fsys := fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+	}
+	long := strings.Repeat("x", 2*maxDiagnosticSize) + "key vault unreachable"
+	tests := []struct {
+		name  string
+		cmder commander
+		want  string
+	}{
+		{"failed", testCommander{[]byte("panic: key vault unreachable\n"), false, nil}, `(plugin stderr: "panic: key vault unreachable")`},
+		{"truncated", testCommander{[]byte(long), false, nil}, `key vault unreachable")`},
+		{"invalid response", testCommander{[]byte("not json"), true, nil}, "failed to decode json response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := &Manager{fsys: fsys, cmder: tt.cmder}
+			runner, err := mgr.Runner("foo")
+			if err != nil {
+				t.Fatalf("Manager.Runner() error = %v", err)
+			}
+			_, err = runner.Run(context.Background(), requester(plugin.CommandGenerateSignature))
+			if !errors.Is(err, ErrNotCompliant) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Runner.Run() error = %v, want %q", err, tt.want)
+			}
+			if len(err.Error()) > 2*maxDiagnosticSize {
+				t.Errorf("Runner.Run() error of %d bytes, want bounded diagnostic", len(err.Error()))
+			}
+		})
+	}
+}
+
+// builtInRunner is an in-process plugin.
+type builtInRunner struct {
+	metadata plugin.Metadata
+}
+
+func (r *builtInRunner) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	if req.Command() == plugin.CommandGetMetadata {
+		return &r.metadata, nil
+	}
+	return nil, plugin.RequestError{Code: plugin.ErrorCodeGeneric}
+}
+
+func TestManager_BuiltIn(t *testing.T) {
+	runner := &builtInRunner{validMetadata}
+	if err := plugin.RegisterBuiltIn("foo", runner); err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.UnregisterBuiltIn("foo")
+	// The on-disk plugin is shadowed by the built-in one.
+	mgr := &Manager{fsys: fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+	}, cmder: testCommander{nil, false, errors.New("should not run")}}
+
+	got, err := mgr.Runner("foo")
+	if err != nil {
+		t.Fatalf("Manager.Runner() error = %v", err)
+	}
+	if got != plugin.Runner(runner) {
+		t.Errorf("Manager.Runner() = %v, want built-in runner", got)
+	}
+	p, err := mgr.Get(context.Background(), "foo")
+	if err != nil {
+		t.Fatalf("Manager.Get() error = %v", err)
+	}
+	if p.Err != nil || p.Path != "" || !reflect.DeepEqual(p.Metadata, validMetadata) {
+		t.Errorf("Manager.Get() = %+v, want valid built-in plugin", p)
+	}
+	list, err := mgr.List(context.Background())
+	if err != nil {
+		t.Fatalf("Manager.List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Err != nil {
+		t.Errorf("Manager.List() = %v, want the built-in plugin only", list)
+	}
+}
+
+type countingCommander struct {
+	testCommander
+	calls int
+}
+
+func (c *countingCommander) Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	c.calls++
+	return c.testCommander.Run(ctx, path, command, req, stdout, stderr)
+}
+
+func TestManager_MetadataCache(t *testing.T) {
+	ctx := context.Background()
+	bin := &fstest.MapFile{ModTime: time.Unix(1, 0)}
+	cmder := &countingCommander{testCommander: testCommander{metadataJSON(validMetadata), true, nil}}
+	mgr := &Manager{fsys: fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): bin,
+	}, cmder: cmder, cache: newMetadataCache()}
+
+	if _, err := mgr.Get(ctx, "foo"); err != nil {
+		t.Fatalf("Manager.Get() error = %v", err)
+	}
+	runner, err := mgr.Runner("foo")
+	if err != nil {
+		t.Fatalf("Manager.Runner() error = %v", err)
+	}
+	got, err := runner.Run(ctx, new(plugin.GetMetadataRequest))
+	if err != nil {
+		t.Fatalf("Runner.Run() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, &validMetadata) {
+		t.Errorf("Runner.Run() = %v, want %v", got, &validMetadata)
+	}
+	if cmder.calls != 1 {
+		t.Errorf("get-plugin-metadata ran %d times, want 1", cmder.calls)
+	}
+
+	// the plugin binary is replaced.
+	bin.ModTime = time.Unix(2, 0)
+	if _, err := runner.Run(ctx, new(plugin.GetMetadataRequest)); err != nil {
+		t.Fatalf("Runner.Run() error = %v", err)
+	}
+	if cmder.calls != 2 {
+		t.Errorf("get-plugin-metadata ran %d times after the binary changed, want 2", cmder.calls)
+	}
+}
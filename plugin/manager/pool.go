@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"context"
+	"sync"
+)
+
+// executionPools limits the number of concurrent executions of each plugin.
+// Executions waiting for a slot are queued in order.
+//
+// A nil pool limits nothing.
+type executionPools struct {
+	limit func(name string) int
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// newExecutionPools returns pools limiting the plugins to limit(name)
+// concurrent executions, no limit applying if not positive.
+func newExecutionPools(limit func(name string) int) *executionPools {
+	return &executionPools{limit: limit, sems: make(map[string]chan struct{})}
+}
+
+// acquire waits for an execution slot of the named plugin until ctx is done.
+// release must be called once the execution completes.
+func (p *executionPools) acquire(ctx context.Context, name string) (release func(), err error) {
+	sem := p.semaphore(name)
+	if sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// semaphore returns the semaphore of the named plugin, or nil if the plugin
+// is not limited.
+func (p *executionPools) semaphore(name string) chan struct{} {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sem, ok := p.sems[name]
+	if !ok {
+		if n := p.limit(name); n > 0 {
+			sem = make(chan struct{}, n)
+		}
+		p.sems[name] = sem
+	}
+	return sem
+}
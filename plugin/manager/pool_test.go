@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// concurrencyCommander records the maximum number of concurrent commands.
+type concurrencyCommander struct {
+	testCommander
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+func (c *concurrencyCommander) Run(ctx context.Context, path string, command string, req []byte, stdout, stderr io.Writer) (bool, error) {
+	c.mu.Lock()
+	c.running++
+	if c.running > c.max {
+		c.max = c.running
+	}
+	c.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	return c.testCommander.Run(ctx, path, command, req, stdout, stderr)
+}
+
+func TestManager_Runner_Run_Concurrency(t *testing.T) {
+	fsys := fstest.MapFS{
+		"foo":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("foo/notation-foo"): new(fstest.MapFile),
+		"bar":                            &fstest.MapFile{Mode: fs.ModeDir},
+		addExeSuffix("bar/notation-bar"): new(fstest.MapFile),
+	}
+	opts := Options{MaxConcurrency: 2, PluginConcurrency: map[string]int{"foo": 1, "bar": 0}}
+	for _, tt := range []struct {
+		name string
+		want int
+	}{
+		{"foo", 1},
+		{"bar", 8},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmder := &concurrencyCommander{testCommander: testCommander{metadataJSON(validMetadata), true, nil}}
+			mgr := &Manager{fsys: fsys, cmder: cmder, opts: opts, pools: newExecutionPools(opts.concurrency)}
+			runner, err := mgr.Runner(tt.name)
+			if err != nil {
+				t.Fatalf("Manager.Runner() error = %v", err)
+			}
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := runner.Run(context.Background(), requester(plugin.CommandDescribeKey)); err != nil {
+						t.Errorf("Runner.Run() error = %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+			if cmder.max > tt.want {
+				t.Errorf("Runner.Run() ran %d concurrent commands, want at most %d", cmder.max, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutionPools_Acquire(t *testing.T) {
+	pools := newExecutionPools(func(string) int { return 1 })
+	release, err := pools.acquire(context.Background(), "foo")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	// other plugins have their own pool
+	releaseBar, err := pools.acquire(context.Background(), "bar")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	releaseBar()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pools.acquire(ctx, "foo"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	release()
+	release, err = pools.acquire(context.Background(), "foo")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	release()
+
+	// nil pools limit nothing
+	var none *executionPools
+	release, err = none.acquire(context.Background(), "foo")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	release()
+}
@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultSandboxEnv are the names of the environment variables passed to the
+// plugins by a sandbox with no Env.
+var DefaultSandboxEnv = []string{"PATH", "HOME", "USERPROFILE", "TMPDIR", "TEMP", "TMP", "SYSTEMROOT", "LANG"}
+
+// SandboxOptions restricts the processes of the plugins.
+//
+// Whether sandboxed or not, plugin processes only inherit the standard
+// streams: the file descriptors opened by the Go runtime are close-on-exec.
+type SandboxOptions struct {
+	// Env lists the names of the environment variables passed to the
+	// plugins, all the other variables being scrubbed.
+	// DefaultSandboxEnv is used if nil.
+	Env []string
+
+	// WorkingDir is the working directory of the plugins.
+	// A private temporary directory, removed once the command completes,
+	// is used if empty.
+	WorkingDir string
+
+	// Limits are the resource limits of the plugin processes.
+	Limits ResourceLimits
+}
+
+// ResourceLimits are the resource limits of a plugin process.
+// Zero values are not limited.
+//
+// Limits are applied as rlimits on Linux, right after the process starts.
+// The commands fail on other platforms if any limit is set.
+type ResourceLimits struct {
+	// MaxMemory is the maximum size in bytes of the virtual memory of the
+	// process.
+	MaxMemory uint64
+
+	// MaxCPUTime is the maximum CPU time of the process, rounded up to the
+	// second.
+	MaxCPUTime time.Duration
+
+	// MaxOpenFiles is the maximum number of file descriptors opened by the
+	// process.
+	MaxOpenFiles uint64
+}
+
+// isZero reports whether no limit is set.
+func (l ResourceLimits) isZero() bool {
+	return l == ResourceLimits{}
+}
+
+// apply configures the environment and the working directory of cmd.
+// cleanup removes the private working directory, if any.
+func (s *SandboxOptions) apply(cmd *exec.Cmd) (cleanup func(), err error) {
+	cmd.Env = s.env()
+	cleanup = func() {}
+	dir := s.WorkingDir
+	if dir == "" {
+		dir, err = os.MkdirTemp("", "notation-plugin-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create plugin working directory: %w", err)
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+	}
+	cmd.Dir = dir
+	// the plugin path must not be resolved from the working directory
+	if !filepath.IsAbs(cmd.Path) {
+		path, err := filepath.Abs(cmd.Path)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		cmd.Path = path
+	}
+	return cleanup, nil
+}
+
+// env returns the whitelisted variables of the environment.
+func (s *SandboxOptions) env() []string {
+	names := s.Env
+	if names == nil {
+		names = DefaultSandboxEnv
+	}
+	env := []string{}
+	for _, kv := range os.Environ() {
+		name := kv
+		if i := strings.Index(kv, "="); i > 0 {
+			name = kv[:i]
+		}
+		for _, allowed := range names {
+			if name == allowed || runtime.GOOS == "windows" && strings.EqualFold(name, allowed) {
+				env = append(env, kv)
+				break
+			}
+		}
+	}
+	return env
+}
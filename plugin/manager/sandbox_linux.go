@@ -0,0 +1,40 @@
+//go:build linux
+// +build linux
+
+package manager
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// rlimit64 is the rlimit structure of the prlimit64 system call.
+type rlimit64 struct {
+	cur, max uint64
+}
+
+// setLimits applies limits to the running process pid.
+func setLimits(pid int, limits ResourceLimits) error {
+	cpu := uint64((limits.MaxCPUTime + time.Second - 1) / time.Second)
+	for _, l := range []struct {
+		name     string
+		resource int
+		value    uint64
+	}{
+		{"memory", syscall.RLIMIT_AS, limits.MaxMemory},
+		{"CPU time", syscall.RLIMIT_CPU, cpu},
+		{"open files", syscall.RLIMIT_NOFILE, limits.MaxOpenFiles},
+	} {
+		if l.value == 0 {
+			continue
+		}
+		rlim := rlimit64{cur: l.value, max: l.value}
+		_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(l.resource), uintptr(unsafe.Pointer(&rlim)), 0, 0, 0)
+		if errno != 0 {
+			return fmt.Errorf("failed to limit %s: %w", l.name, errno)
+		}
+	}
+	return nil
+}
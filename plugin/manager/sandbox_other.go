@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package manager
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// setLimits fails as resource limits are not supported on this platform.
+func setLimits(pid int, limits ResourceLimits) error {
+	return fmt.Errorf("resource limits are not supported on %s", runtime.GOOS)
+}
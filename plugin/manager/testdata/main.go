@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -27,4 +28,25 @@ func main() {
 		}
 		os.Stdout.Write(data)
 	}
+	if flag.Arg(0) == "generate-envelope" {
+		// Report the working directory and the environment.
+		wd, _ := os.Getwd()
+		resp := struct {
+			Annotations map[string]string `json:"annotations"`
+		}{map[string]string{"wd": wd, "env": strings.Join(os.Environ(), "\n")}}
+		data, err := json.Marshal(&resp)
+		if err != nil {
+			panic(err)
+		}
+		os.Stdout.Write(data)
+	}
+	if flag.Arg(0) == "describe-key" {
+		// Flood stdout until killed.
+		data := make([]byte, 4096)
+		for {
+			if _, err := os.Stdout.Write(data); err != nil {
+				os.Exit(1)
+			}
+		}
+	}
 }
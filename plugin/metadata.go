@@ -1,6 +1,9 @@
 package plugin
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Metadata provided by the plugin.
 type Metadata struct {
@@ -64,3 +67,52 @@ func (m *Metadata) SupportsContract(ver string) bool {
 	}
 	return false
 }
+
+// NegotiateContractVersion returns the contract version to use with the
+// plugin, given the contract versions supported by the caller, or
+// SupportedContractVersions if none.
+//
+// Contract versions are compatible within a major version, minor versions
+// only adding optional fields, so the negotiated version is the highest
+// version supported by both sides, each side supporting all the lower minor
+// versions of the versions it states. Malformed versions are ignored.
+// The returned error wraps ErrUnsupportedContractVersion if no major version
+// is shared.
+func (m *Metadata) NegotiateContractVersion(supported ...string) (string, error) {
+	if len(supported) == 0 {
+		supported = SupportedContractVersions
+	}
+	var negotiated string
+	var best contractVersion
+	for _, s := range supported {
+		local, ok := parseContractVersion(s)
+		if !ok {
+			continue
+		}
+		for _, p := range m.SupportedContractVersions {
+			remote, ok := parseContractVersion(p)
+			if !ok || remote.major != local.major {
+				continue
+			}
+			// the lower minor version is supported by both sides
+			candidate, ver := local, s
+			if remote.minor < local.minor {
+				candidate, ver = remote, p
+			}
+			if negotiated == "" || best.less(candidate) {
+				negotiated, best = ver, candidate
+			}
+		}
+	}
+	if negotiated == "" {
+		return "", fmt.Errorf("%w: none of the contract versions %v is compatible with the plugin supported versions %v",
+			ErrUnsupportedContractVersion, supported, m.SupportedContractVersions)
+	}
+	return negotiated, nil
+}
+
+// HasVerificationCapability return true if the metadata states that
+// any SIGNATURE_VERIFIER capability is supported.
+func (m *Metadata) HasVerificationCapability() bool {
+	return m.HasCapability(CapabilityTrustedIdentityVerifier) || m.HasCapability(CapabilityRevocationCheckVerifier)
+}
@@ -1,6 +1,7 @@
 package plugin
 
 import (
+	"errors"
 	"strconv"
 	"testing"
 )
@@ -74,3 +75,37 @@ func TestMetadata_SupportsContract(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadata_NegotiateContractVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		versions  []string
+		supported []string
+		want      string
+		wantErr   bool
+	}{
+		{"default", []string{ContractVersion}, nil, ContractVersion, false},
+		{"highest common minor", []string{"1.1", "1.4"}, []string{"1.0", "1.2"}, "1.2", false},
+		{"older plugin", []string{"1.1"}, []string{"1.3"}, "1.1", false},
+		{"highest major", []string{"1.5", "2.0"}, []string{"1.2", "2.1"}, "2.0", false},
+		{"major only", []string{"1"}, []string{"1.1"}, "1", false},
+		{"malformed versions", []string{"v1", "1.x", "1.0.0"}, []string{"1.0"}, "", true},
+		{"no common major", []string{"2.0"}, []string{"1.3"}, "", true},
+		{"empty versions", nil, []string{"1.0"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Metadata{SupportedContractVersions: tt.versions}
+			got, err := m.NegotiateContractVersion(tt.supported...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Metadata.NegotiateContractVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrUnsupportedContractVersion) {
+				t.Errorf("Metadata.NegotiateContractVersion() error = %v, want ErrUnsupportedContractVersion", err)
+			}
+			if got != tt.want {
+				t.Errorf("Metadata.NegotiateContractVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
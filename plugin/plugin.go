@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"time"
 
 	"github.com/notaryproject/notation-go"
 )
@@ -12,6 +13,11 @@ const Prefix = "notation-"
 // ContractVersion is the <major>.<minor> version of the plugin contract.
 const ContractVersion = "1.0"
 
+// SupportedContractVersions are the <major>.<minor> versions of the plugin
+// contract supported by this library, negotiated with the versions supported
+// by the plugins.
+var SupportedContractVersions = []string{ContractVersion}
+
 // Command is a CLI command available in the plugin contract.
 type Command string
 
@@ -35,6 +41,11 @@ const (
 	// which must be supported by every plugin that has the
 	// SIGNATURE_ENVELOPE_GENERATOR capability.
 	CommandGenerateEnvelope Command = "generate-envelope"
+
+	// CommandVerifySignature is the name of the plugin command
+	// which must be supported by every plugin that has any
+	// SIGNATURE_VERIFIER.* capability.
+	CommandVerifySignature Command = "verify-signature"
 )
 
 // Capability is a feature available in the plugin contract.
@@ -48,6 +59,14 @@ const (
 	// CapabilityEnvelopeGenerator is the name of the capability
 	// which should support a plugin to support generating envelope signatures.
 	CapabilityEnvelopeGenerator Capability = "SIGNATURE_ENVELOPE_GENERATOR"
+
+	// CapabilityTrustedIdentityVerifier is the name of the capability
+	// which should support a plugin to support verifying trusted identities.
+	CapabilityTrustedIdentityVerifier Capability = "SIGNATURE_VERIFIER.TRUSTED_IDENTITY"
+
+	// CapabilityRevocationCheckVerifier is the name of the capability
+	// which should support a plugin to support verifying revocation status.
+	CapabilityRevocationCheckVerifier Capability = "SIGNATURE_VERIFIER.REVOCATION_CHECK"
 )
 
 // GetMetadataRequest contains the parameters passed in a get-plugin-metadata request.
@@ -79,6 +98,9 @@ type DescribeKeyResponse struct {
 }
 
 // GenerateSignatureRequest contains the parameters passed in a generate-signature request.
+//
+// For the ED25519 key spec, the plugin must sign the payload itself
+// instead of its digest.
 type GenerateSignatureRequest struct {
 	ContractVersion string                 `json:"contractVersion"`
 	KeyID           string                 `json:"keyId"`
@@ -86,6 +108,11 @@ type GenerateSignatureRequest struct {
 	Hash            notation.HashAlgorithm `json:"hashAlgorithm"`
 	Payload         []byte                 `json:"payload"`
 	PluginConfig    map[string]string      `json:"pluginConfig,omitempty"`
+
+	// SupportedSigningAlgorithms are the signing algorithms supported by
+	// the library, so that plugins signing with another algorithm can fail
+	// early. The signing algorithm of KeySpec is one of them.
+	SupportedSigningAlgorithms []notation.SignatureAlgorithm `json:"supportedSigningAlgorithms,omitempty"`
 }
 
 func (GenerateSignatureRequest) Command() Command {
@@ -98,6 +125,10 @@ type GenerateSignatureResponse struct {
 	Signature        []byte                      `json:"signature"`
 	SigningAlgorithm notation.SignatureAlgorithm `json:"signingAlgorithm"`
 
+	// Hash is the hash algorithm the payload was hashed with. It must be
+	// the hash algorithm of the request, required by the key spec.
+	Hash notation.HashAlgorithm `json:"hashAlgorithm"`
+
 	// Ordered list of certificates starting with leaf certificate
 	// and ending with root certificate.
 	CertificateChain [][]byte `json:"certificateChain"`
@@ -124,6 +155,53 @@ type GenerateEnvelopeResponse struct {
 	Annotations           map[string]string `json:"annotations,omitempty"`
 }
 
+// VerifySignatureRequest contains the parameters passed in a verify-signature request.
+type VerifySignatureRequest struct {
+	ContractVersion string            `json:"contractVersion"`
+	Signature       Signature         `json:"signature"`
+	TrustPolicy     TrustPolicy       `json:"trustPolicy"`
+	PluginConfig    map[string]string `json:"pluginConfig,omitempty"`
+}
+
+func (VerifySignatureRequest) Command() Command {
+	return CommandVerifySignature
+}
+
+// Signature represents a signature pulled from the envelope.
+type Signature struct {
+	CriticalAttributes    CriticalAttributes `json:"criticalAttributes"`
+	UnprocessedAttributes []string           `json:"unprocessedAttributes"`
+	CertificateChain      [][]byte           `json:"certificateChain"`
+}
+
+// CriticalAttributes contains all Notary V2 defined critical
+// attributes and their values in the signature envelope.
+type CriticalAttributes struct {
+	ContentType          string                 `json:"contentType"`
+	SigningScheme        string                 `json:"signingScheme,omitempty"`
+	Expiry               *time.Time             `json:"expiry,omitempty"`
+	AuthenticSigningTime *time.Time             `json:"authenticSigningTime,omitempty"`
+	ExtendedAttributes   map[string]interface{} `json:"extendedAttributes,omitempty"`
+}
+
+// TrustPolicy represents trusted identities that sign the artifacts.
+type TrustPolicy struct {
+	TrustedIdentities     []string     `json:"trustedIdentities"`
+	SignatureVerification []Capability `json:"signatureVerification"`
+}
+
+// VerifySignatureResponse is the response of a verify-signature request.
+type VerifySignatureResponse struct {
+	VerificationResults map[Capability]*VerificationResult `json:"verificationResults"`
+	ProcessedAttributes []string                           `json:"processedAttributes"`
+}
+
+// VerificationResult is the result of a verification performed by the plugin.
+type VerificationResult struct {
+	Success bool   `json:"success"`
+	Reason  string `json:"reason,omitempty"`
+}
+
 // Request defines a plugin request, which is always associated to a command.
 type Request interface {
 	Command() Command
@@ -141,6 +219,8 @@ type Runner interface {
 	// - the command stdout contains a valid json object which can be unmarshal-ed.
 	//
 	// If the command starts but does not complete successfully, the error is of type RequestError wrapping a *exec.ExitError.
+	// If the command does not complete before ctx is done, the plugin process is killed and the error wraps ctx.Err();
+	// timeouts are reported as a RequestError with code ErrorCodeTimeout.
 	// Other error types may be returned for other situations.
 	Run(ctx context.Context, req Request) (interface{}, error)
 }
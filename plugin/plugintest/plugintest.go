@@ -0,0 +1,163 @@
+// Package plugintest provides in-memory plugin runners and managers, to test
+// signing and verification flows involving plugins without plugin binaries.
+package plugintest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// HandlerFunc handles a request of a plugin command, returning the response
+// the plugin would print on stdout, such as *plugin.DescribeKeyResponse for
+// a describe-key request.
+type HandlerFunc func(ctx context.Context, req plugin.Request) (interface{}, error)
+
+// Runner is an in-memory plugin.Runner dispatching requests to the handler
+// of their command. It is safe for concurrent use once configured.
+type Runner struct {
+	// Metadata is returned on get-plugin-metadata requests, unless a
+	// handler is registered for CommandGetMetadata.
+	Metadata plugin.Metadata
+
+	// Handlers are the handlers of the commands supported by the plugin.
+	// Requests of other commands fail with a validation error.
+	Handlers map[plugin.Command]HandlerFunc
+
+	mu       sync.Mutex
+	requests []plugin.Request
+}
+
+// Run runs the handler of the command of req.
+func (r *Runner) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+
+	if handler, ok := r.Handlers[req.Command()]; ok {
+		return handler(ctx, req)
+	}
+	if req.Command() == plugin.CommandGetMetadata {
+		metadata := r.Metadata
+		return &metadata, nil
+	}
+	return nil, plugin.RequestError{
+		Code: plugin.ErrorCodeValidation,
+		Err:  fmt.Errorf("command %q is not supported", req.Command()),
+	}
+}
+
+// Requests returns the requests run so far, in order.
+func (r *Runner) Requests() []plugin.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]plugin.Request(nil), r.requests...)
+}
+
+// NewSigningRunner returns a runner of a plugin with the SIGNATURE_GENERATOR
+// capability, signing with key the payloads of the key keyID of spec keySpec.
+// certChain is the certificate chain of key, starting with the signing
+// certificate.
+//
+// RSA keys sign with RSASSA-PSS, ECDSA keys return ASN.1 DER encoded
+// signatures, and ED25519 keys sign the payload itself, as real plugins do.
+func NewSigningRunner(name, keyID string, keySpec notation.KeySpec, key crypto.Signer, certChain []*x509.Certificate) *Runner {
+	rawChain := make([][]byte, 0, len(certChain))
+	for _, cert := range certChain {
+		rawChain = append(rawChain, cert.Raw)
+	}
+	return &Runner{
+		Metadata: Metadata(name, plugin.CapabilitySignatureGenerator),
+		Handlers: map[plugin.Command]HandlerFunc{
+			plugin.CommandDescribeKey: func(ctx context.Context, req plugin.Request) (interface{}, error) {
+				r := req.(*plugin.DescribeKeyRequest)
+				if r.KeyID != keyID {
+					return nil, unknownKeyError(r.KeyID)
+				}
+				return &plugin.DescribeKeyResponse{KeyID: keyID, KeySpec: keySpec}, nil
+			},
+			plugin.CommandGenerateSignature: func(ctx context.Context, req plugin.Request) (interface{}, error) {
+				r := req.(*plugin.GenerateSignatureRequest)
+				if r.KeyID != keyID {
+					return nil, unknownKeyError(r.KeyID)
+				}
+				signature, err := sign(key, r.Hash.HashFunc(), r.Payload)
+				if err != nil {
+					return nil, plugin.RequestError{Code: plugin.ErrorCodeGeneric, Err: err}
+				}
+				return &plugin.GenerateSignatureResponse{
+					KeyID:            keyID,
+					Signature:        signature,
+					SigningAlgorithm: keySpec.SignatureAlgorithm(),
+					Hash:             keySpec.SignatureAlgorithm().Hash(),
+					CertificateChain: rawChain,
+				}, nil
+			},
+		},
+	}
+}
+
+// Metadata returns valid metadata of a plugin named name with capabilities.
+func Metadata(name string, capabilities ...plugin.Capability) plugin.Metadata {
+	return plugin.Metadata{
+		Name:                      name,
+		Description:               "in-memory test plugin",
+		Version:                   "1.0.0",
+		URL:                       "https://example.com/" + name,
+		SupportedContractVersions: []string{plugin.ContractVersion},
+		Capabilities:              capabilities,
+	}
+}
+
+// Manager is an in-memory plugin manager resolving runners by name, e.g. to
+// set as the PluginManager of a jws.Verifier. It is safe for concurrent use
+// once configured.
+type Manager map[string]plugin.Runner
+
+// Runner returns the runner of the plugin name.
+func (m Manager) Runner(name string) (plugin.Runner, error) {
+	runner, ok := m[name]
+	if !ok {
+		return nil, fmt.Errorf("plugin %q not found", name)
+	}
+	return runner, nil
+}
+
+// sign signs payload with key, hashing it with hash unless key is an
+// ED25519 key.
+func sign(key crypto.Signer, hash crypto.Hash, payload []byte) ([]byte, error) {
+	switch key.Public().(type) {
+	case ed25519.PublicKey:
+		return key.Sign(rand.Reader, payload, crypto.Hash(0))
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("key type %T is not supported", key.Public())
+	}
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash %v is not available", hash)
+	}
+	h := hash.New()
+	h.Write(payload)
+	digest := h.Sum(nil)
+	var opts crypto.SignerOpts = hash
+	if _, ok := key.Public().(*rsa.PublicKey); ok {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+func unknownKeyError(keyID string) error {
+	return plugin.RequestError{
+		Code: plugin.ErrorCodeValidation,
+		Err:  fmt.Errorf("key %q not found", keyID),
+	}
+}
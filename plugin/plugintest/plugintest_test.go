@@ -0,0 +1,158 @@
+package plugintest
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/plugin"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
+)
+
+func generateCert(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestNewSigningRunner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		keySpec notation.KeySpec
+		key     crypto.Signer
+	}{
+		{name: "rsa", keySpec: notation.RSA_2048, key: rsaKey},
+		{name: "ecdsa", keySpec: notation.EC_384, key: ecKey},
+		{name: "ed25519", keySpec: notation.ED25519, key: edKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := generateCert(t, tt.key)
+			runner := NewSigningRunner("test", "key", tt.keySpec, tt.key, []*x509.Certificate{cert})
+			signer, err := jws.NewSignerPlugin(runner, "key", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			desc := notation.Descriptor{
+				MediaType: "application/vnd.oci.image.manifest.v1+json",
+				Digest:    digest.FromString("artifact"),
+				Size:      8,
+			}
+			sig, err := signer.Sign(ctx, desc, notation.SignOptions{Expiry: time.Now().Add(time.Hour)})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			verifier := jws.NewVerifier()
+			verifier.VerifyOptions.Roots = x509.NewCertPool()
+			verifier.VerifyOptions.Roots.AddCert(cert)
+			outcome, err := verifier.Verify(ctx, sig, notation.VerifyOptions{})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if outcome.Descriptor.Digest != desc.Digest {
+				t.Errorf("Verify() digest = %v, want %v", outcome.Descriptor.Digest, desc.Digest)
+			}
+
+			want := []plugin.Command{plugin.CommandGetMetadata, plugin.CommandDescribeKey, plugin.CommandGenerateSignature}
+			requests := runner.Requests()
+			if len(requests) != len(want) {
+				t.Fatalf("Requests() = %d requests, want %d", len(requests), len(want))
+			}
+			for i, req := range requests {
+				if req.Command() != want[i] {
+					t.Errorf("Requests()[%d] = %v, want %v", i, req.Command(), want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_UnknownKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := NewSigningRunner("test", "key", notation.EC_256, key, []*x509.Certificate{generateCert(t, key)})
+	_, err = runner.Run(context.Background(), &plugin.DescribeKeyRequest{ContractVersion: plugin.ContractVersion, KeyID: "other"})
+	if !errors.Is(err, plugin.ErrValidation) {
+		t.Errorf("Run() error = %v, want %v", err, plugin.ErrValidation)
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	runner := &Runner{Metadata: Metadata("test", plugin.CapabilityTrustedIdentityVerifier)}
+	out, err := runner.Run(context.Background(), &plugin.GetMetadataRequest{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	metadata, ok := out.(*plugin.Metadata)
+	if !ok {
+		t.Fatalf("Run() = %T, want *plugin.Metadata", out)
+	}
+	if err := metadata.Validate(); err != nil {
+		t.Errorf("Run() metadata is invalid: %v", err)
+	}
+
+	_, err = runner.Run(context.Background(), &plugin.VerifySignatureRequest{})
+	if !errors.Is(err, plugin.ErrValidation) {
+		t.Errorf("Run() error = %v, want %v", err, plugin.ErrValidation)
+	}
+}
+
+func TestManager_Runner(t *testing.T) {
+	runner := &Runner{Metadata: Metadata("test", plugin.CapabilitySignatureGenerator)}
+	manager := Manager{"test": runner}
+	got, err := manager.Runner("test")
+	if err != nil {
+		t.Fatalf("Runner() error = %v", err)
+	}
+	if got != runner {
+		t.Errorf("Runner() = %v, want %v", got, runner)
+	}
+	if _, err := manager.Runner("missing"); err == nil {
+		t.Error("Runner() expected error for missing plugin")
+	}
+}
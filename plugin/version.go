@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersion compares two dot separated versions such as "1.2.3",
+// ignoring any leading "v" and any pre-release or build suffix.
+// Non-numeric components are compared lexically.
+// It returns -1, 0 or 1 if a is lower, equal or greater than b.
+func CompareVersion(a, b string) int {
+	split := func(v string) []string {
+		v = strings.TrimPrefix(v, "v")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		return strings.Split(v, ".")
+	}
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+		if sa == "" {
+			na, errA = 0, nil
+		}
+		if sb == "" {
+			nb, errB = 0, nil
+		}
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case sa != sb:
+			if sa < sb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// contractVersion is a parsed <major>.<minor> contract version.
+type contractVersion struct {
+	major, minor int
+}
+
+// parseContractVersion parses a <major>.<minor> contract version, the minor
+// version defaulting to 0 if omitted, e.g. "1".
+func parseContractVersion(v string) (contractVersion, bool) {
+	parts := strings.Split(v, ".")
+	if len(parts) > 2 {
+		return contractVersion{}, false
+	}
+	var ver contractVersion
+	var err error
+	if ver.major, err = strconv.Atoi(parts[0]); err != nil || ver.major < 0 {
+		return contractVersion{}, false
+	}
+	if len(parts) == 2 {
+		if ver.minor, err = strconv.Atoi(parts[1]); err != nil || ver.minor < 0 {
+			return contractVersion{}, false
+		}
+	}
+	return ver, true
+}
+
+// less reports whether v is lower than other.
+func (v contractVersion) less(other contractVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	return v.minor < other.minor
+}
@@ -0,0 +1,125 @@
+package notation
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit limits the rate of the requests sent to each host, e.g. to
+// avoid tripping the rate limits of registries and revocation endpoints
+// during fleet-wide verification sweeps.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate of requests sent to a host.
+	// Requests are not limited if not positive.
+	RequestsPerSecond float64
+
+	// Burst is the maximum number of requests sent to a host at once,
+	// before requests are spaced at the sustained rate.
+	// 1 is used if not positive.
+	Burst int
+}
+
+// NewRateLimitTransport returns a transport sending requests with base,
+// delaying them as needed so that the requests sent to each host, i.e.
+// host:port, conform to limit, as a token bucket per host.
+// http.DefaultTransport is used if base is nil.
+// Delayed requests fail with the error of their context if it is done
+// before they are sent.
+func NewRateLimitTransport(base http.RoundTripper, limit RateLimit) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if limit.RequestsPerSecond <= 0 {
+		return base
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return &rateLimitTransport{
+		base:    base,
+		limit:   limit,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// rateLimitTransport limits the rate of requests with a token bucket per host.
+type rateLimitTransport struct {
+	base  http.RoundTripper
+	limit RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// RoundTrip sends req once a token of the bucket of its host is available.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bucket := t.bucket(req.URL.Host)
+	if delay := bucket.reserve(time.Now()); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			bucket.cancel()
+			return nil, req.Context().Err()
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// bucket returns the token bucket of host.
+func (t *rateLimitTransport) bucket(host string) *tokenBucket {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	bucket, ok := t.buckets[host]
+	if !ok {
+		bucket = &tokenBucket{
+			rate:   t.limit.RequestsPerSecond,
+			burst:  float64(t.limit.Burst),
+			tokens: float64(t.limit.Burst),
+		}
+		t.buckets[host] = bucket
+	}
+	return bucket
+}
+
+// tokenBucket is a token bucket refilled at rate tokens per second, up to
+// burst tokens. Tokens are reserved ahead, so the number of tokens is
+// negative while requests wait.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token at time now, and returns the delay until the token
+// is available.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a token reserved but not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens++; b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
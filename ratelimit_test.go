@@ -0,0 +1,108 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	start := time.Now()
+	bucket := &tokenBucket{rate: 10, burst: 2, tokens: 2}
+	steps := []struct {
+		at   time.Duration
+		want time.Duration
+	}{
+		{at: 0, want: 0},
+		{at: 0, want: 0},
+		{at: 0, want: 100 * time.Millisecond},
+		{at: 0, want: 200 * time.Millisecond},
+		{at: time.Second, want: 0},
+		{at: time.Second, want: 0},
+		{at: time.Second, want: 100 * time.Millisecond},
+	}
+	for i, step := range steps {
+		got := bucket.reserve(start.Add(step.at))
+		if diff := got - step.want; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("reserve() #%d = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	limited := httptest.NewServer(handler)
+	defer limited.Close()
+	other := httptest.NewServer(handler)
+	defer other.Close()
+
+	client := &http.Client{Transport: NewRateLimitTransport(http.DefaultTransport, RateLimit{RequestsPerSecond: 20})}
+	get := func(ctx context.Context, url string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := get(context.Background(), limited.URL); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20 requests per second took %v, want at least 100ms", elapsed)
+	}
+
+	// hosts have their own bucket
+	start = time.Now()
+	if err := get(context.Background(), other.URL); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("request to another host took %v, want no delay", elapsed)
+	}
+
+	// delayed requests are canceled with their context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := get(ctx, limited.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNewRateLimitTransport_NilBase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRateLimitTransport(nil, RateLimit{RequestsPerSecond: 20})}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewRateLimitTransport_Unlimited(t *testing.T) {
+	if got := NewRateLimitTransport(http.DefaultTransport, RateLimit{}); got != http.DefaultTransport {
+		t.Errorf("NewRateLimitTransport() = %T, want the base transport", got)
+	}
+	if got := NewRateLimitTransport(nil, RateLimit{}); got != http.DefaultTransport {
+		t.Errorf("NewRateLimitTransport(nil) = %T, want http.DefaultTransport", got)
+	}
+	transport, err := HTTPClientOptions{RateLimit: &RateLimit{RequestsPerSecond: 1}}.Transport()
+	if err != nil {
+		t.Fatalf("Transport() error = %v", err)
+	}
+	if _, ok := transport.(*rateLimitTransport); !ok {
+		t.Errorf("Transport() = %T, want a rate limited transport", transport)
+	}
+}
@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/trace"
+)
+
+// PushAttestation uploads the DSSE envelope of an attestation, e.g. an
+// in-toto statement with SLSA provenance, and links it to the manifest with
+// an attestation manifest annotated with opts.Annotations.
+// It returns the descriptors of the envelope and the attestation manifest.
+func (c *RepositoryClient) PushAttestation(ctx context.Context, envelope []byte, manifest notation.Descriptor, opts PushSignatureOptions) (notation.Descriptor, notation.Descriptor, error) {
+	ctx = c.withPushScope(ctx)
+	envDesc, err := c.put(ctx, MediaTypeAttestation, envelope)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to upload attestation: %w", err)
+	}
+	manifestDesc, err := c.link(ctx, ArtifactTypeAttestation, manifest, envDesc, opts.Annotations)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to link attestation: %w", err)
+	}
+	return envDesc, manifestDesc, nil
+}
+
+// ListAttestations pages through the attestation manifests linked to the
+// specified manifest as ListSignatures does, the blobs of the manifests
+// being DSSE envelopes to be fetched with Get.
+func (c *RepositoryClient) ListAttestations(ctx context.Context, desc notation.Descriptor, fn func(manifests []SignatureManifest) error) (err error) {
+	ctx, end := c.startOperation(ctx, "ListAttestations", trace.Attribute{Key: "notation.artifact.digest", Value: desc.Digest.String()})
+	defer func() { end(err) }()
+	log.GetLogger(ctx).Debugf("listing attestations of manifest %s in repository %s", desc.Digest, c.Reference)
+	return c.listReferrers(ctx, desc, ArtifactTypeAttestation, fn)
+}
@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestRepositoryClient_PushAttestation(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	if _, _, err := client.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{}); err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+	envDesc, manifestDesc, err := client.PushAttestation(ctx, []byte("envelope"), subject, PushSignatureOptions{
+		Annotations: map[string]string{"buildId": "101"},
+	})
+	if err != nil {
+		t.Fatalf("PushAttestation() error = %v", err)
+	}
+	if envDesc.MediaType != MediaTypeAttestation {
+		t.Errorf("PushAttestation() media type = %q, want %q", envDesc.MediaType, MediaTypeAttestation)
+	}
+	env, err := client.Get(ctx, envDesc.Digest)
+	if err != nil || string(env) != "envelope" {
+		t.Errorf("Get() = %q, %v, want the envelope", env, err)
+	}
+
+	var manifests []SignatureManifest
+	if err := client.ListAttestations(ctx, subject, func(page []SignatureManifest) error {
+		manifests = append(manifests, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("ListAttestations() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Descriptor.Digest != manifestDesc.Digest || len(manifests[0].Blobs) != 1 || manifests[0].Blobs[0].Digest != envDesc.Digest || manifests[0].Annotations["buildId"] != "101" {
+		t.Errorf("ListAttestations() = %+v, want attestation manifest %s", manifests, manifestDesc.Digest)
+	}
+
+	// attestations are not signatures of the artifact
+	digests, err := client.Lookup(ctx, subject.Digest)
+	if err != nil || len(digests) != 1 || digests[0] == envDesc.Digest {
+		t.Errorf("Lookup() = %v, %v, want the signature only", digests, err)
+	}
+}
@@ -0,0 +1,22 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/notaryproject/notation-go"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// NewAnonymousAuthClient creates an auth client without credentials, e.g.
+// to pull signatures from public registries. Registries challenging requests
+// with the Docker registry token auth flow issue anonymous pull tokens to it.
+func NewAnonymousAuthClient(opts notation.HTTPClientOptions) (*auth.Client, error) {
+	return NewAuthClient(nil, opts)
+}
+
+// withPushScope hints the auth client to request tokens scoped to pull from
+// and push to the repository, so that a push flow fetching content before
+// pushing exchanges a single token instead of a pull token and a push token.
+func (c *RepositoryClient) withPushScope(ctx context.Context) context.Context {
+	return auth.AppendScopes(ctx, auth.ScopeRepository(c.Reference.Repository, auth.ActionPull, auth.ActionPush))
+}
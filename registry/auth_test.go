@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// tokenRegistry serves a test registry behind the Docker registry token
+// auth flow, issuing anonymous pull tokens and push tokens to user:pass.
+type tokenRegistry struct {
+	registry *testRegistry
+	realm    string
+
+	mu     sync.Mutex
+	tokens map[string]string // token to scope
+
+	// exchanges are the scopes of the tokens issued, in order.
+	exchanges []string
+}
+
+func (r *tokenRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		r.serveToken(w, req)
+		return
+	}
+	action := "pull"
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		action = "pull,push"
+	}
+	r.mu.Lock()
+	scope, ok := r.tokens[strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")]
+	r.mu.Unlock()
+	if !ok || !strings.HasSuffix(scope, ":"+action) && !strings.HasSuffix(scope, ":pull,push") {
+		w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm=%q,service="test",scope="repository:%s:%s"`, r.realm, testRepository, action))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	r.registry.ServeHTTP(w, req)
+}
+
+func (r *tokenRegistry) serveToken(w http.ResponseWriter, req *http.Request) {
+	scope := strings.Join(req.URL.Query()["scope"], " ")
+	username, password, ok := req.BasicAuth()
+	switch {
+	case ok && (username != "user" || password != "pass"):
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	case !ok && strings.Contains(scope, "push"):
+		// anonymous tokens are limited to pulls
+		scope = fmt.Sprintf("repository:%s:pull", testRepository)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := fmt.Sprintf("token-%d", len(r.exchanges))
+	r.tokens[token] = scope
+	r.exchanges = append(r.exchanges, scope)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"token":%q}`, token)
+}
+
+// reset forgets the tokens exchanged so far.
+func (r *tokenRegistry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = nil
+}
+
+// newTokenRepositoryClient starts a test registry behind the token auth
+// flow, and returns a function creating clients of its repository with
+// credential, with their own token cache.
+func newTokenRepositoryClient(t *testing.T) (func(credential auth.Credential) *RepositoryClient, *tokenRegistry) {
+	reg := &tokenRegistry{registry: newTestRegistry(), tokens: make(map[string]string)}
+	server := httptest.NewServer(reg)
+	t.Cleanup(server.Close)
+	reg.realm = server.URL + "/token"
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(credential auth.Credential) *RepositoryClient {
+		client, err := NewAuthClientWithProvider(StaticCredentials{u.Host: credential}, notation.HTTPClientOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewRepositoryClient(client, registry.Reference{Registry: u.Host, Repository: testRepository}, true)
+	}, reg
+}
+
+func TestAuth_TokenFlow(t *testing.T) {
+	ctx := context.Background()
+	newClient, reg := newTokenRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	pushScope := fmt.Sprintf("repository:%s:pull,push", testRepository)
+	pullScope := fmt.Sprintf("repository:%s:pull", testRepository)
+
+	// a push exchanges a single scoped push token
+	client := newClient(auth.Credential{Username: "user", Password: "pass"})
+	sigDesc, sigManifest, err := client.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{})
+	if err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+	if _, _, err := client.PushSignature(ctx, []byte("other signature"), subject, PushSignatureOptions{}); err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+	if want := []string{pushScope}; fmt.Sprint(reg.exchanges) != fmt.Sprint(want) {
+		t.Errorf("PushSignature() exchanged tokens %v, want %v", reg.exchanges, want)
+	}
+
+	// a countersignature fetching the signature manifest before pushing
+	// exchanges a single push token too
+	reg.reset()
+	client = newClient(auth.Credential{Username: "user", Password: "pass"})
+	if _, _, err := client.Countersign(ctx, digestSigner{}, sigManifest, CountersignOptions{}); err != nil {
+		t.Fatalf("Countersign() error = %v", err)
+	}
+	if want := []string{pushScope}; fmt.Sprint(reg.exchanges) != fmt.Sprint(want) {
+		t.Errorf("Countersign() exchanged tokens %v, want %v", reg.exchanges, want)
+	}
+
+	// anonymous clients pull with a cached anonymous pull token
+	reg.reset()
+	anonymous, err := NewAnonymousAuthClient(notation.HTTPClientOptions{})
+	if err != nil {
+		t.Fatalf("NewAnonymousAuthClient() error = %v", err)
+	}
+	client = NewRepositoryClient(anonymous, client.Reference, true)
+	digests, err := client.Lookup(ctx, subject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(digests) != 2 {
+		t.Errorf("Lookup() = %v, want 2 signatures", digests)
+	}
+	sig, err := client.Get(ctx, sigDesc.Digest)
+	if err != nil || string(sig) != "signature" {
+		t.Errorf("Get() = %q, %v, want signature", sig, err)
+	}
+	if want := []string{pullScope}; fmt.Sprint(reg.exchanges) != fmt.Sprint(want) {
+		t.Errorf("anonymous pulls exchanged tokens %v, want %v", reg.exchanges, want)
+	}
+	if _, _, err := client.PushSignature(ctx, []byte("anonymous"), subject, PushSignatureOptions{}); err == nil {
+		t.Error("PushSignature() error = nil, want error pushing anonymously")
+	}
+}
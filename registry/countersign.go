@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+)
+
+// AnnotationCountersignature is the annotation set to "true" on the
+// signature manifests of countersignatures.
+const AnnotationCountersignature = "io.cncf.notary.countersignature"
+
+// CountersignOptions contains parameters for RepositoryClient.Countersign.
+type CountersignOptions struct {
+	notation.SignOptions
+
+	// Annotations are set on the countersignature manifest, along with
+	// AnnotationCountersignature. They are not covered by the
+	// countersignature.
+	Annotations map[string]string
+}
+
+// Countersign endorses the signature of the signature manifest described by
+// sigManifest, e.g. a signature produced by a build system, by signing the
+// signature manifest with signer. As the signature manifest pins the digest
+// of the signature envelope, the countersignature covers the envelope.
+//
+// The countersignature is linked to the signature manifest with a signature
+// manifest of its own, so the countersignatures of a signature are listed by
+// ListSignatures(ctx, sigManifest, fn), and verify like any signature, with
+// the signature manifest as signed descriptor.
+// It returns the descriptors of the countersignature and of its signature
+// manifest.
+func (c *RepositoryClient) Countersign(ctx context.Context, signer notation.Signer, sigManifest notation.Descriptor, opts CountersignOptions) (notation.Descriptor, notation.Descriptor, error) {
+	if signer == nil {
+		return notation.Descriptor{}, notation.Descriptor{}, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil signer")
+	}
+	if err := sigManifest.Validate(); err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	ctx = c.withPushScope(ctx)
+	manifest, err := c.getArtifactManifest(ctx, sigManifest.Digest)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to fetch signature manifest %s: %w", sigManifest.Digest, err)
+	}
+	if manifest.ArtifactType != ArtifactTypeNotation {
+		return notation.Descriptor{}, notation.Descriptor{}, notation.Errorf(notation.ErrorCodeInvalidArgument, "manifest %s is not a signature manifest", sigManifest.Digest)
+	}
+
+	sig, err := signer.Sign(ctx, sigManifest, opts.SignOptions)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to countersign signature manifest %s: %w", sigManifest.Digest, err)
+	}
+	if opts.DryRun {
+		return notation.Descriptor{}, notation.Descriptor{}, nil
+	}
+	annotations := make(map[string]string, len(opts.Annotations)+1)
+	for k, v := range opts.Annotations {
+		annotations[k] = v
+	}
+	annotations[AnnotationCountersignature] = "true"
+	return c.PushSignature(ctx, sig, sigManifest, PushSignatureOptions{Annotations: annotations})
+}
+
+// IsCountersignature reports whether the signature manifest is the manifest
+// of a countersignature.
+func (m SignatureManifest) IsCountersignature() bool {
+	return m.Annotations[AnnotationCountersignature] == "true"
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+// digestSigner returns the digest signed as signature.
+type digestSigner struct{}
+
+func (digestSigner) Sign(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	return []byte(desc.Digest), nil
+}
+
+func TestRepositoryClient_Countersign(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	_, sigManifest, err := client.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{})
+	if err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+
+	countersig, countersigManifest, err := client.Countersign(ctx, digestSigner{}, sigManifest, CountersignOptions{
+		Annotations: map[string]string{"team": "security", AnnotationCountersignature: "false"},
+	})
+	if err != nil {
+		t.Fatalf("Countersign() error = %v", err)
+	}
+	sig, err := client.Get(ctx, countersig.Digest)
+	if err != nil || string(sig) != string(sigManifest.Digest) {
+		t.Errorf("Get() = %q, %v, want the countersignature of %s", sig, err, sigManifest.Digest)
+	}
+	var manifests []SignatureManifest
+	if err := client.ListSignatures(ctx, sigManifest, func(page []SignatureManifest) error {
+		manifests = append(manifests, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("ListSignatures() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Descriptor.Digest != countersigManifest.Digest || !manifests[0].IsCountersignature() || manifests[0].Annotations["team"] != "security" {
+		t.Errorf("ListSignatures() = %+v, want countersignature manifest %s", manifests, countersigManifest.Digest)
+	}
+
+	// the countersignature is not a signature of the artifact
+	digests, err := client.Lookup(ctx, subject.Digest)
+	if err != nil || len(digests) != 1 {
+		t.Errorf("Lookup() = %v, %v, want the signature only", digests, err)
+	}
+
+	// only signature manifests can be countersigned
+	if _, _, err := client.Countersign(ctx, digestSigner{}, subject, CountersignOptions{}); err == nil {
+		t.Error("Countersign() error = nil, want error countersigning an artifact")
+	}
+	if _, _, err := client.Countersign(ctx, nil, sigManifest, CountersignOptions{}); !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("Countersign() error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"context"
+	"os"
+
+	"github.com/notaryproject/notation-go"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// CredentialProvider provides the credentials of registries, e.g. from a
+// secret store of the service embedding notation-go.
+// DockerCredentials, StaticCredentials, EnvironmentCredentials and
+// ChainCredentials are the built-in providers.
+type CredentialProvider interface {
+	// Credential returns the credential of registry, i.e. host:port.
+	// auth.EmptyCredential is returned if no credential is configured.
+	Credential(ctx context.Context, registry string) (auth.Credential, error)
+}
+
+// CredentialProviderFunc is an adapter to use ordinary functions as
+// credential providers.
+type CredentialProviderFunc func(ctx context.Context, registry string) (auth.Credential, error)
+
+// Credential calls f(ctx, registry).
+func (f CredentialProviderFunc) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	return f(ctx, registry)
+}
+
+// NewAuthClientWithProvider creates an auth client resolving credentials
+// with provider, and sending requests with the transport configured by opts.
+func NewAuthClientWithProvider(provider CredentialProvider, opts notation.HTTPClientOptions) (*auth.Client, error) {
+	return NewAuthClient(provider.Credential, opts)
+}
+
+// StaticCredentials maps registries, i.e. host:port, to their credentials.
+type StaticCredentials map[string]auth.Credential
+
+// Credential returns the credential of registry.
+// auth.EmptyCredential is returned if registry is not mapped.
+func (c StaticCredentials) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	if cred, ok := c[registry]; ok {
+		return cred, nil
+	}
+	return auth.EmptyCredential, nil
+}
+
+// DefaultEnvironmentPrefix is the default prefix of the environment
+// variables of EnvironmentCredentials.
+const DefaultEnvironmentPrefix = "NOTATION"
+
+// EnvironmentCredentials resolves registry credentials from the environment
+// variables {Prefix}_USERNAME and {Prefix}_PASSWORD, e.g. NOTATION_USERNAME
+// and NOTATION_PASSWORD, as commonly set in CI pipelines.
+type EnvironmentCredentials struct {
+	// Prefix is the prefix of the environment variables.
+	// DefaultEnvironmentPrefix is used if empty.
+	Prefix string
+
+	// Registries restricts the credential to these registries, i.e.
+	// host:port. The credential is used for all registries if empty.
+	Registries []string
+}
+
+// Credential returns the credential of the environment if it applies to
+// registry. auth.EmptyCredential is returned if the environment variables
+// are not set.
+func (c EnvironmentCredentials) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	if len(c.Registries) > 0 && !containsString(c.Registries, registry) {
+		return auth.EmptyCredential, nil
+	}
+	prefix := c.Prefix
+	if prefix == "" {
+		prefix = DefaultEnvironmentPrefix
+	}
+	return auth.Credential{
+		Username: os.Getenv(prefix + "_USERNAME"),
+		Password: os.Getenv(prefix + "_PASSWORD"),
+	}, nil
+}
+
+// ChainCredentials returns the first credential other than
+// auth.EmptyCredential of its providers, in order, e.g. to prefer the
+// environment over the Docker configuration file.
+type ChainCredentials []CredentialProvider
+
+// Credential returns the credential of registry of the first provider
+// configuring one, or the first error.
+func (c ChainCredentials) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	for _, provider := range c {
+		cred, err := provider.Credential(ctx, registry)
+		if err != nil {
+			return auth.EmptyCredential, err
+		}
+		if cred != auth.EmptyCredential {
+			return cred, nil
+		}
+	}
+	return auth.EmptyCredential, nil
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
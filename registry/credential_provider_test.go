@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestStaticCredentials(t *testing.T) {
+	creds := StaticCredentials{
+		"registry.example.com": {Username: "user", Password: "pass"},
+	}
+	got, err := creds.Credential(context.Background(), "registry.example.com")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if want := (auth.Credential{Username: "user", Password: "pass"}); got != want {
+		t.Errorf("Credential() = %v, want %v", got, want)
+	}
+	got, err = creds.Credential(context.Background(), "other.example.com")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if got != auth.EmptyCredential {
+		t.Errorf("Credential() = %v, want empty credential", got)
+	}
+}
+
+func TestEnvironmentCredentials(t *testing.T) {
+	t.Setenv("NOTATION_USERNAME", "user")
+	t.Setenv("NOTATION_PASSWORD", "pass")
+	t.Setenv("CI_USERNAME", "ci")
+	t.Setenv("CI_PASSWORD", "secret")
+	tests := []struct {
+		name     string
+		creds    EnvironmentCredentials
+		registry string
+		want     auth.Credential
+	}{
+		{
+			name:     "default prefix",
+			registry: "registry.example.com",
+			want:     auth.Credential{Username: "user", Password: "pass"},
+		},
+		{
+			name:     "prefix",
+			creds:    EnvironmentCredentials{Prefix: "CI"},
+			registry: "registry.example.com",
+			want:     auth.Credential{Username: "ci", Password: "secret"},
+		},
+		{
+			name:     "registry",
+			creds:    EnvironmentCredentials{Registries: []string{"registry.example.com"}},
+			registry: "registry.example.com",
+			want:     auth.Credential{Username: "user", Password: "pass"},
+		},
+		{
+			name:     "other registry",
+			creds:    EnvironmentCredentials{Registries: []string{"registry.example.com"}},
+			registry: "other.example.com",
+			want:     auth.EmptyCredential,
+		},
+		{
+			name:     "unset",
+			creds:    EnvironmentCredentials{Prefix: "UNSET"},
+			registry: "registry.example.com",
+			want:     auth.EmptyCredential,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.creds.Credential(context.Background(), tt.registry)
+			if err != nil {
+				t.Fatalf("Credential() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Credential() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainCredentials(t *testing.T) {
+	errProvider := errors.New("provider failed")
+	static := StaticCredentials{"registry.example.com": {Username: "static"}}
+	failing := CredentialProviderFunc(func(ctx context.Context, registry string) (auth.Credential, error) {
+		return auth.EmptyCredential, errProvider
+	})
+	chain := ChainCredentials{StaticCredentials{}, static, failing}
+
+	got, err := chain.Credential(context.Background(), "registry.example.com")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if want := (auth.Credential{Username: "static"}); got != want {
+		t.Errorf("Credential() = %v, want %v", got, want)
+	}
+	if _, err := chain.Credential(context.Background(), "other.example.com"); !errors.Is(err, errProvider) {
+		t.Errorf("Credential() error = %v, want %v", err, errProvider)
+	}
+}
+
+func TestNewAuthClientWithProvider(t *testing.T) {
+	var _ CredentialProvider = &DockerCredentials{}
+	client, err := NewAuthClientWithProvider(StaticCredentials{"registry.example.com": {Username: "user"}}, notation.HTTPClientOptions{})
+	if err != nil {
+		t.Fatalf("NewAuthClientWithProvider() error = %v", err)
+	}
+	got, err := client.Credential(context.Background(), "registry.example.com")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if got.Username != "user" {
+		t.Errorf("Credential() username = %q, want %q", got.Username, "user")
+	}
+}
@@ -0,0 +1,228 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/config"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// dockerHubRegistry is the registry host of Docker Hub.
+	dockerHubRegistry = "docker.io"
+
+	// dockerHubServerAddress is the server address of Docker Hub
+	// used as the key of the Docker configuration and credential helpers.
+	dockerHubServerAddress = "https://index.docker.io/v1/"
+
+	// tokenUsername is the username returned by credential helpers
+	// for identity tokens.
+	tokenUsername = "<token>"
+)
+
+// errCredentialsNotFound is the message returned by credential helpers
+// when no credentials are stored for a server.
+const errCredentialsNotFound = "credentials not found in native keychain"
+
+// dockerConfig is the subset of the Docker configuration file
+// related to registry credentials.
+type dockerConfig struct {
+	Auths       map[string]dockerAuthConfig `json:"auths"`
+	CredsStore  string                      `json:"credsStore,omitempty"`
+	CredHelpers map[string]string           `json:"credHelpers,omitempty"`
+}
+
+// dockerAuthConfig contains the credentials of a registry
+// stored in the Docker configuration file.
+type dockerAuthConfig struct {
+	Auth          string `json:"auth,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// helperCredentials is the output of the get command of credential helpers.
+type helperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// DockerCredentials resolves registry credentials from the Docker
+// configuration file, the credential store and the credential helpers
+// configured in it.
+//
+// It implements CredentialProvider, and its Credential method can be used as
+// auth.Client.Credential.
+type DockerCredentials struct {
+	config dockerConfig
+
+	// runHelper runs the get command of a credential helper.
+	runHelper func(ctx context.Context, helper, serverAddress string) ([]byte, error)
+}
+
+// LoadDockerCredentials loads the Docker configuration file at path.
+// If path is empty, config.json in $DOCKER_CONFIG or ~/.docker is used.
+// A missing configuration file results in no credentials.
+func LoadDockerCredentials(path string) (*DockerCredentials, error) {
+	if path == "" {
+		dir := os.Getenv("DOCKER_CONFIG")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			dir = filepath.Join(home, ".docker")
+		}
+		path = filepath.Join(dir, "config.json")
+	}
+	creds := &DockerCredentials{
+		runHelper: runCredentialHelper,
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return creds, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &creds.config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config %q: %w", path, err)
+	}
+	return creds, nil
+}
+
+// ApplyConfig makes the credentials store and the credential helpers of cfg
+// take precedence over the ones of the Docker configuration file.
+func (c *DockerCredentials) ApplyConfig(cfg *config.Config) {
+	if cfg.CredentialsStore != "" {
+		c.config.CredsStore = cfg.CredentialsStore
+	}
+	if len(cfg.CredentialHelpers) > 0 && c.config.CredHelpers == nil {
+		c.config.CredHelpers = make(map[string]string, len(cfg.CredentialHelpers))
+	}
+	for registry, helper := range cfg.CredentialHelpers {
+		c.config.CredHelpers[registry] = helper
+	}
+}
+
+// Credential returns the credential of registry, i.e. host:port.
+// auth.EmptyCredential is returned if no credential is configured.
+func (c *DockerCredentials) Credential(ctx context.Context, registry string) (auth.Credential, error) {
+	if helper, ok := c.config.CredHelpers[registry]; ok {
+		return c.helperCredential(ctx, helper, registry)
+	}
+	if c.config.CredsStore != "" {
+		return c.helperCredential(ctx, c.config.CredsStore, registry)
+	}
+	for key, authConfig := range c.config.Auths {
+		if normalizeRegistry(key) == registry {
+			return authConfig.credential()
+		}
+	}
+	return auth.EmptyCredential, nil
+}
+
+// helperCredential gets the credential of registry from a credential helper.
+func (c *DockerCredentials) helperCredential(ctx context.Context, helper, registry string) (auth.Credential, error) {
+	serverAddress := registry
+	if registry == dockerHubRegistry {
+		serverAddress = dockerHubServerAddress
+	}
+	out, err := c.runHelper(ctx, helper, serverAddress)
+	if err != nil {
+		if strings.Contains(string(out), errCredentialsNotFound) {
+			return auth.EmptyCredential, nil
+		}
+		return auth.EmptyCredential, fmt.Errorf("credential helper %q failed for %q: %w", helper, registry, err)
+	}
+	var creds helperCredentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return auth.EmptyCredential, fmt.Errorf("credential helper %q returned invalid credentials for %q: %w", helper, registry, err)
+	}
+	if creds.Username == tokenUsername {
+		return auth.Credential{RefreshToken: creds.Secret}, nil
+	}
+	return auth.Credential{Username: creds.Username, Password: creds.Secret}, nil
+}
+
+// credential returns the credential stored in the Docker configuration file.
+func (a dockerAuthConfig) credential() (auth.Credential, error) {
+	cred := auth.Credential{
+		Username:     a.Username,
+		Password:     a.Password,
+		RefreshToken: a.IdentityToken,
+		AccessToken:  a.RegistryToken,
+	}
+	if a.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return auth.EmptyCredential, fmt.Errorf("invalid auth in docker config: %w", err)
+		}
+		i := strings.Index(string(decoded), ":")
+		if i < 0 {
+			return auth.EmptyCredential, errors.New("invalid auth in docker config: missing password")
+		}
+		cred.Username, cred.Password = string(decoded[:i]), string(decoded[i+1:])
+	}
+	return cred, nil
+}
+
+// normalizeRegistry returns the registry host of a key of the Docker
+// configuration file, which may contain a scheme and a path.
+func normalizeRegistry(key string) string {
+	if key == dockerHubServerAddress {
+		return dockerHubRegistry
+	}
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	if i := strings.Index(key, "/"); i >= 0 {
+		key = key[:i]
+	}
+	return key
+}
+
+// runCredentialHelper runs the get command of the credential helper
+// docker-credential-{helper}.
+func runCredentialHelper(ctx context.Context, helper, serverAddress string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverAddress)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stdout
+	err := cmd.Run()
+	return bytes.TrimSpace(stdout.Bytes()), err
+}
+
+// NewAuthClient creates an auth client resolving credentials with credential,
+// e.g. DockerCredentials.Credential, and sending requests with the transport
+// configured by opts. Requests are anonymous if credential is nil.
+// Requests failing with transient errors are retried with DefaultRetryPolicy.
+//
+// Registries challenging requests with the Docker registry token auth flow
+// are handled transparently: the client exchanges the credential, if any,
+// for a token scoped to the repository and the actions of the request, and
+// caches tokens per registry and scope until they are rejected.
+func NewAuthClient(credential func(ctx context.Context, registry string) (auth.Credential, error), opts notation.HTTPClientOptions) (*auth.Client, error) {
+	transport, err := opts.Transport()
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Client{
+		Client:     &http.Client{Transport: NewRetryTransport(transport, DefaultRetryPolicy)},
+		Credential: credential,
+		Cache:      auth.NewCache(),
+	}, nil
+}
@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/notaryproject/notation-go/config"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const testDockerConfig = `{
+	"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+		"https://registry.example.com/v2/": {"identitytoken": "refresh"},
+		"plain.example.com:5000": {"username": "foo", "password": "bar"}
+	},
+	"credHelpers": {
+		"helper.example.com": "test",
+		"docker.io": "test"
+	}
+}`
+
+func loadTestCredentials(t *testing.T, config string) *DockerCredentials {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	creds, err := LoadDockerCredentials(path)
+	if err != nil {
+		t.Fatalf("LoadDockerCredentials() error = %v", err)
+	}
+	creds.runHelper = func(ctx context.Context, helper, serverAddress string) ([]byte, error) {
+		if helper != "test" {
+			return []byte("exec: not found"), errors.New("exit status 1")
+		}
+		switch serverAddress {
+		case "helper.example.com":
+			return []byte(`{"ServerURL":"helper.example.com","Username":"helper","Secret":"secret"}`), nil
+		case dockerHubServerAddress:
+			return []byte(`{"ServerURL":"https://index.docker.io/v1/","Username":"<token>","Secret":"token"}`), nil
+		}
+		return []byte(errCredentialsNotFound), errors.New("exit status 1")
+	}
+	return creds
+}
+
+func TestDockerCredentials_Credential(t *testing.T) {
+	creds := loadTestCredentials(t, testDockerConfig)
+	tests := []struct {
+		registry string
+		want     auth.Credential
+	}{
+		{"registry.example.com", auth.Credential{RefreshToken: "refresh"}},
+		{"plain.example.com:5000", auth.Credential{Username: "foo", Password: "bar"}},
+		{"helper.example.com", auth.Credential{Username: "helper", Password: "secret"}},
+		{"docker.io", auth.Credential{RefreshToken: "token"}},
+		{"unknown.example.com", auth.EmptyCredential},
+	}
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			got, err := creds.Credential(context.Background(), tt.registry)
+			if err != nil {
+				t.Fatalf("Credential() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Credential() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerCredentials_CredsStore(t *testing.T) {
+	creds := loadTestCredentials(t, `{"credsStore": "test", "auths": {"helper.example.com": {"auth": "dXNlcjpwYXNz"}}}`)
+	got, err := creds.Credential(context.Background(), "helper.example.com")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if want := (auth.Credential{Username: "helper", Password: "secret"}); got != want {
+		t.Errorf("Credential() = %+v, want %+v", got, want)
+	}
+	got, err = creds.Credential(context.Background(), "other.example.com")
+	if err != nil || got != auth.EmptyCredential {
+		t.Errorf("Credential() = %+v, %v, want empty credential", got, err)
+	}
+
+	creds = loadTestCredentials(t, `{"credsStore": "missing"}`)
+	if _, err := creds.Credential(context.Background(), "helper.example.com"); err == nil {
+		t.Error("Credential() error = nil, want error for failing credential helper")
+	}
+}
+
+func TestLoadDockerCredentials(t *testing.T) {
+	creds, err := LoadDockerCredentials(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("LoadDockerCredentials() error = %v, want nil for missing config", err)
+	}
+	if got, err := creds.Credential(context.Background(), "docker.io"); err != nil || got != auth.EmptyCredential {
+		t.Errorf("Credential() = %+v, %v, want empty credential", got, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDockerCredentials(path); err == nil {
+		t.Error("LoadDockerCredentials() error = nil, want error for malformed config")
+	}
+}
+
+func TestDockerCredentials_ApplyConfig(t *testing.T) {
+	creds := loadTestCredentials(t, `{"auths": {"plain.example.com:5000": {"username": "foo", "password": "bar"}}}`)
+	creds.ApplyConfig(&config.Config{
+		CredentialHelpers: map[string]string{"helper.example.com": "test"},
+	})
+	got, err := creds.Credential(context.Background(), "helper.example.com")
+	if err != nil {
+		t.Fatalf("Credential() error = %v", err)
+	}
+	if want := (auth.Credential{Username: "helper", Password: "secret"}); got != want {
+		t.Errorf("Credential() = %+v, want %+v", got, want)
+	}
+
+	creds.ApplyConfig(&config.Config{CredentialsStore: "missing"})
+	if _, err := creds.Credential(context.Background(), "plain.example.com:5000"); err == nil {
+		t.Error("Credential() error = nil, want error for failing credentials store")
+	}
+}
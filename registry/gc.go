@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/log"
+)
+
+// GarbageCollectOptions contains parameters for GarbageCollectSignatures.
+type GarbageCollectOptions struct {
+	// VerifyOptions contains the parameters of the verification of the
+	// signatures.
+	VerifyOptions notation.VerifyOptions
+
+	// DryRun reports the signature manifests to be deleted without deleting
+	// them.
+	DryRun bool
+}
+
+// GarbageCollectSignatures deletes the signature manifests linked to the
+// manifest desc whose signatures all fail verification by verifier, e.g.
+// because their certificates expired or were revoked, or because they do not
+// meet the trust policy the verifier enforces. Signature manifests holding a
+// valid signature are kept.
+//
+// A signature fails verification if the outcome of the verification reports
+// definitive failures only, i.e. the signature is expired, invalid, or its
+// signer is untrusted, revoked or denied by policy. If a signature cannot be
+// verified conclusively, e.g. because of invalid options, a failed or
+// missing verification plugin, a network error or a canceled context,
+// garbage collection stops with the error before anything is deleted.
+//
+// It returns the signature manifests deleted, or to be deleted if
+// opts.DryRun is set.
+func GarbageCollectSignatures(ctx context.Context, repo *RepositoryClient, desc notation.Descriptor, verifier notation.Verifier, opts GarbageCollectOptions) ([]SignatureManifest, error) {
+	if verifier == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil verifier")
+	}
+	logger := log.GetLogger(ctx)
+
+	// collect the garbage first, as deleting while listing shifts the pages
+	var garbage []SignatureManifest
+	if err := repo.ListSignatures(ctx, desc, func(manifests []SignatureManifest) error {
+		for _, manifest := range manifests {
+			valid, err := hasValidSignature(ctx, repo, desc, manifest, verifier, opts.VerifyOptions)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				garbage = append(garbage, manifest)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return garbage, nil
+	}
+
+	for i, manifest := range garbage {
+		logger.Infof("deleting signature manifest %s of manifest %s", manifest.Descriptor.Digest, desc.Digest)
+		if err := repo.DeleteSignature(ctx, manifest.Descriptor); err != nil {
+			return garbage[:i], fmt.Errorf("failed to delete signature manifest %s: %w", manifest.Descriptor.Digest, err)
+		}
+	}
+	return garbage, nil
+}
+
+// hasValidSignature reports whether the signature manifest holds a signature
+// of the manifest desc passing verification.
+func hasValidSignature(ctx context.Context, repo *RepositoryClient, desc notation.Descriptor, manifest SignatureManifest, verifier notation.Verifier, opts notation.VerifyOptions) (bool, error) {
+	logger := log.GetLogger(ctx)
+	for _, blob := range manifest.Blobs {
+		sig, err := repo.Get(ctx, blob.Digest)
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch signature %s: %w", blob.Digest, err)
+		}
+		outcome, err := verifier.Verify(ctx, sig, opts)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
+		if err != nil {
+			if outcome == nil || len(outcome.Failures()) == 0 {
+				return false, fmt.Errorf("failed to verify signature %s: %w", blob.Digest, err)
+			}
+			for _, failure := range outcome.Failures() {
+				if !isDefinitiveFailure(failure.Error) {
+					return false, fmt.Errorf("failed to verify signature %s: %w", blob.Digest, failure.Error)
+				}
+			}
+			logger.Debugf("signature %s of manifest %s is invalid: %v", blob.Digest, desc.Digest, err)
+			continue
+		}
+		if outcome.Descriptor.Digest != desc.Digest {
+			logger.Debugf("signature %s signs manifest %s instead of %s", blob.Digest, outcome.Descriptor.Digest, desc.Digest)
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// definitiveFailures are the verification failures which do not change when
+// the verification is retried, so that the signatures failing them are
+// garbage.
+var definitiveFailures = []error{
+	notation.ErrSignatureExpired,
+	notation.ErrUntrustedSigner,
+	notation.ErrPolicyDenied,
+	notation.ErrInvalidSignature,
+	notation.ErrMalformedSignature,
+	notation.ErrInvalidCertificate,
+}
+
+// isDefinitiveFailure reports whether err is a definitive verification
+// failure. Plugin failures, e.g. plugin timeouts, and errors without a
+// notation error code, e.g. network errors, are not.
+func isDefinitiveFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	for _, target := range definitiveFailures {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+// statusVerifier verifies the signatures "valid" of subject, fails the
+// expiry check of the signatures "expired", fails the authenticity check of
+// the signatures "plugin-failed" with a plugin failure and cannot verify
+// others.
+type statusVerifier struct {
+	subject notation.Descriptor
+}
+
+func (v statusVerifier) Verify(ctx context.Context, signature []byte, opts notation.VerifyOptions) (*notation.VerificationOutcome, error) {
+	outcome := &notation.VerificationOutcome{Descriptor: v.subject}
+	switch string(signature) {
+	case "valid":
+		outcome.Pass(notation.CheckExpiry)
+		return outcome, nil
+	case "expired":
+		return outcome, outcome.Fail(notation.CheckExpiry, notation.Errorf(notation.ErrorCodeSignatureExpired, "signature expired"))
+	case "plugin-failed":
+		return outcome, outcome.Fail(notation.CheckAuthenticity, notation.Errorf(notation.ErrorCodePluginFailed, "plugin timed out"))
+	}
+	return nil, errors.New("verifier unavailable")
+}
+
+func TestGarbageCollectSignatures(t *testing.T) {
+	ctx := context.Background()
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	listDigests := func(client *RepositoryClient) map[digest.Digest]bool {
+		digests := make(map[digest.Digest]bool)
+		if err := client.ListSignatures(ctx, subject, func(manifests []SignatureManifest) error {
+			for _, manifest := range manifests {
+				digests[manifest.Descriptor.Digest] = true
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("ListSignatures() error = %v", err)
+		}
+		return digests
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		client, _ := newTestRepositoryClient(t)
+		client.ReferrerListPageSize = 1
+		_, valid, err := client.PushSignature(ctx, []byte("valid"), subject, PushSignatureOptions{})
+		if err != nil {
+			t.Fatalf("PushSignature() error = %v", err)
+		}
+		_, expired, err := client.PushSignature(ctx, []byte("expired"), subject, PushSignatureOptions{})
+		if err != nil {
+			t.Fatalf("PushSignature() error = %v", err)
+		}
+
+		deleted, err := GarbageCollectSignatures(ctx, client, subject, statusVerifier{subject: subject}, GarbageCollectOptions{DryRun: dryRun})
+		if err != nil {
+			t.Fatalf("GarbageCollectSignatures() error = %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].Descriptor.Digest != expired.Digest {
+			t.Errorf("GarbageCollectSignatures() = %v, want [%v]", deleted, expired.Digest)
+		}
+		remaining := listDigests(client)
+		if !remaining[valid.Digest] || remaining[expired.Digest] != dryRun {
+			t.Errorf("GarbageCollectSignatures(DryRun: %v) left %v", dryRun, remaining)
+		}
+	}
+}
+
+func TestGarbageCollectSignatures_VerificationError(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	for _, sig := range []string{"expired", "unverifiable"} {
+		if _, _, err := client.PushSignature(ctx, []byte(sig), subject, PushSignatureOptions{}); err != nil {
+			t.Fatalf("PushSignature() error = %v", err)
+		}
+	}
+	if _, err := GarbageCollectSignatures(ctx, client, subject, statusVerifier{subject: subject}, GarbageCollectOptions{}); err == nil {
+		t.Fatal("GarbageCollectSignatures() error = nil, want error")
+	}
+	digests, err := client.Lookup(ctx, subject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(digests) != 2 {
+		t.Errorf("GarbageCollectSignatures() deleted signatures on error, %d left", len(digests))
+	}
+}
+
+func TestGarbageCollectSignatures_Inconclusive(t *testing.T) {
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		sig  string
+		want error
+	}{
+		{name: "plugin failure", ctx: context.Background(), sig: "plugin-failed", want: notation.ErrPluginFailed},
+		{name: "canceled context", ctx: canceled, sig: "expired", want: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestRepositoryClient(t)
+			if _, _, err := client.PushSignature(context.Background(), []byte(tt.sig), subject, PushSignatureOptions{}); err != nil {
+				t.Fatalf("PushSignature() error = %v", err)
+			}
+			if _, err := GarbageCollectSignatures(tt.ctx, client, subject, statusVerifier{subject: subject}, GarbageCollectOptions{}); !errors.Is(err, tt.want) {
+				t.Errorf("GarbageCollectSignatures() error = %v, want %v", err, tt.want)
+			}
+			digests, err := client.Lookup(context.Background(), subject.Digest)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if len(digests) != 1 {
+				t.Errorf("GarbageCollectSignatures() deleted an inconclusively verified signature")
+			}
+		})
+	}
+}
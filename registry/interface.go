@@ -20,4 +20,19 @@ type SignatureRepository interface {
 
 	// Link creates an signature artifact linking the manifest and the signature
 	Link(ctx context.Context, manifest, signature notation.Descriptor) (notation.Descriptor, error)
+
+	// DeleteSignature deletes the signature manifest described by desc,
+	// unlinking its signatures from the manifest they sign
+	DeleteSignature(ctx context.Context, desc notation.Descriptor) error
+}
+
+// SignatureLister is implemented by signature repositories able to look up
+// the descriptors of the signatures of a manifest, so that signatures can be
+// limited in number and size before being fetched.
+type SignatureLister interface {
+	// LookupDescriptors finds the descriptors of the signatures for the
+	// specified manifest. If limit is positive and the manifest has more
+	// signatures, it stops looking up and fails with a
+	// notation.ErrorCodeLimitExceeded error.
+	LookupDescriptors(ctx context.Context, manifestDigest digest.Digest, limit int) ([]notation.Descriptor, error)
 }
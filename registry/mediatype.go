@@ -5,3 +5,11 @@ const ArtifactTypeNotation = "application/vnd.cncf.notary.v2.signature"
 
 // MediaTypeNotationSignature specifies the media type for the notation signature.
 const MediaTypeNotationSignature = "application/jose+json"
+
+// ArtifactTypeAttestation specifies the artifact type for the manifests
+// linking attestations, e.g. in-toto statements, to artifacts.
+const ArtifactTypeAttestation = "application/vnd.in-toto+json"
+
+// MediaTypeAttestation specifies the media type for the DSSE envelopes of
+// attestations.
+const MediaTypeAttestation = "application/vnd.dsse.envelope.v1+json"
@@ -0,0 +1,44 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/notaryproject/notation-go/config"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// MirrorReference returns the reference of ref in the mirror of its registry
+// configured in cfg, and whether the registry is mirrored.
+// For instance, docker.io/library/alpine is mirrored as
+// mirror.example.com/dockerhub/library/alpine if docker.io is mirrored by
+// mirror.example.com/dockerhub.
+func MirrorReference(ref registry.Reference, cfg *config.Config) (registry.Reference, bool) {
+	mirror, ok := cfg.RegistryMirror(ref.Registry)
+	if !ok {
+		return ref, false
+	}
+	mirrored := ref
+	mirrored.Registry = mirror
+	if i := strings.Index(mirror, "/"); i >= 0 {
+		mirrored.Registry = mirror[:i]
+		mirrored.Repository = mirror[i+1:] + "/" + ref.Repository
+	}
+	return mirrored, true
+}
+
+// NewMirrorRepositoryClientWithConfig creates a client fetching the
+// signatures of the repository ref from the mirror of its registry
+// configured in cfg, if any, over plain HTTP if the mirror is an insecure
+// registry of cfg. It is equivalent to NewRepositoryClientWithConfig if the
+// registry is not mirrored.
+//
+// The client is meant for verification: artifacts are still referenced by
+// their upstream name, e.g. to match the registry scopes of trust policies,
+// while their signatures are fetched from the mirror. Signatures are pushed
+// to the upstream repository with a client created by
+// NewRepositoryClientWithConfig.
+func NewMirrorRepositoryClientWithConfig(client remote.Client, ref registry.Reference, cfg *config.Config) *RepositoryClient {
+	ref, _ = MirrorReference(ref, cfg)
+	return NewRepositoryClientWithConfig(client, ref, cfg)
+}
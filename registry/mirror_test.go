@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/config"
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestMirrorReference(t *testing.T) {
+	cfg := &config.Config{
+		RegistryMirrors: map[string]string{
+			"docker.io":            "mirror.example.com/dockerhub",
+			"registry.example.com": "localhost:5000",
+		},
+	}
+	tests := []struct {
+		name   string
+		ref    registry.Reference
+		want   registry.Reference
+		wantOK bool
+	}{
+		{
+			name:   "path prefix",
+			ref:    registry.Reference{Registry: "docker.io", Repository: "library/alpine", Reference: "sha256:abc"},
+			want:   registry.Reference{Registry: "mirror.example.com", Repository: "dockerhub/library/alpine", Reference: "sha256:abc"},
+			wantOK: true,
+		},
+		{
+			name:   "host",
+			ref:    registry.Reference{Registry: "registry.example.com", Repository: "app"},
+			want:   registry.Reference{Registry: "localhost:5000", Repository: "app"},
+			wantOK: true,
+		},
+		{
+			name: "not mirrored",
+			ref:  registry.Reference{Registry: "ghcr.io", Repository: "app"},
+			want: registry.Reference{Registry: "ghcr.io", Repository: "app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MirrorReference(tt.ref, cfg)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("MirrorReference() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewMirrorRepositoryClientWithConfig(t *testing.T) {
+	ctx := context.Background()
+	mirror, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	sigDesc, _, err := mirror.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{})
+	if err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+
+	cfg := &config.Config{
+		InsecureRegistries: []string{mirror.Reference.Registry},
+		RegistryMirrors:    map[string]string{"registry.example.com": mirror.Reference.Registry},
+	}
+	upstream := registry.Reference{Registry: "registry.example.com", Repository: testRepository}
+	client := NewMirrorRepositoryClientWithConfig(mirror.Client, upstream, cfg)
+	digests, err := client.Lookup(ctx, subject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(digests) != 1 || digests[0] != sigDesc.Digest {
+		t.Errorf("Lookup() = %v, want [%v]", digests, sigDesc.Digest)
+	}
+}
@@ -0,0 +1,170 @@
+// Package registrytest provides an in-memory signature repository, to test
+// signing and verification flows without a live registry.
+package registrytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/opencontainers/go-digest"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+var _ registry.SignatureRepository = (*Repository)(nil)
+
+// Repository is an in-memory registry.SignatureRepository. Signatures are
+// stored by Put and linked to the manifests they sign by Link, as a registry
+// stores signature blobs and signature manifests.
+//
+// The zero value is an empty repository ready to use. A Repository is safe
+// for concurrent use.
+type Repository struct {
+	// Fail, if set, is called with the name of each operation, e.g. "Get",
+	// before running it. The operation fails with the error returned, if
+	// any, e.g. to test the handling of registry failures.
+	Fail func(operation string) error
+
+	mu        sync.Mutex
+	blobs     map[digest.Digest][]byte
+	manifests map[digest.Digest]artifactspec.Manifest
+
+	// links are the digests of the signature manifests, in link order.
+	links []digest.Digest
+}
+
+// Lookup returns the digests of the signatures linked to the manifest
+// manifestDigest, in link order.
+func (r *Repository) Lookup(ctx context.Context, manifestDigest digest.Digest) ([]digest.Digest, error) {
+	if err := r.fail("Lookup"); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var signatures []digest.Digest
+	for _, link := range r.links {
+		manifest := r.manifests[link]
+		if manifest.Subject.Digest != manifestDigest {
+			continue
+		}
+		for _, blob := range manifest.Blobs {
+			signatures = append(signatures, blob.Digest)
+		}
+	}
+	return signatures, nil
+}
+
+// Get returns the signature signatureDigest. The returned error wraps
+// errdef.ErrNotFound if the signature is not stored.
+func (r *Repository) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
+	if err := r.fail("Get"); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	signature, ok := r.blobs[signatureDigest]
+	if !ok {
+		return nil, fmt.Errorf("signature %s: %w", signatureDigest, errdef.ErrNotFound)
+	}
+	return append([]byte(nil), signature...), nil
+}
+
+// Put stores the signature.
+func (r *Repository) Put(ctx context.Context, signature []byte) (notation.Descriptor, error) {
+	if err := r.fail("Put"); err != nil {
+		return notation.Descriptor{}, err
+	}
+	desc := notation.Descriptor{
+		MediaType: registry.MediaTypeNotationSignature,
+		Digest:    digest.FromBytes(signature),
+		Size:      int64(len(signature)),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.blobs == nil {
+		r.blobs = make(map[digest.Digest][]byte)
+	}
+	r.blobs[desc.Digest] = append([]byte(nil), signature...)
+	return desc, nil
+}
+
+// Link stores a signature manifest linking the manifest and the signature,
+// and returns its descriptor. The signature must be stored.
+func (r *Repository) Link(ctx context.Context, manifest, signature notation.Descriptor) (notation.Descriptor, error) {
+	if err := r.fail("Link"); err != nil {
+		return notation.Descriptor{}, err
+	}
+	artifact := artifactspec.Manifest{
+		MediaType:    artifactspec.MediaTypeArtifactManifest,
+		ArtifactType: registry.ArtifactTypeNotation,
+		Blobs:        []artifactspec.Descriptor{artifactDescriptor(signature)},
+		Subject:      artifactDescriptor(manifest),
+	}
+	artifactJSON, err := json.Marshal(artifact)
+	if err != nil {
+		return notation.Descriptor{}, err
+	}
+	desc := notation.Descriptor{
+		MediaType: artifactspec.MediaTypeArtifactManifest,
+		Digest:    digest.FromBytes(artifactJSON),
+		Size:      int64(len(artifactJSON)),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.blobs[signature.Digest]; !ok {
+		return notation.Descriptor{}, fmt.Errorf("signature %s: %w", signature.Digest, errdef.ErrNotFound)
+	}
+	if _, ok := r.manifests[desc.Digest]; ok {
+		return desc, nil
+	}
+	if r.manifests == nil {
+		r.manifests = make(map[digest.Digest]artifactspec.Manifest)
+	}
+	r.manifests[desc.Digest] = artifact
+	r.links = append(r.links, desc.Digest)
+	return desc, nil
+}
+
+// DeleteSignature deletes the signature manifest described by desc,
+// unlinking its signature from the manifest it signs. The returned error
+// wraps errdef.ErrNotFound if the signature manifest is not stored.
+func (r *Repository) DeleteSignature(ctx context.Context, desc notation.Descriptor) error {
+	if err := r.fail("DeleteSignature"); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.manifests[desc.Digest]; !ok {
+		return fmt.Errorf("signature manifest %s: %w", desc.Digest, errdef.ErrNotFound)
+	}
+	delete(r.manifests, desc.Digest)
+	for i, link := range r.links {
+		if link == desc.Digest {
+			r.links = append(r.links[:i], r.links[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// fail returns the error r.Fail returns for operation, if any.
+func (r *Repository) fail(operation string) error {
+	if r.Fail == nil {
+		return nil
+	}
+	return r.Fail(operation)
+}
+
+// artifactDescriptor converts desc to an artifact descriptor.
+func artifactDescriptor(desc notation.Descriptor) artifactspec.Descriptor {
+	return artifactspec.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	}
+}
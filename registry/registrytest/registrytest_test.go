@@ -0,0 +1,97 @@
+package registrytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	manifest := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("artifact"),
+		Size:      8,
+	}
+	var repo Repository
+
+	var sigDescs, manifestDescs []notation.Descriptor
+	for _, sig := range []string{"first", "second"} {
+		sigDesc, err := repo.Put(ctx, []byte(sig))
+		if err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		manifestDesc, err := repo.Link(ctx, manifest, sigDesc)
+		if err != nil {
+			t.Fatalf("Link() error = %v", err)
+		}
+		sigDescs = append(sigDescs, sigDesc)
+		manifestDescs = append(manifestDescs, manifestDesc)
+	}
+
+	got, err := repo.Lookup(ctx, manifest.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	want := []digest.Digest{sigDescs[0].Digest, sigDescs[1].Digest}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup() = %v, want %v", got, want)
+	}
+	sig, err := repo.Get(ctx, sigDescs[1].Digest)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !bytes.Equal(sig, []byte("second")) {
+		t.Errorf("Get() = %q, want %q", sig, "second")
+	}
+
+	if err := repo.DeleteSignature(ctx, manifestDescs[0]); err != nil {
+		t.Fatalf("DeleteSignature() error = %v", err)
+	}
+	got, err = repo.Lookup(ctx, manifest.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if want := []digest.Digest{sigDescs[1].Digest}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lookup() after DeleteSignature() = %v, want %v", got, want)
+	}
+	if err := repo.DeleteSignature(ctx, manifestDescs[0]); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("DeleteSignature() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestRepository_NotFound(t *testing.T) {
+	ctx := context.Background()
+	var repo Repository
+	if _, err := repo.Get(ctx, digest.FromString("missing")); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	missing := notation.Descriptor{Digest: digest.FromString("missing")}
+	if _, err := repo.Link(ctx, missing, missing); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Link() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestRepository_Fail(t *testing.T) {
+	errInjected := errors.New("injected")
+	repo := Repository{
+		Fail: func(operation string) error {
+			if operation == "Put" {
+				return errInjected
+			}
+			return nil
+		},
+	}
+	if _, err := repo.Put(context.Background(), []byte("sig")); !errors.Is(err, errInjected) {
+		t.Errorf("Put() error = %v, want %v", err, errInjected)
+	}
+	if _, err := repo.Lookup(context.Background(), digest.FromString("artifact")); err != nil {
+		t.Errorf("Lookup() error = %v", err)
+	}
+}
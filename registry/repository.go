@@ -4,9 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/config"
+	"github.com/notaryproject/notation-go/event"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/metrics"
+	"github.com/notaryproject/notation-go/trace"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
@@ -20,6 +27,25 @@ const (
 	maxManifestSizeLimit = 4 * 1024 * 1024  // 4 MiB
 )
 
+// PushSignatureOptions contains parameters for RepositoryClient.PushSignature.
+type PushSignatureOptions struct {
+	// Annotations are set on the signature manifest, e.g. a build ID or a
+	// pipeline URL. They are not covered by the signature.
+	Annotations map[string]string
+}
+
+// SignatureManifest is a signature manifest linked to an artifact.
+type SignatureManifest struct {
+	// Descriptor is the descriptor of the signature manifest.
+	Descriptor notation.Descriptor
+
+	// Blobs are the descriptors of the signatures.
+	Blobs []notation.Descriptor
+
+	// Annotations are the annotations of the signature manifest.
+	Annotations map[string]string
+}
+
 type RepositoryClient struct {
 	remote.Repository
 }
@@ -35,8 +61,17 @@ func NewRepositoryClient(client remote.Client, ref registry.Reference, plainHTTP
 	}
 }
 
+// NewRepositoryClientWithConfig creates a new registry client accessing the
+// registry over plain HTTP if it is an insecure registry of cfg.
+func NewRepositoryClientWithConfig(client remote.Client, ref registry.Reference, cfg *config.Config) *RepositoryClient {
+	return NewRepositoryClient(client, ref, cfg.IsRegistryInsecure(ref.Registry))
+}
+
 // GetManifestDescriptor returns signature manifest information by tag or digest.
-func (c *RepositoryClient) GetManifestDescriptor(ctx context.Context, ref string) (notation.Descriptor, error) {
+func (c *RepositoryClient) GetManifestDescriptor(ctx context.Context, ref string) (_ notation.Descriptor, err error) {
+	ctx, end := c.startOperation(ctx, "Resolve", trace.Attribute{Key: "notation.registry.reference", Value: ref})
+	defer func() { end(err) }()
+	log.GetLogger(ctx).Debugf("resolving manifest %q of repository %s", ref, c.Reference)
 	desc, err := c.Repository.Resolve(ctx, ref)
 	if err != nil {
 		return notation.Descriptor{}, err
@@ -46,33 +81,98 @@ func (c *RepositoryClient) GetManifestDescriptor(ctx context.Context, ref string
 
 // Lookup finds all signatures for the specified manifest
 func (c *RepositoryClient) Lookup(ctx context.Context, manifestDigest digest.Digest) ([]digest.Digest, error) {
-	var digests []digest.Digest
-	// TODO(shizhMSFT): filter artifact type at the server side
-	if err := c.Repository.Referrers(ctx, ocispec.Descriptor{
+	descs, err := c.LookupDescriptors(ctx, manifestDigest, 0)
+	if err != nil {
+		return nil, err
+	}
+	digests := make([]digest.Digest, 0, len(descs))
+	for _, desc := range descs {
+		digests = append(digests, desc.Digest)
+	}
+	return digests, nil
+}
+
+// LookupDescriptors finds the descriptors of the signatures for the
+// specified manifest. If limit is positive, the referrers are no longer
+// paged through once more than limit signatures are found.
+func (c *RepositoryClient) LookupDescriptors(ctx context.Context, manifestDigest digest.Digest, limit int) ([]notation.Descriptor, error) {
+	var descs []notation.Descriptor
+	if err := c.ListSignatures(ctx, notation.Descriptor{
 		Digest: manifestDigest,
+	}, func(manifests []SignatureManifest) error {
+		for _, manifest := range manifests {
+			descs = append(descs, manifest.Blobs...)
+		}
+		if limit > 0 && len(descs) > limit {
+			return errTooManySignatures
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, errTooManySignatures) {
+			return nil, notation.Errorf(notation.ErrorCodeLimitExceeded, "manifest %s has more than the maximum of %d signatures", manifestDigest, limit)
+		}
+		return nil, err
+	}
+	return descs, nil
+}
+
+// errTooManySignatures stops listing the signatures of a manifest having more
+// than the limit of LookupDescriptors.
+var errTooManySignatures = errors.New("too many signatures")
+
+// ListSignatures pages through the signature manifests linked to the
+// specified manifest, calling fn with the signature manifests of each page
+// of referrers. Pages are fetched lazily, and listing stops with the error
+// returned by fn, if any.
+func (c *RepositoryClient) ListSignatures(ctx context.Context, desc notation.Descriptor, fn func(manifests []SignatureManifest) error) (err error) {
+	ctx, end := c.startOperation(ctx, "ListSignatures", trace.Attribute{Key: "notation.artifact.digest", Value: desc.Digest.String()})
+	defer func() { end(err) }()
+	log.GetLogger(ctx).Debugf("listing signatures of manifest %s in repository %s", desc.Digest, c.Reference)
+	return c.listReferrers(ctx, desc, ArtifactTypeNotation, fn)
+}
+
+// listReferrers pages through the manifests of type artifactType linked to
+// the specified manifest, as ListSignatures.
+func (c *RepositoryClient) listReferrers(ctx context.Context, desc notation.Descriptor, artifactType string, fn func(manifests []SignatureManifest) error) error {
+	logger := log.GetLogger(ctx)
+	// TODO(shizhMSFT): filter artifact type at the server side
+	return c.Repository.Referrers(ctx, ocispec.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
 	}, func(referrers []artifactspec.Descriptor) error {
+		logger.Debugf("fetched a page of %d referrers of manifest %s", len(referrers), desc.Digest)
+		var manifests []SignatureManifest
 		for _, desc := range referrers {
-			if desc.ArtifactType != ArtifactTypeNotation || desc.MediaType != artifactspec.MediaTypeArtifactManifest {
+			if desc.ArtifactType != artifactType || desc.MediaType != artifactspec.MediaTypeArtifactManifest {
 				continue
 			}
 			artifact, err := c.getArtifactManifest(ctx, desc.Digest)
 			if err != nil {
 				return fmt.Errorf("failed to fetch manifest: %v: %v", desc.Digest, err)
 			}
+			manifest := SignatureManifest{
+				Descriptor:  notationDescriptorFromArtifact(desc),
+				Annotations: artifact.Annotations,
+			}
 			for _, blob := range artifact.Blobs {
-				digests = append(digests, blob.Digest)
+				manifest.Blobs = append(manifest.Blobs, notationDescriptorFromArtifact(blob))
 			}
+			manifests = append(manifests, manifest)
 		}
-		return nil
-	}); err != nil {
-		return nil, err
-	}
-	return digests, nil
+		if len(manifests) == 0 {
+			return nil
+		}
+		return fn(manifests)
+	})
 }
 
 // Get downloads the signature by the specified digest
-func (c *RepositoryClient) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
-	desc, err := c.Repository.Resolve(ctx, signatureDigest.String())
+func (c *RepositoryClient) Get(ctx context.Context, signatureDigest digest.Digest) (_ []byte, err error) {
+	ctx, end := c.startOperation(ctx, "Get", trace.Attribute{Key: "notation.signature.digest", Value: signatureDigest.String()})
+	defer func() { end(err) }()
+	log.GetLogger(ctx).Debugf("fetching signature %s from repository %s", signatureDigest, c.Reference)
+	desc, err := c.Repository.Blobs().Resolve(ctx, signatureDigest.String())
 	if err != nil {
 		return nil, err
 	}
@@ -84,11 +184,19 @@ func (c *RepositoryClient) Get(ctx context.Context, signatureDigest digest.Diges
 
 // Put uploads the signature to the registry
 func (c *RepositoryClient) Put(ctx context.Context, signature []byte) (notation.Descriptor, error) {
+	return c.put(ctx, MediaTypeNotationSignature, signature)
+}
+
+// put uploads the signature blob of media type mediaType.
+func (c *RepositoryClient) put(ctx context.Context, mediaType string, signature []byte) (_ notation.Descriptor, err error) {
 	desc := ocispec.Descriptor{
-		MediaType: MediaTypeNotationSignature,
+		MediaType: mediaType,
 		Digest:    digest.FromBytes(signature),
 		Size:      int64(len(signature)),
 	}
+	ctx, end := c.startOperation(ctx, "Put", trace.Attribute{Key: "notation.signature.digest", Value: desc.Digest.String()})
+	defer func() { end(err) }()
+	log.GetLogger(ctx).Debugf("uploading blob %s of media type %q to repository %s", desc.Digest, desc.MediaType, c.Reference)
 	if err := c.Repository.Blobs().Push(ctx, desc, bytes.NewReader(signature)); err != nil {
 		return notation.Descriptor{}, err
 	}
@@ -97,30 +205,96 @@ func (c *RepositoryClient) Put(ctx context.Context, signature []byte) (notation.
 
 // Link creates an signature artifact linking the manifest and the signature
 func (c *RepositoryClient) Link(ctx context.Context, manifest, signature notation.Descriptor) (notation.Descriptor, error) {
-	// generate artifact manifest
+	return c.link(ctx, ArtifactTypeNotation, manifest, signature, nil)
+}
+
+// PushSignature uploads the signature and links it to the manifest with a
+// signature manifest annotated with opts.Annotations.
+// It returns the descriptors of the signature and the signature manifest.
+func (c *RepositoryClient) PushSignature(ctx context.Context, signature []byte, manifest notation.Descriptor, opts PushSignatureOptions) (notation.Descriptor, notation.Descriptor, error) {
+	ctx = c.withPushScope(ctx)
+	sigDesc, err := c.Put(ctx, signature)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to upload signature: %w", err)
+	}
+	manifestDesc, err := c.link(ctx, ArtifactTypeNotation, manifest, sigDesc, opts.Annotations)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to link signature: %w", err)
+	}
+	event.Emit(ctx, event.Event{Type: event.SignaturePushed, Artifact: manifest.Digest, Signature: sigDesc.Digest})
+	return sigDesc, manifestDesc, nil
+}
+
+// DeleteSignature deletes the signature manifest described by desc,
+// unlinking its signatures from the manifest they sign. The signature blobs
+// are left to the garbage collection of the registry.
+func (c *RepositoryClient) DeleteSignature(ctx context.Context, desc notation.Descriptor) (err error) {
+	ctx, end := c.startOperation(ctx, "DeleteSignature", trace.Attribute{Key: "notation.signature.manifest.digest", Value: desc.Digest.String()})
+	defer func() { end(err) }()
+	log.GetLogger(ctx).Debugf("deleting signature manifest %s from repository %s", desc.Digest, c.Reference)
+	return c.Repository.Manifests().Delete(ctx, ocispec.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	})
+}
+
+// link pushes a manifest of type artifactType with annotations linking the
+// manifest and the signature
+func (c *RepositoryClient) link(ctx context.Context, artifactType string, manifest, signature notation.Descriptor, annotations map[string]string) (_ notation.Descriptor, err error) {
+	ctx, end := c.startOperation(ctx, "Link",
+		trace.Attribute{Key: "notation.artifact.digest", Value: manifest.Digest.String()},
+		trace.Attribute{Key: "notation.signature.digest", Value: signature.Digest.String()},
+	)
+	defer func() { end(err) }()
+	artifactJSON, desc, err := newArtifactManifest(artifactType, manifest, signature, annotations)
+	if err != nil {
+		return notation.Descriptor{}, err
+	}
+
+	// upload manifest
+	log.GetLogger(ctx).Debugf("linking signature %s to manifest %s with signature manifest %s", signature.Digest, manifest.Digest, desc.Digest)
+	if err := c.Repository.Manifests().Push(ctx, desc, bytes.NewReader(artifactJSON)); err != nil {
+		return notation.Descriptor{}, err
+	}
+	return notationDescriptorFromOCI(desc), nil
+}
+
+// newArtifactManifest generates a manifest of type artifactType with
+// annotations linking the manifest and the blob, and returns the manifest
+// and its descriptor.
+func newArtifactManifest(artifactType string, manifest, blob notation.Descriptor, annotations map[string]string) ([]byte, ocispec.Descriptor, error) {
 	artifact := artifactspec.Manifest{
 		MediaType:    artifactspec.MediaTypeArtifactManifest,
-		ArtifactType: ArtifactTypeNotation,
+		ArtifactType: artifactType,
 		Blobs: []artifactspec.Descriptor{
-			artifactDescriptorFromNotation(signature),
+			artifactDescriptorFromNotation(blob),
 		},
-		Subject: artifactDescriptorFromNotation(manifest),
+		Subject:     artifactDescriptorFromNotation(manifest),
+		Annotations: annotations,
 	}
 	artifactJSON, err := json.Marshal(artifact)
 	if err != nil {
-		return notation.Descriptor{}, err
+		return nil, ocispec.Descriptor{}, err
 	}
-
-	// upload manifest
-	desc := ocispec.Descriptor{
+	return artifactJSON, ocispec.Descriptor{
 		MediaType: artifactspec.MediaTypeArtifactManifest,
 		Digest:    digest.FromBytes(artifactJSON),
 		Size:      int64(len(artifactJSON)),
+	}, nil
+}
+
+// startOperation starts the span of a registry operation on the repository,
+// and returns a function ending the span and recording the operation latency.
+func (c *RepositoryClient) startOperation(ctx context.Context, operation string, attrs ...trace.Attribute) (context.Context, func(err error)) {
+	start := time.Now()
+	attrs = append(attrs, trace.Attribute{Key: "notation.registry.repository", Value: c.Reference.String()})
+	ctx, span := trace.StartSpan(ctx, "notation.registry."+operation, attrs...)
+	recorder := metrics.GetRecorder(ctx)
+	return ctx, func(err error) {
+		trace.EndSpan(span, err)
+		recorder.RecordRegistryOperation(operation, time.Since(start), err)
 	}
-	if err := c.Repository.Manifests().Push(ctx, desc, bytes.NewReader(artifactJSON)); err != nil {
-		return notation.Descriptor{}, err
-	}
-	return notationDescriptorFromOCI(desc), nil
 }
 
 func (c *RepositoryClient) getArtifactManifest(ctx context.Context, manifestDigest digest.Digest) (artifactspec.Manifest, error) {
@@ -157,6 +331,14 @@ func artifactDescriptorFromNotation(desc notation.Descriptor) artifactspec.Descr
 	}
 }
 
+func notationDescriptorFromArtifact(desc artifactspec.Descriptor) notation.Descriptor {
+	return notation.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	}
+}
+
 func notationDescriptorFromOCI(desc ocispec.Descriptor) notation.Descriptor {
 	return notation.Descriptor{
 		MediaType: desc.MediaType,
@@ -0,0 +1,354 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/metrics"
+	"github.com/notaryproject/notation-go/trace"
+	"github.com/opencontainers/go-digest"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+const testRepository = "test/repo"
+
+// testRegistry is an in-memory registry serving a single repository.
+type testRegistry struct {
+	mu        sync.Mutex
+	blobs     map[digest.Digest][]byte
+	manifests map[digest.Digest][]byte
+
+	// referrersPages counts the pages of referrers served.
+	referrersPages int
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{
+		blobs:     make(map[digest.Digest][]byte),
+		manifests: make(map[digest.Digest][]byte),
+	}
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	base := "/v2/" + testRepository
+	referrersPrefix := "/oras/artifacts/v1/" + testRepository + "/manifests/"
+	switch path := req.URL.Path; {
+	case req.Method == http.MethodPost && path == base+"/blobs/uploads/":
+		w.Header().Set("Location", base+"/blobs/uploads/upload")
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPut && path == base+"/blobs/uploads/upload":
+		data, _ := io.ReadAll(req.Body)
+		r.blobs[digest.Digest(req.URL.Query().Get("digest"))] = data
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodPut && strings.HasPrefix(path, base+"/manifests/"):
+		data, _ := io.ReadAll(req.Body)
+		r.manifests[digest.FromBytes(data)] = data
+		w.WriteHeader(http.StatusCreated)
+	case req.Method == http.MethodDelete && strings.HasPrefix(path, base+"/manifests/"):
+		dgst := digest.Digest(strings.TrimPrefix(path, base+"/manifests/"))
+		if _, ok := r.manifests[dgst]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(r.manifests, dgst)
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(path, base+"/manifests/"):
+		data, ok := r.manifests[digest.Digest(strings.TrimPrefix(path, base+"/manifests/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", artifactspec.MediaTypeArtifactManifest)
+		r.writeContent(w, req, data)
+	case strings.HasPrefix(path, base+"/blobs/"):
+		data, ok := r.blobs[digest.Digest(strings.TrimPrefix(path, base+"/blobs/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		r.writeContent(w, req, data)
+	case req.Method == http.MethodGet && strings.HasPrefix(path, referrersPrefix):
+		subject := digest.Digest(strings.TrimSuffix(strings.TrimPrefix(path, referrersPrefix), "/referrers"))
+		references := []artifactspec.Descriptor{}
+		for dgst, data := range r.manifests {
+			var manifest artifactspec.Manifest
+			if err := json.Unmarshal(data, &manifest); err != nil || manifest.Subject.Digest != subject {
+				continue
+			}
+			references = append(references, artifactspec.Descriptor{
+				MediaType:    manifest.MediaType,
+				ArtifactType: manifest.ArtifactType,
+				Digest:       dgst,
+				Size:         int64(len(data)),
+			})
+		}
+		sort.Slice(references, func(i, j int) bool {
+			return references[i].Digest < references[j].Digest
+		})
+		// page by the last digest of the previous page
+		if last := req.URL.Query().Get("last"); last != "" {
+			i := sort.Search(len(references), func(i int) bool {
+				return references[i].Digest > digest.Digest(last)
+			})
+			references = references[i:]
+		}
+		if n, err := strconv.Atoi(req.URL.Query().Get("n")); err == nil && n < len(references) {
+			references = references[:n]
+			w.Header().Set("Link", fmt.Sprintf("<%s?last=%s>; rel=\"next\"", path, references[n-1].Digest))
+		}
+		r.referrersPages++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"references": references})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *testRegistry) writeContent(w http.ResponseWriter, req *http.Request, data []byte) {
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	if req.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// newTestRepositoryClient starts a test registry and returns a client of its repository.
+func newTestRepositoryClient(t *testing.T) (*RepositoryClient, *testRegistry) {
+	reg := newTestRegistry()
+	server := httptest.NewServer(reg)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRepositoryClient(server.Client(), registry.Reference{
+		Registry:   u.Host,
+		Repository: testRepository,
+	}, true), reg
+}
+
+func TestPushSignature(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	annotations := map[string]string{
+		"buildId":     "42",
+		"pipelineUrl": "https://ci.example.com/pipelines/42",
+	}
+
+	sigDesc, manifestDesc, err := client.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+	if _, _, err := client.PushSignature(ctx, []byte("other signature"), subject, PushSignatureOptions{}); err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+
+	var manifests []SignatureManifest
+	if err := client.ListSignatures(ctx, subject, func(page []SignatureManifest) error {
+		manifests = append(manifests, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("ListSignatures() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("ListSignatures() returned %d manifests, want 2", len(manifests))
+	}
+	var found bool
+	for _, manifest := range manifests {
+		if manifest.Descriptor.Digest != manifestDesc.Digest {
+			if manifest.Annotations != nil {
+				t.Errorf("ListSignatures() Annotations = %v, want nil", manifest.Annotations)
+			}
+			continue
+		}
+		found = true
+		if !reflect.DeepEqual(manifest.Annotations, annotations) {
+			t.Errorf("ListSignatures() Annotations = %v, want %v", manifest.Annotations, annotations)
+		}
+		if !reflect.DeepEqual(manifest.Blobs, []notation.Descriptor{sigDesc}) {
+			t.Errorf("ListSignatures() Blobs = %v, want [%v]", manifest.Blobs, sigDesc)
+		}
+	}
+	if !found {
+		t.Errorf("ListSignatures() = %v, want manifest %v", manifests, manifestDesc.Digest)
+	}
+
+	digests, err := client.Lookup(ctx, subject.Digest)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if len(digests) != 2 {
+		t.Errorf("Lookup() = %v, want 2 signatures", digests)
+	}
+	sig, err := client.Get(ctx, sigDesc.Digest)
+	if err != nil || string(sig) != "signature" {
+		t.Errorf("Get() = %q, %v, want signature", sig, err)
+	}
+}
+
+func TestListSignatures_Paging(t *testing.T) {
+	ctx := context.Background()
+	client, reg := newTestRepositoryClient(t)
+	client.ReferrerListPageSize = 2
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	for i := 0; i < 5; i++ {
+		if _, _, err := client.PushSignature(ctx, []byte(fmt.Sprintf("signature %d", i)), subject, PushSignatureOptions{}); err != nil {
+			t.Fatalf("PushSignature() error = %v", err)
+		}
+	}
+
+	var pages, count int
+	if err := client.ListSignatures(ctx, subject, func(manifests []SignatureManifest) error {
+		pages++
+		count += len(manifests)
+		return nil
+	}); err != nil {
+		t.Fatalf("ListSignatures() error = %v", err)
+	}
+	if pages != 3 || count != 5 {
+		t.Errorf("ListSignatures() listed %d manifests in %d pages, want 5 in 3 pages", count, pages)
+	}
+
+	// stop after the first page
+	reg.referrersPages = 0
+	errStop := errors.New("stop")
+	if err := client.ListSignatures(ctx, subject, func(manifests []SignatureManifest) error {
+		return errStop
+	}); err != errStop {
+		t.Fatalf("ListSignatures() error = %v, want %v", err, errStop)
+	}
+	if reg.referrersPages != 1 {
+		t.Errorf("ListSignatures() fetched %d pages, want 1", reg.referrersPages)
+	}
+}
+
+func TestLookupDescriptors_Limit(t *testing.T) {
+	ctx := context.Background()
+	client, reg := newTestRepositoryClient(t)
+	client.ReferrerListPageSize = 2
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	for i := 0; i < 5; i++ {
+		if _, _, err := client.PushSignature(ctx, []byte(fmt.Sprintf("signature %d", i)), subject, PushSignatureOptions{}); err != nil {
+			t.Fatalf("PushSignature() error = %v", err)
+		}
+	}
+
+	descs, err := client.LookupDescriptors(ctx, subject.Digest, 5)
+	if err != nil {
+		t.Fatalf("LookupDescriptors() error = %v", err)
+	}
+	if len(descs) != 5 || descs[0].Size != int64(len("signature 0")) {
+		t.Errorf("LookupDescriptors() = %v, want 5 signatures with their sizes", descs)
+	}
+
+	// stop after the page exceeding the limit
+	reg.referrersPages = 0
+	if _, err := client.LookupDescriptors(ctx, subject.Digest, 1); !errors.Is(err, notation.ErrLimitExceeded) {
+		t.Fatalf("LookupDescriptors() error = %v, want %v", err, notation.ErrLimitExceeded)
+	}
+	if reg.referrersPages != 1 {
+		t.Errorf("LookupDescriptors() fetched %d pages, want 1", reg.referrersPages)
+	}
+}
+
+// recordingTracer records the names of the spans started.
+type recordingTracer struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...trace.Attribute) (context.Context, trace.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.names = append(t.names, name)
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...trace.Attribute) {}
+func (noopSpan) RecordError(err error)                  {}
+func (noopSpan) End()                                   {}
+
+func TestRepositoryClient_Tracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	ctx := trace.WithTracer(context.Background(), tracer)
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	if _, _, err := client.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{}); err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+	if _, err := client.Lookup(ctx, subject.Digest); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	want := []string{"notation.registry.Put", "notation.registry.Link", "notation.registry.ListSignatures"}
+	if !reflect.DeepEqual(tracer.names, want) {
+		t.Errorf("spans = %v, want %v", tracer.names, want)
+	}
+}
+
+type recordingRecorder struct {
+	metrics.Recorder
+	operations []string
+}
+
+func (r *recordingRecorder) RecordRegistryOperation(operation string, latency time.Duration, err error) {
+	r.operations = append(r.operations, operation)
+}
+
+func TestRepositoryClient_Metrics(t *testing.T) {
+	recorder := &recordingRecorder{Recorder: metrics.Discard}
+	ctx := metrics.WithRecorder(context.Background(), recorder)
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	if _, _, err := client.PushSignature(ctx, []byte("signature"), subject, PushSignatureOptions{}); err != nil {
+		t.Fatalf("PushSignature() error = %v", err)
+	}
+	want := []string{"Put", "Link"}
+	if !reflect.DeepEqual(recorder.operations, want) {
+		t.Errorf("recorded operations = %v, want %v", recorder.operations, want)
+	}
+}
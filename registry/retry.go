@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures the retries of registry requests failing
+// with transient errors.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a request.
+	// Requests are not retried if zero.
+	MaxRetries int
+
+	// MinWait is the wait before the first retry.
+	// The wait doubles after each retry, with a random jitter.
+	MinWait time.Duration
+
+	// MaxWait caps the wait between retries. Requests are not retried
+	// if the Retry-After header of the response exceeds MaxWait.
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of the clients created with NewAuthClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	MinWait:    200 * time.Millisecond,
+	MaxWait:    5 * time.Second,
+}
+
+// NewRetryTransport returns a transport retrying requests on network errors,
+// 429 and 5xx responses with exponential backoff, honoring the Retry-After
+// header of the responses.
+// http.DefaultTransport is used if base is nil.
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:   base,
+		policy: policy,
+	}
+}
+
+// retryTransport retries requests according to a RetryPolicy.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+// RoundTrip sends the request, and retries it while it fails with a transient error.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.policy.MaxRetries || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+		wait, ok := t.wait(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		// rewind the request body before retrying
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// wait returns the wait before the retry following attempt,
+// and reports whether the request can be retried.
+func (t *retryTransport) wait(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if t.policy.MaxWait > 0 && retryAfter > t.policy.MaxWait {
+				return 0, false
+			}
+			return retryAfter, true
+		}
+	}
+	wait := t.policy.MinWait << attempt
+	if wait <= 0 || (t.policy.MaxWait > 0 && wait > t.policy.MaxWait) {
+		wait = t.policy.MaxWait
+	}
+	// pick a random wait in [wait/2, wait) to spread the retries of
+	// concurrent clients.
+	if half := int64(wait / 2); half > 0 {
+		wait = time.Duration(half + rand.Int63n(half))
+	}
+	return wait, true
+}
+
+// retryable reports whether a request failed with a transient error.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses the Retry-After header, either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
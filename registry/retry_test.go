@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinWait:    time.Millisecond,
+	MaxWait:    10 * time.Millisecond,
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		want       int
+		wantCalls  int32
+	}{
+		{
+			name:      "success",
+			statuses:  []int{http.StatusOK},
+			want:      http.StatusOK,
+			wantCalls: 1,
+		},
+		{
+			name:      "transient errors",
+			statuses:  []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			want:      http.StatusOK,
+			wantCalls: 3,
+		},
+		{
+			name:      "retries exhausted",
+			statuses:  []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			want:      http.StatusBadGateway,
+			wantCalls: 4,
+		},
+		{
+			name:      "not retryable",
+			statuses:  []int{http.StatusNotFound, http.StatusOK},
+			want:      http.StatusNotFound,
+			wantCalls: 1,
+		},
+		{
+			name:       "retry after",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "0",
+			want:       http.StatusOK,
+			wantCalls:  2,
+		},
+		{
+			name:       "retry after exceeds max wait",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "60",
+			want:       http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+					t.Errorf("request %d body = %q, want payload", n, body)
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: NewRetryTransport(nil, testRetryPolicy)}
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+			if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("Post() status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Post() sent %d requests, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport := NewRetryTransport(nil, RetryPolicy{MaxRetries: 3, MinWait: time.Minute, MaxWait: time.Minute})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := transport.RoundTrip(req); err != context.Canceled {
+		t.Fatalf("RoundTrip() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "", wantOk: false},
+		{value: "3", want: 3 * time.Second, wantOk: true},
+		{value: "-1", wantOk: false},
+		{value: "soon", wantOk: false},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOk: true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/trace"
+	"github.com/opencontainers/go-digest"
+)
+
+// Media types of SBOMs, which are also the artifact types of their manifests.
+const (
+	MediaTypeSPDX      = "application/spdx+json"
+	MediaTypeCycloneDX = "application/vnd.cyclonedx+json"
+)
+
+// PushSBOMOptions contains parameters for RepositoryClient.PushSBOM.
+type PushSBOMOptions struct {
+	notation.SignOptions
+
+	// Annotations are set on the SBOM manifest. They are covered by the
+	// signature of the SBOM.
+	Annotations map[string]string
+}
+
+// PushSBOM attaches the SBOM of media type mediaType, e.g. MediaTypeSPDX or
+// MediaTypeCycloneDX, to the manifest described by subject, and signs it
+// with signer in one operation.
+//
+// The SBOM is linked to the manifest with an SBOM manifest of artifact type
+// mediaType, which is signed and linked to a signature manifest, so that
+// the SBOM verifies like any artifact, with the SBOM manifest as signed
+// descriptor.
+// The SBOM manifest is signed before anything is pushed, so nothing is
+// attached if signing fails, and the SBOM manifest is deleted if the
+// signature fails to be pushed.
+//
+// It returns the descriptors of the SBOM manifest and of its signature
+// manifest.
+func (c *RepositoryClient) PushSBOM(ctx context.Context, sbom []byte, mediaType string, subject notation.Descriptor, signer notation.Signer, opts PushSBOMOptions) (_ notation.Descriptor, _ notation.Descriptor, err error) {
+	if signer == nil {
+		return notation.Descriptor{}, notation.Descriptor{}, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil signer")
+	}
+	if len(sbom) == 0 || mediaType == "" {
+		return notation.Descriptor{}, notation.Descriptor{}, notation.Errorf(notation.ErrorCodeInvalidArgument, "empty SBOM or media type")
+	}
+	if err := subject.Validate(); err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	ctx, end := c.startOperation(ctx, "PushSBOM", trace.Attribute{Key: "notation.artifact.digest", Value: subject.Digest.String()})
+	defer func() { end(err) }()
+	ctx = c.withPushScope(ctx)
+
+	sbomDesc := notation.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(sbom),
+		Size:      int64(len(sbom)),
+	}
+	manifestJSON, ociDesc, err := newArtifactManifest(mediaType, subject, sbomDesc, opts.Annotations)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, err
+	}
+	manifestDesc := notationDescriptorFromOCI(ociDesc)
+	sig, err := signer.Sign(ctx, manifestDesc, opts.SignOptions)
+	if err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to sign SBOM manifest %s: %w", manifestDesc.Digest, err)
+	}
+	if opts.DryRun {
+		return notation.Descriptor{}, notation.Descriptor{}, nil
+	}
+
+	logger := log.GetLogger(ctx)
+	logger.Debugf("attaching SBOM %s to manifest %s with SBOM manifest %s", sbomDesc.Digest, subject.Digest, manifestDesc.Digest)
+	if _, err := c.put(ctx, mediaType, sbom); err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to upload SBOM: %w", err)
+	}
+	if err := c.Repository.Manifests().Push(ctx, ociDesc, bytes.NewReader(manifestJSON)); err != nil {
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to push SBOM manifest: %w", err)
+	}
+	_, sigManifestDesc, err := c.PushSignature(ctx, sig, manifestDesc, PushSignatureOptions{})
+	if err != nil {
+		if deleteErr := c.DeleteSignature(ctx, manifestDesc); deleteErr != nil {
+			logger.Warnf("failed to delete SBOM manifest %s after a signature push failure: %v", manifestDesc.Digest, deleteErr)
+		}
+		return notation.Descriptor{}, notation.Descriptor{}, fmt.Errorf("failed to push signature of SBOM manifest %s: %w", manifestDesc.Digest, err)
+	}
+	return manifestDesc, sigManifestDesc, nil
+}
@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+	artifactspec "github.com/oras-project/artifacts-spec/specs-go/v1"
+)
+
+// failingSigner fails to sign.
+type failingSigner struct{}
+
+func (failingSigner) Sign(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	return nil, errors.New("signing failed")
+}
+
+func TestRepositoryClient_PushSBOM(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	sbom := []byte(`{"spdxVersion":"SPDX-2.3"}`)
+	sbomManifest, sigManifest, err := client.PushSBOM(ctx, sbom, MediaTypeSPDX, subject, digestSigner{}, PushSBOMOptions{
+		Annotations: map[string]string{"generator": "syft"},
+	})
+	if err != nil {
+		t.Fatalf("PushSBOM() error = %v", err)
+	}
+	if sbomManifest.MediaType != artifactspec.MediaTypeArtifactManifest || sigManifest.MediaType != artifactspec.MediaTypeArtifactManifest {
+		t.Errorf("PushSBOM() media types = %q, %q, want %q", sbomManifest.MediaType, sigManifest.MediaType, artifactspec.MediaTypeArtifactManifest)
+	}
+	if blob, err := client.Get(ctx, digest.FromBytes(sbom)); err != nil || string(blob) != string(sbom) {
+		t.Errorf("Get() = %q, %v, want the SBOM", blob, err)
+	}
+
+	var manifests []SignatureManifest
+	if err := client.listReferrers(ctx, subject, MediaTypeSPDX, func(page []SignatureManifest) error {
+		manifests = append(manifests, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("listReferrers() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Descriptor.Digest != sbomManifest.Digest || manifests[0].Annotations["generator"] != "syft" {
+		t.Errorf("listReferrers() = %+v, want SBOM manifest %s", manifests, sbomManifest.Digest)
+	}
+
+	// the SBOM manifest is signed, not the subject
+	digests, err := client.Lookup(ctx, sbomManifest.Digest)
+	if err != nil || len(digests) != 1 {
+		t.Fatalf("Lookup() = %v, %v, want the signature of the SBOM manifest", digests, err)
+	}
+	if sig, err := client.Get(ctx, digests[0]); err != nil || string(sig) != string(sbomManifest.Digest) {
+		t.Errorf("Get() = %q, %v, want the signature of %s", sig, err, sbomManifest.Digest)
+	}
+	if digests, err := client.Lookup(ctx, subject.Digest); err != nil || len(digests) != 0 {
+		t.Errorf("Lookup() = %v, %v, want no signature of the subject", digests, err)
+	}
+}
+
+func TestRepositoryClient_PushSBOM_SignFailure(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestRepositoryClient(t)
+	subject := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	sbom := []byte(`{"bomFormat":"CycloneDX"}`)
+	if _, _, err := client.PushSBOM(ctx, sbom, MediaTypeCycloneDX, subject, failingSigner{}, PushSBOMOptions{}); err == nil {
+		t.Fatal("PushSBOM() error = nil, want error")
+	}
+	if _, err := client.Get(ctx, digest.FromBytes(sbom)); err == nil {
+		t.Error("Get() error = nil, want the SBOM not pushed")
+	}
+	if _, _, err := client.PushSBOM(ctx, sbom, MediaTypeCycloneDX, subject, nil, PushSBOMOptions{}); !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("PushSBOM() error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,132 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ResignOptions contains parameters for Resign.
+type ResignOptions struct {
+	SignOptions
+
+	// Verifier verifies the existing signatures of the artifact, e.g. a
+	// verifier trusting the key being rotated out.
+	Verifier Verifier
+
+	// VerifyOptions contains the parameters of the verification of the
+	// existing signatures.
+	VerifyOptions VerifyOptions
+
+	// Resolve resolves an artifact reference to the descriptor of the
+	// artifact, e.g. registry.RepositoryClient.GetManifestDescriptor.
+	Resolve func(ctx context.Context, reference string) (Descriptor, error)
+
+	// ListSignatures returns the descriptors of the signatures of the
+	// artifact.
+	ListSignatures func(ctx context.Context, desc Descriptor) ([]Descriptor, error)
+
+	// FetchSignature returns the signature described by sigDesc, one of the
+	// descriptors returned by ListSignatures.
+	FetchSignature func(ctx context.Context, sigDesc Descriptor) ([]byte, error)
+
+	// Store stores the new signature of the artifact if present,
+	// e.g. by pushing it to the registry and linking it to the artifact.
+	Store func(ctx context.Context, reference string, desc Descriptor, signature []byte) error
+
+	// DeleteSignature deletes the replaced signature described by sigDesc
+	// if present, once the new signature is stored. The replaced signature
+	// is kept if nil.
+	DeleteSignature func(ctx context.Context, sigDesc Descriptor) error
+}
+
+// ResignResult is the result of Resign.
+type ResignResult struct {
+	// Descriptor is the descriptor signed, i.e. the descriptor of the
+	// artifact with the annotations signed by the replaced signature.
+	Descriptor Descriptor
+
+	// Signature is the new signature.
+	Signature []byte
+
+	// Replaced is the descriptor of the replaced signature.
+	Replaced Descriptor
+}
+
+// Resign replaces a signature of the artifact referenced by ref with a
+// signature of signer, e.g. to rotate signing keys.
+//
+// The signatures of the artifact are verified in the order returned by
+// opts.ListSignatures, and the payload of the first valid one, i.e. the
+// signed descriptor including its annotations, is signed again with
+// opts.SignOptions. The new signature is stored before the replaced one is
+// deleted, so that the artifact stays signed if Resign fails half way.
+func Resign(ctx context.Context, signer Signer, ref string, opts ResignOptions) (*ResignResult, error) {
+	if signer == nil {
+		return nil, errors.New("nil signer")
+	}
+	if opts.Verifier == nil {
+		return nil, errors.New("nil verifier")
+	}
+	if opts.Resolve == nil || opts.ListSignatures == nil || opts.FetchSignature == nil {
+		return nil, errors.New("missing artifact resolver or signature fetcher")
+	}
+	desc, err := opts.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve artifact: %w", err)
+	}
+	sigDescs, err := opts.ListSignatures(ctx, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signatures: %w", err)
+	}
+	if len(sigDescs) == 0 {
+		return nil, fmt.Errorf("artifact %s has no signatures", desc.Digest)
+	}
+
+	// find the first valid signature of the artifact
+	var result *ResignResult
+	var verifyErr error
+	for _, sigDesc := range sigDescs {
+		sig, err := opts.FetchSignature(ctx, sigDesc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch signature %s: %w", sigDesc.Digest, err)
+		}
+		outcome, err := opts.Verifier.Verify(ctx, sig, opts.VerifyOptions)
+		if err != nil {
+			verifyErr = fmt.Errorf("signature %s: %w", sigDesc.Digest, err)
+			continue
+		}
+		if outcome.Descriptor.Digest != desc.Digest {
+			verifyErr = fmt.Errorf("signature %s: signed artifact %s does not match %s", sigDesc.Digest, outcome.Descriptor.Digest, desc.Digest)
+			continue
+		}
+		result = &ResignResult{
+			Descriptor: outcome.Descriptor,
+			Replaced:   sigDesc,
+		}
+		break
+	}
+	if result == nil {
+		return nil, fmt.Errorf("artifact %s has no valid signature to re-sign: %w", desc.Digest, verifyErr)
+	}
+
+	// sign the payload of the valid signature
+	result.Signature, err = signer.Sign(ctx, result.Descriptor, opts.SignOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign artifact: %w", err)
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	if opts.Store != nil {
+		if err := opts.Store(ctx, ref, result.Descriptor, result.Signature); err != nil {
+			return result, fmt.Errorf("failed to store signature: %w", err)
+		}
+	}
+	if opts.DeleteSignature != nil {
+		if err := opts.DeleteSignature(ctx, result.Replaced); err != nil {
+			return result, fmt.Errorf("failed to delete replaced signature %s: %w", result.Replaced.Digest, err)
+		}
+	}
+	return result, nil
+}
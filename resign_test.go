@@ -0,0 +1,111 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// prefixVerifier accepts the signatures prefixed by "valid:", followed by the
+// digest signed.
+type prefixVerifier struct{}
+
+func (prefixVerifier) Verify(ctx context.Context, signature []byte, opts VerifyOptions) (*VerificationOutcome, error) {
+	outcome := &VerificationOutcome{}
+	signed := strings.TrimPrefix(string(signature), "valid:")
+	if signed == string(signature) {
+		return outcome, errors.New("invalid signature")
+	}
+	outcome.Descriptor = Descriptor{
+		Digest:      digest.Digest(signed),
+		Annotations: map[string]string{"signed": "true"},
+	}
+	return outcome, nil
+}
+
+func TestResign(t *testing.T) {
+	artifact := digest.FromString("artifact")
+	tests := []struct {
+		name         string
+		signatures   map[digest.Digest]string
+		wantReplaced digest.Digest
+		wantErr      bool
+	}{
+		{
+			name: "first valid signature replaced",
+			signatures: map[digest.Digest]string{
+				"sha256:1": "invalid",
+				"sha256:2": "valid:" + string(digest.FromString("other")),
+				"sha256:3": "valid:" + string(artifact),
+				"sha256:4": "valid:" + string(artifact),
+			},
+			wantReplaced: "sha256:3",
+		},
+		{
+			name:       "no valid signature",
+			signatures: map[digest.Digest]string{"sha256:1": "invalid"},
+			wantErr:    true,
+		},
+		{
+			name:       "no signature",
+			signatures: map[digest.Digest]string{},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored []byte
+			var deleted []digest.Digest
+			result, err := Resign(context.Background(), &mockSigner{}, "artifact", ResignOptions{
+				Verifier: prefixVerifier{},
+				Resolve: func(ctx context.Context, ref string) (Descriptor, error) {
+					return Descriptor{Digest: digest.FromString(ref)}, nil
+				},
+				ListSignatures: func(ctx context.Context, desc Descriptor) ([]Descriptor, error) {
+					var descs []Descriptor
+					for _, d := range []digest.Digest{"sha256:1", "sha256:2", "sha256:3", "sha256:4"} {
+						if _, ok := tt.signatures[d]; ok {
+							descs = append(descs, Descriptor{Digest: d})
+						}
+					}
+					return descs, nil
+				},
+				FetchSignature: func(ctx context.Context, sigDesc Descriptor) ([]byte, error) {
+					return []byte(tt.signatures[sigDesc.Digest]), nil
+				},
+				Store: func(ctx context.Context, ref string, desc Descriptor, sig []byte) error {
+					stored = sig
+					return nil
+				},
+				DeleteSignature: func(ctx context.Context, sigDesc Descriptor) error {
+					deleted = append(deleted, sigDesc.Digest)
+					return nil
+				},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if stored != nil || deleted != nil {
+					t.Errorf("Resign() stored %q and deleted %v, want neither", stored, deleted)
+				}
+				return
+			}
+			if result.Replaced.Digest != tt.wantReplaced {
+				t.Errorf("Resign() Replaced = %v, want %v", result.Replaced.Digest, tt.wantReplaced)
+			}
+			if result.Descriptor.Annotations["signed"] != "true" {
+				t.Errorf("Resign() Descriptor = %v, want the signed descriptor", result.Descriptor)
+			}
+			if string(stored) != string(artifact) || string(result.Signature) != string(stored) {
+				t.Errorf("Resign() stored %q, want the signature of %v", stored, artifact)
+			}
+			if len(deleted) != 1 || deleted[0] != tt.wantReplaced {
+				t.Errorf("Resign() deleted %v, want [%v]", deleted, tt.wantReplaced)
+			}
+		})
+	}
+}
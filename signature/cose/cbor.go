@@ -0,0 +1,138 @@
+package cose
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// CBOR major types, as defined in RFC 8949 section 3.1.
+const (
+	cborUnsignedInt byte = 0
+	cborNegativeInt byte = 1
+	cborByteString  byte = 2
+	cborTextString  byte = 3
+	cborArray       byte = 4
+	cborMapType     byte = 5
+	cborTagType     byte = 6
+)
+
+// cborTag is a tagged CBOR data item.
+type cborTag struct {
+	number  uint64
+	content interface{}
+}
+
+// cborMap is a CBOR map. Its keys are encoded in the core deterministic
+// order of RFC 8949 section 4.2.1.
+type cborMap map[interface{}]interface{}
+
+// marshalCBOR returns the deterministic CBOR encoding of v, which is made of
+// integers, booleans, strings, byte strings, arrays, cborMap and cborTag
+// values.
+func marshalCBOR(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCBOR(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeCBOR appends the CBOR encoding of v to buf.
+func encodeCBOR(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case int:
+		encodeCBORInt(buf, int64(v))
+	case int64:
+		encodeCBORInt(buf, v)
+	case uint64:
+		encodeCBORHead(buf, cborUnsignedInt, v)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case string:
+		encodeCBORHead(buf, cborTextString, uint64(len(v)))
+		buf.WriteString(v)
+	case []byte:
+		encodeCBORHead(buf, cborByteString, uint64(len(v)))
+		buf.Write(v)
+	case []interface{}:
+		encodeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := encodeCBOR(buf, item); err != nil {
+				return err
+			}
+		}
+	case cborMap:
+		type entry struct {
+			key, value []byte
+		}
+		entries := make([]entry, 0, len(v))
+		for key, value := range v {
+			encodedKey, err := marshalCBOR(key)
+			if err != nil {
+				return err
+			}
+			encodedValue, err := marshalCBOR(value)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{key: encodedKey, value: encodedValue})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		encodeCBORHead(buf, cborMapType, uint64(len(entries)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			buf.Write(e.value)
+		}
+	case cborTag:
+		encodeCBORHead(buf, cborTagType, v.number)
+		return encodeCBOR(buf, v.content)
+	default:
+		return fmt.Errorf("unsupported CBOR value of type %T", v)
+	}
+	return nil
+}
+
+// encodeCBORInt appends the CBOR encoding of the integer n to buf.
+func encodeCBORInt(buf *bytes.Buffer, n int64) {
+	if n >= 0 {
+		encodeCBORHead(buf, cborUnsignedInt, uint64(n))
+		return
+	}
+	encodeCBORHead(buf, cborNegativeInt, uint64(-1-n))
+}
+
+// encodeCBORHead appends the shortest head of a data item of major type
+// major and argument n to buf.
+func encodeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= 0xff:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(major | 25)
+		var b [2]byte
+		binary.BigEndian.PutUint16(b[:], uint16(n))
+		buf.Write(b[:])
+	case n <= 0xffffffff:
+		buf.WriteByte(major | 26)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(n))
+		buf.Write(b[:])
+	default:
+		buf.WriteByte(major | 27)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], n)
+		buf.Write(b[:])
+	}
+}
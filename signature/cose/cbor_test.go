@@ -0,0 +1,164 @@
+package cose
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMarshalCBOR(t *testing.T) {
+	// test vectors of RFC 8949 appendix A
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{value: 0, want: "00"},
+		{value: 23, want: "17"},
+		{value: 24, want: "1818"},
+		{value: 100, want: "1864"},
+		{value: 1000, want: "1903e8"},
+		{value: 1000000, want: "1a000f4240"},
+		{value: int64(1000000000000), want: "1b000000e8d4a51000"},
+		{value: uint64(18446744073709551615), want: "1bffffffffffffffff"},
+		{value: -1, want: "20"},
+		{value: -100, want: "3863"},
+		{value: -1000, want: "3903e7"},
+		{value: false, want: "f4"},
+		{value: true, want: "f5"},
+		{value: "", want: "60"},
+		{value: "IETF", want: "6449455446"},
+		{value: []byte{}, want: "40"},
+		{value: []byte{1, 2, 3, 4}, want: "4401020304"},
+		{value: []interface{}{1, []interface{}{2, 3}, []interface{}{4, 5}}, want: "8301820203820405"},
+		{value: cborMap{1: 2, 3: 4}, want: "a201020304"},
+		{value: cborMap{"a": 1, "b": []interface{}{2, 3}}, want: "a26161016162820203"},
+		{value: cborTag{number: 1, content: 1363896240}, want: "c11a514b67b0"},
+		// keys are sorted by their encoding
+		{value: cborMap{"b": 1, 10: 2, -1: 3}, want: "a30a022003616201"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := marshalCBOR(tt.value)
+			if err != nil {
+				t.Fatalf("marshalCBOR() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("marshalCBOR() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+	if _, err := marshalCBOR(1.5); err == nil {
+		t.Errorf("marshalCBOR() error = nil, want unsupported type error")
+	}
+}
+
+// unmarshalCBOR decodes the CBOR data items encoded by marshalCBOR, with
+// integers decoded as int64 and maps as cborMap.
+func unmarshalCBOR(data []byte) (interface{}, error) {
+	v, rest, err := decodeCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data")
+	}
+	return v, nil
+}
+
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.New("unexpected end of data")
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return nil, nil, errors.New("unexpected end of data")
+		}
+		for _, b := range data[:size] {
+			n = n<<8 | uint64(b)
+		}
+		data = data[size:]
+	default:
+		return nil, nil, fmt.Errorf("unsupported additional information %d", info)
+	}
+	switch major {
+	case cborUnsignedInt:
+		return int64(n), data, nil
+	case cborNegativeInt:
+		return -1 - int64(n), data, nil
+	case cborByteString, cborTextString:
+		if uint64(len(data)) < n {
+			return nil, nil, errors.New("unexpected end of data")
+		}
+		if major == cborTextString {
+			return string(data[:n]), data[n:], nil
+		}
+		return data[:n], data[n:], nil
+	case cborArray:
+		items := make([]interface{}, n)
+		for i := range items {
+			var err error
+			if items[i], data, err = decodeCBOR(data); err != nil {
+				return nil, nil, err
+			}
+		}
+		return items, data, nil
+	case cborMapType:
+		m := make(cborMap, n)
+		for i := uint64(0); i < n; i++ {
+			key, rest, err := decodeCBOR(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key], data, err = decodeCBOR(rest)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return m, data, nil
+	case cborTagType:
+		content, rest, err := decodeCBOR(data)
+		return cborTag{number: n, content: content}, rest, err
+	}
+	switch info {
+	case 20:
+		return false, data, nil
+	case 21:
+		return true, data, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported simple value %d", info)
+}
+
+func TestUnmarshalCBOR(t *testing.T) {
+	data, err := marshalCBOR(cborTag{number: 18, content: []interface{}{[]byte("p"), cborMap{1: -7, "k": true}, "s"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := unmarshalCBOR(data)
+	if err != nil {
+		t.Fatalf("unmarshalCBOR() error = %v", err)
+	}
+	got, err := marshalCBOR(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(got) != hex.EncodeToString(data) {
+		t.Errorf("round trip = %x, want %x", got, data)
+	}
+	if _, err := unmarshalCBOR(append(data, 0)); err == nil {
+		t.Errorf("unmarshalCBOR() error = nil, want trailing data error")
+	}
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], 1)
+	if _, err := unmarshalCBOR(append([]byte{0x5b}, b[:]...)); err == nil {
+		t.Errorf("unmarshalCBOR() error = nil, want end of data error")
+	}
+}
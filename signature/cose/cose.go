@@ -0,0 +1,192 @@
+// Package cose assembles notation signatures in COSE_Sign1 envelopes, as
+// defined in RFC 9052, from raw signatures of their signing input, so that
+// signers producing raw signatures support the COSE format.
+//
+// The signing and expiry times are protected headers, and the certificate
+// chain is the x5chain unprotected header defined in RFC 9360.
+package cose
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+)
+
+// MediaTypeEnvelope describes the media type of the COSE envelope.
+const MediaTypeEnvelope = notation.MediaTypeCOSEEnvelope
+
+// COSE header labels, as registered in the IANA COSE Header Parameters
+// registry.
+const (
+	headerAlgorithm   = 1
+	headerCritical    = 2
+	headerContentType = 3
+	headerX5Chain     = 33
+)
+
+// Notary header labels.
+const (
+	headerSigningScheme        = "io.cncf.notary.signingScheme"
+	headerSigningTime          = "io.cncf.notary.signingTime"
+	headerAuthenticSigningTime = "io.cncf.notary.authenticSigningTime"
+	headerExpiry               = "io.cncf.notary.expiry"
+	headerSigningAgent         = "io.cncf.notary.signingAgent"
+)
+
+// CBOR tags of COSE envelopes.
+const (
+	cborTagEpochTime = 1
+	cborTagSign1     = 18
+)
+
+// reservedHeaders lists the Notary headers managed by the signer, which
+// cannot be set as extended signed attributes.
+var reservedHeaders = []string{
+	headerSigningScheme,
+	headerSigningTime,
+	headerAuthenticSigningTime,
+	headerExpiry,
+	headerSigningAgent,
+}
+
+// Payload is the payload of COSE envelopes.
+type Payload struct {
+	// Subject is the descriptor of the signed artifact.
+	Subject notation.Descriptor `json:"subject"`
+}
+
+// Message is a COSE_Sign1 message to be signed.
+type Message struct {
+	protected    []byte
+	payload      []byte
+	signingInput []byte
+	signingAgent string
+}
+
+// NewMessage creates the message signing desc with the signature algorithm
+// alg at signingTime, with the signing scheme, expiry, payload content type,
+// extended signed attributes and signing agent of opts. desc is signed as
+// is, so that signed annotations and user metadata are expected to be
+// applied already, and the signing scheme of opts is expected to be set.
+// The extended signed attributes are limited to integer, boolean and string
+// values, and timestamping is not supported.
+func NewMessage(alg notation.SignatureAlgorithm, desc notation.Descriptor, signingTime time.Time, opts notation.SignOptions) (*Message, error) {
+	coseAlg := alg.COSE()
+	if coseAlg == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedSigningAlgorithm, "signing algorithm %q not supported", alg)
+	}
+	if opts.TSA != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "timestamping is not supported for COSE envelopes")
+	}
+	if !opts.Expiry.IsZero() && !opts.Expiry.After(signingTime) {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "expiry %v is not after signing time %v", opts.Expiry, signingTime)
+	}
+	protected, err := protectedHeaders(coseAlg, signingTime, opts)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(Payload{Subject: desc})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	// Sig_structure of RFC 9052 section 4.4, without external data.
+	signingInput, err := marshalCBOR([]interface{}{"Signature1", protected, []byte{}, payload})
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{
+		protected:    protected,
+		payload:      payload,
+		signingInput: signingInput,
+	}
+	if !opts.Deterministic {
+		m.signingAgent = opts.SigningAgent
+	}
+	return m, nil
+}
+
+// SigningInput returns the Sig_structure of the message, which is signed by
+// the signature of the envelope.
+func (m *Message) SigningInput() []byte {
+	return m.signingInput
+}
+
+// Envelope returns the COSE_Sign1 envelope of the message signed with sig,
+// the raw signature of the signing input by the key of the first
+// certificate of certChain. ECDSA signatures are in the fixed-size R||S
+// form.
+func (m *Message) Envelope(sig []byte, certChain []*x509.Certificate) ([]byte, error) {
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing signer certificate chain")
+	}
+	unprotected := cborMap{}
+	if len(certChain) == 1 {
+		unprotected[headerX5Chain] = certChain[0].Raw
+	} else {
+		chain := make([]interface{}, len(certChain))
+		for i, cert := range certChain {
+			chain[i] = cert.Raw
+		}
+		unprotected[headerX5Chain] = chain
+	}
+	if m.signingAgent != "" {
+		unprotected[headerSigningAgent] = m.signingAgent
+	}
+	return marshalCBOR(cborTag{
+		number:  cborTagSign1,
+		content: []interface{}{m.protected, unprotected, m.payload, sig},
+	})
+}
+
+// protectedHeaders returns the encoded protected headers of a message signed
+// with the COSE algorithm alg at signingTime with opts.
+func protectedHeaders(alg int, signingTime time.Time, opts notation.SignOptions) ([]byte, error) {
+	cty := opts.PayloadContentType
+	if cty == "" {
+		cty = notation.MediaTypePayload
+	}
+	headers := cborMap{
+		headerAlgorithm:     alg,
+		headerContentType:   cty,
+		headerSigningScheme: string(opts.SigningScheme),
+	}
+	crit := []interface{}{headerSigningScheme}
+	// The signing authority attests the signing time by signing it.
+	if opts.SigningScheme == notation.SigningSchemeX509SigningAuthority {
+		headers[headerAuthenticSigningTime] = cborTag{number: cborTagEpochTime, content: signingTime.Unix()}
+		crit = append(crit, headerAuthenticSigningTime)
+	} else {
+		headers[headerSigningTime] = cborTag{number: cborTagEpochTime, content: signingTime.Unix()}
+	}
+	if !opts.Expiry.IsZero() {
+		headers[headerExpiry] = cborTag{number: cborTagEpochTime, content: opts.Expiry.Unix()}
+		crit = append(crit, headerExpiry)
+	}
+	for _, attr := range opts.ExtendedSignedAttributes {
+		if isReserved(attr.Key) {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "extended signed attribute %q is reserved", attr.Key)
+		}
+		if _, err := marshalCBOR(attr.Value); err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "extended signed attribute %q: %w", attr.Key, err)
+		}
+		headers[attr.Key] = attr.Value
+		if attr.Critical {
+			crit = append(crit, attr.Key)
+		}
+	}
+	headers[headerCritical] = crit
+	return marshalCBOR(headers)
+}
+
+// isReserved reports whether key is a reserved Notary header.
+func isReserved(key string) bool {
+	for _, reserved := range reservedHeaders {
+		if key == reserved {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package cose
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+var testDescriptor = notation.Descriptor{
+	MediaType: "application/vnd.oci.image.manifest.v1+json",
+	Digest:    digest.FromString("net-monitor"),
+	Size:      11,
+}
+
+func newTestCertificate(t *testing.T, public ed25519.PublicKey, key ed25519.PrivateKey, name string) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, public, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestMessage(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, public, key, "signer")
+	signingTime := time.Unix(1660000000, 0)
+	expiry := signingTime.Add(time.Hour)
+	msg, err := NewMessage(notation.EDDSA_ED25519, testDescriptor, signingTime, notation.SignOptions{
+		SigningScheme:            notation.SigningSchemeX509SigningAuthority,
+		Expiry:                   expiry,
+		ExtendedSignedAttributes: []notation.SignedAttribute{{Key: "io.acme.team", Value: "net-monitor", Critical: true}},
+		SigningAgent:             "notation/test",
+	})
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	env, err := msg.Envelope(ed25519.Sign(key, msg.SigningInput()), []*x509.Certificate{cert, cert})
+	if err != nil {
+		t.Fatalf("Envelope() error = %v", err)
+	}
+
+	v, err := unmarshalCBOR(env)
+	if err != nil {
+		t.Fatalf("unmarshalCBOR() error = %v", err)
+	}
+	tag, ok := v.(cborTag)
+	if !ok || tag.number != cborTagSign1 {
+		t.Fatalf("envelope = %v, want a COSE_Sign1 tag", v)
+	}
+	items, ok := tag.content.([]interface{})
+	if !ok || len(items) != 4 {
+		t.Fatalf("COSE_Sign1 = %v, want 4 items", tag.content)
+	}
+	protected, unprotected, payload, sig := items[0].([]byte), items[1].(cborMap), items[2].([]byte), items[3].([]byte)
+
+	// the signature signs the Sig_structure of the envelope
+	toBeSigned, err := marshalCBOR([]interface{}{"Signature1", protected, []byte{}, payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(toBeSigned) != string(msg.SigningInput()) {
+		t.Errorf("SigningInput() = %x, want %x", msg.SigningInput(), toBeSigned)
+	}
+	if !ed25519.Verify(public, toBeSigned, sig) {
+		t.Errorf("signature is not verified by the signing key")
+	}
+
+	headers, err := unmarshalCBOR(protected)
+	if err != nil {
+		t.Fatalf("unmarshalCBOR() error = %v", err)
+	}
+	want := cborMap{
+		int64(headerAlgorithm):     int64(-8),
+		int64(headerCritical):      []interface{}{headerSigningScheme, headerAuthenticSigningTime, headerExpiry, "io.acme.team"},
+		int64(headerContentType):   notation.MediaTypePayload,
+		headerSigningScheme:        string(notation.SigningSchemeX509SigningAuthority),
+		headerAuthenticSigningTime: cborTag{number: cborTagEpochTime, content: signingTime.Unix()},
+		headerExpiry:               cborTag{number: cborTagEpochTime, content: expiry.Unix()},
+		"io.acme.team":             "net-monitor",
+	}
+	gotHeaders, _ := marshalCBOR(headers)
+	wantHeaders, _ := marshalCBOR(want)
+	if string(gotHeaders) != string(wantHeaders) {
+		t.Errorf("protected headers = %v, want %v", headers, want)
+	}
+	chain, ok := unprotected[int64(headerX5Chain)].([]interface{})
+	if !ok || len(chain) != 2 || string(chain[0].([]byte)) != string(cert.Raw) {
+		t.Errorf("x5chain = %v, want the certificate chain", unprotected[int64(headerX5Chain)])
+	}
+	if got := unprotected[headerSigningAgent]; got != "notation/test" {
+		t.Errorf("signing agent = %v, want notation/test", got)
+	}
+
+	var p Payload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if !p.Subject.Equal(testDescriptor) {
+		t.Errorf("subject = %+v, want %+v", p.Subject, testDescriptor)
+	}
+}
+
+func TestMessage_Deterministic(t *testing.T) {
+	opts := notation.SignOptions{
+		SigningScheme: notation.SigningSchemeX509,
+		Deterministic: true,
+		SigningAgent:  "notation/test",
+	}
+	msg, err := NewMessage(notation.ECDSA_SHA_256, testDescriptor, time.Unix(1660000000, 0), opts)
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if msg.signingAgent != "" {
+		t.Errorf("signing agent = %q, want it omitted", msg.signingAgent)
+	}
+	again, err := NewMessage(notation.ECDSA_SHA_256, testDescriptor, time.Unix(1660000000, 0), opts)
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if string(msg.SigningInput()) != string(again.SigningInput()) {
+		t.Errorf("SigningInput() is not deterministic")
+	}
+}
+
+func TestNewMessage_Errors(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		alg  notation.SignatureAlgorithm
+		opts notation.SignOptions
+		want error
+	}{
+		{name: "unsupported algorithm", alg: "RSASSA_PKCS1_V1_5_SHA_256", want: notation.ErrUnsupportedSigningAlgorithm},
+		{name: "expired", alg: notation.ECDSA_SHA_256, opts: notation.SignOptions{Expiry: now.Add(-time.Hour)}, want: notation.ErrInvalidArgument},
+		{name: "reserved attribute", alg: notation.ECDSA_SHA_256, opts: notation.SignOptions{
+			ExtendedSignedAttributes: []notation.SignedAttribute{{Key: headerExpiry, Value: "never"}},
+		}, want: notation.ErrInvalidArgument},
+		{name: "unsupported attribute", alg: notation.ECDSA_SHA_256, opts: notation.SignOptions{
+			ExtendedSignedAttributes: []notation.SignedAttribute{{Key: "io.acme.ratio", Value: 0.5}},
+		}, want: notation.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMessage(tt.alg, testDescriptor, now, tt.opts); !errors.Is(err, tt.want) {
+				t.Errorf("NewMessage() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	msg, err := NewMessage(notation.ECDSA_SHA_256, testDescriptor, now, notation.SignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := msg.Envelope([]byte("sig"), nil); !errors.Is(err, notation.ErrEmptyCertificateChain) {
+		t.Errorf("Envelope() error = %v, want %v", err, notation.ErrEmptyCertificateChain)
+	}
+}
@@ -0,0 +1,290 @@
+package dsse
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
+)
+
+var testSubject = notation.Descriptor{
+	MediaType: "application/vnd.oci.image.manifest.v1+json",
+	Digest:    digest.FromString("net-monitor"),
+	Size:      11,
+}
+
+// testProvenance is a SLSA provenance predicate.
+var testProvenance = map[string]interface{}{
+	"buildDefinition": map[string]interface{}{"buildType": "https://example.com/build/v1"},
+	"runDetails":      map[string]interface{}{"builder": map[string]string{"id": "https://example.com/builder"}},
+}
+
+// newTestSigner returns a signer of key with a self-signed code signing
+// certificate, and the certificate.
+func newTestSigner(t *testing.T, key crypto.Signer) (*Signer, *x509.Certificate) {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "builder"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwsSigner, err := jws.NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSigner(jwsSigner)
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	return s, cert
+}
+
+func newTestVerifier(certs ...*x509.Certificate) *Verifier {
+	v := NewVerifier()
+	v.VerifyOptions.Roots = x509.NewCertPool()
+	for _, cert := range certs {
+		v.VerifyOptions.Roots.AddCert(cert)
+	}
+	return v
+}
+
+func TestPAE(t *testing.T) {
+	got := string(PAE("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Errorf("PAE() = %q, want %q", got, want)
+	}
+}
+
+func TestSignStatement(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		key  crypto.Signer
+	}{
+		{name: "RSA", key: rsaKey},
+		{name: "ECDSA", key: ecKey},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cert := newTestSigner(t, tt.key)
+			statement, err := NewStatement("registry.acme-rockets.io/net-monitor", testSubject, PredicateTypeSLSAProvenanceV1, testProvenance)
+			if err != nil {
+				t.Fatalf("NewStatement() error = %v", err)
+			}
+			env, err := s.SignStatement(context.Background(), statement, SignOptions{})
+			if err != nil {
+				t.Fatalf("SignStatement() error = %v", err)
+			}
+			envelope, err := ParseEnvelope(env)
+			if err != nil {
+				t.Fatalf("ParseEnvelope() error = %v", err)
+			}
+			if envelope.PayloadType != PayloadTypeInToto {
+				t.Errorf("PayloadType = %q, want %q", envelope.PayloadType, PayloadTypeInToto)
+			}
+			if _, ok := tt.key.(*ecdsa.PrivateKey); ok {
+				var sig struct{ R, S *big.Int }
+				if _, err := asn1.Unmarshal(envelope.Signatures[0].Sig, &sig); err != nil {
+					t.Errorf("ECDSA signature is not ASN.1 DER encoded: %v", err)
+				}
+			}
+
+			got, outcome, err := newTestVerifier(cert).VerifyStatement(context.Background(), env, testSubject, notation.VerifyOptions{})
+			if err != nil {
+				t.Fatalf("VerifyStatement() error = %v", err)
+			}
+			if got.PredicateType != PredicateTypeSLSAProvenanceV1 || !got.HasSubject(testSubject.Digest) {
+				t.Errorf("VerifyStatement() statement = %+v, want the signed statement", got)
+			}
+			if !outcome.Descriptor.Equal(testSubject) || len(outcome.CertificateChain) != 1 {
+				t.Errorf("VerifyStatement() outcome = %+v, want the subject and the certificate chain", outcome)
+			}
+			if result := outcome.Result(notation.CheckAuthenticity); result == nil || result.Status != notation.VerificationPassed {
+				t.Errorf("VerifyStatement() authenticity = %v, want passed", result)
+			}
+		})
+	}
+}
+
+func TestVerifyStatement_Failures(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, cert := newTestSigner(t, key)
+	_, untrusted := newTestSigner(t, key)
+	statement, err := NewStatement("net-monitor", testSubject, PredicateTypeSLSAProvenanceV1, testProvenance)
+	if err != nil {
+		t.Fatalf("NewStatement() error = %v", err)
+	}
+	env, err := s.SignStatement(context.Background(), statement, SignOptions{})
+	if err != nil {
+		t.Fatalf("SignStatement() error = %v", err)
+	}
+	custom, err := s.Sign(context.Background(), "application/vnd.example+json", []byte(`{}`), SignOptions{})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	retyped := func() []byte {
+		envelope, err := ParseEnvelope(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope.PayloadType = "application/vnd.example+json"
+		data, err := json.Marshal(envelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}()
+
+	tests := []struct {
+		name      string
+		env       []byte
+		subject   notation.Descriptor
+		verifier  *Verifier
+		wantCheck notation.VerificationCheck
+		wantErr   string
+	}{
+		{
+			name:      "untrusted",
+			env:       env,
+			subject:   testSubject,
+			verifier:  newTestVerifier(untrusted),
+			wantCheck: notation.CheckAuthenticity,
+			wantErr:   "x509",
+		},
+		{
+			name:      "other subject",
+			env:       env,
+			subject:   notation.Descriptor{MediaType: testSubject.MediaType, Digest: digest.FromString("other"), Size: 5},
+			verifier:  newTestVerifier(cert),
+			wantCheck: notation.CheckIntegrity,
+			wantErr:   "is not about artifact",
+		},
+		{
+			name:      "payload type not accepted",
+			env:       custom,
+			subject:   testSubject,
+			verifier:  newTestVerifier(cert),
+			wantCheck: notation.CheckIntegrity,
+			wantErr:   "is not accepted",
+		},
+		{
+			name:      "payload type tampered",
+			env:       retyped,
+			subject:   testSubject,
+			verifier:  &Verifier{VerifyOptions: newTestVerifier(cert).VerifyOptions, PayloadTypes: []string{"application/vnd.example+json"}},
+			wantCheck: notation.CheckIntegrity,
+			wantErr:   "verification",
+		},
+		{
+			name:      "not a statement",
+			env:       custom,
+			subject:   testSubject,
+			verifier:  &Verifier{VerifyOptions: newTestVerifier(cert).VerifyOptions, PayloadTypes: []string{"application/vnd.example+json"}},
+			wantCheck: notation.CheckIntegrity,
+			wantErr:   "is not an in-toto statement",
+		},
+		{
+			name:      "malformed",
+			env:       []byte(`{"payloadType":"application/vnd.in-toto+json"}`),
+			subject:   testSubject,
+			verifier:  newTestVerifier(cert),
+			wantCheck: notation.CheckIntegrity,
+			wantErr:   "no signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement, outcome, err := tt.verifier.VerifyStatement(context.Background(), tt.env, tt.subject, notation.VerifyOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("VerifyStatement() error = %v, want %v", err, tt.wantErr)
+			}
+			if result := outcome.Result(tt.wantCheck); result == nil || result.Status != notation.VerificationFailed {
+				t.Errorf("VerifyStatement() %s = %v, want failed", tt.wantCheck, result)
+			}
+			if tt.wantCheck == notation.CheckIntegrity && statement != nil {
+				t.Errorf("VerifyStatement() statement = %+v, want nil", statement)
+			}
+		})
+	}
+}
+
+func TestStatement_Validate(t *testing.T) {
+	valid := func() *Statement {
+		return &Statement{
+			Type:          StatementTypeV01,
+			Subject:       []Subject{{Name: "net-monitor", Digest: map[string]string{"sha256": testSubject.Digest.Encoded(), "gitCommit": "0123abc"}}},
+			PredicateType: PredicateTypeSLSAProvenanceV02,
+		}
+	}
+	tests := []struct {
+		name    string
+		modify  func(s *Statement)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(s *Statement) {}},
+		{name: "unknown type", modify: func(s *Statement) { s.Type = "https://in-toto.io/Statement/v9" }, wantErr: true},
+		{name: "no predicate type", modify: func(s *Statement) { s.PredicateType = "" }, wantErr: true},
+		{name: "no subject", modify: func(s *Statement) { s.Subject = nil }, wantErr: true},
+		{name: "invalid digest", modify: func(s *Statement) { s.Subject[0].Digest["sha256"] = "zz" }, wantErr: true},
+		{name: "empty digest", modify: func(s *Statement) { s.Subject[0].Digest["gitCommit"] = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(s)
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// nopSigner is a notation.Signer which cannot sign payloads.
+type nopSigner struct{}
+
+func (nopSigner) Sign(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	return nil, nil
+}
+
+func TestNewSigner_NotPayloadSigner(t *testing.T) {
+	if _, err := NewSigner(nopSigner{}); !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("NewSigner() error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,65 @@
+// Package dsse signs and verifies payloads wrapped in DSSE envelopes, as
+// defined in https://github.com/secure-systems-lab/dsse, such as in-toto
+// attestations, e.g. SLSA provenance.
+//
+// Envelopes are signed by the signers of the jws package, through the same
+// plugin workflow as notation signatures, and verified against the same
+// trust roots.
+package dsse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MediaTypeEnvelope is the media type of DSSE envelopes.
+const MediaTypeEnvelope = "application/vnd.dsse.envelope.v1+json"
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	// PayloadType is the type of the payload, e.g. PayloadTypeInToto.
+	PayloadType string `json:"payloadType"`
+
+	// Payload is the signed payload.
+	Payload []byte `json:"payload"`
+
+	// Signatures are the signatures of the payload.
+	Signatures []Signature `json:"signatures"`
+}
+
+// Signature is a signature of a DSSE envelope.
+type Signature struct {
+	// KeyID is an unauthenticated hint of the signing key.
+	KeyID string `json:"keyid,omitempty"`
+
+	// Sig is the signature of the pre-authentication encoding of the
+	// payload.
+	Sig []byte `json:"sig"`
+
+	// CertificateChain is the DER encoded certificate chain of the signing
+	// key, starting with the signing certificate. It extends the DSSE
+	// signature format, like the x5c header of JWS.
+	CertificateChain [][]byte `json:"x5c,omitempty"`
+}
+
+// PAE returns the pre-authentication encoding of payload, which is signed
+// instead of the payload so that the payload type is authenticated.
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// ParseEnvelope parses a DSSE envelope.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid DSSE envelope: %w", err)
+	}
+	if env.PayloadType == "" {
+		return nil, errors.New("invalid DSSE envelope: missing payload type")
+	}
+	if len(env.Signatures) == 0 {
+		return nil, errors.New("invalid DSSE envelope: no signature")
+	}
+	return &env, nil
+}
@@ -0,0 +1,121 @@
+package dsse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+// PayloadTypeInToto is the payload type of in-toto statements.
+const PayloadTypeInToto = "application/vnd.in-toto+json"
+
+// Types of in-toto statements.
+const (
+	StatementTypeV01 = "https://in-toto.io/Statement/v0.1"
+	StatementTypeV1  = "https://in-toto.io/Statement/v1"
+)
+
+// Predicate types of SLSA provenance.
+const (
+	PredicateTypeSLSAProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	PredicateTypeSLSAProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+// Statement is an in-toto statement, binding a predicate, e.g. SLSA
+// provenance, to the artifacts it is about.
+type Statement struct {
+	// Type is the type of the statement, e.g. StatementTypeV1.
+	Type string `json:"_type"`
+
+	// Subject are the artifacts the statement is about.
+	Subject []Subject `json:"subject"`
+
+	// PredicateType is the type of the predicate, e.g.
+	// PredicateTypeSLSAProvenanceV1.
+	PredicateType string `json:"predicateType"`
+
+	// Predicate is the predicate of the statement.
+	Predicate json.RawMessage `json:"predicate,omitempty"`
+}
+
+// Subject is an artifact an in-toto statement is about.
+type Subject struct {
+	// Name is the name of the artifact, e.g. its repository.
+	Name string `json:"name"`
+
+	// Digest maps digest algorithms to the hex encoded digests of the
+	// artifact, e.g. "sha256" to the digest of its manifest.
+	Digest map[string]string `json:"digest"`
+}
+
+// NewStatement returns an in-toto statement of predicate, of type
+// predicateType, about the artifact named name described by desc.
+func NewStatement(name string, desc notation.Descriptor, predicateType string, predicate interface{}) (*Statement, error) {
+	if err := desc.Validate(); err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	predicateJSON, err := json.Marshal(predicate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal predicate: %w", err)
+	}
+	s := &Statement{
+		Type: StatementTypeV1,
+		Subject: []Subject{{
+			Name:   name,
+			Digest: map[string]string{desc.Digest.Algorithm().String(): desc.Digest.Encoded()},
+		}},
+		PredicateType: predicateType,
+		Predicate:     predicateJSON,
+	}
+	if err := s.Validate(); err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	return s, nil
+}
+
+// Validate validates the statement has a known type, a predicate type, and
+// subjects with digests.
+func (s *Statement) Validate() error {
+	if s.Type != StatementTypeV01 && s.Type != StatementTypeV1 {
+		return fmt.Errorf("unsupported in-toto statement type %q", s.Type)
+	}
+	if s.PredicateType == "" {
+		return errors.New("in-toto statement has no predicate type")
+	}
+	if len(s.Subject) == 0 {
+		return errors.New("in-toto statement has no subject")
+	}
+	for i, subject := range s.Subject {
+		if len(subject.Digest) == 0 {
+			return fmt.Errorf("subject %d of in-toto statement has no digest", i)
+		}
+		// in-toto digests are not limited to the algorithms of OCI, e.g.
+		// gitCommit digests, so only the known algorithms are validated
+		for alg, encoded := range subject.Digest {
+			if encoded == "" {
+				return fmt.Errorf("subject %d of in-toto statement has an empty %s digest", i, alg)
+			}
+			if !digest.Algorithm(alg).Available() {
+				continue
+			}
+			if err := digest.NewDigestFromEncoded(digest.Algorithm(alg), encoded).Validate(); err != nil {
+				return fmt.Errorf("subject %d of in-toto statement has an invalid digest: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// HasSubject reports whether the artifact of digest d is a subject of the
+// statement.
+func (s *Statement) HasSubject(d digest.Digest) bool {
+	for _, subject := range s.Subject {
+		if subject.Digest[d.Algorithm().String()] == d.Encoded() {
+			return true
+		}
+	}
+	return false
+}
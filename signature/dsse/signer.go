@@ -0,0 +1,93 @@
+package dsse
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+// SignOptions contains optional parameters for Signer.Sign.
+type SignOptions struct {
+	// PluginConfig is passed to the signing plugin, along with the plugin
+	// config of the signer.
+	PluginConfig map[string]string
+}
+
+// Signer signs payloads into DSSE envelopes.
+type Signer struct {
+	signer jws.PayloadSigner
+}
+
+// NewSigner creates a DSSE signer signing with the key of signer, which must
+// be a signer of the jws package, e.g. as returned by jws.NewSigner or
+// jws.NewSignerPlugin.
+func NewSigner(signer notation.Signer) (*Signer, error) {
+	payloadSigner, ok := signer.(jws.PayloadSigner)
+	if !ok {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "signer %T cannot sign payloads", signer)
+	}
+	return &Signer{signer: payloadSigner}, nil
+}
+
+// Sign signs payload of type payloadType, and returns the DSSE envelope.
+// ECDSA signatures are ASN.1 DER encoded, as expected by DSSE verifiers.
+func (s *Signer) Sign(ctx context.Context, payloadType string, payload []byte, opts SignOptions) ([]byte, error) {
+	if payloadType == "" {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "empty payload type")
+	}
+	sig, err := s.signer.SignPayload(ctx, PAE(payloadType, payload), opts.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	signature := sig.Signature
+	if key, ok := sig.CertificateChain[0].PublicKey.(*ecdsa.PublicKey); ok {
+		if signature, err = asn1ECDSASignature(signature, key); err != nil {
+			return nil, err
+		}
+	}
+	rawCerts := make([][]byte, len(sig.CertificateChain))
+	for i, cert := range sig.CertificateChain {
+		rawCerts[i] = cert.Raw
+	}
+	return json.Marshal(Envelope{
+		PayloadType: payloadType,
+		Payload:     payload,
+		Signatures: []Signature{{
+			Sig:              signature,
+			CertificateChain: rawCerts,
+		}},
+	})
+}
+
+// SignStatement signs the in-toto statement, and returns the DSSE envelope.
+func (s *Signer) SignStatement(ctx context.Context, statement *Statement, opts SignOptions) ([]byte, error) {
+	if err := statement.Validate(); err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal in-toto statement: %w", err)
+	}
+	return s.Sign(ctx, PayloadTypeInToto, payload, opts)
+}
+
+// asn1ECDSASignature converts a fixed-size R||S ECDSA signature to its
+// ASN.1 DER encoding.
+func asn1ECDSASignature(sig []byte, key *ecdsa.PublicKey) ([]byte, error) {
+	size := (key.Params().BitSize + 7) / 8
+	if len(sig) != 2*size {
+		return sig, nil
+	}
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(sig[:size]),
+		S: new(big.Int).SetBytes(sig[size:]),
+	})
+}
@@ -0,0 +1,202 @@
+package dsse
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/certpolicy"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+// Verifier verifies DSSE envelopes.
+type Verifier struct {
+	// VerifyOptions is the verify option to verify the signing certificate.
+	// The Intermediates are ignored and reconstructed from the certificate
+	// chain of the signature. An empty list of KeyUsages implies the
+	// extended key usages required by CertificatePolicy.
+	VerifyOptions x509.VerifyOptions
+
+	// CertificatePolicy validates the certificate chain of the signature.
+	// certpolicy.Default is used if nil.
+	CertificatePolicy *certpolicy.Policy
+
+	// PayloadTypes are the payload types of the envelopes accepted.
+	// Only PayloadTypeInToto is accepted if empty.
+	PayloadTypes []string
+}
+
+// NewVerifier creates a DSSE verifier. Callers are expected to set the
+// trusted roots in VerifyOptions.
+func NewVerifier() *Verifier {
+	return &Verifier{}
+}
+
+// Verify verifies the DSSE envelope env, and returns the envelope along with
+// the outcome of the verification.
+//
+// The integrity check verifies the payload type is accepted and the
+// envelope has a signature verified by its signing certificate, and the
+// authenticity check verifies the certificate chain of that signature. The
+// envelope is only returned if the integrity check passed.
+func (v *Verifier) Verify(ctx context.Context, env []byte, opts notation.VerifyOptions) (*Envelope, *notation.VerificationOutcome, error) {
+	return v.verify(ctx, env, opts, nil)
+}
+
+// VerifyStatement verifies the DSSE envelope env of an in-toto statement
+// about the artifact described by subject, and returns the statement along
+// with the outcome of the verification.
+//
+// In addition to the checks of Verify, the integrity check verifies the
+// payload is a valid in-toto statement with the digest of subject among its
+// subjects. The descriptor of the outcome is subject.
+func (v *Verifier) VerifyStatement(ctx context.Context, env []byte, subject notation.Descriptor, opts notation.VerifyOptions) (*Statement, *notation.VerificationOutcome, error) {
+	var statement Statement
+	_, outcome, err := v.verify(ctx, env, opts, func(envelope *Envelope, outcome *notation.VerificationOutcome) error {
+		if envelope.PayloadType != PayloadTypeInToto {
+			return notation.Errorf(notation.ErrorCodeMalformedSignature, "payload type %q is not an in-toto statement", envelope.PayloadType)
+		}
+		if err := json.Unmarshal(envelope.Payload, &statement); err != nil {
+			return notation.Errorf(notation.ErrorCodeMalformedSignature, "invalid in-toto statement: %w", err)
+		}
+		if err := statement.Validate(); err != nil {
+			return notation.WrapError(notation.ErrorCodeMalformedSignature, err)
+		}
+		if err := subject.ValidateDigestAlgorithms(opts.AllowedDigestAlgorithms); err != nil {
+			return notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+		}
+		if !statement.HasSubject(subject.Digest) {
+			return fmt.Errorf("in-toto statement is not about artifact %s", subject.Digest)
+		}
+		outcome.Descriptor = subject
+		return nil
+	})
+	if outcome.Result(notation.CheckIntegrity).Status != notation.VerificationPassed {
+		return nil, outcome, err
+	}
+	return &statement, outcome, err
+}
+
+// verify verifies the envelope, checking its payload with checkPayload, if
+// not nil, as part of the integrity check.
+func (v *Verifier) verify(ctx context.Context, env []byte, opts notation.VerifyOptions, checkPayload func(*Envelope, *notation.VerificationOutcome) error) (*Envelope, *notation.VerificationOutcome, error) {
+	outcome := new(notation.VerificationOutcome)
+	if err := opts.Validate(); err != nil {
+		return nil, outcome, outcome.Fail(notation.CheckIntegrity, err)
+	}
+	envelope, err := ParseEnvelope(env)
+	if err != nil {
+		return nil, outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+	}
+	if !v.acceptsPayloadType(envelope.PayloadType) {
+		return nil, outcome, outcome.Fail(notation.CheckIntegrity, notation.Errorf(notation.ErrorCodeMalformedSignature, "payload type %q is not accepted", envelope.PayloadType))
+	}
+	certs, err := verifySignatures(envelope)
+	if err != nil {
+		return nil, outcome, outcome.Fail(notation.CheckIntegrity, err)
+	}
+	if err := notation.CheckFIPSCertificateChain(certs); err != nil {
+		return nil, outcome, outcome.Fail(notation.CheckIntegrity, err)
+	}
+	if checkPayload != nil {
+		if err := checkPayload(envelope, outcome); err != nil {
+			return nil, outcome, outcome.Fail(notation.CheckIntegrity, err)
+		}
+	}
+	outcome.CertificateChain = certs
+	outcome.SelfSigned = notation.IsSelfSigned(certs[0])
+	outcome.Pass(notation.CheckIntegrity)
+
+	// verify signing identity
+	if chains, err := v.verifyCertificateChain(certs, opts.Clock); err != nil {
+		outcome.Fail(notation.CheckAuthenticity, err)
+	} else {
+		outcome.VerifiedChains = chains
+		outcome.Pass(notation.CheckAuthenticity)
+	}
+	for _, check := range []notation.VerificationCheck{notation.CheckTrustedIdentity, notation.CheckRevocation} {
+		outcome.Skip(check)
+	}
+
+	logger := log.GetLogger(ctx)
+	for _, result := range outcome.Results {
+		logger.Debugf("DSSE envelope of payload type %q: %v", envelope.PayloadType, result)
+	}
+	return envelope, outcome, outcome.Err()
+}
+
+// acceptsPayloadType reports whether envelopes of payloadType are accepted.
+func (v *Verifier) acceptsPayloadType(payloadType string) bool {
+	if len(v.PayloadTypes) == 0 {
+		return payloadType == PayloadTypeInToto
+	}
+	for _, t := range v.PayloadTypes {
+		if t == payloadType {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySignatures returns the certificate chain of the first signature of
+// the envelope verified by its signing certificate.
+func verifySignatures(envelope *Envelope) ([]*x509.Certificate, error) {
+	pae := PAE(envelope.PayloadType, envelope.Payload)
+	var errs []error
+	for _, sig := range envelope.Signatures {
+		if len(sig.CertificateChain) == 0 {
+			errs = append(errs, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "signer certificates not found"))
+			continue
+		}
+		certs := make([]*x509.Certificate, len(sig.CertificateChain))
+		var err error
+		for i, raw := range sig.CertificateChain {
+			if certs[i], err = x509.ParseCertificate(raw); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			errs = append(errs, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+			continue
+		}
+		if err := jws.VerifyPayloadSignature(pae, sig.Sig, certs[0]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return certs, nil
+	}
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, notation.Errorf(notation.ErrorCodeInvalidSignature, "none of the %d signatures of the envelope is valid: %v", len(errs), errs[0])
+}
+
+// verifyCertificateChain verifies the certificate chain meets the
+// certificate policy and chains up to the trusted roots at the time
+// returned by clock, if not nil. It returns the verified chains.
+func (v *Verifier) verifyCertificateChain(certs []*x509.Certificate, clock func() time.Time) ([][]*x509.Certificate, error) {
+	if err := v.CertificatePolicy.ValidateChain(certs); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate does not meet the minimum requirements: %w", err)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	verifyOpts := v.VerifyOptions
+	verifyOpts.Intermediates = intermediates
+	if clock != nil {
+		verifyOpts.CurrentTime = clock()
+	}
+	if len(verifyOpts.KeyUsages) == 0 {
+		verifyOpts.KeyUsages = v.CertificatePolicy.RequiredExtKeyUsages()
+	}
+	chains, err := certs[0].Verify(verifyOpts)
+	if err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeUntrustedSigner, err)
+	}
+	return chains, nil
+}
@@ -1,46 +1,46 @@
 package jws
 
 import (
-	"crypto"
-	"crypto/ecdsa"
 	"crypto/rsa"
-	"errors"
-	"fmt"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
 )
 
-func keySpecFromKey(key interface{}) (notation.KeySpec, error) {
-	if k, ok := key.(interface {
-		Public() crypto.PublicKey
-	}); ok {
-		key = k.Public()
+// strictPSSMethods contains the RSASSA-PSS signing methods of the supported
+// algorithms, which only accept signatures with a salt length equal to the
+// hash length, as required by RFC 7518 section 3.5.
+// The jwt package defaults accept any salt length during verification.
+var strictPSSMethods = func() map[notation.SignatureAlgorithm]*jwt.SigningMethodRSAPSS {
+	methods := make(map[notation.SignatureAlgorithm]*jwt.SigningMethodRSAPSS)
+	for _, info := range notation.SignatureAlgorithms() {
+		if method, ok := jwt.GetSigningMethod(info.JWS).(*jwt.SigningMethodRSAPSS); ok {
+			methods[info.Algorithm] = &jwt.SigningMethodRSAPSS{
+				SigningMethodRSA: method.SigningMethodRSA,
+				Options:          &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash},
+			}
+		}
 	}
+	return methods
+}()
 
-	switch key := key.(type) {
-	case *rsa.PublicKey:
-		switch size := key.Size(); size {
-		case 256:
-			return notation.RSA_2048, nil
-		case 384:
-			return notation.RSA_3072, nil
-		case 512:
-			return notation.RSA_4096, nil
-		default:
-			return "", fmt.Errorf("RSA key of size %q bits is not supported", key.N.BitLen())
-		}
-	case *ecdsa.PublicKey:
-		params := key.Curve.Params()
-		switch size := params.N.BitLen(); size {
-		case 256:
-			return notation.EC_256, nil
-		case 384:
-			return notation.EC_384, nil
-		case 521:
-			return notation.EC_512, nil
-		default:
-			return "", fmt.Errorf("EC key %q of size %q bits is not supported", params.Name, size)
-		}
+// signingMethod returns the JWS signing method of alg,
+// or nil if alg is not supported.
+func signingMethod(alg notation.SignatureAlgorithm) jwt.SigningMethod {
+	if method, ok := strictPSSMethods[alg]; ok {
+		return method
 	}
-	return "", errors.New("unsupported key type, only RSA and EC keys are supported")
+	jwsAlg := alg.JWS()
+	if jwsAlg == "" {
+		return nil
+	}
+	return jwt.GetSigningMethod(jwsAlg)
+}
+
+// KeySpecFromKey returns the key spec of a public key, or of the public key
+// of a private key or a crypto.Signer.
+//
+// Deprecated: use notation.KeySpecFromKey.
+func KeySpecFromKey(key interface{}) (notation.KeySpec, error) {
+	return notation.KeySpecFromKey(key)
 }
@@ -0,0 +1,109 @@
+package jws
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+)
+
+// EnvelopeInfo is the content of a JWS signature envelope, as read by
+// Inspect. None of it is verified.
+type EnvelopeInfo struct {
+	// Descriptor is the descriptor of the signed artifact, including the
+	// signed annotations.
+	Descriptor notation.Descriptor
+
+	// ContentType is the content type of the payload.
+	ContentType string
+
+	// SignatureAlgorithm is the signature algorithm declared by the
+	// protected header. It is empty if the algorithm is not supported.
+	SignatureAlgorithm notation.SignatureAlgorithm
+
+	// SigningScheme is the signing scheme of the signature.
+	SigningScheme notation.SigningScheme
+
+	// SigningTime is the signing time claimed by the signer, if any.
+	SigningTime time.Time
+
+	// AuthenticSigningTime is the signing time attested by the signing
+	// authority, if any.
+	AuthenticSigningTime time.Time
+
+	// Expiry is the expiry of the signature, if any.
+	Expiry time.Time
+
+	// ExtendedSignedAttributes are the extended signed attributes of the
+	// signature, sorted by key.
+	ExtendedSignedAttributes []notation.SignedAttribute
+
+	// CertificateChain is the certificate chain of the signature, starting
+	// with the signing certificate.
+	CertificateChain []*x509.Certificate
+
+	// Signature is the signature value.
+	Signature []byte
+
+	// TimestampToken is the RFC 3161 timestamp countersignature, if any.
+	TimestampToken []byte
+
+	// SigningAgent identifies the software which produced the signature,
+	// if declared.
+	SigningAgent string
+}
+
+// Inspect parses the JWS signature envelope sig without verifying it, e.g.
+// to display the signature for debugging. It fails if the envelope is
+// malformed.
+func Inspect(sig []byte) (*EnvelopeInfo, error) {
+	envelope, err := openEnvelope(sig)
+	if err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeMalformedSignature, err)
+	}
+	certs, err := parseSignerCertChain(envelope.Header.CertChain)
+	if err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeMalformedSignature, err)
+	}
+	var header map[string]interface{}
+	if err := decodeBase64URLJSON(envelope.Protected, &header); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope protected header can't be decoded: %w", err)
+	}
+	attrs, err := parseSignedAttributes(header)
+	if err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeMalformedSignature, err)
+	}
+	crit, err := criticalHeaders(header)
+	if err != nil {
+		return nil, err
+	}
+	var claims notaryClaim
+	if err := decodeBase64URLJSON(envelope.Payload, &claims); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope payload can't be decoded: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(envelope.Signature)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope signature can't be decoded: %w", err)
+	}
+	alg, _ := header["alg"].(string)
+	cty, _ := header["cty"].(string)
+	info := &EnvelopeInfo{
+		Descriptor:               claims.Subject,
+		ContentType:              cty,
+		SignatureAlgorithm:       notation.NewSignatureAlgorithmJWS(alg),
+		SigningScheme:            attrs.signingScheme,
+		SigningTime:              attrs.signingTime,
+		AuthenticSigningTime:     attrs.authenticSigningTime,
+		Expiry:                   attrs.expiry,
+		ExtendedSignedAttributes: extendedAttributes(header, crit),
+		CertificateChain:         certs,
+		Signature:                signature,
+		TimestampToken:           envelope.Header.TimeStampToken,
+		SigningAgent:             envelope.Header.SigningAgent,
+	}
+	if info.Expiry.IsZero() && claims.ExpiresAt != nil {
+		info.Expiry = claims.ExpiresAt.Time
+	}
+	return info, nil
+}
@@ -0,0 +1,192 @@
+package jws
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/plugin"
+	"github.com/notaryproject/notation-go/trace"
+)
+
+// PayloadSignature is a signature of an arbitrary payload.
+type PayloadSignature struct {
+	// Signature is the signature of the payload. ECDSA signatures are in the
+	// fixed-size R||S form.
+	Signature []byte
+
+	// Algorithm is the signature algorithm of the signature.
+	Algorithm notation.SignatureAlgorithm
+
+	// CertificateChain is the certificate chain of the signing key,
+	// starting with the signing certificate.
+	CertificateChain []*x509.Certificate
+}
+
+// PayloadSigner is implemented by the signers of this package, which sign
+// arbitrary payloads with their key through the same plugin workflow as
+// artifacts, e.g. to sign the DSSE envelopes of attestations.
+type PayloadSigner interface {
+	// SignPayload signs payload with the key of the signer, passing
+	// pluginConfig to the plugin along with the plugin config of the signer.
+	SignPayload(ctx context.Context, payload []byte, pluginConfig map[string]string) (*PayloadSignature, error)
+}
+
+// SignPayload signs payload with the generate-signature command of the
+// plugin. Plugins which only generate signature envelopes cannot sign
+// payloads.
+func (s *pluginSigner) SignPayload(ctx context.Context, payload []byte, pluginConfig map[string]string) (_ *PayloadSignature, err error) {
+	ctx, span := trace.StartSpan(ctx, "notation.SignPayload", trace.Attribute{Key: "notation.key.id", Value: s.keyID})
+	defer func() { trace.EndSpan(span, err) }()
+	if len(payload) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "empty payload")
+	}
+	contractVersion, key, config, err := s.payloadKey(ctx, pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	signature, certs, err := s.runGenerateSignature(ctx, contractVersion, key, config, payload)
+	if err != nil {
+		return nil, err
+	}
+	return &PayloadSignature{
+		Signature:        signature,
+		Algorithm:        key.KeySpec.SignatureAlgorithm(),
+		CertificateChain: certs,
+	}, nil
+}
+
+// SignatureAlgorithm returns the signature algorithm of the payload
+// signatures of the plugin, passing pluginConfig to the plugin along with the
+// plugin config of the signer.
+func (s *pluginSigner) SignatureAlgorithm(ctx context.Context, pluginConfig map[string]string) (notation.SignatureAlgorithm, error) {
+	_, key, _, err := s.payloadKey(ctx, pluginConfig)
+	if err != nil {
+		return "", err
+	}
+	return key.KeySpec.SignatureAlgorithm(), nil
+}
+
+// payloadKey describes the key signing payloads with the plugin, and returns
+// the negotiated contract version, the key and the merged plugin config.
+func (s *pluginSigner) payloadKey(ctx context.Context, pluginConfig map[string]string) (string, *plugin.DescribeKeyResponse, map[string]string, error) {
+	metadata, err := s.getMetadata(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	contractVersion, err := metadata.NegotiateContractVersion()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if !metadata.HasCapability(plugin.CapabilitySignatureGenerator) {
+		return "", nil, nil, errors.New("plugin does not have the SIGNATURE_GENERATOR capability required to sign payloads")
+	}
+	config := s.mergeConfig(pluginConfig)
+	key, err := s.describeKey(ctx, contractVersion, config)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if s.keyID != key.KeyID {
+		return "", nil, nil, notation.Errorf(notation.ErrorCodeKeyIDMismatch, "keyID in describeKey response %q does not match request %q", key.KeyID, s.keyID)
+	}
+	if key.KeySpec.SignatureAlgorithm() == "" {
+		return "", nil, nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q for key %q is not supported", key.KeySpec, key.KeyID)
+	}
+	if err := notation.CheckFIPSKeySpec(key.KeySpec); err != nil {
+		return "", nil, nil, err
+	}
+	return contractVersion, key, config, nil
+}
+
+// SignEnvelope signs desc into a JWS envelope with signer, whose payload
+// signatures have the signature algorithm alg. The envelope is assembled
+// from the raw signature of its signing input, so that signers which only
+// produce raw signatures, e.g. implemented outside of this package, can
+// sign artifacts. Dry runs and envelope generating plugins are not
+// supported.
+func SignEnvelope(ctx context.Context, signer PayloadSigner, alg notation.SignatureAlgorithm, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	jwsAlg := alg.JWS()
+	if jwsAlg == "" {
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedSigningAlgorithm, "signing algorithm %q not supported", alg)
+	}
+	if opts.DryRun {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "dry run is not supported by payload signers")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateExtendedAttributes(opts.ExtendedSignedAttributes); err != nil {
+		return nil, err
+	}
+	if err := desc.ValidateDigestAlgorithms(opts.AllowedDigestAlgorithms); err != nil {
+		return nil, err
+	}
+	if opts.SigningScheme == "" {
+		opts.SigningScheme = notation.SigningSchemeX509
+	}
+	desc, err := SignedDescriptor(desc, opts)
+	if err != nil {
+		return nil, err
+	}
+	payload := packPayload(desc, opts)
+	if err := validateClaims(payload, payload.IssuedAt.Time); err != nil {
+		return nil, err
+	}
+	signingInput, err := jwtToken(jwsAlg, opts, payload).SigningString()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signing payload: %v", err)
+	}
+	sig, err := SignPayloadWith(ctx, signer, alg, []byte(signingInput), opts.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	return jwsEnvelope(ctx, opts, signingInput+"."+base64.RawURLEncoding.EncodeToString(sig.Signature), rawCertChain(sig.CertificateChain))
+}
+
+// SignPayloadWith signs payload with signer, and checks the signature has
+// the signature algorithm alg and is verified by the signing certificate.
+func SignPayloadWith(ctx context.Context, signer PayloadSigner, alg notation.SignatureAlgorithm, payload []byte, pluginConfig map[string]string) (*PayloadSignature, error) {
+	sig, err := signer.SignPayload(ctx, payload, pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	if sig.Algorithm != alg {
+		return nil, notation.Errorf(notation.ErrorCodeKeySpecMismatch, "signing algorithm %q of the payload signature does not match %q", sig.Algorithm, alg)
+	}
+	if len(sig.CertificateChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "payload signature has empty certificate chain")
+	}
+	if err := VerifyPayloadSignature(payload, sig.Signature, sig.CertificateChain[0]); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidSignature, "payload signature cannot be verified: %w", err)
+	}
+	return sig, nil
+}
+
+// VerifyPayloadSignature verifies sig is a signature of payload by the key
+// of cert, with the signature algorithm of the key spec of the key.
+// ECDSA signatures are accepted in the fixed-size R||S form or ASN.1 DER
+// encoded.
+func VerifyPayloadSignature(payload, sig []byte, cert *x509.Certificate) error {
+	keySpec, err := notation.KeySpecFromKey(cert.PublicKey)
+	if err != nil {
+		return err
+	}
+	alg := keySpec.SignatureAlgorithm()
+	method := signingMethod(alg)
+	if method == nil {
+		return notation.Errorf(notation.ErrorCodeUnsupportedSigningAlgorithm, "signing algorithm %q not supported", alg)
+	}
+	if key, ok := cert.PublicKey.(*ecdsa.PublicKey); ok {
+		if sig, err = jwsECDSASignature(sig, key); err != nil {
+			return notation.WrapError(notation.ErrorCodeInvalidSignature, err)
+		}
+	}
+	if err := method.Verify(string(payload), base64.RawURLEncoding.EncodeToString(sig), cert.PublicKey); err != nil {
+		return notation.WrapError(notation.ErrorCodeInvalidSignature, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package jws
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+)
+
+func TestSignPayload(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		key     crypto.PrivateKey
+		wantAlg notation.SignatureAlgorithm
+	}{
+		{name: "RSA", key: rsaKey, wantAlg: notation.RSASSA_PSS_SHA_256},
+		{name: "ECDSA", key: ecKey, wantAlg: notation.ECDSA_SHA_256},
+		{name: "Ed25519", key: edKey, wantAlg: notation.EDDSA_ED25519},
+	}
+	payload := []byte("DSSEv1 4 test 7 payload")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := generateCert(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := NewSigner(tt.key, []*x509.Certificate{cert})
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+			sig, err := s.(PayloadSigner).SignPayload(context.Background(), payload, nil)
+			if err != nil {
+				t.Fatalf("SignPayload() error = %v", err)
+			}
+			if sig.Algorithm != tt.wantAlg {
+				t.Errorf("SignPayload() Algorithm = %v, want %v", sig.Algorithm, tt.wantAlg)
+			}
+			if len(sig.CertificateChain) != 1 || !sig.CertificateChain[0].Equal(cert) {
+				t.Errorf("SignPayload() CertificateChain = %v, want the signing certificate", sig.CertificateChain)
+			}
+			if err := VerifyPayloadSignature(payload, sig.Signature, cert); err != nil {
+				t.Errorf("VerifyPayloadSignature() error = %v", err)
+			}
+			if err := VerifyPayloadSignature([]byte("tampered"), sig.Signature, cert); err == nil {
+				t.Error("VerifyPayloadSignature() of a tampered payload error = nil, want error")
+			}
+		})
+	}
+
+	t.Run("ASN.1 ECDSA signature", func(t *testing.T) {
+		cert, err := generateCert(ecKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256(payload)
+		sig, err := ecdsa.SignASN1(rand.Reader, ecKey, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyPayloadSignature(payload, sig, cert); err != nil {
+			t.Errorf("VerifyPayloadSignature() error = %v", err)
+		}
+	})
+
+	t.Run("empty payload", func(t *testing.T) {
+		cert, err := generateCert(rsaKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewSigner(rsaKey, []*x509.Certificate{cert})
+		if err != nil {
+			t.Fatalf("NewSigner() error = %v", err)
+		}
+		if _, err := s.(PayloadSigner).SignPayload(context.Background(), nil, nil); err == nil {
+			t.Error("SignPayload() error = nil, want error")
+		}
+	})
+}
@@ -2,15 +2,26 @@ package jws
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/certpolicy"
+	"github.com/notaryproject/notation-go/config"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/metrics"
 	"github.com/notaryproject/notation-go/plugin"
+	"github.com/notaryproject/notation-go/signature/cose"
+	"github.com/notaryproject/notation-go/trace"
 )
 
 // pluginSigner signs artifacts and generates JWS signatures.
@@ -18,38 +29,131 @@ type pluginSigner struct {
 	runner       plugin.Runner
 	keyID        string
 	pluginConfig map[string]string
+
+	// keyCache caches the describe-key responses, if not nil.
+	keyCache *describeKeyCache
+
+	// certPolicy validates the certificate chains returned by the plugin.
+	certPolicy *certpolicy.Policy
+
+	// aiaFetcher completes the certificate chains returned by the plugin.
+	aiaFetcher *certpolicy.AIAFetcher
+}
+
+// CertificateChainProvider is implemented by the plugin runners which know
+// the certificate chain of a key without signing, such as the runners of
+// local keys, so that dry runs validate the certificate chain.
+type CertificateChainProvider interface {
+	// CertificateChain returns the certificate chain of the key keyID.
+	CertificateChain(ctx context.Context, keyID string) ([][]byte, error)
+}
+
+// SignerPluginOptions contains optional parameters for
+// NewSignerPluginWithOptions.
+type SignerPluginOptions struct {
+	// DisableKeyCache disables caching the describe-key response of the key,
+	// e.g. for keys rotated while the signer is in use.
+	// By default, describe-key runs once per plugin config for the lifetime
+	// of the signer.
+	DisableKeyCache bool
+
+	// CertificatePolicy validates the certificate chains returned by the
+	// plugin. certpolicy.Default is used if nil.
+	CertificatePolicy *certpolicy.Policy
+
+	// AIAFetcher completes the certificate chains returned by the plugin
+	// with the intermediates fetched from their AIA URLs before they are
+	// validated. Chains are not completed if nil.
+	AIAFetcher *certpolicy.AIAFetcher
 }
 
 // NewSignerPlugin creates a notation.Signer that signs artifacts and generates JWS signatures
 // by delegating the one or more operations to the named plugin,
 // as defined in
 // https://github.com/notaryproject/notaryproject/blob/main/specs/plugin-extensibility.md#signing-interfaces.
+//
+// The describe-key response of the key is cached by the signer.
 func NewSignerPlugin(runner plugin.Runner, keyID string, pluginConfig map[string]string) (notation.Signer, error) {
+	return NewSignerPluginWithOptions(runner, keyID, pluginConfig, SignerPluginOptions{})
+}
+
+// NewSignerPluginWithOptions creates a plugin signer as NewSignerPlugin,
+// configured with opts.
+func NewSignerPluginWithOptions(runner plugin.Runner, keyID string, pluginConfig map[string]string, opts SignerPluginOptions) (notation.Signer, error) {
 	if runner == nil {
-		return nil, errors.New("nil plugin runner")
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil plugin runner")
 	}
 	if keyID == "" {
-		return nil, errors.New("nil signing keyID")
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil signing keyID")
 	}
-	return &pluginSigner{runner, keyID, pluginConfig}, nil
+	s := &pluginSigner{
+		runner:       runner,
+		keyID:        keyID,
+		pluginConfig: pluginConfig,
+		certPolicy:   opts.CertificatePolicy,
+		aiaFetcher:   opts.AIAFetcher,
+	}
+	if !opts.DisableKeyCache {
+		s.keyCache = newDescribeKeyCache()
+	}
+	return s, nil
+}
+
+// NewSignerPluginWithConfig creates a plugin signer as NewSignerPlugin,
+// checking that the signature format configured in cfg is JWS.
+func NewSignerPluginWithConfig(runner plugin.Runner, keyID string, pluginConfig map[string]string, cfg *config.Config) (notation.Signer, error) {
+	mediaType, err := cfg.SignatureMediaType()
+	if err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeInvalidArgument, err)
+	}
+	if mediaType != notation.MediaTypeJWSEnvelope {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "signature format %q is not supported by the JWS signer", cfg.SignatureFormat)
+	}
+	return NewSignerPlugin(runner, keyID, pluginConfig)
 }
 
 // Sign signs the artifact described by its descriptor, and returns the signature.
-func (s *pluginSigner) Sign(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+func (s *pluginSigner) Sign(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) (_ []byte, err error) {
+	ctx, span := trace.StartSpan(ctx, "notation.Sign",
+		trace.Attribute{Key: "notation.artifact.digest", Value: desc.Digest.String()},
+		trace.Attribute{Key: "notation.key.id", Value: s.keyID},
+	)
+	defer func() {
+		trace.EndSpan(span, err)
+		metrics.GetRecorder(ctx).RecordSign(err)
+	}()
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateExtendedAttributes(opts.ExtendedSignedAttributes); err != nil {
+		return nil, err
+	}
+	if err := desc.ValidateDigestAlgorithms(opts.AllowedDigestAlgorithms); err != nil {
+		return nil, err
+	}
+	if opts.SigningScheme == "" {
+		opts.SigningScheme = notation.SigningSchemeX509
+	}
+	desc, err = signedDescriptor(desc, opts.SignedAnnotations, opts.UserMetadata)
+	if err != nil {
+		return nil, err
+	}
+	log.GetLogger(ctx).Debugf("signing %s with key %q and signing scheme %q", desc.Digest, s.keyID, opts.SigningScheme)
 	metadata, err := s.getMetadata(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if !metadata.SupportsContract(plugin.ContractVersion) {
-		return nil, fmt.Errorf(
-			"contract version %q is not in the list of the plugin supported versions %v",
-			plugin.ContractVersion, metadata.SupportedContractVersions,
-		)
+	contractVersion, err := metadata.NegotiateContractVersion()
+	if err != nil {
+		return nil, err
 	}
 	if metadata.HasCapability(plugin.CapabilitySignatureGenerator) {
-		return s.generateSignature(ctx, desc, opts)
+		return s.generateSignature(ctx, contractVersion, desc, opts)
 	} else if metadata.HasCapability(plugin.CapabilityEnvelopeGenerator) {
-		return s.generateSignatureEnvelope(ctx, desc, opts)
+		if opts.EnvelopeType == notation.MediaTypeCOSEEnvelope {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "COSE envelopes require the %s capability", plugin.CapabilitySignatureGenerator)
+		}
+		return s.generateSignatureEnvelope(ctx, contractVersion, desc, opts)
 	}
 	return nil, fmt.Errorf("plugin does not have signing capabilities")
 }
@@ -57,7 +161,7 @@ func (s *pluginSigner) Sign(ctx context.Context, desc notation.Descriptor, opts
 func (s *pluginSigner) getMetadata(ctx context.Context) (*plugin.Metadata, error) {
 	out, err := s.runner.Run(ctx, new(plugin.GetMetadataRequest))
 	if err != nil {
-		return nil, fmt.Errorf("metadata command failed: %w", err)
+		return nil, notation.Errorf(notation.ErrorCodePluginFailed, "metadata command failed: %w", err)
 	}
 	metadata, ok := out.(*plugin.Metadata)
 	if !ok {
@@ -69,111 +173,298 @@ func (s *pluginSigner) getMetadata(ctx context.Context) (*plugin.Metadata, error
 	return metadata, nil
 }
 
-func (s *pluginSigner) describeKey(ctx context.Context, config map[string]string) (*plugin.DescribeKeyResponse, error) {
+func (s *pluginSigner) describeKey(ctx context.Context, contractVersion string, config map[string]string) (*plugin.DescribeKeyResponse, error) {
+	if resp, ok := s.keyCache.load(config); ok {
+		return resp, nil
+	}
 	req := &plugin.DescribeKeyRequest{
-		ContractVersion: plugin.ContractVersion,
+		ContractVersion: contractVersion,
 		KeyID:           s.keyID,
 		PluginConfig:    config,
 	}
 	out, err := s.runner.Run(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("describe-key command failed: %w", err)
+		return nil, notation.Errorf(notation.ErrorCodePluginFailed, "describe-key command failed: %w", err)
 	}
 	resp, ok := out.(*plugin.DescribeKeyResponse)
 	if !ok {
 		return nil, fmt.Errorf("plugin runner returned incorrect describe-key response type '%T'", out)
 	}
+	s.keyCache.store(config, resp)
 	return resp, nil
 }
 
-func (s *pluginSigner) generateSignature(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+// describeKeyCache caches the describe-key responses of a key per plugin
+// config. A nil cache caches nothing.
+type describeKeyCache struct {
+	mu        sync.Mutex
+	responses map[string]plugin.DescribeKeyResponse
+}
+
+// newDescribeKeyCache returns an empty describe-key cache.
+func newDescribeKeyCache() *describeKeyCache {
+	return &describeKeyCache{responses: make(map[string]plugin.DescribeKeyResponse)}
+}
+
+// load returns the cached describe-key response for config.
+func (c *describeKeyCache) load(config map[string]string) (*plugin.DescribeKeyResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key, err := describeKeyCacheKey(config)
+	if err != nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.responses[key]
+	if !ok {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// store caches the describe-key response for config.
+func (c *describeKeyCache) store(config map[string]string, resp *plugin.DescribeKeyResponse) {
+	if c == nil {
+		return
+	}
+	key, err := describeKeyCacheKey(config)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = *resp
+}
+
+// describeKeyCacheKey returns the cache key of config.
+// Maps are marshaled with sorted keys, so equal configs have equal keys.
+func describeKeyCacheKey(config map[string]string) (string, error) {
+	data, err := json.Marshal(config)
+	return string(data), err
+}
+
+func (s *pluginSigner) generateSignature(ctx context.Context, contractVersion string, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
 	config := s.mergeConfig(opts.PluginConfig)
 	// Get key info.
-	key, err := s.describeKey(ctx, config)
+	key, err := s.describeKey(ctx, contractVersion, config)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check keyID is honored.
 	if s.keyID != key.KeyID {
-		return nil, fmt.Errorf("keyID in describeKey response %q does not match request %q", key.KeyID, s.keyID)
+		return nil, notation.Errorf(notation.ErrorCodeKeyIDMismatch, "keyID in describeKey response %q does not match request %q", key.KeyID, s.keyID)
 	}
 
 	// Get algorithm associated to key.
 	alg := key.KeySpec.SignatureAlgorithm()
 	if alg == "" {
-		return nil, fmt.Errorf("keySpec %q for key %q is not supported", key.KeySpec, key.KeyID)
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q for key %q is not supported", key.KeySpec, key.KeyID)
+	}
+	if err := notation.CheckFIPSKeySpec(key.KeySpec); err != nil {
+		return nil, err
+	}
+	if opts.EnvelopeType == notation.MediaTypeCOSEEnvelope {
+		return s.generateCOSESignature(ctx, contractVersion, desc, opts, key, config)
 	}
 
 	// Generate payload to be signed.
 	payload := packPayload(desc, opts)
-	if err := payload.Valid(); err != nil {
+	if err := validateClaims(payload, payload.IssuedAt.Time); err != nil {
 		return nil, err
 	}
 
 	// Generate signing string.
-	token := jwtToken(alg.JWS(), payload)
+	token := jwtToken(alg.JWS(), opts, payload)
 	payloadToSign, err := token.SigningString()
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal signing payload: %v", err)
 	}
+	if opts.DryRun {
+		return nil, s.dryRun(ctx, desc, opts, key, payload.IssuedAt.Time, []byte(payloadToSign))
+	}
+
+	signature, certs, err := s.runGenerateSignature(ctx, contractVersion, key, config, []byte(payloadToSign))
+	if err != nil {
+		return nil, err
+	}
+	signed64Url := base64.RawURLEncoding.EncodeToString(signature)
+
+	// Assemble the JWS signature envelope.
+	return jwsEnvelope(ctx, opts, payloadToSign+"."+signed64Url, rawCertChain(certs))
+}
+
+// generateCOSESignature signs desc with key through the generate-signature
+// command of the plugin, and assembles the COSE envelope from the raw
+// signature of its Sig_structure.
+func (s *pluginSigner) generateCOSESignature(ctx context.Context, contractVersion string, desc notation.Descriptor, opts notation.SignOptions, key *plugin.DescribeKeyResponse, config map[string]string) ([]byte, error) {
+	signedAt := signingTime(opts)
+	msg, err := cose.NewMessage(key.KeySpec.SignatureAlgorithm(), desc, signedAt, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return nil, s.dryRun(ctx, desc, opts, key, signedAt, msg.SigningInput())
+	}
+	signature, certs, err := s.runGenerateSignature(ctx, contractVersion, key, config, msg.SigningInput())
+	if err != nil {
+		return nil, err
+	}
+	return msg.Envelope(signature, certs)
+}
 
+// runGenerateSignature runs the generate-signature command of the plugin to
+// sign payload with key, and validates the response.
+// ECDSA signatures are returned in the fixed-size R||S form.
+func (s *pluginSigner) runGenerateSignature(ctx context.Context, contractVersion string, key *plugin.DescribeKeyResponse, config map[string]string, payload []byte) ([]byte, []*x509.Certificate, error) {
 	// Execute plugin sign command.
+	alg := key.KeySpec.SignatureAlgorithm()
 	req := &plugin.GenerateSignatureRequest{
-		ContractVersion: plugin.ContractVersion,
+		ContractVersion: contractVersion,
 		KeyID:           s.keyID,
 		KeySpec:         key.KeySpec,
 		Hash:            alg.Hash(),
-		Payload:         []byte(payloadToSign),
+		Payload:         payload,
 		PluginConfig:    config,
+
+		SupportedSigningAlgorithms: supportedSigningAlgorithms(),
 	}
 	out, err := s.runner.Run(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("generate-signature command failed: %w", err)
+		return nil, nil, notation.Errorf(notation.ErrorCodePluginFailed, "generate-signature command failed: %w", err)
 	}
 	resp, ok := out.(*plugin.GenerateSignatureResponse)
 	if !ok {
-		return nil, fmt.Errorf("plugin runner returned incorrect generate-signature response type '%T'", out)
+		return nil, nil, fmt.Errorf("plugin runner returned incorrect generate-signature response type '%T'", out)
 	}
 
 	// Check keyID is honored.
 	if s.keyID != resp.KeyID {
-		return nil, fmt.Errorf("keyID in generateSignature response %q does not match request %q", resp.KeyID, s.keyID)
+		return nil, nil, notation.Errorf(notation.ErrorCodeKeyIDMismatch, "keyID in generateSignature response %q does not match request %q", resp.KeyID, s.keyID)
 	}
 
 	// Check algorithm is supported.
 	jwsAlg := resp.SigningAlgorithm.JWS()
 	if jwsAlg == "" {
-		return nil, fmt.Errorf("signing algorithm %q in generateSignature response is not supported", resp.SigningAlgorithm)
+		return nil, nil, notation.Errorf(notation.ErrorCodeUnsupportedSigningAlgorithm, "signing algorithm %q in generateSignature response is not supported", resp.SigningAlgorithm)
+	}
+
+	// Check the payload was hashed as required by the key spec, since the
+	// signature would not be verified otherwise.
+	if resp.Hash != req.Hash {
+		return nil, nil, notation.Errorf(notation.ErrorCodeHashMismatch, "hash algorithm %q in generateSignature response does not match %q required by keySpec %q", resp.Hash, req.Hash, key.KeySpec)
 	}
 
 	// Check certificate chain is not empty.
 	if len(resp.CertificateChain) == 0 {
-		return nil, errors.New("generateSignature response has empty certificate chain")
+		return nil, nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "generateSignature response has empty certificate chain")
 	}
 
-	certs, err := parseCertChain(resp.CertificateChain)
+	certs, err := s.validateCertChain(ctx, resp.CertificateChain, key, "generateSignature response.CertificateChain")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if resp.SigningAlgorithm != alg {
+		return nil, nil, notation.Errorf(notation.ErrorCodeKeySpecMismatch, "signing algorithm %q in generateSignature response does not match keySpec %q", resp.SigningAlgorithm, key.KeySpec)
+	}
+
+	// ECDSA signatures are commonly returned ASN.1 DER encoded,
+	// while JWS requires the fixed-size R||S form.
+	signature := resp.Signature
+	if key, ok := certs[0].PublicKey.(*ecdsa.PublicKey); ok {
+		if signature, err = jwsECDSASignature(signature, key); err != nil {
+			return nil, nil, notation.Errorf(notation.ErrorCodeInvalidSignature, "signature returned by generateSignature cannot be decoded: %w", err)
+		}
 	}
 
 	// Verify the hash of the request payload against the response signature
 	// using the public key of the signing certificate.
 	// At this point, resp.Signature is not base64-encoded,
 	// but verifyJWT expects a base64URL encoded string.
-	signed64Url := base64.RawURLEncoding.EncodeToString(resp.Signature)
-	err = verifyJWT(jwsAlg, payloadToSign, signed64Url, certs[0])
+	err = verifyJWT(jwsAlg, string(payload), base64.RawURLEncoding.EncodeToString(signature), certs[0])
+	if err != nil {
+		return nil, nil, notation.Errorf(notation.ErrorCodeInvalidSignature, "signature returned by generateSignature cannot be verified: %v", err)
+	}
+	return signature, certs, nil
+}
+
+// supportedSigningAlgorithms returns the signing algorithms supported by the
+// library, restricted to the FIPS approved ones in FIPS mode.
+func supportedSigningAlgorithms() []notation.SignatureAlgorithm {
+	var algs []notation.SignatureAlgorithm
+	for _, info := range notation.SignatureAlgorithms() {
+		if notation.CheckFIPSKeySpec(info.KeySpec) == nil {
+			algs = append(algs, info.Algorithm)
+		}
+	}
+	return algs
+}
+
+// validateCertChain parses the certificate chain of key found in source,
+// completes it, and checks it matches the key spec of key and conforms to
+// the certificate policy.
+func (s *pluginSigner) validateCertChain(ctx context.Context, rawCerts [][]byte, key *plugin.DescribeKeyResponse, source string) ([]*x509.Certificate, error) {
+	certs, err := parseCertChain(rawCerts)
 	if err != nil {
-		return nil, fmt.Errorf("signature returned by generateSignature cannot be verified: %v", err)
+		return nil, err
+	}
+	// Plugins may return the chain out of order or with extra certificates.
+	if certs, err = certpolicy.BuildChain(certs); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "certificate chain in %s is invalid: %w", source, err)
+	}
+	certs = s.aiaFetcher.Complete(ctx, certs)
+
+	// Check the signing certificate matches the key spec.
+	certKeySpec, err := notation.KeySpecFromKey(certs[0].PublicKey)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate in %s is not supported: %w", source, err)
+	}
+	if certKeySpec != key.KeySpec {
+		return nil, notation.Errorf(notation.ErrorCodeKeySpecMismatch, "keySpec %q of the signing certificate does not match keySpec %q of key %q", certKeySpec, key.KeySpec, key.KeyID)
 	}
 
 	// Check the the certificate chain conforms to the spec.
-	if err := verifyCertExtKeyUsage(certs[0], x509.ExtKeyUsageCodeSigning); err != nil {
-		return nil, fmt.Errorf("signing certificate in generateSignature response.CertificateChain does not meet the minimum requirements: %w", err)
+	if err := s.certPolicy.ValidateChain(certs); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate in %s does not meet the minimum requirements: %w", source, err)
 	}
+	if err := notation.CheckFIPSCertificateChain(certs); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
 
-	// Assemble the JWS signature envelope.
-	return jwsEnvelope(ctx, opts, payloadToSign+"."+signed64Url, resp.CertificateChain)
+// dryRun validates the certificate chain of key if the plugin knows it
+// without signing, and reports what would be signed to opts.OnDryRun.
+func (s *pluginSigner) dryRun(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions, key *plugin.DescribeKeyResponse, signingTime time.Time, signingInput []byte) error {
+	report := notation.DryRunReport{
+		Descriptor:         desc,
+		KeyID:              s.keyID,
+		KeySpec:            key.KeySpec,
+		SignatureAlgorithm: key.KeySpec.SignatureAlgorithm(),
+		SigningScheme:      opts.SigningScheme,
+		SigningTime:        signingTime,
+		Expiry:             opts.Expiry,
+		SigningInput:       signingInput,
+	}
+	if provider, ok := s.runner.(CertificateChainProvider); ok {
+		rawCerts, err := provider.CertificateChain(ctx, s.keyID)
+		if err != nil {
+			return notation.Errorf(notation.ErrorCodePluginFailed, "failed to get the certificate chain of key %q: %w", s.keyID, err)
+		}
+		if len(rawCerts) == 0 {
+			return notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "empty certificate chain of key %q", s.keyID)
+		}
+		if report.CertificateChain, err = s.validateCertChain(ctx, rawCerts, key, "the certificate chain of the key"); err != nil {
+			return err
+		}
+	}
+	log.GetLogger(ctx).Infof("dry run: %s would be signed with key %q", desc.Digest, s.keyID)
+	if opts.OnDryRun != nil {
+		opts.OnDryRun(report)
+	}
+	return nil
 }
 
 func (s *pluginSigner) mergeConfig(config map[string]string) map[string]string {
@@ -189,24 +480,43 @@ func (s *pluginSigner) mergeConfig(config map[string]string) map[string]string {
 	return c
 }
 
-func (s *pluginSigner) generateSignatureEnvelope(ctx context.Context, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+func (s *pluginSigner) generateSignatureEnvelope(ctx context.Context, contractVersion string, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	// The generate-envelope command has no way to convey extended signed attributes.
+	if len(opts.ExtendedSignedAttributes) > 0 {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "extended signed attributes are not supported by plugins generating signature envelopes")
+	}
+	// The envelope is built by the plugin when signing, so nothing more is
+	// known without signing.
+	if opts.DryRun {
+		log.GetLogger(ctx).Infof("dry run: %s would be signed with key %q", desc.Digest, s.keyID)
+		if opts.OnDryRun != nil {
+			opts.OnDryRun(notation.DryRunReport{
+				Descriptor:    desc,
+				KeyID:         s.keyID,
+				SigningScheme: opts.SigningScheme,
+				SigningTime:   signingTime(opts),
+				Expiry:        opts.Expiry,
+			})
+		}
+		return nil, nil
+	}
 	rawDesc, err := json.Marshal(desc)
 	if err != nil {
 		return nil, err
 	}
 	// Execute plugin sign command.
 	req := &plugin.GenerateEnvelopeRequest{
-		ContractVersion:       plugin.ContractVersion,
+		ContractVersion:       contractVersion,
 		KeyID:                 s.keyID,
 		Payload:               rawDesc,
 		SignatureEnvelopeType: notation.MediaTypeJWSEnvelope,
 		// TODO: Update payload type once https://github.com/notaryproject/notaryproject/pull/158 is approved.
-		PayloadType:  notation.MediaTypePayload,
+		PayloadType:  payloadContentType(opts),
 		PluginConfig: s.mergeConfig(opts.PluginConfig),
 	}
 	out, err := s.runner.Run(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("generate-envelope command failed: %w", err)
+		return nil, notation.Errorf(notation.ErrorCodePluginFailed, "generate-envelope command failed: %w", err)
 	}
 	resp, ok := out.(*plugin.GenerateEnvelopeResponse)
 	if !ok {
@@ -229,26 +539,54 @@ func (s *pluginSigner) generateSignatureEnvelope(ctx context.Context, desc notat
 		len(envelope.Signature) == 0 ||
 		len(envelope.Header.CertChain) == 0 {
 
-		return nil, errors.New("envelope content does not match envelope format")
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope content does not match envelope format")
 	}
 
 	// Check algorithm is supported.
 	var protected notation.JWSProtectedHeader
 	if err = decodeBase64URLJSON(envelope.Protected, &protected); err != nil {
-		return nil, fmt.Errorf("envelope protected header can't be decoded: %w", err)
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope protected header can't be decoded: %w", err)
 	}
 	if notation.NewSignatureAlgorithmJWS(protected.Algorithm) == "" {
-		return nil, fmt.Errorf("signing algorithm %q not supported", protected.Algorithm)
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedSigningAlgorithm, "signing algorithm %q not supported", protected.Algorithm)
+	}
+
+	// Check payload content type is honored.
+	if protected.ContentType != req.PayloadType {
+		return nil, fmt.Errorf("payload content type %q of the envelope does not match requested payload content type %q", protected.ContentType, req.PayloadType)
+	}
+
+	// Check signing scheme is honored.
+	var header map[string]interface{}
+	if err = decodeBase64URLJSON(envelope.Protected, &header); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope protected header can't be decoded: %w", err)
+	}
+	attrs, err := parseSignedAttributes(header)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope signed attributes are invalid: %w", err)
+	}
+	if attrs.signingScheme != opts.SigningScheme {
+		return nil, fmt.Errorf("signing scheme %q of the envelope does not match requested signing scheme %q", attrs.signingScheme, opts.SigningScheme)
+	}
+
+	// Check the protected header conforms to the spec, as verifiers would
+	// reject the envelope otherwise.
+	crit, err := criticalHeaders(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateProtectedHeader(header, crit, attrs, []string{req.PayloadType}); err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeMalformedSignature, err)
 	}
 
 	// Check descriptor subject is honored.
 	var payload notation.JWSPayload
 	err = decodeBase64URLJSON(envelope.Payload, &payload)
 	if err != nil {
-		return nil, fmt.Errorf("envelope payload can't be decoded: %w", err)
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope payload can't be decoded: %w", err)
 	}
 	if !descriptorPartialEqual(desc, payload.Subject) {
-		return nil, errors.New("descriptor subject has changed")
+		return nil, notation.Errorf(notation.ErrorCodeInvalidSignature, "descriptor subject has changed")
 	}
 
 	// Check signatureEnvelope can be verified against signing certificate.
@@ -256,16 +594,53 @@ func (s *pluginSigner) generateSignatureEnvelope(ctx context.Context, desc notat
 	if err != nil {
 		return nil, err
 	}
+	if certs, err = certpolicy.BuildChain(certs); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "certificate chain in generateEnvelope response is invalid: %w", err)
+	}
+	certs = s.aiaFetcher.Complete(ctx, certs)
+	certKeySpec, err := notation.KeySpecFromKey(certs[0].PublicKey)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate is not supported: %w", err)
+	}
+	if certKeySpec.SignatureAlgorithm().JWS() != protected.Algorithm {
+		return nil, notation.Errorf(notation.ErrorCodeKeySpecMismatch, "signing algorithm %q does not match keySpec %q of the signing certificate", protected.Algorithm, certKeySpec)
+	}
 	err = verifyJWT(protected.Algorithm, envelope.Protected+"."+envelope.Payload, envelope.Signature, certs[0])
 	if err != nil {
 		return nil, err
 	}
 
 	// Check the the certificate chain conforms to the spec.
-	if err := verifyCertExtKeyUsage(certs[0], x509.ExtKeyUsageCodeSigning); err != nil {
-		return nil, fmt.Errorf("signing certificate does not meet the minimum requirements: %w", err)
+	if err := s.certPolicy.ValidateChain(certs); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate does not meet the minimum requirements: %w", err)
+	}
+	if err := notation.CheckFIPSKeySpec(certKeySpec); err != nil {
+		return nil, err
 	}
-	return resp.SignatureEnvelope, nil
+	if err := notation.CheckFIPSCertificateChain(certs); err != nil {
+		return nil, err
+	}
+	// The certificate chain and the signing agent are in the unprotected
+	// header, so that they can be updated without invalidating the signature.
+	var updated bool
+	if certChain := rawCertChain(certs); !reflect.DeepEqual(certChain, envelope.Header.CertChain) {
+		envelope.Header.CertChain = certChain
+		updated = true
+	}
+	if opts.Deterministic {
+		// the envelope is re-encoded without the headers which are not
+		// reproducible
+		envelope.Header.SigningAgent = ""
+		envelope.Header.TimeStampToken = nil
+		updated = true
+	} else if envelope.Header.SigningAgent == "" && opts.SigningAgent != "" {
+		envelope.Header.SigningAgent = opts.SigningAgent
+		updated = true
+	}
+	if !updated {
+		return resp.SignatureEnvelope, nil
+	}
+	return json.Marshal(envelope)
 }
 
 // descriptorPartialEqual checks if the both descriptors point to the same resource
@@ -291,6 +666,15 @@ func decodeBase64URLJSON(str string, v interface{}) error {
 	return json.Unmarshal(dec, v)
 }
 
+// rawCertChain returns the DER encoding of certs.
+func rawCertChain(certs []*x509.Certificate) [][]byte {
+	raw := make([][]byte, len(certs))
+	for i, cert := range certs {
+		raw[i] = cert.Raw
+	}
+	return raw
+}
+
 func parseCertChain(certChain [][]byte) ([]*x509.Certificate, error) {
 	certs := make([]*x509.Certificate, len(certChain))
 	for i, cert := range certChain {
@@ -303,8 +687,34 @@ func parseCertChain(certChain [][]byte) ([]*x509.Certificate, error) {
 	return certs, nil
 }
 
+// jwsECDSASignature converts an ASN.1 DER encoded ECDSA signature to the
+// fixed-size R||S form defined in RFC 7518 section 3.4.
+// Signatures already in the fixed-size form are returned as is.
+func jwsECDSASignature(sig []byte, key *ecdsa.PublicKey) ([]byte, error) {
+	size := (key.Curve.Params().BitSize + 7) / 8
+	if len(sig) == 2*size {
+		return sig, nil
+	}
+	var esig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(sig, &esig); err != nil || len(rest) != 0 {
+		return nil, errors.New("malformed ECDSA signature")
+	}
+	if esig.R.Sign() <= 0 || esig.S.Sign() <= 0 || esig.R.BitLen() > 8*size || esig.S.BitLen() > 8*size {
+		return nil, errors.New("malformed ECDSA signature")
+	}
+	out := make([]byte, 2*size)
+	esig.R.FillBytes(out[:size])
+	esig.S.FillBytes(out[size:])
+	return out, nil
+}
+
 func verifyJWT(sigAlg string, payload string, sig string, signingCert *x509.Certificate) error {
 	// Verify the hash of req.payload against resp.signature using the public key in the leaf certificate.
-	method := jwt.GetSigningMethod(sigAlg)
+	method := signingMethod(notation.NewSignatureAlgorithmJWS(sigAlg))
+	if method == nil {
+		return notation.Errorf(notation.ErrorCodeUnsupportedSigningAlgorithm, "signing algorithm %q not supported", sigAlg)
+	}
 	return method.Verify(payload, sig, signingCert.PublicKey)
 }
@@ -2,6 +2,9 @@ package jws
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -17,9 +20,16 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/config"
 	"github.com/notaryproject/notation-go/plugin"
 )
 
+var testDescriptor = notation.Descriptor{
+	MediaType: notation.MediaTypePayload,
+	Digest:    "sha256:2f3a23b6373afb134ddcd864be8e037e34a662d090d33ee849471ff73c873345",
+	Size:      1,
+}
+
 var validMetadata = plugin.Metadata{
 	Name: "foo", Description: "friendly", Version: "1", URL: "example.com",
 	SupportedContractVersions: []string{plugin.ContractVersion},
@@ -42,7 +52,9 @@ type mockSignerPlugin struct {
 	KeySpec    notation.KeySpec
 	Sign       func(payload []byte) []byte
 	SigningAlg notation.SignatureAlgorithm
+	Hash       notation.HashAlgorithm // the requested hash if empty
 	Cert       []byte
+	Request    *plugin.GenerateSignatureRequest
 	n          int
 }
 
@@ -69,13 +81,19 @@ func (s *mockSignerPlugin) Run(ctx context.Context, req plugin.Request) (interfa
 	case 1:
 		return &plugin.DescribeKeyResponse{KeyID: s.KeyID, KeySpec: s.KeySpec}, nil
 	case 2:
+		s.Request = req.(*plugin.GenerateSignatureRequest)
 		var signed []byte
 		if s.Sign != nil {
-			signed = s.Sign(req.(*plugin.GenerateSignatureRequest).Payload)
+			signed = s.Sign(s.Request.Payload)
+		}
+		hash := s.Hash
+		if hash == "" {
+			hash = s.Request.Hash
 		}
 		return &plugin.GenerateSignatureResponse{
 			KeyID:            s.KeyID,
 			SigningAlgorithm: s.SigningAlg,
+			Hash:             hash,
 			Signature:        signed,
 			CertificateChain: chain,
 		}, nil
@@ -85,12 +103,22 @@ func (s *mockSignerPlugin) Run(ctx context.Context, req plugin.Request) (interfa
 
 func testSignerError(t *testing.T, signer pluginSigner, wantEr string) {
 	t.Helper()
-	_, err := signer.Sign(context.Background(), notation.Descriptor{}, notation.SignOptions{})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || !strings.Contains(err.Error(), wantEr) {
 		t.Errorf("Signer.Sign() error = %v, wantErr %v", err, wantEr)
 	}
 }
 
+func TestNewSignerPluginWithConfig(t *testing.T) {
+	if _, err := NewSignerPluginWithConfig(&mockRunner{}, "1", nil, &config.Config{SignatureFormat: config.SignatureFormatJWS}); err != nil {
+		t.Fatalf("NewSignerPluginWithConfig() error = %v", err)
+	}
+	_, err := NewSignerPluginWithConfig(&mockRunner{}, "1", nil, &config.Config{SignatureFormat: "cose"})
+	if !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("NewSignerPluginWithConfig() error = %v, want ErrInvalidArgument", err)
+	}
+}
+
 func TestSigner_Sign_RunMetadataFails(t *testing.T) {
 	signer := pluginSigner{
 		runner: &mockRunner{[]interface{}{nil}, []error{errors.New("failed")}, 0},
@@ -107,6 +135,51 @@ func TestSigner_Sign_NoCapability(t *testing.T) {
 	testSignerError(t, signer, "does not have signing capabilities")
 }
 
+// contractRunner returns its metadata and fails the describe-key requests,
+// recording their contract version.
+type contractRunner struct {
+	metadata        plugin.Metadata
+	contractVersion string
+}
+
+func (r *contractRunner) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	switch req := req.(type) {
+	case *plugin.GetMetadataRequest:
+		return &r.metadata, nil
+	case *plugin.DescribeKeyRequest:
+		r.contractVersion = req.ContractVersion
+	}
+	return nil, errors.New("failed")
+}
+
+func TestSigner_Sign_ContractVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+		wantErr  bool
+	}{
+		{"same version", []string{plugin.ContractVersion}, plugin.ContractVersion, false},
+		{"newer minor version", []string{"1.3"}, plugin.ContractVersion, false},
+		{"major version only", []string{"1"}, plugin.ContractVersion, false},
+		{"unsupported major version", []string{"2.0", "0.9"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &contractRunner{metadata: validMetadata}
+			runner.metadata.SupportedContractVersions = tt.versions
+			signer := pluginSigner{runner: runner, keyID: "1"}
+			_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+			if got := errors.Is(err, plugin.ErrUnsupportedContractVersion); got != tt.wantErr {
+				t.Fatalf("Signer.Sign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if runner.contractVersion != tt.want {
+				t.Errorf("Signer.Sign() sent contract version %q, want %q", runner.contractVersion, tt.want)
+			}
+		})
+	}
+}
+
 func TestSigner_Sign_DescribeKeyFailed(t *testing.T) {
 	signer := pluginSigner{
 		runner: &mockRunner{[]interface{}{&validMetadata, nil}, []error{nil, errors.New("failed")}, 0},
@@ -122,6 +195,48 @@ func TestSigner_Sign_DescribeKeyKeyIDMismatch(t *testing.T) {
 	testSignerError(t, signer, "keyID in describeKey response \"2\" does not match request \"1\"")
 }
 
+func TestSigner_Sign_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner plugin.Runner
+		want   *notation.Error
+	}{
+		{
+			name:   "describe-key failed",
+			runner: &mockRunner{[]interface{}{&validMetadata, nil}, []error{nil, errors.New("failed")}, 0},
+			want:   notation.ErrPluginFailed,
+		},
+		{
+			name:   "keyID mismatch",
+			runner: &mockSignerPlugin{KeyID: "2", KeySpec: notation.RSA_2048},
+			want:   notation.ErrKeyIDMismatch,
+		},
+		{
+			name:   "unsupported algorithm",
+			runner: &mockSignerPlugin{KeyID: "1", KeySpec: notation.RSA_2048, SigningAlg: "custom"},
+			want:   notation.ErrUnsupportedSigningAlgorithm,
+		},
+		{
+			name:   "empty certificate chain",
+			runner: &mockSignerPlugin{KeyID: "1", KeySpec: notation.RSA_2048, SigningAlg: notation.RSASSA_PSS_SHA_256},
+			want:   notation.ErrEmptyCertificateChain,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := pluginSigner{runner: tt.runner, keyID: "1"}
+			_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Signer.Sign() error = %v, want %v", err, tt.want)
+			}
+			var e *notation.Error
+			if !errors.As(err, &e) || e.Code != tt.want.Code {
+				t.Errorf("Signer.Sign() error code = %v, want %v", e, tt.want.Code)
+			}
+		})
+	}
+}
+
 func TestSigner_Sign_KeySpecNotSupported(t *testing.T) {
 	signer := pluginSigner{
 		runner: &mockSignerPlugin{KeyID: "1", KeySpec: "custom"},
@@ -138,7 +253,7 @@ func TestSigner_Sign_PayloadNotValid(t *testing.T) {
 		}, []error{nil, nil}, 0},
 		keyID: "1",
 	}
-	_, err := signer.Sign(context.Background(), notation.Descriptor{}, notation.SignOptions{Expiry: time.Now().Add(-100)})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{Expiry: time.Now().Add(-100)})
 	wantEr := "token is expired"
 	if err == nil || !strings.Contains(err.Error(), wantEr) {
 		t.Errorf("Signer.Sign() error = %v, wantErr %v", err, wantEr)
@@ -326,7 +441,7 @@ func TestSigner_Sign_Valid(t *testing.T) {
 		},
 		keyID: "1",
 	}
-	data, err := signer.Sign(context.Background(), notation.Descriptor{}, notation.SignOptions{})
+	data, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err != nil {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -336,13 +451,25 @@ func TestSigner_Sign_Valid(t *testing.T) {
 		t.Fatal(err)
 	}
 	want := notation.JWSEnvelope{
-		Protected: "eyJhbGciOiJQUzI1NiIsImN0eSI6ImFwcGxpY2F0aW9uL3ZuZC5jbmNmLm5vdGFyeS5wYXlsb2FkLnYxK2pzb24ifQ",
 		Header: notation.JWSUnprotectedHeader{
 			CertChain: [][]byte{cert.Raw},
 		},
 	}
-	if got.Protected != want.Protected {
-		t.Errorf("Signer.Sign() Protected %v, want %v", got.Protected, want.Protected)
+	var protected notation.JWSProtectedHeader
+	if err := decodeBase64URLJSON(got.Protected, &protected); err != nil {
+		t.Fatalf("Signer.Sign() Protected %v is not valid: %v", got.Protected, err)
+	}
+	if protected.Algorithm != "PS256" || protected.ContentType != notation.MediaTypePayload || protected.SigningScheme != notation.SigningSchemeX509 {
+		t.Errorf("Signer.Sign() Protected = %+v, want alg PS256, cty %q and signing scheme %q", protected, notation.MediaTypePayload, notation.SigningSchemeX509)
+	}
+	if protected.SigningTime == nil || time.Since(*protected.SigningTime) > time.Minute {
+		t.Errorf("Signer.Sign() Protected signing time = %v, want now", protected.SigningTime)
+	}
+	if protected.Expiry != nil {
+		t.Errorf("Signer.Sign() Protected expiry = %v, want none", protected.Expiry)
+	}
+	if want := []string{headerSigningScheme}; !reflect.DeepEqual(protected.Critical, want) {
+		t.Errorf("Signer.Sign() Protected crit = %v, want %v", protected.Critical, want)
 	}
 	if _, err = base64.RawURLEncoding.DecodeString(got.Signature); err != nil {
 		t.Errorf("Signer.Sign() Signature %v is not encoded as Base64URL", got.Signature)
@@ -359,18 +486,170 @@ func TestSigner_Sign_Valid(t *testing.T) {
 	}
 }
 
+// keyCountingPlugin serves signing requests by command, counting the
+// describe-key requests.
+type keyCountingPlugin struct {
+	sign         func([]byte) []byte
+	cert         []byte
+	chain        [][]byte
+	describeKeys int
+}
+
+// certChain returns chain if set, or the single certificate cert.
+func (p *keyCountingPlugin) certChain() [][]byte {
+	if p.chain != nil {
+		return p.chain
+	}
+	return [][]byte{p.cert}
+}
+
+func (p *keyCountingPlugin) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	switch req := req.(type) {
+	case *plugin.GetMetadataRequest:
+		return &validMetadata, nil
+	case *plugin.DescribeKeyRequest:
+		p.describeKeys++
+		return &plugin.DescribeKeyResponse{KeyID: req.KeyID, KeySpec: notation.RSA_2048}, nil
+	case *plugin.GenerateSignatureRequest:
+		return &plugin.GenerateSignatureResponse{
+			KeyID:            req.KeyID,
+			SigningAlgorithm: notation.RSASSA_PSS_SHA_256,
+			Hash:             req.Hash,
+			Signature:        p.sign(req.Payload),
+			CertificateChain: p.certChain(),
+		}, nil
+	}
+	return nil, errors.New("unexpected request")
+}
+
+func TestSigner_Sign_DescribeKeyCache(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opts SignerPluginOptions
+		want int
+	}{
+		{name: "cached", want: 2},
+		{name: "disabled", opts: SignerPluginOptions{DisableKeyCache: true}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &keyCountingPlugin{sign: validSign(t, key), cert: cert.Raw}
+			signer, err := NewSignerPluginWithOptions(runner, "1", nil, tt.opts)
+			if err != nil {
+				t.Fatalf("NewSignerPluginWithOptions() error = %v", err)
+			}
+			for _, opts := range []notation.SignOptions{
+				{},
+				{},
+				{PluginConfig: map[string]string{"region": "us-west-2"}},
+			} {
+				if _, err := signer.Sign(context.Background(), testDescriptor, opts); err != nil {
+					t.Fatalf("Signer.Sign() error = %v", err)
+				}
+			}
+			if runner.describeKeys != tt.want {
+				t.Errorf("describe-key ran %d times, want %d", runner.describeKeys, tt.want)
+			}
+		})
+	}
+}
+
+// generateCAChain generates a signing key with its certificate issued by a
+// root CA.
+func generateCAChain(t *testing.T) (*rsa.PrivateKey, *x509.Certificate, *x509.Certificate) {
+	t.Helper()
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "root"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}, root, &key.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, leaf, root
+}
+
+func TestSigner_Sign_UnorderedCertChain(t *testing.T) {
+	key, leaf, root := generateCAChain(t)
+	runner := &keyCountingPlugin{
+		sign:  validSign(t, key),
+		chain: [][]byte{root.Raw, leaf.Raw, root.Raw},
+	}
+	signer := pluginSigner{runner: runner, keyID: "1"}
+	data, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+	if err != nil {
+		t.Fatalf("Signer.Sign() error = %v", err)
+	}
+	var envelope notation.JWSEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]byte{leaf.Raw, root.Raw}; !reflect.DeepEqual(envelope.Header.CertChain, want) {
+		t.Errorf("Signer.Sign() certificate chain has %d certificates, want the ordered chain leaf, root", len(envelope.Header.CertChain))
+	}
+
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	v.VerifyOptions.Roots = roots
+	if _, err := v.Verify(context.Background(), data, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+}
+
 type mockEnvelopePlugin struct {
 	err          error
 	envelopeType string
 	certChain    [][]byte
 	key          interface{}
+
+	// header overrides the protected headers of the envelope, removing
+	// those set to nil.
+	header map[string]interface{}
 }
 
 func (s *mockEnvelopePlugin) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
 	switch req.Command() {
 	case plugin.CommandGetMetadata:
 		m := validMetadata
-		m.Capabilities[0] = plugin.CapabilityEnvelopeGenerator
+		m.Capabilities = []plugin.Capability{plugin.CapabilityEnvelopeGenerator}
 		return &m, nil
 	case plugin.CommandGenerateEnvelope:
 		if s.err != nil {
@@ -384,18 +663,29 @@ func (s *mockEnvelopePlugin) Run(ctx context.Context, req plugin.Request) (inter
 		if key == nil {
 			key = tmpkey
 		}
-		keySpec, err := keySpecFromKey(key)
+		keySpec, err := notation.KeySpecFromKey(key)
 		if err != nil {
 			return nil, err
 		}
 		alg := keySpec.SignatureAlgorithm().JWS()
 		req1 := req.(*plugin.GenerateEnvelopeRequest)
+		header := map[string]interface{}{
+			"alg":               alg,
+			"cty":               notation.MediaTypePayload,
+			"crit":              []string{headerSigningScheme},
+			headerSigningScheme: notation.SigningSchemeX509,
+			headerSigningTime:   time.Now().Format(time.RFC3339),
+		}
+		for name, value := range s.header {
+			if value == nil {
+				delete(header, name)
+			} else {
+				header[name] = value
+			}
+		}
 		t := &jwt.Token{
 			Method: jwt.GetSigningMethod(alg),
-			Header: map[string]interface{}{
-				"alg": alg,
-				"cty": notation.MediaTypePayload,
-			},
+			Header: header,
 			Claims: struct {
 				jwt.RegisteredClaims
 				Subject json.RawMessage `json:"subject"`
@@ -445,10 +735,7 @@ func TestPluginSigner_SignEnvelope_RunFailed(t *testing.T) {
 		runner: &mockEnvelopePlugin{err: errors.New("failed")},
 		keyID:  "1",
 	}
-	_, err := signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || err.Error() != "generate-envelope command failed: failed" {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -459,10 +746,7 @@ func TestPluginSigner_SignEnvelope_InvalidEnvelopeType(t *testing.T) {
 		runner: &mockEnvelopePlugin{envelopeType: "other"},
 		keyID:  "1",
 	}
-	_, err := signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || err.Error() != "signatureEnvelopeType in generateEnvelope response \"other\" does not match request \"application/vnd.cncf.notary.v2.jws.v1\"" {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -473,10 +757,7 @@ func TestPluginSigner_SignEnvelope_EmptyCert(t *testing.T) {
 		runner: &mockEnvelopePlugin{certChain: make([][]byte, 0)},
 		keyID:  "1",
 	}
-	_, err := signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || err.Error() != "envelope content does not match envelope format" {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -487,10 +768,7 @@ func TestPluginSigner_SignEnvelope_MalformedCertChain(t *testing.T) {
 		runner: &mockEnvelopePlugin{certChain: [][]byte{make([]byte, 0)}},
 		keyID:  "1",
 	}
-	_, err := signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || err.Error() != "x509: malformed certificate" {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -519,10 +797,7 @@ func TestPluginSigner_SignEnvelope_CertBasicConstraintCA(t *testing.T) {
 		},
 		keyID: "1",
 	}
-	_, err = signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err = signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || err.Error() != "signing certificate does not meet the minimum requirements: keyUsage must have the bit positions for digitalSignature set" {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -537,10 +812,7 @@ func TestPluginSigner_SignEnvelope_SignatureVerifyError(t *testing.T) {
 		runner: &mockEnvelopePlugin{key: key},
 		keyID:  "1",
 	}
-	_, err = signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err = signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err == nil || err.Error() != "crypto/rsa: verification error" {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
@@ -551,11 +823,368 @@ func TestPluginSigner_SignEnvelope_Valid(t *testing.T) {
 		runner: &mockEnvelopePlugin{},
 		keyID:  "1",
 	}
-	_, err := signer.Sign(context.Background(), notation.Descriptor{
-		MediaType: notation.MediaTypePayload,
-		Size:      1,
-	}, notation.SignOptions{})
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+	if err != nil {
+		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
+	}
+}
+
+func TestPluginSigner_SignEnvelope_InvalidProtectedHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  map[string]interface{}
+		wantErr string
+	}{
+		{"non-critical signing scheme", map[string]interface{}{"crit": nil}, "not listed as critical"},
+		{"missing signing time", map[string]interface{}{headerSigningTime: nil}, "is required by signing scheme"},
+		{"missing critical attribute", map[string]interface{}{"crit": []string{headerSigningScheme, "io.acme.missing"}}, "is missing from the protected header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := pluginSigner{
+				runner: &mockEnvelopePlugin{header: tt.header},
+				keyID:  "1",
+			}
+			_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+			if !errors.Is(err, notation.ErrMalformedSignature) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Signer.Sign() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPluginSigner_SignEnvelope_SigningAgent(t *testing.T) {
+	signer := pluginSigner{
+		runner: &mockEnvelopePlugin{},
+		keyID:  "1",
+	}
+	data, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{SigningAgent: "notation/1.0.0"})
+	if err != nil {
+		t.Fatalf("Signer.Sign() error = %v", err)
+	}
+	var envelope notation.JWSEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	if got := envelope.Header.SigningAgent; got != "notation/1.0.0" {
+		t.Errorf("Signer.Sign() SigningAgent = %q, want %q", got, "notation/1.0.0")
+	}
+}
+
+func TestPluginSigner_SignEnvelope_ExtendedSignedAttributes(t *testing.T) {
+	signer := pluginSigner{
+		runner: &mockEnvelopePlugin{},
+		keyID:  "1",
+	}
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{
+		ExtendedSignedAttributes: []notation.SignedAttribute{{Key: "foo", Value: "bar"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "extended signed attributes are not supported") {
+		t.Errorf("Signer.Sign() error = %v, want extended signed attributes not supported", err)
+	}
+}
+
+// configRecorder records the plugin config of the requests it receives.
+type configRecorder struct {
+	mockSignerPlugin
+	configs []map[string]string
+}
+
+func (r *configRecorder) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	switch req := req.(type) {
+	case *plugin.DescribeKeyRequest:
+		r.configs = append(r.configs, req.PluginConfig)
+	case *plugin.GenerateSignatureRequest:
+		r.configs = append(r.configs, req.PluginConfig)
+	}
+	return r.mockSignerPlugin.Run(ctx, req)
+}
+
+func TestPluginSigner_Sign_PluginConfig(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &configRecorder{mockSignerPlugin: mockSignerPlugin{
+		KeyID:      "1",
+		KeySpec:    notation.RSA_2048,
+		SigningAlg: notation.RSASSA_PSS_SHA_256,
+		Sign:       validSign(t, key),
+		Cert:       cert.Raw,
+	}}
+	signer, err := NewSignerPlugin(runner, "1", map[string]string{"region": "us-east-1", "profile": "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = signer.Sign(context.Background(), testDescriptor, notation.SignOptions{
+		PluginConfig: map[string]string{"region": "eu-west-1", "endpoint": "kms.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Signer.Sign() error = %v", err)
+	}
+	want := map[string]string{"region": "eu-west-1", "profile": "default", "endpoint": "kms.example.com"}
+	if len(runner.configs) != 2 {
+		t.Fatalf("plugin received %d requests with config, want 2", len(runner.configs))
+	}
+	for _, got := range runner.configs {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("PluginConfig = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestPluginSigner_SignEnvelope_PluginConfig(t *testing.T) {
+	var got map[string]string
+	runner := &envelopeConfigRecorder{config: &got}
+	signer, err := NewSignerPlugin(runner, "1", map[string]string{"a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = signer.Sign(context.Background(), testDescriptor, notation.SignOptions{PluginConfig: map[string]string{"b": "2"}})
+	if err != nil {
+		t.Fatalf("Signer.Sign() error = %v", err)
+	}
+	if want := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PluginConfig = %v, want %v", got, want)
+	}
+}
+
+// envelopeConfigRecorder records the plugin config of generate-envelope requests.
+type envelopeConfigRecorder struct {
+	mockEnvelopePlugin
+	config *map[string]string
+}
+
+func (r *envelopeConfigRecorder) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	if req, ok := req.(*plugin.GenerateEnvelopeRequest); ok {
+		*r.config = req.PluginConfig
+	}
+	return r.mockEnvelopePlugin.Run(ctx, req)
+}
+
+func TestSigner_Sign_EC(t *testing.T) {
+	tests := []struct {
+		keySpec notation.KeySpec
+		curve   elliptic.Curve
+	}{
+		{notation.EC_256, elliptic.P256()},
+		{notation.EC_384, elliptic.P384()},
+		{notation.EC_512, elliptic.P521()},
+	}
+	for _, tt := range tests {
+		key, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := generateCert(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		alg := tt.keySpec.SignatureAlgorithm()
+		signers := map[string]func([]byte) []byte{
+			"jws": func(payload []byte) []byte {
+				signed, err := jwt.GetSigningMethod(alg.JWS()).Sign(string(payload), key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				raw, err := base64.RawURLEncoding.DecodeString(signed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			},
+			"der": func(payload []byte) []byte {
+				h := alg.Hash().HashFunc().New()
+				h.Write(payload)
+				sig, err := key.Sign(rand.Reader, h.Sum(nil), alg.Hash().HashFunc())
+				if err != nil {
+					t.Fatal(err)
+				}
+				return sig
+			},
+		}
+		for name, sign := range signers {
+			t.Run(string(tt.keySpec)+"/"+name, func(t *testing.T) {
+				signer := pluginSigner{
+					runner: &mockSignerPlugin{
+						KeyID:      "1",
+						KeySpec:    tt.keySpec,
+						SigningAlg: alg,
+						Sign:       sign,
+						Cert:       cert.Raw,
+					},
+					keyID: "1",
+				}
+				data, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+				if err != nil {
+					t.Fatalf("Signer.Sign() error = %v", err)
+				}
+				v := NewVerifier()
+				roots := x509.NewCertPool()
+				roots.AddCert(cert)
+				v.VerifyOptions.Roots = roots
+				if _, err := v.Verify(context.Background(), data, notation.VerifyOptions{}); err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestSigner_Sign_KeySpecMismatch(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := pluginSigner{
+		runner: &mockSignerPlugin{
+			KeyID:      "1",
+			KeySpec:    notation.EC_256,
+			SigningAlg: notation.ECDSA_SHA_256,
+			Sign:       validSign(t, key),
+			Cert:       cert.Raw,
+		},
+		keyID: "1",
+	}
+	testSignerError(t, signer, "keySpec \"RSA_2048\" of the signing certificate does not match keySpec \"EC_256\" of key \"1\"")
+}
+
+func TestSigner_Sign_SigningAlgorithmMismatch(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := pluginSigner{
+		runner: &mockSignerPlugin{
+			KeyID:      "1",
+			KeySpec:    notation.RSA_2048,
+			SigningAlg: notation.RSASSA_PSS_SHA_512,
+			Sign:       validSign(t, key),
+			Cert:       cert.Raw,
+		},
+		keyID: "1",
+	}
+	testSignerError(t, signer, "signing algorithm \"RSASSA_PSS_SHA_512\" in generateSignature response does not match keySpec \"RSA_2048\"")
+}
+
+func TestSigner_Sign_HashNegotiation(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &mockSignerPlugin{
+		KeyID:      "1",
+		KeySpec:    notation.RSA_2048,
+		SigningAlg: notation.RSASSA_PSS_SHA_256,
+		Hash:       notation.SHA256,
+		Sign:       validSign(t, key),
+		Cert:       cert.Raw,
+	}
+	signer := pluginSigner{runner: runner, keyID: "1"}
+	if _, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{}); err != nil {
+		t.Fatalf("Signer.Sign() error = %v", err)
+	}
+	if runner.Request.Hash != notation.SHA256 {
+		t.Errorf("GenerateSignatureRequest.Hash = %v, want %v", runner.Request.Hash, notation.SHA256)
+	}
+	if got, want := len(runner.Request.SupportedSigningAlgorithms), len(notation.SignatureAlgorithms()); got != want {
+		t.Errorf("GenerateSignatureRequest.SupportedSigningAlgorithms = %v, want %d algorithms", runner.Request.SupportedSigningAlgorithms, want)
+	}
+
+	// the supported algorithms are restricted in FIPS mode
+	notation.SetFIPSMode(true)
+	defer notation.SetFIPSMode(false)
+	for _, alg := range supportedSigningAlgorithms() {
+		if alg == notation.EDDSA_ED25519 {
+			t.Errorf("supportedSigningAlgorithms() = %v, want no %v in FIPS mode", supportedSigningAlgorithms(), alg)
+		}
+	}
+}
+
+func TestSigner_Sign_HashMismatch(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := pluginSigner{
+		runner: &mockSignerPlugin{
+			KeyID:      "1",
+			KeySpec:    notation.RSA_2048,
+			SigningAlg: notation.RSASSA_PSS_SHA_256,
+			Hash:       notation.SHA512,
+			Sign:       validSign(t, key),
+			Cert:       cert.Raw,
+		},
+		keyID: "1",
+	}
+	_, err = signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+	if !errors.Is(err, notation.ErrHashMismatch) {
+		t.Errorf("Signer.Sign() error = %v, want %v", err, notation.ErrHashMismatch)
+	}
+}
+
+func TestSigner_Sign_MissingHash(t *testing.T) {
+	signer := pluginSigner{
+		runner: &mockRunner{[]interface{}{
+			&validMetadata,
+			&plugin.DescribeKeyResponse{KeyID: "1", KeySpec: notation.RSA_2048},
+			&plugin.GenerateSignatureResponse{KeyID: "1", SigningAlgorithm: notation.RSASSA_PSS_SHA_256},
+		}, []error{nil, nil, nil}, 0},
+		keyID: "1",
+	}
+	_, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+	if !errors.Is(err, notation.ErrHashMismatch) {
+		t.Errorf("Signer.Sign() error = %v, want %v", err, notation.ErrHashMismatch)
+	}
+}
+
+func TestPluginSigner_SignEnvelope_EC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := generateCert(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := pluginSigner{
+		runner: &mockEnvelopePlugin{key: key, certChain: [][]byte{cert.Raw}},
+		keyID:  "1",
+	}
+	_, err = signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
 	if err != nil {
 		t.Errorf("Signer.Sign() error = %v, wantErr nil", err)
 	}
 }
+
+func TestSigner_Sign_Ed25519(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := generateCert(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := pluginSigner{
+		runner: &mockSignerPlugin{
+			KeyID:      "1",
+			KeySpec:    notation.ED25519,
+			SigningAlg: notation.EDDSA_ED25519,
+			Sign:       func(payload []byte) []byte { return ed25519.Sign(key, payload) },
+			Cert:       cert.Raw,
+		},
+		keyID: "1",
+	}
+	data, err := signer.Sign(context.Background(), testDescriptor, notation.SignOptions{})
+	if err != nil {
+		t.Fatalf("Signer.Sign() error = %v", err)
+	}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	if _, err := v.Verify(context.Background(), data, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+}
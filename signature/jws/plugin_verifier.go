@@ -0,0 +1,269 @@
+package jws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/event"
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+// Protected header names used to declare the verification plugin of a signature.
+const (
+	headerVerificationPlugin           = "io.cncf.notary.verificationPlugin"
+	headerVerificationPluginMinVersion = "io.cncf.notary.verificationPluginMinVersion"
+)
+
+// processedHeaders lists the protected headers understood by the verifier,
+// which are never forwarded to the verification plugin.
+var processedHeaders = []string{
+	"alg",
+	"cty",
+	"crit",
+	headerSigningScheme,
+	headerSigningTime,
+	headerAuthenticSigningTime,
+	headerExpiry,
+	headerVerificationPlugin,
+	headerVerificationPluginMinVersion,
+}
+
+// PluginManager resolves plugins by name.
+type PluginManager interface {
+	// Runner returns a plugin.Runner for the named plugin.
+	Runner(name string) (plugin.Runner, error)
+}
+
+// checksByCapability maps the plugin verification capabilities
+// to the checks they perform.
+var checksByCapability = map[plugin.Capability]notation.VerificationCheck{
+	plugin.CapabilityTrustedIdentityVerifier: notation.CheckTrustedIdentity,
+	plugin.CapabilityRevocationCheckVerifier: notation.CheckRevocation,
+}
+
+// verifyWithPlugin invokes the verification plugin declared in the protected
+// header of the signature, if any, and records its verification results.
+// It fails if the plugin cannot be invoked or if any critical extended
+// attribute is left unprocessed.
+func (v *Verifier) verifyWithPlugin(ctx context.Context, outcome *notation.VerificationOutcome, envelope *notation.JWSEnvelope, claims notaryClaim) error {
+	var header map[string]interface{}
+	if err := decodeBase64URLJSON(envelope.Protected, &header); err != nil {
+		return notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope protected header can't be decoded: %w", err)
+	}
+	crit, err := criticalHeaders(header)
+	if err != nil {
+		return err
+	}
+	var unprocessed []string
+	extendedAttributes := make(map[string]interface{})
+	for _, name := range crit {
+		if isPresent(name, processedHeaders) {
+			continue
+		}
+		unprocessed = append(unprocessed, name)
+		extendedAttributes[name] = header[name]
+	}
+
+	pluginName, _ := header[headerVerificationPlugin].(string)
+	if pluginName == "" {
+		if len(unprocessed) > 0 {
+			return notation.Errorf(notation.ErrorCodeUnsupportedCriticalAttribute, "signature has unsupported critical attributes %q", unprocessed)
+		}
+		return nil
+	}
+	var runner plugin.Runner
+	if v.PluginManager != nil {
+		runner, err = v.PluginManager.Runner(pluginName)
+	} else {
+		err = errors.New("no plugin manager configured")
+	}
+	if err != nil {
+		if v.RequireVerificationPlugin || len(unprocessed) > 0 {
+			return notation.Errorf(notation.ErrorCodePluginFailed, "verification plugin %q declared by the signature is not available: %w", pluginName, err)
+		}
+		return nil
+	}
+
+	verifyCtx, cancel := v.phaseContext(ctx, PhaseVerification)
+	defer cancel()
+	metadata, contractVersion, err := verificationPluginMetadata(verifyCtx, runner)
+	if err != nil {
+		return notation.Errorf(notation.ErrorCodePluginFailed, "verification plugin %q: %w", pluginName, err)
+	}
+	if minVersion, _ := header[headerVerificationPluginMinVersion].(string); minVersion != "" {
+		if plugin.CompareVersion(metadata.Version, minVersion) < 0 {
+			return notation.Errorf(notation.ErrorCodePluginFailed, "verification plugin %q version %s is lower than the minimum version %s required by the signature", pluginName, metadata.Version, minVersion)
+		}
+	}
+	var capabilities []plugin.Capability
+	for _, c := range []plugin.Capability{plugin.CapabilityTrustedIdentityVerifier, plugin.CapabilityRevocationCheckVerifier} {
+		if metadata.HasCapability(c) {
+			capabilities = append(capabilities, c)
+		}
+	}
+	// the revocation check is bounded separately if the phases are bounded
+	var checkRevocation bool
+	if v.PhaseContext != nil && metadata.HasCapability(plugin.CapabilityRevocationCheckVerifier) {
+		capabilities = capabilities[:len(capabilities)-1]
+		checkRevocation = true
+	}
+
+	cty, _ := header["cty"].(string)
+	req := &plugin.VerifySignatureRequest{
+		ContractVersion: contractVersion,
+		Signature: plugin.Signature{
+			CriticalAttributes: plugin.CriticalAttributes{
+				ContentType:        cty,
+				SigningScheme:      string(outcome.SigningScheme),
+				ExtendedAttributes: extendedAttributes,
+			},
+			UnprocessedAttributes: unprocessed,
+			CertificateChain:      envelope.Header.CertChain,
+		},
+		TrustPolicy: plugin.TrustPolicy{
+			TrustedIdentities:     v.TrustedIdentities,
+			SignatureVerification: capabilities,
+		},
+		PluginConfig: v.PluginConfig,
+	}
+	if claims.ExpiresAt != nil {
+		expiry := claims.ExpiresAt.Time.UTC().Truncate(time.Second)
+		req.Signature.CriticalAttributes.Expiry = &expiry
+	}
+	if !outcome.AuthenticSigningTime.IsZero() {
+		signingTime := outcome.AuthenticSigningTime.UTC()
+		req.Signature.CriticalAttributes.AuthenticSigningTime = &signingTime
+	}
+	if len(capabilities) > 0 || len(unprocessed) > 0 || !checkRevocation {
+		resp, err := runVerifySignature(verifyCtx, runner, pluginName, req, outcome)
+		if err != nil {
+			return err
+		}
+		var left []string
+		for _, name := range unprocessed {
+			if !isPresent(name, resp.ProcessedAttributes) {
+				left = append(left, name)
+			}
+		}
+		if len(left) > 0 {
+			return notation.Errorf(notation.ErrorCodeUnsupportedCriticalAttribute, "critical attributes %q were not processed by verification plugin %q", left, pluginName)
+		}
+	}
+	if checkRevocation {
+		revocationCtx, cancel := v.phaseContext(ctx, PhaseRevocation)
+		defer cancel()
+		revocationReq := *req
+		revocationReq.Signature.UnprocessedAttributes = nil
+		revocationReq.TrustPolicy.SignatureVerification = []plugin.Capability{plugin.CapabilityRevocationCheckVerifier}
+		if _, err := runVerifySignature(revocationCtx, runner, pluginName, &revocationReq, outcome); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runVerifySignature runs the verify-signature command of the verification
+// plugin, and records the results of the capabilities requested by req.
+func runVerifySignature(ctx context.Context, runner plugin.Runner, pluginName string, req *plugin.VerifySignatureRequest, outcome *notation.VerificationOutcome) (_ *plugin.VerifySignatureResponse, err error) {
+	for _, c := range req.TrustPolicy.SignatureVerification {
+		if c != plugin.CapabilityRevocationCheckVerifier {
+			continue
+		}
+		event.Emit(ctx, event.Event{Type: event.RevocationCheckStarted, Artifact: outcome.Descriptor.Digest, Plugin: pluginName})
+		start := time.Now()
+		defer func() {
+			checkErr := err
+			if result := outcome.Result(notation.CheckRevocation); checkErr == nil && result != nil {
+				checkErr = result.Error
+			}
+			event.Emit(ctx, event.Event{Type: event.RevocationCheckFinished, Artifact: outcome.Descriptor.Digest, Plugin: pluginName, Duration: time.Since(start), Err: checkErr})
+		}()
+	}
+	out, err := runner.Run(ctx, req)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodePluginFailed, "verify-signature command of plugin %q failed: %w", pluginName, err)
+	}
+	resp, ok := out.(*plugin.VerifySignatureResponse)
+	if !ok {
+		return nil, fmt.Errorf("plugin runner returned incorrect verify-signature response type '%T'", out)
+	}
+
+	// Merge the plugin results.
+	capabilities := req.TrustPolicy.SignatureVerification
+	for _, c := range capabilities {
+		result := resp.VerificationResults[c]
+		if result == nil {
+			return nil, fmt.Errorf("verification plugin %q did not return a result for %s", pluginName, c)
+		}
+	}
+	for _, c := range capabilities {
+		result := resp.VerificationResults[c]
+		if !result.Success {
+			outcome.Fail(checksByCapability[c], notation.Errorf(notation.ErrorCodeUntrustedSigner, "verification plugin %q failed %s verification: %s", pluginName, c, result.Reason))
+		} else {
+			outcome.Pass(checksByCapability[c])
+		}
+	}
+	return resp, nil
+}
+
+// verificationPluginMetadata fetches and validates the metadata of a
+// verification plugin, and negotiates the contract version to use with it.
+func verificationPluginMetadata(ctx context.Context, runner plugin.Runner) (*plugin.Metadata, string, error) {
+	out, err := runner.Run(ctx, new(plugin.GetMetadataRequest))
+	if err != nil {
+		return nil, "", fmt.Errorf("metadata command failed: %w", err)
+	}
+	metadata, ok := out.(*plugin.Metadata)
+	if !ok {
+		return nil, "", fmt.Errorf("plugin runner returned incorrect get-plugin-metadata response type '%T'", out)
+	}
+	if err := metadata.Validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid plugin metadata: %w", err)
+	}
+	contractVersion, err := metadata.NegotiateContractVersion()
+	if err != nil {
+		return nil, "", err
+	}
+	if !metadata.HasVerificationCapability() {
+		return nil, "", errors.New("plugin does not have verification capabilities")
+	}
+	return metadata, contractVersion, nil
+}
+
+// criticalHeaders returns the names listed in the crit protected header.
+func criticalHeaders(header map[string]interface{}) ([]string, error) {
+	raw, ok := header["crit"]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, `malformed "crit" protected header`)
+	}
+	crit := make([]string, 0, len(values))
+	for _, v := range values {
+		name, ok := v.(string)
+		if !ok {
+			return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, `malformed "crit" protected header`)
+		}
+		if _, ok := header[name]; !ok {
+			return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "critical attribute %q is missing from the protected header", name)
+		}
+		crit = append(crit, name)
+	}
+	return crit, nil
+}
+
+// isPresent reports whether val is in values.
+func isPresent(val string, values []string) bool {
+	for _, v := range values {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package jws
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/event"
+	"github.com/notaryproject/notation-go/plugin"
+)
+
+type mockPluginManager map[string]plugin.Runner
+
+func (m mockPluginManager) Runner(name string) (plugin.Runner, error) {
+	if r, ok := m[name]; ok {
+		return r, nil
+	}
+	return nil, errors.New("plugin not found")
+}
+
+type mockVerifierPlugin struct {
+	metadata plugin.Metadata
+	resp     *plugin.VerifySignatureResponse
+	req      *plugin.VerifySignatureRequest
+}
+
+func (p *mockVerifierPlugin) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	switch req := req.(type) {
+	case *plugin.GetMetadataRequest:
+		return &p.metadata, nil
+	case *plugin.VerifySignatureRequest:
+		p.req = req
+		return p.resp, nil
+	}
+	return nil, errors.New("unsupported command")
+}
+
+// signWithHeader signs an envelope with additional protected headers.
+// The headers are merged into the mandatory protected headers of a
+// notary.x509 signature, where a nil value removes a mandatory header, and
+// the mandatory critical headers are added to "crit".
+func signWithHeader(t *testing.T, header map[string]interface{}) ([]byte, *x509.Certificate) {
+	t.Helper()
+	h := map[string]interface{}{
+		"alg":               "PS256",
+		"cty":               notation.MediaTypePayload,
+		headerSigningScheme: notation.SigningSchemeX509,
+		headerSigningTime:   time.Now().Format(time.RFC3339),
+	}
+	for k, v := range header {
+		if v == nil {
+			delete(h, k)
+		} else {
+			h[k] = v
+		}
+	}
+	crit, _ := h["crit"].([]string)
+	for _, name := range []string{headerSigningScheme, headerAuthenticSigningTime, headerExpiry} {
+		if _, ok := h[name]; ok && !isPresent(name, crit) {
+			crit = append(crit, name)
+		}
+	}
+	if len(crit) > 0 {
+		h["crit"] = crit
+	}
+	return signWithRawHeader(t, h)
+}
+
+// signWithRawHeader signs an envelope with the given protected headers.
+func signWithRawHeader(t *testing.T, h map[string]interface{}) ([]byte, *x509.Certificate) {
+	t.Helper()
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := &jwt.Token{
+		Method: jwt.SigningMethodPS256,
+		Header: h,
+		Claims: notaryClaim{
+			RegisteredClaims: jwt.RegisteredClaims{
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			Subject: testDescriptor,
+		},
+	}
+	compact, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(compact, ".")
+	sig, err := json.Marshal(notation.JWSEnvelope{
+		Protected: parts[0],
+		Payload:   parts[1],
+		Signature: parts[2],
+		Header:    notation.JWSUnprotectedHeader{CertChain: [][]byte{cert.Raw}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig, cert
+}
+
+func verifierPlugin(success bool, processed ...string) *mockVerifierPlugin {
+	return &mockVerifierPlugin{
+		metadata: plugin.Metadata{
+			Name: "foo", Description: "friendly", Version: "1.2.0", URL: "example.com",
+			SupportedContractVersions: []string{plugin.ContractVersion},
+			Capabilities:              []plugin.Capability{plugin.CapabilityTrustedIdentityVerifier},
+		},
+		resp: &plugin.VerifySignatureResponse{
+			VerificationResults: map[plugin.Capability]*plugin.VerificationResult{
+				plugin.CapabilityTrustedIdentityVerifier: {Success: success, Reason: "untrusted"},
+			},
+			ProcessedAttributes: processed,
+		},
+	}
+}
+
+func TestVerifyWithPlugin(t *testing.T) {
+	withPlugin := map[string]interface{}{
+		headerVerificationPlugin: "foo",
+		"io.cncf.acme.team":      "net-monitor",
+		"crit":                   []string{headerVerificationPlugin, "io.cncf.acme.team"},
+	}
+	tests := []struct {
+		name    string
+		header  map[string]interface{}
+		manager PluginManager
+		require bool
+		wantErr string
+	}{
+		{"no plugin", nil, nil, true, ""},
+		{"unsupported critical attribute", map[string]interface{}{"foo": "bar", "crit": []string{"foo"}}, nil, false, "unsupported critical attributes"},
+		{"missing critical attribute", map[string]interface{}{"crit": []string{"foo"}}, nil, false, "missing from the protected header"},
+		{"valid", withPlugin, mockPluginManager{"foo": verifierPlugin(true, "io.cncf.acme.team")}, true, ""},
+		{"plugin verification failed", withPlugin, mockPluginManager{"foo": verifierPlugin(false, "io.cncf.acme.team")}, true, "failed SIGNATURE_VERIFIER.TRUSTED_IDENTITY verification: untrusted"},
+		{"attribute not processed", withPlugin, mockPluginManager{"foo": verifierPlugin(true)}, true, "were not processed"},
+		{"plugin absent", withPlugin, mockPluginManager{}, false, "is not available"},
+		{
+			"plugin absent not required",
+			map[string]interface{}{headerVerificationPlugin: "foo", "crit": []string{headerVerificationPlugin}},
+			mockPluginManager{}, false, "",
+		},
+		{
+			"plugin absent required",
+			map[string]interface{}{headerVerificationPlugin: "foo", "crit": []string{headerVerificationPlugin}},
+			nil, true, "is not available",
+		},
+		{
+			"plugin version too low",
+			map[string]interface{}{headerVerificationPlugin: "foo", headerVerificationPluginMinVersion: "1.10.0"},
+			mockPluginManager{"foo": verifierPlugin(true)}, true, "lower than the minimum version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, cert := signWithHeader(t, tt.header)
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			v.PluginManager = tt.manager
+			v.RequireVerificationPlugin = tt.require
+			v.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=Acme"}
+			_, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify() error = %v, want nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyWithPlugin_Request(t *testing.T) {
+	sig, cert := signWithHeader(t, map[string]interface{}{
+		headerVerificationPlugin: "foo",
+		"io.cncf.acme.team":      "net-monitor",
+		"crit":                   []string{headerVerificationPlugin, "io.cncf.acme.team"},
+	})
+	p := verifierPlugin(true, "io.cncf.acme.team")
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	v.PluginManager = mockPluginManager{"foo": p}
+	v.PluginConfig = map[string]string{"key": "value"}
+	v.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=Acme"}
+	if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	req := p.req
+	if req == nil {
+		t.Fatal("verify-signature was not invoked")
+	}
+	if got := req.Signature.UnprocessedAttributes; len(got) != 1 || got[0] != "io.cncf.acme.team" {
+		t.Errorf("UnprocessedAttributes = %v, want [io.cncf.acme.team]", got)
+	}
+	if got := req.Signature.CriticalAttributes.ExtendedAttributes["io.cncf.acme.team"]; got != "net-monitor" {
+		t.Errorf("ExtendedAttributes = %v, want net-monitor", got)
+	}
+	if req.Signature.CriticalAttributes.ContentType != notation.MediaTypePayload {
+		t.Errorf("ContentType = %v, want %v", req.Signature.CriticalAttributes.ContentType, notation.MediaTypePayload)
+	}
+	if req.Signature.CriticalAttributes.Expiry == nil {
+		t.Error("Expiry = nil, want non-nil")
+	}
+	if len(req.TrustPolicy.TrustedIdentities) != 1 || req.PluginConfig["key"] != "value" {
+		t.Errorf("request trust policy or plugin config not set: %+v", req)
+	}
+}
+
+func TestVerifyWithPlugin_Outcome(t *testing.T) {
+	sig, cert := signWithHeader(t, map[string]interface{}{headerVerificationPlugin: "foo"})
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	v.PluginManager = mockPluginManager{"foo": verifierPlugin(false)}
+	outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+	if err == nil {
+		t.Fatal("Verify() error = nil, want error")
+	}
+	if result := outcome.Result(notation.CheckTrustedIdentity); result == nil || result.Status != notation.VerificationFailed {
+		t.Errorf("Result(trustedIdentity) = %v, want failed", result)
+	}
+	if result := outcome.Result(notation.CheckRevocation); result == nil || result.Status != notation.VerificationSkipped {
+		t.Errorf("Result(revocation) = %v, want skipped", result)
+	}
+	if result := outcome.Result(notation.CheckAuthenticity); result == nil || result.Status != notation.VerificationPassed {
+		t.Errorf("Result(authenticity) = %v, want passed", result)
+	}
+}
+
+type phaseKey struct{}
+
+// phasePlugin records the phase of the context of each verify-signature
+// command along with the capabilities requested.
+type phasePlugin struct {
+	*mockVerifierPlugin
+	phases map[Phase][]plugin.Capability
+}
+
+func (p *phasePlugin) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	if req, ok := req.(*plugin.VerifySignatureRequest); ok {
+		phase, _ := ctx.Value(phaseKey{}).(Phase)
+		p.phases[phase] = append(p.phases[phase], req.TrustPolicy.SignatureVerification...)
+	}
+	return p.mockVerifierPlugin.Run(ctx, req)
+}
+
+func TestVerifyWithPlugin_PhaseContext(t *testing.T) {
+	sig, cert := signWithHeader(t, map[string]interface{}{headerVerificationPlugin: "foo"})
+	mock := verifierPlugin(true)
+	mock.metadata.Capabilities = append(mock.metadata.Capabilities, plugin.CapabilityRevocationCheckVerifier)
+	mock.resp.VerificationResults[plugin.CapabilityRevocationCheckVerifier] = &plugin.VerificationResult{Success: false, Reason: "revoked"}
+	p := &phasePlugin{mockVerifierPlugin: mock, phases: make(map[Phase][]plugin.Capability)}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	v.PluginManager = mockPluginManager{"foo": p}
+	v.PhaseContext = func(ctx context.Context, phase Phase) (context.Context, context.CancelFunc) {
+		return context.WithCancel(context.WithValue(ctx, phaseKey{}, phase))
+	}
+	outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+	if err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Fatalf("Verify() error = %v, want revoked", err)
+	}
+	if got := p.phases[PhaseVerification]; len(got) != 1 || got[0] != plugin.CapabilityTrustedIdentityVerifier {
+		t.Errorf("verification phase capabilities = %v, want [%s]", got, plugin.CapabilityTrustedIdentityVerifier)
+	}
+	if got := p.phases[PhaseRevocation]; len(got) != 1 || got[0] != plugin.CapabilityRevocationCheckVerifier {
+		t.Errorf("revocation phase capabilities = %v, want [%s]", got, plugin.CapabilityRevocationCheckVerifier)
+	}
+	if result := outcome.Result(notation.CheckTrustedIdentity); result == nil || result.Status != notation.VerificationPassed {
+		t.Errorf("Result(trustedIdentity) = %v, want passed", result)
+	}
+	if result := outcome.Result(notation.CheckRevocation); result == nil || result.Status != notation.VerificationFailed {
+		t.Errorf("Result(revocation) = %v, want failed", result)
+	}
+}
+
+func TestVerifyWithPlugin_RevocationEvents(t *testing.T) {
+	sig, cert := signWithHeader(t, map[string]interface{}{headerVerificationPlugin: "foo"})
+	mock := verifierPlugin(true)
+	mock.metadata.Capabilities = append(mock.metadata.Capabilities, plugin.CapabilityRevocationCheckVerifier)
+	mock.resp.VerificationResults[plugin.CapabilityRevocationCheckVerifier] = &plugin.VerificationResult{Success: false, Reason: "revoked"}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	v.PluginManager = mockPluginManager{"foo": mock}
+	var events []event.Event
+	ctx := event.WithHandler(context.Background(), event.HandlerFunc(func(e event.Event) {
+		events = append(events, e)
+	}))
+	if _, err := v.Verify(ctx, sig, notation.VerifyOptions{}); err == nil {
+		t.Fatal("Verify() error = nil, want revoked")
+	}
+	if len(events) != 2 || events[0].Type != event.RevocationCheckStarted || events[1].Type != event.RevocationCheckFinished {
+		t.Fatalf("Verify() emitted %+v, want revocation check started and finished", events)
+	}
+	if e := events[1]; e.Plugin != "foo" || e.Err == nil || !strings.Contains(e.Err.Error(), "revoked") {
+		t.Errorf("revocation check finished event = %+v, want the failure of plugin foo", e)
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
@@ -23,15 +25,18 @@ import (
 // and should be verified by the caller.
 func NewSigner(key crypto.PrivateKey, certChain []*x509.Certificate) (notation.Signer, error) {
 	if key == nil {
-		return nil, errors.New("nil signing key")
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil signing key")
 	}
 	if len(certChain) == 0 {
-		return nil, errors.New("missing signer certificate chain")
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing signer certificate chain")
 	}
-	keySpec, err := keySpecFromKey(key)
+	keySpec, err := notation.KeySpecFromKey(key)
 	if err != nil {
 		return nil, err
 	}
+	if err := notation.CheckFIPSKeySpec(keySpec); err != nil {
+		return nil, err
+	}
 	// verify the signing certificate
 	cert := certChain[0]
 	roots := x509.NewCertPool()
@@ -84,6 +89,11 @@ func (builtinPlugin) metadata() *plugin.Metadata {
 	}
 }
 
+// CertificateChain returns the certificate chain of the key.
+func (r *builtinPlugin) CertificateChain(_ context.Context, _ string) ([][]byte, error) {
+	return r.certChain, nil
+}
+
 // Run implement the generate-signature workflow.
 func (r *builtinPlugin) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
 	switch req.Command() {
@@ -102,7 +112,7 @@ func (r *builtinPlugin) Run(ctx context.Context, req plugin.Request) (interface{
 		// the hash provided in req1.Hash and a Sign method
 		// which does not hash data itself.
 		sigAlg := r.keySpec.SignatureAlgorithm()
-		method := jwt.GetSigningMethod(sigAlg.JWS())
+		method := signingMethod(sigAlg)
 		signed, err := method.Sign(string(req1.Payload), r.key)
 		if err != nil {
 			return nil, plugin.RequestError{
@@ -123,6 +133,7 @@ func (r *builtinPlugin) Run(ctx context.Context, req plugin.Request) (interface{
 			KeyID:            req1.KeyID,
 			Signature:        signedDecoded,
 			SigningAlgorithm: sigAlg,
+			Hash:             sigAlg.Hash(),
 			CertificateChain: r.certChain,
 		}, nil
 	}
@@ -132,12 +143,36 @@ func (r *builtinPlugin) Run(ctx context.Context, req plugin.Request) (interface{
 	}
 }
 
-func jwtToken(alg string, claims jwt.Claims) *jwt.Token {
+func jwtToken(alg string, opts notation.SignOptions, claims notaryClaim) *jwt.Token {
+	crit := []string{headerSigningScheme}
+	header := map[string]interface{}{
+		"alg":               alg,
+		"cty":               payloadContentType(opts),
+		headerSigningScheme: opts.SigningScheme,
+	}
+	// The signing authority attests the signing time by signing it.
+	if opts.SigningScheme == notation.SigningSchemeX509SigningAuthority {
+		header[headerAuthenticSigningTime] = claims.IssuedAt.Time.Format(time.RFC3339)
+		crit = append(crit, headerAuthenticSigningTime)
+	} else {
+		header[headerSigningTime] = claims.IssuedAt.Time.Format(time.RFC3339)
+	}
+	if claims.ExpiresAt != nil {
+		header[headerExpiry] = claims.ExpiresAt.Time.Format(time.RFC3339)
+		crit = append(crit, headerExpiry)
+	}
+	for _, attr := range opts.ExtendedSignedAttributes {
+		header[attr.Key] = attr.Value
+		if attr.Critical {
+			crit = append(crit, attr.Key)
+		}
+	}
+	if opts.Deterministic {
+		sort.Strings(crit)
+	}
+	header["crit"] = crit
 	return &jwt.Token{
-		Header: map[string]interface{}{
-			"alg": alg,
-			"cty": notation.MediaTypePayload,
-		},
+		Header: header,
 		Claims: claims,
 	}
 }
@@ -155,6 +190,10 @@ func jwsEnvelope(ctx context.Context, opts notation.SignOptions, compact string,
 			CertChain: certChain,
 		},
 	}
+	if opts.Deterministic {
+		return json.Marshal(envelope)
+	}
+	envelope.Header.SigningAgent = opts.SigningAgent
 
 	// timestamp JWT
 	if opts.TSA != nil {
@@ -190,7 +229,7 @@ func timestampSignature(ctx context.Context, sig string, tsa timestamp.Timestamp
 	tokenBytes := resp.TokenBytes()
 
 	// verify the timestamp signature
-	if _, err := verifyTimestamp(decodedSig, tokenBytes, opts.Roots); err != nil {
+	if _, err := verifyTimestamp(decodedSig, tokenBytes, opts.Roots, opts.CurrentTime); err != nil {
 		return nil, err
 	}
 
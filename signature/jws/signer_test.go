@@ -4,11 +4,13 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"math"
 	"math/big"
 	"testing"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/notaryproject/notation-go"
 	"github.com/notaryproject/notation-go/crypto/timestamp/timestamptest"
+	"github.com/notaryproject/notation-go/signature/cose"
 	"github.com/opencontainers/go-digest"
 )
 
@@ -76,6 +79,13 @@ func TestSignWithCertChain(t *testing.T) {
 			name: string(notation.EC_512),
 			fn:   func() (crypto.PrivateKey, error) { return ecdsa.GenerateKey(elliptic.P521(), rand.Reader) },
 		},
+		{
+			name: string(notation.ED25519),
+			fn: func() (crypto.PrivateKey, error) {
+				_, key, err := ed25519.GenerateKey(rand.Reader)
+				return key, err
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -137,6 +147,7 @@ func TestSignWithoutExpiry(t *testing.T) {
 	ctx := context.Background()
 	desc, sOpts := generateSigningContent(nil)
 	sOpts.Expiry = time.Time{} // reset expiry
+	sOpts.SigningAgent = "notation/1.0.0"
 	sig, err := s.Sign(ctx, desc, sOpts)
 	if err != nil {
 		t.Fatalf("Sign() error = %v", err)
@@ -147,9 +158,19 @@ func TestSignWithoutExpiry(t *testing.T) {
 	roots := x509.NewCertPool()
 	roots.AddCert(cert)
 	v.VerifyOptions.Roots = roots
-	if _, err := v.Verify(ctx, sig, notation.VerifyOptions{}); err != nil {
+	outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{})
+	if err != nil {
 		t.Fatalf("Verify() error = %v", err)
 	}
+	if !outcome.Expiry.IsZero() {
+		t.Errorf("Verify() Expiry = %v, want none", outcome.Expiry)
+	}
+	if outcome.SigningTime.IsZero() {
+		t.Errorf("Verify() SigningTime is not populated")
+	}
+	if outcome.SigningAgent != sOpts.SigningAgent {
+		t.Errorf("Verify() SigningAgent = %q, want %q", outcome.SigningAgent, sOpts.SigningAgent)
+	}
 }
 
 // generateSigningContent generates common signing content with options for testing.
@@ -214,3 +235,306 @@ func generateCert(key crypto.PrivateKey) (*x509.Certificate, error) {
 	}
 	return cert, nil
 }
+
+func TestFIPSMode(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edCert, err := generateCert(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSigner, err := NewSigner(edKey, []*x509.Certificate{edCert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	desc, sOpts := generateSigningContent(nil)
+	edSig, err := edSigner.Sign(context.Background(), desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	notation.SetFIPSMode(true)
+	defer notation.SetFIPSMode(false)
+
+	// approved keys are still supported
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSignWithCertChain(t, rsaKey)
+
+	if _, err := NewSigner(edKey, []*x509.Certificate{edCert}); !errors.Is(err, notation.ErrFIPSViolation) {
+		t.Errorf("NewSigner() error = %v, want ErrFIPSViolation", err)
+	}
+	plugin := pluginSigner{runner: &mockSignerPlugin{KeyID: "1", KeySpec: notation.ED25519}, keyID: "1"}
+	if _, err := plugin.Sign(context.Background(), testDescriptor, notation.SignOptions{}); !errors.Is(err, notation.ErrFIPSViolation) {
+		t.Errorf("Signer.Sign() error = %v, want ErrFIPSViolation", err)
+	}
+	v := NewVerifier()
+	v.VerifyOptions.Roots = x509.NewCertPool()
+	v.VerifyOptions.Roots.AddCert(edCert)
+	if _, err := v.Verify(context.Background(), edSig, notation.VerifyOptions{}); !errors.Is(err, notation.ErrFIPSViolation) {
+		t.Errorf("Verify() error = %v, want ErrFIPSViolation", err)
+	}
+}
+
+func TestSignDeterministic(t *testing.T) {
+	tsa, err := timestamptest.NewTSA()
+	if err != nil {
+		t.Fatalf("timestamptest.NewTSA() error = %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	desc, sOpts := generateSigningContent(tsa)
+	sOpts.SigningAgent = "notation/test"
+	sOpts.ExtendedSignedAttributes = []notation.SignedAttribute{
+		{Key: "b", Value: "2"},
+		{Key: "a", Value: "1"},
+	}
+	sOpts.Clock = func() time.Time { return signingTime }
+	sOpts.Deterministic = true
+
+	tests := []struct {
+		name          string
+		key           crypto.PrivateKey
+		wantIdentical bool
+	}{
+		{name: "Ed25519", key: edKey, wantIdentical: true},
+		{name: "ECDSA", key: ecKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := generateCert(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := NewSigner(tt.key, []*x509.Certificate{cert})
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+			var envelopes []*notation.JWSEnvelope
+			var sigs [][]byte
+			for i := 0; i < 2; i++ {
+				sig, err := s.Sign(context.Background(), desc, sOpts)
+				if err != nil {
+					t.Fatalf("Sign() error = %v", err)
+				}
+				envelope, err := openEnvelope(sig)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sigs = append(sigs, sig)
+				envelopes = append(envelopes, envelope)
+			}
+			if got := envelopes[0]; got.Header.TimeStampToken != nil || got.Header.SigningAgent != "" {
+				t.Errorf("Sign() unprotected header = %+v, want only the certificate chain", got.Header)
+			}
+			if envelopes[0].Protected != envelopes[1].Protected || envelopes[0].Payload != envelopes[1].Payload {
+				t.Errorf("Sign() protected header or payload differs between signings")
+			}
+			if identical := string(sigs[0]) == string(sigs[1]); identical != tt.wantIdentical {
+				t.Errorf("Sign() byte-identical envelopes = %v, want %v", identical, tt.wantIdentical)
+			}
+
+			v := NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			outcome, err := v.Verify(context.Background(), sigs[0], notation.VerifyOptions{})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !outcome.SigningTime.Equal(signingTime) {
+				t.Errorf("Verify() signing time = %v, want %v", outcome.SigningTime, signingTime)
+			}
+		})
+	}
+
+	sOpts.Clock = nil
+	cert, err := generateCert(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSigner(edKey, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	if _, err := s.Sign(context.Background(), desc, sOpts); err == nil {
+		t.Errorf("Sign() without clock error = nil, want error")
+	}
+}
+
+func TestSignDryRun(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	local, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	runner := &mockSignerPlugin{KeyID: "1", KeySpec: notation.RSA_2048}
+	remote, err := NewSignerPlugin(runner, "1", nil)
+	if err != nil {
+		t.Fatalf("NewSignerPlugin() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		signer    notation.Signer
+		wantChain bool
+	}{
+		{"local key", local, true},
+		{"plugin", remote, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, opts := generateSigningContent(nil)
+			opts.UserMetadata = map[string]string{"buildId": "42"}
+			opts.DryRun = true
+			var report *notation.DryRunReport
+			opts.OnDryRun = func(r notation.DryRunReport) {
+				report = &r
+			}
+			sig, err := tt.signer.Sign(context.Background(), desc, opts)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if sig != nil {
+				t.Errorf("Sign() = %s, want no signature", sig)
+			}
+			if report == nil {
+				t.Fatal("Sign() reported nothing")
+			}
+			if report.Descriptor.Digest != desc.Digest || report.Descriptor.Annotations["buildId"] != "42" {
+				t.Errorf("DryRunReport.Descriptor = %v, want %v with user metadata", report.Descriptor, desc)
+			}
+			if report.KeySpec != notation.RSA_2048 || report.SignatureAlgorithm != notation.RSASSA_PSS_SHA_256 {
+				t.Errorf("DryRunReport key spec = %v and algorithm = %v, want %v and %v", report.KeySpec, report.SignatureAlgorithm, notation.RSA_2048, notation.RSASSA_PSS_SHA_256)
+			}
+			if len(report.SigningInput) == 0 || !report.Expiry.Equal(opts.Expiry) {
+				t.Errorf("DryRunReport = %+v, want signing input and expiry", report)
+			}
+			if gotChain := len(report.CertificateChain) == 1 && report.CertificateChain[0].Equal(cert); gotChain != tt.wantChain {
+				t.Errorf("DryRunReport.CertificateChain = %v, want chain %v", report.CertificateChain, tt.wantChain)
+			}
+		})
+	}
+	if runner.n != 2 {
+		t.Errorf("plugin ran %d commands, want get-plugin-metadata and describe-key only", runner.n)
+	}
+}
+
+func TestSignCOSE(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key     crypto.Signer
+		alg     notation.SignatureAlgorithm
+		sigSize int
+	}{
+		{key: ecKey, alg: notation.ECDSA_SHA_384, sigSize: 96},
+		{key: rsaKey, alg: notation.RSASSA_PSS_SHA_256, sigSize: 256},
+		{key: edKey, alg: notation.EDDSA_ED25519, sigSize: ed25519.SignatureSize},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.alg), func(t *testing.T) {
+			cert, err := generateCert(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := NewSigner(tt.key, []*x509.Certificate{cert})
+			if err != nil {
+				t.Fatal(err)
+			}
+			desc, opts := generateSigningContent(nil)
+			signingTime := time.Now().Truncate(time.Second)
+			opts.Clock = func() time.Time { return signingTime }
+			opts.EnvelopeType = notation.MediaTypeCOSEEnvelope
+			opts.SigningAgent = "notation/test"
+			env, err := s.Sign(context.Background(), desc, opts)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			// the raw signature of the Sig_structure is the last item of
+			// the envelope
+			sig := env[len(env)-tt.sigSize:]
+			opts.SigningScheme = notation.SigningSchemeX509
+			msg, err := cose.NewMessage(tt.alg, desc, signingTime, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := msg.Envelope(sig, []*x509.Certificate{cert})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(env) != string(want) {
+				t.Errorf("Sign() = %x, want %x", env, want)
+			}
+			if err := VerifyPayloadSignature(msg.SigningInput(), sig, cert); err != nil {
+				t.Errorf("VerifyPayloadSignature() error = %v", err)
+			}
+
+			// dry runs report the Sig_structure as signing input
+			opts.DryRun = true
+			var report notation.DryRunReport
+			opts.OnDryRun = func(r notation.DryRunReport) { report = r }
+			if _, err := s.Sign(context.Background(), desc, opts); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if string(report.SigningInput) != string(msg.SigningInput()) {
+				t.Errorf("DryRunReport.SigningInput = %x, want %x", report.SigningInput, msg.SigningInput())
+			}
+		})
+	}
+}
+
+func TestSignCOSE_Errors(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsa, err := timestamptest.NewTSA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, opts := generateSigningContent(tsa)
+	opts.EnvelopeType = notation.MediaTypeCOSEEnvelope
+	if _, err := s.Sign(context.Background(), desc, opts); !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("Sign() with TSA error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+
+	desc, opts = generateSigningContent(nil)
+	opts.EnvelopeType = "application/pgp-signature"
+	if _, err := s.Sign(context.Background(), desc, opts); err == nil {
+		t.Errorf("Sign() with unsupported envelope type error = nil, want error")
+	}
+
+	envelopeSigner := pluginSigner{runner: &mockEnvelopePlugin{}, keyID: "1"}
+	_, err = envelopeSigner.Sign(context.Background(), testDescriptor, notation.SignOptions{EnvelopeType: notation.MediaTypeCOSEEnvelope})
+	if !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("Sign() with envelope generator error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+}
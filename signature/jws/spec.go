@@ -4,24 +4,61 @@
 package jws
 
 import (
-	"crypto/ecdsa"
-	"crypto/rsa"
-	"crypto/x509"
-	"errors"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
 )
 
+// Protected header names of the signed attributes defined by the signature specification.
+const (
+	headerSigningScheme        = "io.cncf.notary.signingScheme"
+	headerSigningTime          = "io.cncf.notary.signingTime"
+	headerAuthenticSigningTime = "io.cncf.notary.authenticSigningTime"
+	headerExpiry               = "io.cncf.notary.expiry"
+)
+
+// Unprotected header names defined by the signature specification.
+const (
+	headerCertChain      = "x5c"
+	headerTimeStampToken = "timestamp"
+	headerSigningAgent   = "io.cncf.notary.signingAgent"
+)
+
+// unprotectedHeaders lists the headers allowed in the unprotected header.
+// Any other header could be tampered with, and is rejected by the verifier.
+var unprotectedHeaders = []string{
+	headerCertChain,
+	headerTimeStampToken,
+	headerSigningAgent,
+}
+
+// reservedHeaders lists the protected headers managed by the signer,
+// which cannot be set as extended signed attributes.
+var reservedHeaders = []string{
+	"alg",
+	"cty",
+	"crit",
+	"typ",
+	"kid",
+	"x5c",
+	headerSigningScheme,
+	headerSigningTime,
+	headerAuthenticSigningTime,
+	headerExpiry,
+}
+
 type notaryClaim struct {
 	jwt.RegisteredClaims
 	Subject notation.Descriptor `json:"subject"`
 }
 
 // packPayload generates JWS payload according the signing content and options.
-func packPayload(desc notation.Descriptor, opts notation.SignOptions) jwt.Claims {
+func packPayload(desc notation.Descriptor, opts notation.SignOptions) notaryClaim {
 	var expiresAt *jwt.NumericDate
 	if !opts.Expiry.IsZero() {
 		expiresAt = jwt.NewNumericDate(opts.Expiry)
@@ -29,52 +66,268 @@ func packPayload(desc notation.Descriptor, opts notation.SignOptions) jwt.Claims
 	return notaryClaim{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: expiresAt,
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(signingTime(opts)),
 		},
 		Subject: desc,
 	}
 }
 
-var (
-	oidExtensionKeyUsage = []int{2, 5, 29, 15}
-)
+// signingTime returns the signing time of a signature signed with opts.
+func signingTime(opts notation.SignOptions) time.Time {
+	if opts.Clock != nil {
+		return opts.Clock()
+	}
+	return time.Now()
+}
 
-// verifyCertExtKeyUsage checks cert meets the requirements defined in
-// https://github.com/notaryproject/notaryproject/blob/main/signature-specification.md#certificate-requirements.
-func verifyCertExtKeyUsage(cert *x509.Certificate, extKeyUsage x509.ExtKeyUsage) error {
-	if cert.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
-		return errors.New("keyUsage must have the bit positions for digitalSignature set")
-	}
-	var hasExtKeyUsage bool
-	for _, ext := range cert.ExtKeyUsage {
-		if ext == extKeyUsage {
-			hasExtKeyUsage = true
-			break
-		}
+// validateClaims validates the time based claims of c at time now, as
+// jwt.RegisteredClaims.Valid does at jwt.TimeFunc.
+func validateClaims(c notaryClaim, now time.Time) error {
+	if !c.VerifyExpiresAt(now, false) {
+		return fmt.Errorf("%w by %v", jwt.ErrTokenExpired, now.Sub(c.ExpiresAt.Time))
+	}
+	if !c.VerifyIssuedAt(now, false) {
+		return jwt.ErrTokenUsedBeforeIssued
 	}
-	if !hasExtKeyUsage {
-		return fmt.Errorf("extKeyUsage must contain %d", extKeyUsage)
+	if !c.VerifyNotBefore(now, false) {
+		return jwt.ErrTokenNotValidYet
+	}
+	return nil
+}
+
+// SignedDescriptor returns the descriptor signed by signatures signed with
+// opts, i.e. a copy of desc with its annotations restricted to the signed
+// annotations, if any, and the user metadata added to its annotations.
+func SignedDescriptor(desc notation.Descriptor, opts notation.SignOptions) (notation.Descriptor, error) {
+	return signedDescriptor(desc, opts.SignedAnnotations, opts.UserMetadata)
+}
+
+// signedDescriptor returns a copy of desc with its annotations restricted
+// to the signed annotations, if any, and the user metadata added to its
+// annotations.
+func signedDescriptor(desc notation.Descriptor, signed []string, metadata map[string]string) (notation.Descriptor, error) {
+	if len(signed) == 0 && len(metadata) == 0 {
+		return desc, nil
 	}
-	for _, e := range cert.Extensions {
-		if e.Id.Equal(oidExtensionKeyUsage) {
-			if !e.Critical {
-				return errors.New("the keyUsage extension must be marked critical")
+	annotations := make(map[string]string, len(desc.Annotations)+len(metadata)+1)
+	if len(signed) == 0 {
+		for k, v := range desc.Annotations {
+			annotations[k] = v
+		}
+	} else {
+		keys := make([]string, 0, len(signed))
+		for _, k := range signed {
+			if v, ok := desc.Annotations[k]; ok {
+				annotations[k] = v
 			}
-			break
+			if !isPresent(k, keys) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		annotations[notation.AnnotationSignedAnnotations] = strings.Join(keys, ",")
+	}
+	for k, v := range metadata {
+		if _, ok := annotations[k]; ok {
+			return notation.Descriptor{}, notation.Errorf(notation.ErrorCodeInvalidArgument, "user metadata key %q conflicts with a descriptor annotation", k)
+		}
+		annotations[k] = v
+	}
+	desc.Annotations = annotations
+	return desc, nil
+}
+
+// validateExtendedAttributes checks the extended signed attributes
+// do not override the protected headers managed by the signer.
+func validateExtendedAttributes(attrs []notation.SignedAttribute) error {
+	for _, attr := range attrs {
+		if isPresent(attr.Key, reservedHeaders) {
+			return notation.Errorf(notation.ErrorCodeInvalidArgument, "extended signed attribute %q is reserved", attr.Key)
+		}
+	}
+	return nil
+}
+
+// extendedAttributes returns the extended signed attributes in the protected header,
+// sorted by key.
+func extendedAttributes(header map[string]interface{}, crit []string) []notation.SignedAttribute {
+	var attrs []notation.SignedAttribute
+	for k, v := range header {
+		if isPresent(k, reservedHeaders) {
+			continue
+		}
+		attrs = append(attrs, notation.SignedAttribute{
+			Key:      k,
+			Value:    v,
+			Critical: isPresent(k, crit),
+		})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+	return attrs
+}
+
+// verifyUserMetadata checks the signed annotations contain the required user metadata.
+func verifyUserMetadata(annotations, required map[string]string) error {
+	for k, want := range required {
+		got, ok := annotations[k]
+		if !ok {
+			return notation.Errorf(notation.ErrorCodeUserMetadataMismatch, "user metadata %q not found in the signature", k)
+		}
+		if got != want {
+			return notation.Errorf(notation.ErrorCodeUserMetadataMismatch, "user metadata %q has value %q, want %q", k, got, want)
+		}
+	}
+	return nil
+}
+
+// verifyAnnotations checks the annotations listed as signed in the signed
+// annotations match the current annotations of the artifact. It reports
+// whether the signed annotations list their keys.
+func verifyAnnotations(signed, current map[string]string) (bool, error) {
+	list, ok := signed[notation.AnnotationSignedAnnotations]
+	if !ok {
+		return false, nil
+	}
+	for _, k := range strings.Split(list, ",") {
+		want, signedOK := signed[k]
+		got, currentOK := current[k]
+		switch {
+		case signedOK && !currentOK:
+			return true, notation.Errorf(notation.ErrorCodeAnnotationsMismatch, "signed annotation %q was removed from the artifact", k)
+		case !signedOK && currentOK:
+			return true, notation.Errorf(notation.ErrorCodeAnnotationsMismatch, "annotation %q was added to the artifact after signing", k)
+		case got != want:
+			return true, notation.Errorf(notation.ErrorCodeAnnotationsMismatch, "annotation %q has value %q, signed %q", k, got, want)
+		}
+	}
+	return true, nil
+}
+
+// signedAttributes are the signed attributes defined by the signature
+// specification, read from the protected header.
+type signedAttributes struct {
+	signingScheme        notation.SigningScheme
+	signingTime          time.Time
+	authenticSigningTime time.Time
+	expiry               time.Time
+}
+
+// parseSignedAttributes reads the signed attributes from the protected header,
+// and checks the header satisfies the rules of the signing scheme.
+func parseSignedAttributes(header map[string]interface{}) (signedAttributes, error) {
+	attrs := signedAttributes{
+		signingScheme: notation.SigningSchemeX509,
+	}
+	if raw, ok := header[headerSigningScheme]; ok {
+		s, _ := raw.(string)
+		attrs.signingScheme = notation.SigningScheme(s)
+		if err := attrs.signingScheme.Validate(); err != nil {
+			return signedAttributes{}, err
 		}
 	}
-	if cert.BasicConstraintsValid && cert.IsCA {
-		return errors.New("if the basicConstraints extension is present, the CA field MUST be set false")
+	raw, ok := header[headerAuthenticSigningTime]
+	switch {
+	case ok && attrs.signingScheme != notation.SigningSchemeX509SigningAuthority:
+		return signedAttributes{}, fmt.Errorf("%s is only valid for signing scheme %q", headerAuthenticSigningTime, notation.SigningSchemeX509SigningAuthority)
+	case !ok && attrs.signingScheme == notation.SigningSchemeX509SigningAuthority:
+		return signedAttributes{}, fmt.Errorf("%s is required by signing scheme %q", headerAuthenticSigningTime, notation.SigningSchemeX509SigningAuthority)
 	}
-	switch key := cert.PublicKey.(type) {
-	case *rsa.PublicKey:
-		if key.N.BitLen() < 2048 {
-			return errors.New("RSA public key length must be 2048 bits or higher")
+	var err error
+	if ok {
+		if attrs.authenticSigningTime, err = parseTimeHeader(headerAuthenticSigningTime, raw); err != nil {
+			return signedAttributes{}, err
 		}
-	case *ecdsa.PublicKey:
-		if key.Params().N.BitLen() < 256 {
-			return errors.New("ECDSA public key length must be 256 bits or higher")
+	}
+	if raw, ok := header[headerSigningTime]; ok {
+		if attrs.signingTime, err = parseTimeHeader(headerSigningTime, raw); err != nil {
+			return signedAttributes{}, err
 		}
 	}
+	if raw, ok := header[headerExpiry]; ok {
+		if attrs.expiry, err = parseTimeHeader(headerExpiry, raw); err != nil {
+			return signedAttributes{}, err
+		}
+	}
+	return attrs, nil
+}
+
+// parseTimeHeader parses a protected header holding an RFC 3339 time.
+func parseTimeHeader(name string, raw interface{}) (time.Time, error) {
+	s, ok := raw.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("malformed %s protected header", name)
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed %s protected header: %w", name, err)
+	}
+	return t, nil
+}
+
+// payloadContentType returns the content type of the payload signed with
+// opts.
+func payloadContentType(opts notation.SignOptions) string {
+	if opts.PayloadContentType == "" {
+		return notation.MediaTypePayload
+	}
+	return opts.PayloadContentType
+}
+
+// validateProtectedHeader checks the protected header contains the mandatory
+// headers defined by the signature specification, with one of contentTypes
+// as payload content type, and that the signed attributes affecting
+// verification are listed as critical. Only MediaTypePayload is accepted if
+// contentTypes is empty.
+func validateProtectedHeader(header map[string]interface{}, crit []string, attrs signedAttributes, contentTypes []string) error {
+	cty, _ := header["cty"].(string)
+	if len(contentTypes) == 0 {
+		contentTypes = []string{notation.MediaTypePayload}
+	}
+	if !isPresent(cty, contentTypes) {
+		return fmt.Errorf("cty protected header %q is not one of the accepted payload content types %q", cty, contentTypes)
+	}
+	if _, ok := header[headerSigningScheme]; !ok {
+		return fmt.Errorf("%s protected header is missing", headerSigningScheme)
+	}
+	if attrs.signingScheme == notation.SigningSchemeX509 && attrs.signingTime.IsZero() {
+		return fmt.Errorf("%s is required by signing scheme %q", headerSigningTime, notation.SigningSchemeX509)
+	}
+	for _, name := range []string{headerSigningScheme, headerAuthenticSigningTime, headerExpiry} {
+		if _, ok := header[name]; ok && !isPresent(name, crit) {
+			return fmt.Errorf("%s protected header is not listed as critical", name)
+		}
+	}
+	return nil
+}
+
+// validateUnprotectedHeader checks the unprotected header of the envelope sig
+// only contains the allowed headers, none of which is also a protected
+// header.
+func validateUnprotectedHeader(sig []byte, protected map[string]interface{}) error {
+	var envelope struct {
+		Header map[string]json.RawMessage `json:"header"`
+	}
+	if err := json.Unmarshal(sig, &envelope); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(envelope.Header))
+	for name := range envelope.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var unknown []string
+	for _, name := range names {
+		if _, ok := protected[name]; ok {
+			return fmt.Errorf("unprotected header %q collides with a protected header", name)
+		}
+		if !isPresent(name, unprotectedHeaders) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unprotected header contains unsupported headers %q", unknown)
+	}
 	return nil
 }
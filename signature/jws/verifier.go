@@ -13,7 +13,11 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/certpolicy"
 	"github.com/notaryproject/notation-go/crypto/timestamp"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/metrics"
+	"github.com/notaryproject/notation-go/trace"
 )
 
 // maxTimestampAccuracy specifies the max acceptable accuracy for timestamp.
@@ -38,12 +42,72 @@ type Verifier struct {
 	// VerifyOptions is the verify option to verify the certificate of the incoming signature.
 	// The `Intermediates` in the verify options will be ignored and re-contrusted using
 	// the certificates in the incoming signature.
-	// An empty list of `KeyUsages` in the verify options implies `ExtKeyUsageCodeSigning`.
+	// An empty list of `KeyUsages` in the verify options implies the extended key usages
+	// required by CertificatePolicy.
 	VerifyOptions x509.VerifyOptions
 
+	// CertificatePolicy validates the certificate chain of the incoming signature.
+	// certpolicy.Default is used if nil.
+	CertificatePolicy *certpolicy.Policy
+
+	// AlgorithmPolicy rejects the incoming signature if made with algorithms
+	// or keys weaker than its thresholds at the verification time.
+	// No signature is rejected if nil.
+	AlgorithmPolicy *notation.AlgorithmPolicy
+
+	// AIAFetcher completes the certificate chain of the incoming signature with the
+	// intermediates fetched from their AIA URLs, e.g. if the signature only contains
+	// the signing certificate. Chains are not completed if nil.
+	AIAFetcher *certpolicy.AIAFetcher
+
 	// TSARoots is the set of trusted root certificates for verifying the fetched timestamp
 	// signature. If nil, the system roots or the platform verifier are used.
 	TSARoots *x509.CertPool
+
+	// PluginManager resolves the verification plugin declared by a signature
+	// in its protected header.
+	// If nil, no verification plugin is available.
+	PluginManager PluginManager
+
+	// PluginConfig is passed to the verification plugin.
+	PluginConfig map[string]string
+
+	// TrustedIdentities are passed to the verification plugin as part of the trust policy.
+	TrustedIdentities []string
+
+	// RequireVerificationPlugin enforces the verification plugin declared by a signature
+	// to be available, as required by the strict verification level.
+	// If false, a signature declaring an unavailable plugin is verified without it
+	// unless it has critical attributes that only the plugin can process.
+	RequireVerificationPlugin bool
+
+	// PhaseContext returns the context of each phase of Verify, e.g. with
+	// a share of the deadline of the context of Verify. If not nil, the
+	// revocation check of the verification plugin is performed by a separate
+	// verify-signature command, so that it is bounded separately.
+	PhaseContext func(ctx context.Context, phase Phase) (context.Context, context.CancelFunc)
+}
+
+// Phase is a phase of Verifier.Verify bounded by Verifier.PhaseContext.
+type Phase string
+
+const (
+	// PhaseVerification verifies the signature, including fetching the
+	// certificates of its chain from their AIA URLs and the checks of the
+	// verification plugin other than revocation.
+	PhaseVerification Phase = "verification"
+
+	// PhaseRevocation is the revocation check of the verification plugin.
+	PhaseRevocation Phase = "revocation"
+)
+
+// phaseContext returns the context of phase, as returned by PhaseContext
+// if not nil.
+func (v *Verifier) phaseContext(ctx context.Context, phase Phase) (context.Context, context.CancelFunc) {
+	if v.PhaseContext == nil {
+		return ctx, func() {}
+	}
+	return v.PhaseContext(ctx, phase)
 }
 
 // NewVerifier creates a verifier with a set of trusted verification keys.
@@ -53,116 +117,294 @@ func NewVerifier() *Verifier {
 	return &Verifier{}
 }
 
-// Verify verifies the signature and returns the verified descriptor and
-// metadata of the signed artifact.
-func (v *Verifier) Verify(ctx context.Context, sig []byte, opts notation.VerifyOptions) (notation.Descriptor, error) {
+// Verify verifies the signature and returns the outcome of the verification,
+// including the verified descriptor of the signed artifact.
+//
+// All checks are performed once the integrity of the signature is verified,
+// even if some of them fail, so that the outcome reports every failure.
+// The returned error is the error of the first failed check.
+func (v *Verifier) Verify(ctx context.Context, sig []byte, opts notation.VerifyOptions) (_ *notation.VerificationOutcome, err error) {
+	ctx, span := trace.StartSpan(ctx, "notation.Verify")
+	defer func() {
+		trace.EndSpan(span, err)
+		metrics.GetRecorder(ctx).RecordVerify(err)
+	}()
+	outcome := new(notation.VerificationOutcome)
+	if err := opts.Validate(); err != nil {
+		return outcome, err
+	}
+
 	// unpack envelope
 	envelope, err := openEnvelope(sig)
 	if err != nil {
-		return notation.Descriptor{}, err
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
 	}
-
-	// verify signing identity
-	key, err := v.verifySigner(envelope)
+	certs, err := parseSignerCertChain(envelope.Header.CertChain)
 	if err != nil {
-		return notation.Descriptor{}, err
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+	}
+	aiaCtx, cancel := v.phaseContext(ctx, PhaseVerification)
+	certs = v.AIAFetcher.Complete(aiaCtx, certs)
+	cancel()
+	now := jwt.TimeFunc()
+	var currentTime time.Time
+	if opts.Clock != nil {
+		now = opts.Clock()
+		currentTime = now
+	}
+	if err := notation.CheckFIPSCertificateChain(certs); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, err)
 	}
 
 	// verify JWT
 	compact := strings.Join([]string{envelope.Protected, envelope.Payload, envelope.Signature}, ".")
-	claim, err := v.verifyJWT(key, compact)
+	claims, err := v.verifyJWT(certs[0].PublicKey, compact)
 	if err != nil {
-		return notation.Descriptor{}, err
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeInvalidSignature, err))
+	}
+	var header map[string]interface{}
+	if err := decodeBase64URLJSON(envelope.Protected, &header); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.Errorf(notation.ErrorCodeMalformedSignature, "envelope protected header can't be decoded: %w", err))
+	}
+	attrs, err := parseSignedAttributes(header)
+	if err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+	}
+	crit, err := criticalHeaders(header)
+	if err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, err)
+	}
+	if err := validateUnprotectedHeader(sig, header); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+	}
+	if err := validateProtectedHeader(header, crit, attrs, opts.AllowedPayloadContentTypes); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+	}
+	alg, _ := header["alg"].(string)
+	if err := v.AlgorithmPolicy.Check(notation.NewSignatureAlgorithmJWS(alg), certs, now); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, err)
+	}
+	if !attrs.expiry.IsZero() && (claims.ExpiresAt == nil || !claims.ExpiresAt.Time.Equal(attrs.expiry)) {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.Errorf(notation.ErrorCodeMalformedSignature, "%s protected header does not match the expiry of the payload", headerExpiry))
+	}
+	if err := claims.Subject.ValidateDigestAlgorithms(opts.AllowedDigestAlgorithms); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeMalformedSignature, err))
+	}
+	if err := claims.Subject.ValidateMediaTypes(opts.AllowedMediaTypes, opts.AllowedArtifactTypes); err != nil {
+		return outcome, outcome.Fail(notation.CheckIntegrity, notation.WrapError(notation.ErrorCodeInvalidSignature, err))
+	}
+	outcome.ExtendedSignedAttributes = extendedAttributes(header, crit)
+	outcome.Descriptor = claims.Subject
+	outcome.CertificateChain = certs
+	outcome.SelfSigned = notation.IsSelfSigned(certs[0])
+	outcome.SigningAgent = envelope.Header.SigningAgent
+	outcome.UserMetadata = claims.Subject.Annotations
+	outcome.SigningScheme = attrs.signingScheme
+	outcome.SigningTime = attrs.signingTime
+	outcome.AuthenticSigningTime = attrs.authenticSigningTime
+	if claims.ExpiresAt != nil {
+		outcome.Expiry = claims.ExpiresAt.Time
+	}
+	outcome.Pass(notation.CheckIntegrity)
+
+	// verify signing identity
+	v.verifySigner(outcome, certs, envelope.Header.TimeStampToken, envelope.Signature, currentTime)
+
+	// verify expiry
+	if err := validateClaims(claims, now); err != nil {
+		outcome.Fail(notation.CheckExpiry, notation.WrapError(notation.ErrorCodeSignatureExpired, err))
+	} else {
+		outcome.Pass(notation.CheckExpiry)
+		opts.WarnExpiry(outcome.Descriptor, outcome.Expiry, now)
+	}
+
+	// verify user metadata
+	if len(opts.UserMetadata) > 0 {
+		if err := verifyUserMetadata(outcome.UserMetadata, opts.UserMetadata); err != nil {
+			outcome.Fail(notation.CheckUserMetadata, err)
+		} else {
+			outcome.Pass(notation.CheckUserMetadata)
+		}
+	}
+
+	// verify the signed annotations still match the artifact
+	if opts.ArtifactAnnotations != nil {
+		if listed, err := verifyAnnotations(outcome.UserMetadata, opts.ArtifactAnnotations); err != nil {
+			outcome.Fail(notation.CheckAnnotations, err)
+		} else if listed {
+			outcome.Pass(notation.CheckAnnotations)
+		}
+	}
+
+	// verify extended attributes with the verification plugin
+	if err := v.verifyWithPlugin(ctx, outcome, envelope, claims); err != nil {
+		outcome.Fail(notation.CheckAuthenticity, err)
+	}
+	for _, check := range []notation.VerificationCheck{notation.CheckTrustedIdentity, notation.CheckRevocation} {
+		if outcome.Result(check) == nil {
+			outcome.Skip(check)
+		}
+	}
+
+	span.SetAttributes(trace.Attribute{Key: "notation.artifact.digest", Value: outcome.Descriptor.Digest.String()})
+	logger := log.GetLogger(ctx)
+	for _, result := range outcome.Results {
+		logger.Debugf("signature of %s: %v", outcome.Descriptor.Digest, result)
 	}
 
-	return claim, nil
+	return outcome, outcome.Err()
 }
 
-// verifySigner verifies the signing identity and returns the verification key.
-func (v *Verifier) verifySigner(sig *notation.JWSEnvelope) (crypto.PublicKey, error) {
-	if len(sig.Header.CertChain) == 0 {
-		return nil, errors.New("signer certificates not found")
+// VerifySignature verifies the signature sig of the artifact described by
+// desc, and returns the outcome of the verification.
+//
+// In addition to the checks of Verify, the integrity check verifies the
+// signed descriptor has the digest, media type and size of desc, so that
+// callers do not have to match the descriptor of the outcome themselves.
+// The descriptor of the outcome is the signed descriptor, including its
+// signed annotations.
+func (v *Verifier) VerifySignature(ctx context.Context, sig []byte, desc notation.Descriptor, opts notation.VerifyOptions) (*notation.VerificationOutcome, error) {
+	if err := desc.ValidateDigestAlgorithms(opts.AllowedDigestAlgorithms); err != nil {
+		return new(notation.VerificationOutcome), notation.WrapError(notation.ErrorCodeInvalidArgument, err)
 	}
-	return v.verifySignerFromCertChain(sig.Header.CertChain, sig.Header.TimeStampToken, sig.Signature)
+	outcome, err := v.Verify(ctx, sig, opts)
+	if result := outcome.Result(notation.CheckIntegrity); result == nil || result.Status != notation.VerificationPassed {
+		return outcome, err
+	}
+	if signed := outcome.Descriptor; !signed.Equal(desc) {
+		outcome.Fail(notation.CheckIntegrity, fmt.Errorf("signed artifact %s of media type %q and size %d does not match artifact %s of media type %q and size %d", signed.Digest, signed.MediaType, signed.Size, desc.Digest, desc.MediaType, desc.Size))
+		return outcome, outcome.Err()
+	}
+	return outcome, err
 }
 
-// verifySignerFromCertChain verifies the signing identity from the provided certificate
-// chain and returns the verification key. The first certificate of the certificate chain
-// contains the key, which used to sign the artifact.
+// parseSignerCertChain parses the certificate chain of the signature.
+// The first certificate of the certificate chain contains the key,
+// which used to sign the artifact.
 // Reference: RFC 7515 4.1.6 "x5c" (X.509 Certificate Chain) Header Parameter.
-func (v *Verifier) verifySignerFromCertChain(certChain [][]byte, timeStampToken []byte, encodedSig string) (crypto.PublicKey, error) {
-	// prepare for certificate verification
-	certs := make([]*x509.Certificate, 0, len(certChain))
-	for _, certBytes := range certChain {
-		cert, err := x509.ParseCertificate(certBytes)
-		if err != nil {
-			return nil, err
-		}
-		certs = append(certs, cert)
+func parseSignerCertChain(certChain [][]byte) ([]*x509.Certificate, error) {
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "signer certificates not found")
 	}
+	return parseCertChain(certChain)
+}
+
+// verifySigner verifies the signing identity from the provided certificate
+// chain, and records the results of the authenticity and timestamp checks.
+// The certificates are verified at currentTime if not zero, overriding the
+// current time of v.VerifyOptions.
+func (v *Verifier) verifySigner(outcome *notation.VerificationOutcome, certs []*x509.Certificate, timeStampToken []byte, encodedSig string, currentTime time.Time) {
+	// prepare for certificate verification
 	intermediates := x509.NewCertPool()
 	for _, cert := range certs[1:] {
 		intermediates.AddCert(cert)
 	}
 	verifyOpts := v.VerifyOptions
 	verifyOpts.Intermediates = intermediates
+	if !currentTime.IsZero() {
+		verifyOpts.CurrentTime = currentTime
+	}
 	if len(verifyOpts.KeyUsages) == 0 {
-		verifyOpts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+		verifyOpts.KeyUsages = v.CertificatePolicy.RequiredExtKeyUsages()
+	}
+
+	// the certificate chain must meet the certificate policy
+	if err := v.CertificatePolicy.ValidateChain(certs); err != nil {
+		outcome.Fail(notation.CheckAuthenticity, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate does not meet the minimum requirements: %w", err))
+		outcome.Skip(notation.CheckTimestamp)
+		return
+	}
+
+	// The signing time attested by a signing authority is authentic,
+	// so the certificate is verified at the time of signing.
+	if !outcome.AuthenticSigningTime.IsZero() {
+		verifyOpts.CurrentTime = outcome.AuthenticSigningTime
 	}
 
 	// verify the signing certificate
 	checkTimestamp := v.EnforceExpiryValidation
 	cert := certs[0]
-	if _, err := cert.Verify(verifyOpts); err != nil {
-		if certErr, ok := err.(x509.CertificateInvalidError); !ok || certErr.Reason != x509.Expired {
-			return nil, err
+	chains, certErr := cert.Verify(verifyOpts)
+	if certErr != nil {
+		if err, ok := certErr.(x509.CertificateInvalidError); !ok || err.Reason != x509.Expired {
+			outcome.Fail(notation.CheckAuthenticity, notation.WrapError(notation.ErrorCodeUntrustedSigner, certErr))
+			outcome.Skip(notation.CheckTimestamp)
+			return
+		}
+
+		// Signatures of signing authorities are not timestamped,
+		// and the certificate was expired at the authentic signing time.
+		if outcome.SigningScheme == notation.SigningSchemeX509SigningAuthority {
+			outcome.Fail(notation.CheckAuthenticity, notation.WrapError(notation.ErrorCodeUntrustedSigner, certErr))
+			outcome.Skip(notation.CheckTimestamp)
+			return
 		}
 
 		// verification failed due to expired certificate
 		checkTimestamp = true
 	}
-	if checkTimestamp {
-		stampedTime, err := v.verifyTimestamp(timeStampToken, encodedSig)
-		if err != nil {
-			return nil, err
-		}
-		verifyOpts.CurrentTime = stampedTime
-		if _, err := cert.Verify(verifyOpts); err != nil {
-			return nil, err
+	if outcome.SigningScheme == notation.SigningSchemeX509SigningAuthority {
+		checkTimestamp = false
+	}
+	if !checkTimestamp {
+		outcome.VerifiedChains = chains
+		outcome.Pass(notation.CheckAuthenticity)
+		outcome.Skip(notation.CheckTimestamp)
+		return
+	}
+	stampedTime, err := v.verifyTimestamp(timeStampToken, encodedSig, verifyOpts.CurrentTime)
+	if err != nil {
+		if certErr != nil {
+			outcome.Fail(notation.CheckAuthenticity, notation.WrapError(notation.ErrorCodeUntrustedSigner, certErr))
+		} else {
+			outcome.VerifiedChains = chains
+			outcome.Pass(notation.CheckAuthenticity)
 		}
+		outcome.Fail(notation.CheckTimestamp, err)
+		return
+	}
+	verifyOpts.CurrentTime = stampedTime
+	if chains, err := cert.Verify(verifyOpts); err != nil {
+		outcome.Fail(notation.CheckAuthenticity, notation.WrapError(notation.ErrorCodeUntrustedSigner, err))
+	} else {
+		outcome.VerifiedChains = chains
+		outcome.Pass(notation.CheckAuthenticity)
 	}
-	return cert.PublicKey, nil
+	outcome.Pass(notation.CheckTimestamp)
 }
 
-// verifyTimestamp verifies the timestamp token and returns stamped time.
-func (v *Verifier) verifyTimestamp(tokenBytes []byte, encodedSig string) (time.Time, error) {
+// verifyTimestamp verifies the timestamp token at currentTime, or at the
+// current time if zero, and returns stamped time.
+func (v *Verifier) verifyTimestamp(tokenBytes []byte, encodedSig string, currentTime time.Time) (time.Time, error) {
 	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
 	if err != nil {
 		return time.Time{}, err
 	}
-	return verifyTimestamp(sig, tokenBytes, v.TSARoots)
+	return verifyTimestamp(sig, tokenBytes, v.TSARoots, currentTime)
 }
 
 // verifyJWT verifies the JWT token against the specified verification key, and
 // returns notation claim.
-func (v *Verifier) verifyJWT(key crypto.PublicKey, tokenString string) (notation.Descriptor, error) {
-	keySpec, err := keySpecFromKey(key)
+func (v *Verifier) verifyJWT(key crypto.PublicKey, tokenString string) (notaryClaim, error) {
+	keySpec, err := notation.KeySpecFromKey(key)
 	if err != nil {
-		return notation.Descriptor{}, err
+		return notaryClaim{}, err
 	}
 	sigAlg := keySpec.SignatureAlgorithm()
 	var method jwt.SigningMethod
 	if v.ResolveSigningMethod != nil {
 		method, err = v.ResolveSigningMethod(sigAlg)
 		if err != nil {
-			return notation.Descriptor{}, err
+			return notaryClaim{}, err
 		}
 	} else {
-		method = jwt.GetSigningMethod(sigAlg.JWS())
+		method = signingMethod(sigAlg)
 	}
 	// parse and verify token
+	// Note: the registered claims are validated separately as part of the expiry check.
 	parser := &jwt.Parser{
-		ValidMethods: v.ValidMethods,
+		ValidMethods:         v.ValidMethods,
+		SkipClaimsValidation: true,
 	}
 	var claims notaryClaim
 	if _, err := parser.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
@@ -175,15 +417,14 @@ func (v *Verifier) verifyJWT(key crypto.PublicKey, tokenString string) (notation
 		t.Method = method
 		return key, nil
 	}); err != nil {
-		return notation.Descriptor{}, err
+		return notaryClaim{}, err
 	}
 
 	// ensure required claims exist.
-	// Note: the registered claims are already verified by parser.ParseWithClaims().
 	if claims.IssuedAt == nil {
-		return notation.Descriptor{}, errors.New("missing iat")
+		return notaryClaim{}, errors.New("missing iat")
 	}
-	return claims.Subject, nil
+	return claims, nil
 }
 
 // openEnvelope opens the signature envelope and get the embedded signature.
@@ -195,14 +436,16 @@ func openEnvelope(sig []byte) (*notation.JWSEnvelope, error) {
 	return &envelope, nil
 }
 
-// verifyTimestamp verifies the timestamp token and returns stamped time.
-func verifyTimestamp(contentBytes, tokenBytes []byte, roots *x509.CertPool) (time.Time, error) {
+// verifyTimestamp verifies the timestamp token at currentTime, or at the
+// current time if zero, and returns stamped time.
+func verifyTimestamp(contentBytes, tokenBytes []byte, roots *x509.CertPool, currentTime time.Time) (time.Time, error) {
 	token, err := timestamp.ParseSignedToken(tokenBytes)
 	if err != nil {
 		return time.Time{}, err
 	}
 	opts := x509.VerifyOptions{
-		Roots: roots,
+		Roots:       roots,
+		CurrentTime: currentTime,
 	}
 	if _, err := token.Verify(opts); err != nil {
 		return time.Time{}, err
@@ -2,13 +2,23 @@ package jws
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/certpolicy"
 	"github.com/notaryproject/notation-go/crypto/timestamp/timestamptest"
+	"github.com/opencontainers/go-digest"
 )
 
 func TestVerifierInterface(t *testing.T) {
@@ -48,10 +58,11 @@ func TestVerifyWithCertChain(t *testing.T) {
 	roots := x509.NewCertPool()
 	roots.AddCert(cert)
 	v.VerifyOptions.Roots = roots
-	got, err := v.Verify(ctx, sig, vOpts)
+	outcome, err := v.Verify(ctx, sig, vOpts)
 	if err != nil {
 		t.Fatalf("Verify() error = %v", err)
 	}
+	got := outcome.Descriptor
 	if !got.Equal(desc) {
 		t.Errorf("Verify() Descriptor = %v, want %v", got, desc)
 	}
@@ -100,10 +111,11 @@ func TestVerifyWithTimestamp(t *testing.T) {
 
 	// verify again with certificate trusted
 	v.TSARoots = sOpts.TSAVerifyOptions.Roots
-	got, err := v.Verify(ctx, sig, vOpts)
+	outcome, err := v.Verify(ctx, sig, vOpts)
 	if err != nil {
 		t.Fatalf("Verify() error = %v", err)
 	}
+	got := outcome.Descriptor
 	if !got.Equal(desc) {
 		t.Errorf("Verify() Descriptor = %v, want %v", got, desc)
 	}
@@ -111,3 +123,1039 @@ func TestVerifyWithTimestamp(t *testing.T) {
 		t.Errorf("Verify() Descriptor = %v, want %v", got, desc)
 	}
 }
+
+func TestVerify_Outcome(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// verify after the signature expired
+	timeFunc := jwt.TimeFunc
+	jwt.TimeFunc = func() time.Time {
+		return sOpts.Expiry.Add(time.Minute)
+	}
+	defer func() { jwt.TimeFunc = timeFunc }()
+
+	tests := []struct {
+		name    string
+		trusted bool
+		want    map[notation.VerificationCheck]notation.VerificationStatus
+	}{
+		{
+			name:    "trusted",
+			trusted: true,
+			want: map[notation.VerificationCheck]notation.VerificationStatus{
+				notation.CheckIntegrity:       notation.VerificationPassed,
+				notation.CheckAuthenticity:    notation.VerificationPassed,
+				notation.CheckTimestamp:       notation.VerificationSkipped,
+				notation.CheckExpiry:          notation.VerificationFailed,
+				notation.CheckTrustedIdentity: notation.VerificationSkipped,
+				notation.CheckRevocation:      notation.VerificationSkipped,
+			},
+		},
+		{
+			name:    "untrusted",
+			trusted: false,
+			want: map[notation.VerificationCheck]notation.VerificationStatus{
+				notation.CheckIntegrity:       notation.VerificationPassed,
+				notation.CheckAuthenticity:    notation.VerificationFailed,
+				notation.CheckTimestamp:       notation.VerificationSkipped,
+				notation.CheckExpiry:          notation.VerificationFailed,
+				notation.CheckTrustedIdentity: notation.VerificationSkipped,
+				notation.CheckRevocation:      notation.VerificationSkipped,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			if tt.trusted {
+				roots := x509.NewCertPool()
+				roots.AddCert(cert)
+				v.VerifyOptions.Roots = roots
+			}
+			outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{})
+			if err == nil {
+				t.Fatal("Verify() error = nil, want error")
+			}
+			if outcome == nil {
+				t.Fatal("Verify() outcome = nil, want non-nil")
+			}
+			if err != outcome.Err() {
+				t.Errorf("Verify() error = %v, want %v", err, outcome.Err())
+			}
+			if !outcome.Descriptor.Equal(desc) {
+				t.Errorf("Verify() Descriptor = %v, want %v", outcome.Descriptor, desc)
+			}
+			if len(outcome.Results) != len(tt.want) {
+				t.Errorf("Verify() Results = %v, want %d results", outcome.Results, len(tt.want))
+			}
+			for check, want := range tt.want {
+				result := outcome.Result(check)
+				if result == nil {
+					t.Errorf("Result(%s) = nil, want %s", check, want)
+					continue
+				}
+				if result.Status != want {
+					t.Errorf("Result(%s) = %v, want %s", check, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerify_ExpiryWarning(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+
+	tests := []struct {
+		name     string
+		period   time.Duration
+		wantWarn bool
+		wantErr  bool
+	}{
+		{"disabled", 0, false, false},
+		{"expires within period", 2 * time.Hour, true, false},
+		{"expires after period", 30 * time.Minute, false, false},
+		{"negative period", -time.Hour, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warned bool
+			vOpts := notation.VerifyOptions{
+				ExpiryWarningPeriod: tt.period,
+				OnExpiryWarning: func(d notation.Descriptor, expiry time.Time) {
+					warned = true
+					if !d.Equal(desc) {
+						t.Errorf("OnExpiryWarning() desc = %v, want %v", d, desc)
+					}
+					if expiry.Unix() != sOpts.Expiry.Unix() {
+						t.Errorf("OnExpiryWarning() expiry = %v, want %v", expiry, sOpts.Expiry)
+					}
+				},
+			}
+			outcome, err := v.Verify(ctx, sig, vOpts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("OnExpiryWarning() called = %v, want %v", warned, tt.wantWarn)
+			}
+			if !tt.wantErr && outcome.Expiry.Unix() != sOpts.Expiry.Unix() {
+				t.Errorf("Verify() Expiry = %v, want %v", outcome.Expiry, sOpts.Expiry)
+			}
+		})
+	}
+}
+
+func TestVerify_OutcomeTampered(t *testing.T) {
+	outcome, err := NewVerifier().Verify(context.Background(), []byte("{}"), notation.VerifyOptions{})
+	if err == nil {
+		t.Fatal("Verify() error = nil, want error")
+	}
+	result := outcome.Result(notation.CheckIntegrity)
+	if result == nil || result.Status != notation.VerificationFailed {
+		t.Fatalf("Result(integrity) = %v, want failed", result)
+	}
+	if len(outcome.Results) != 1 {
+		t.Errorf("Verify() Results = %v, want only the integrity result", outcome.Results)
+	}
+}
+
+func TestSignVerify_RSAKeySpecAlgorithm(t *testing.T) {
+	tests := []struct {
+		bits int
+		alg  string
+	}{
+		{2048, "PS256"},
+		{3072, "PS384"},
+		{4096, "PS512"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			key, err := rsa.GenerateKey(rand.Reader, tt.bits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert, err := generateCert(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := NewSigner(key, []*x509.Certificate{cert})
+			if err != nil {
+				t.Fatal(err)
+			}
+			desc, sOpts := generateSigningContent(nil)
+			sig, err := s.Sign(context.Background(), desc, sOpts)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			envelope, err := openEnvelope(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var protected notation.JWSProtectedHeader
+			if err := decodeBase64URLJSON(envelope.Protected, &protected); err != nil {
+				t.Fatal(err)
+			}
+			if protected.Algorithm != tt.alg {
+				t.Errorf("Sign() alg = %v, want %v", protected.Algorithm, tt.alg)
+			}
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+// signPSS signs a JWT with the given PSS parameters, bypassing the jwt signing methods.
+func signPSS(t *testing.T, key *rsa.PrivateKey, alg string, hash crypto.Hash, saltLength int) []byte {
+	t.Helper()
+	cert, err := generateCert(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwtToken(alg, notation.SignOptions{SigningScheme: notation.SigningSchemeX509}, packPayload(testDescriptor, notation.SignOptions{}))
+	signingString, err := token.SigningString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := hash.New()
+	h.Write([]byte(signingString))
+	sig, err := rsa.SignPSS(rand.Reader, key, hash, h.Sum(nil), &rsa.PSSOptions{SaltLength: saltLength})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(signingString, ".")
+	envelope, err := json.Marshal(notation.JWSEnvelope{
+		Protected: parts[0],
+		Payload:   parts[1],
+		Signature: base64.RawURLEncoding.EncodeToString(sig),
+		Header:    notation.JWSUnprotectedHeader{CertChain: [][]byte{cert.Raw}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestVerify_PSSParameters(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		alg        string
+		hash       crypto.Hash
+		saltLength int
+		wantErr    bool
+	}{
+		{"valid", "PS384", crypto.SHA384, rsa.PSSSaltLengthEqualsHash, false},
+		{"salt length mismatch", "PS384", crypto.SHA384, rsa.PSSSaltLengthAuto, true},
+		{"hash mismatch", "PS384", crypto.SHA256, rsa.PSSSaltLengthEqualsHash, true},
+		{"algorithm mismatch", "PS256", crypto.SHA256, rsa.PSSSaltLengthEqualsHash, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := signPSS(t, key, tt.alg, tt.hash, tt.saltLength)
+			envelope, err := openEnvelope(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert, err := x509.ParseCertificate(envelope.Header.CertChain[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			_, err = v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignVerify_SigningScheme(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	tests := []struct {
+		scheme  notation.SigningScheme
+		want    notation.SigningScheme
+		wantErr bool
+	}{
+		{"", notation.SigningSchemeX509, false},
+		{notation.SigningSchemeX509, notation.SigningSchemeX509, false},
+		{notation.SigningSchemeX509SigningAuthority, notation.SigningSchemeX509SigningAuthority, false},
+		{"notary.foo", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.scheme), func(t *testing.T) {
+			desc, sOpts := generateSigningContent(nil)
+			sOpts.SigningScheme = tt.scheme
+			sig, err := s.Sign(context.Background(), desc, sOpts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			envelope, err := openEnvelope(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var protected notation.JWSProtectedHeader
+			if err := decodeBase64URLJSON(envelope.Protected, &protected); err != nil {
+				t.Fatal(err)
+			}
+			if protected.SigningScheme != tt.want {
+				t.Errorf("Sign() signing scheme = %v, want %v", protected.SigningScheme, tt.want)
+			}
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if outcome.SigningScheme != tt.want {
+				t.Errorf("Verify() SigningScheme = %v, want %v", outcome.SigningScheme, tt.want)
+			}
+			if got := !outcome.AuthenticSigningTime.IsZero(); got != (tt.want == notation.SigningSchemeX509SigningAuthority) {
+				t.Errorf("Verify() AuthenticSigningTime = %v", outcome.AuthenticSigningTime)
+			}
+		})
+	}
+}
+
+func TestVerify_SigningSchemeRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  map[string]interface{}
+		wantErr bool
+	}{
+		{"x509", nil, false},
+		{"missing signing scheme", map[string]interface{}{headerSigningScheme: nil}, true},
+		{"missing signing time", map[string]interface{}{headerSigningTime: nil}, true},
+		{"malformed signing time", map[string]interface{}{headerSigningTime: time.Now().Unix()}, true},
+		{"missing cty", map[string]interface{}{"cty": nil}, true},
+		{"unexpected cty", map[string]interface{}{"cty": "application/json"}, true},
+		{"expiry not matching the payload", map[string]interface{}{headerExpiry: time.Now().Add(2 * time.Hour).Format(time.RFC3339)}, true},
+		{"unsupported", map[string]interface{}{headerSigningScheme: "notary.foo"}, true},
+		{"authentic signing time with x509", map[string]interface{}{
+			headerSigningScheme:        notation.SigningSchemeX509,
+			headerAuthenticSigningTime: time.Now().Format(time.RFC3339),
+		}, true},
+		{"authentic signing time with signing authority", map[string]interface{}{
+			headerSigningScheme:        notation.SigningSchemeX509SigningAuthority,
+			headerAuthenticSigningTime: time.Now().Add(time.Minute).Format(time.RFC3339),
+		}, false},
+		{"signing authority without authentic signing time", map[string]interface{}{
+			headerSigningScheme: notation.SigningSchemeX509SigningAuthority,
+		}, true},
+		{"malformed authentic signing time", map[string]interface{}{
+			headerSigningScheme:        notation.SigningSchemeX509SigningAuthority,
+			headerAuthenticSigningTime: "now",
+		}, true},
+		{"numeric authentic signing time", map[string]interface{}{
+			headerSigningScheme:        notation.SigningSchemeX509SigningAuthority,
+			headerAuthenticSigningTime: time.Now().Unix(),
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, cert := signWithHeader(t, tt.header)
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if result := outcome.Result(notation.CheckIntegrity); result == nil || result.Status != notation.VerificationFailed {
+					t.Errorf("Result(integrity) = %v, want failed", result)
+				}
+			}
+		})
+	}
+}
+
+func TestVerify_NonCriticalSigningScheme(t *testing.T) {
+	sig, cert := signWithRawHeader(t, map[string]interface{}{
+		"alg":               "PS256",
+		"cty":               notation.MediaTypePayload,
+		headerSigningScheme: notation.SigningSchemeX509,
+		headerSigningTime:   time.Now().Format(time.RFC3339),
+	})
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err == nil || !strings.Contains(err.Error(), "not listed as critical") {
+		t.Errorf("Verify() error = %v, want not listed as critical", err)
+	}
+}
+
+func TestVerify_AuthenticSigningTime(t *testing.T) {
+	// signWithHeader generates the certificate after the signing time
+	// is picked, so leave a margin for the certificate to be valid.
+	now := time.Now().Add(time.Minute)
+	tests := []struct {
+		name        string
+		signingTime time.Time
+		currentTime time.Time
+		wantErr     bool
+	}{
+		{"certificate valid at signing time", now, time.Time{}, false},
+		{"certificate expired after signing time", now, now.Add(48 * time.Hour), false},
+		{"certificate not valid at signing time", now.Add(-48 * time.Hour), time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, cert := signWithHeader(t, map[string]interface{}{
+				headerSigningScheme:        notation.SigningSchemeX509SigningAuthority,
+				headerAuthenticSigningTime: tt.signingTime.Format(time.RFC3339),
+			})
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			v.VerifyOptions.CurrentTime = tt.currentTime
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := outcome.AuthenticSigningTime; got.Unix() != tt.signingTime.Unix() {
+				t.Errorf("Verify() AuthenticSigningTime = %v, want %v", got, tt.signingTime)
+			}
+			if tt.wantErr {
+				if result := outcome.Result(notation.CheckAuthenticity); result == nil || result.Status != notation.VerificationFailed {
+					t.Errorf("Result(authenticity) = %v, want failed", result)
+				}
+			}
+		})
+	}
+}
+
+func TestSignVerify_UserMetadata(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+
+	tests := []struct {
+		name        string
+		metadata    map[string]string
+		required    map[string]string
+		wantSignErr bool
+		wantErr     bool
+	}{
+		{"no metadata", nil, nil, false, false},
+		{"metadata", map[string]string{"buildId": "42"}, nil, false, false},
+		{"required metadata", map[string]string{"buildId": "42", "repo": "acme"}, map[string]string{"buildId": "42"}, false, false},
+		{"required metadata missing", nil, map[string]string{"buildId": "42"}, false, true},
+		{"required metadata mismatch", map[string]string{"buildId": "41"}, map[string]string{"buildId": "42"}, false, true},
+		{"reserved key", map[string]string{"io.cncf.notary.foo": "bar"}, nil, true, false},
+		{"empty key", map[string]string{"": "bar"}, nil, true, false},
+		{"conflicting key", map[string]string{"foo": "baz"}, nil, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, sOpts := generateSigningContent(nil)
+			desc.Annotations = map[string]string{"foo": "bar"}
+			sOpts.UserMetadata = tt.metadata
+			sig, err := s.Sign(context.Background(), desc, sOpts)
+			if (err != nil) != tt.wantSignErr {
+				t.Fatalf("Sign() error = %v, wantErr %v", err, tt.wantSignErr)
+			}
+			if tt.wantSignErr {
+				return
+			}
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{UserMetadata: tt.required})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for k, want := range tt.metadata {
+				if got := outcome.UserMetadata[k]; got != want {
+					t.Errorf("Verify() UserMetadata[%q] = %q, want %q", k, got, want)
+				}
+			}
+			result := outcome.Result(notation.CheckUserMetadata)
+			switch {
+			case len(tt.required) == 0 && result != nil:
+				t.Errorf("Result(userMetadata) = %v, want nil", result)
+			case tt.wantErr && (result == nil || result.Status != notation.VerificationFailed):
+				t.Errorf("Result(userMetadata) = %v, want failed", result)
+			}
+		})
+	}
+}
+
+func TestSignVerify_SignedAnnotations(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+
+	const revision = "org.opencontainers.image.revision"
+	annotations := map[string]string{revision: "6f6d3e1", "org.opencontainers.image.created": "2022-07-01"}
+	tests := []struct {
+		name        string
+		signed      []string
+		current     map[string]string
+		wantSignErr bool
+		wantStatus  notation.VerificationStatus
+	}{
+		{name: "not checked", signed: []string{revision}},
+		{name: "match", signed: []string{revision, "org.example.missing"}, current: map[string]string{revision: "6f6d3e1", "org.example.other": "x"}, wantStatus: notation.VerificationPassed},
+		{name: "tampered", signed: []string{revision}, current: map[string]string{revision: "0000000"}, wantStatus: notation.VerificationFailed},
+		{name: "removed", signed: []string{revision}, current: map[string]string{}, wantStatus: notation.VerificationFailed},
+		{name: "added", signed: []string{revision, "org.example.missing"}, current: map[string]string{revision: "6f6d3e1", "org.example.missing": "x"}, wantStatus: notation.VerificationFailed},
+		{name: "all annotations signed", current: map[string]string{revision: "0000000"}},
+		{name: "reserved key", signed: []string{notation.AnnotationSignedAnnotations}, wantSignErr: true},
+		{name: "invalid key", signed: []string{"a,b"}, wantSignErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, sOpts := generateSigningContent(nil)
+			desc.Annotations = annotations
+			sOpts.SignedAnnotations = tt.signed
+			sig, err := s.Sign(context.Background(), desc, sOpts)
+			if (err != nil) != tt.wantSignErr {
+				t.Fatalf("Sign() error = %v, wantErr %v", err, tt.wantSignErr)
+			}
+			if tt.wantSignErr {
+				return
+			}
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{ArtifactAnnotations: tt.current})
+			if wantErr := tt.wantStatus == notation.VerificationFailed; (err != nil) != wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, wantErr)
+			}
+			if len(tt.signed) > 0 {
+				if _, ok := outcome.Descriptor.Annotations["org.opencontainers.image.created"]; ok {
+					t.Errorf("Verify() Descriptor.Annotations = %v, want the signed annotations only", outcome.Descriptor.Annotations)
+				}
+			}
+			result := outcome.Result(notation.CheckAnnotations)
+			switch {
+			case tt.wantStatus == "" && result != nil:
+				t.Errorf("Result(annotations) = %v, want nil", result)
+			case tt.wantStatus != "" && (result == nil || result.Status != tt.wantStatus):
+				t.Errorf("Result(annotations) = %v, want %s", result, tt.wantStatus)
+			case tt.wantStatus == notation.VerificationFailed && !errors.Is(result.Error, notation.ErrAnnotationsMismatch):
+				t.Errorf("Result(annotations) error = %v, want %v", result.Error, notation.ErrAnnotationsMismatch)
+			}
+		})
+	}
+}
+
+func TestSignVerify_ExtendedSignedAttributes(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	v.PluginManager = mockPluginManager{"foo": verifierPlugin(true, "io.cncf.acme.team")}
+
+	tests := []struct {
+		name        string
+		attrs       []notation.SignedAttribute
+		wantSignErr bool
+		wantErr     bool
+	}{
+		{"non-critical", []notation.SignedAttribute{{Key: "io.cncf.acme.team", Value: "net-monitor"}}, false, false},
+		{"unknown critical", []notation.SignedAttribute{{Key: "io.cncf.acme.team", Value: "net-monitor", Critical: true}}, false, true},
+		{"critical processed by plugin", []notation.SignedAttribute{
+			{Key: headerVerificationPlugin, Value: "foo", Critical: true},
+			{Key: "io.cncf.acme.team", Value: "net-monitor", Critical: true},
+		}, false, false},
+		{"reserved", []notation.SignedAttribute{{Key: "alg", Value: "none"}}, true, false},
+		{"duplicate", []notation.SignedAttribute{{Key: "foo", Value: "bar"}, {Key: "foo", Value: "baz"}}, true, false},
+		{"empty key", []notation.SignedAttribute{{Value: "bar"}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, sOpts := generateSigningContent(nil)
+			sOpts.ExtendedSignedAttributes = tt.attrs
+			sig, err := s.Sign(context.Background(), desc, sOpts)
+			if (err != nil) != tt.wantSignErr {
+				t.Fatalf("Sign() error = %v, wantErr %v", err, tt.wantSignErr)
+			}
+			if tt.wantSignErr {
+				return
+			}
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(outcome.ExtendedSignedAttributes) != len(tt.attrs) {
+				t.Fatalf("Verify() ExtendedSignedAttributes = %v, want %v", outcome.ExtendedSignedAttributes, tt.attrs)
+			}
+			for _, want := range tt.attrs {
+				var found bool
+				for _, got := range outcome.ExtendedSignedAttributes {
+					if got.Key == want.Key {
+						found = true
+						if got.Value != want.Value || got.Critical != want.Critical {
+							t.Errorf("Verify() extended signed attribute = %+v, want %+v", got, want)
+						}
+					}
+				}
+				if !found {
+					t.Errorf("Verify() extended signed attribute %q not found", want.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestSignVerify_DigestAlgorithmPolicy(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+
+	// the sha256 descriptor is rejected by a sha384+ policy
+	strict := []digest.Algorithm{digest.SHA384, digest.SHA512}
+	sOpts.AllowedDigestAlgorithms = strict
+	if _, err := s.Sign(ctx, desc, sOpts); err == nil || !strings.Contains(err.Error(), "is not allowed") {
+		t.Fatalf("Sign() error = %v, want digest algorithm not allowed", err)
+	}
+	sOpts.AllowedDigestAlgorithms = nil
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	if _, err := v.Verify(ctx, sig, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{AllowedDigestAlgorithms: strict})
+	if err == nil || !strings.Contains(err.Error(), "is not allowed") {
+		t.Fatalf("Verify() error = %v, want digest algorithm not allowed", err)
+	}
+	if !errors.Is(err, notation.ErrMalformedSignature) {
+		t.Errorf("Verify() error = %v, want %v", err, notation.ErrMalformedSignature)
+	}
+	if result := outcome.Result(notation.CheckIntegrity); result == nil || result.Status != notation.VerificationFailed {
+		t.Errorf("Result(integrity) = %v, want failed", result)
+	}
+}
+
+func TestSignVerify_MediaTypePolicy(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	desc.ArtifactType = "application/spdx+json"
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{AllowedMediaTypes: []string{desc.MediaType}, AllowedArtifactTypes: []string{desc.ArtifactType}})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if outcome.Descriptor.ArtifactType != desc.ArtifactType {
+		t.Errorf("Verify() Descriptor.ArtifactType = %q, want %q", outcome.Descriptor.ArtifactType, desc.ArtifactType)
+	}
+	for _, opts := range []notation.VerifyOptions{
+		{AllowedMediaTypes: []string{"application/vnd.oci.image.manifest.v1+json"}},
+		{AllowedArtifactTypes: []string{"application/vnd.cyclonedx+json"}},
+	} {
+		outcome, err := v.Verify(ctx, sig, opts)
+		if !errors.Is(err, notation.ErrInvalidSignature) || !strings.Contains(err.Error(), "is not allowed") {
+			t.Errorf("Verify() error = %v, want type not allowed", err)
+		}
+		if result := outcome.Result(notation.CheckIntegrity); result == nil || result.Status != notation.VerificationFailed {
+			t.Errorf("Result(integrity) = %v, want failed", result)
+		}
+	}
+}
+
+func TestSignVerify_PayloadContentType(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	sOpts.PayloadContentType = "application/vnd.acme.payload.v2+json"
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	info, err := Inspect(sig)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if info.ContentType != sOpts.PayloadContentType {
+		t.Errorf("Inspect() ContentType = %q, want %q", info.ContentType, sOpts.PayloadContentType)
+	}
+
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	if _, err := v.Verify(ctx, sig, notation.VerifyOptions{}); !errors.Is(err, notation.ErrMalformedSignature) {
+		t.Errorf("Verify() error = %v, want %v", err, notation.ErrMalformedSignature)
+	}
+	outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{AllowedPayloadContentTypes: []string{notation.MediaTypePayload, sOpts.PayloadContentType}})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !outcome.Descriptor.Equal(desc) {
+		t.Errorf("Verify() Descriptor = %+v, want %+v", outcome.Descriptor, desc)
+	}
+
+	sOpts.PayloadContentType = "not a media type"
+	if _, err := s.Sign(ctx, desc, sOpts); err == nil {
+		t.Error("Sign() error = nil, want invalid payload content type")
+	}
+}
+
+func TestSignVerify_AlgorithmPolicy(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	cutoff := time.Now().Add(time.Minute)
+	v.AlgorithmPolicy = &notation.AlgorithmPolicy{KeySizeRules: []notation.KeySizeRule{{Since: cutoff, MinRSAKeySize: 3072}}}
+	if _, err := v.Verify(ctx, sig, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() before the cutoff error = %v", err)
+	}
+	outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{Clock: func() time.Time { return cutoff }})
+	if !errors.Is(err, notation.ErrWeakAlgorithm) {
+		t.Errorf("Verify() after the cutoff error = %v, want %v", err, notation.ErrWeakAlgorithm)
+	}
+	if result := outcome.Result(notation.CheckIntegrity); result == nil || result.Status != notation.VerificationFailed {
+		t.Errorf("Result(integrity) = %v, want failed", result)
+	}
+}
+
+func TestSignVerify_CertificatePolicy(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	strict := &certpolicy.Policy{MinRSAKeySize: 3072}
+
+	// the 2048-bit signing certificate is rejected by the signer
+	runner := &keyCountingPlugin{sign: validSign(t, key), cert: cert.Raw}
+	s, err := NewSignerPluginWithOptions(runner, "1", nil, SignerPluginOptions{CertificatePolicy: strict})
+	if err != nil {
+		t.Fatalf("NewSignerPluginWithOptions() error = %v", err)
+	}
+	if _, err := s.Sign(ctx, desc, sOpts); !errors.Is(err, notation.ErrInvalidCertificate) {
+		t.Fatalf("Sign() error = %v, want %v", err, notation.ErrInvalidCertificate)
+	}
+
+	// and by the verifier
+	s, err = NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	v := NewVerifier()
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	v.VerifyOptions.Roots = roots
+	if _, err := v.Verify(ctx, sig, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	v.CertificatePolicy = strict
+	outcome, err := v.Verify(ctx, sig, notation.VerifyOptions{})
+	if !errors.Is(err, notation.ErrInvalidCertificate) {
+		t.Fatalf("Verify() error = %v, want %v", err, notation.ErrInvalidCertificate)
+	}
+	if result := outcome.Result(notation.CheckAuthenticity); result == nil || result.Status != notation.VerificationFailed {
+		t.Errorf("Result(authenticity) = %v, want failed", result)
+	}
+}
+
+func TestVerify_UnprotectedHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  map[string]interface{}
+		wantErr string
+	}{
+		{name: "signing agent", header: map[string]interface{}{headerSigningAgent: "notation/1.0.0"}},
+		{name: "unknown header", header: map[string]interface{}{"io.cncf.acme.foo": "bar"}, wantErr: "unsupported headers"},
+		{name: "protected header", header: map[string]interface{}{headerSigningScheme: notation.SigningSchemeX509SigningAuthority}, wantErr: "collides with a protected header"},
+		{name: "content type", header: map[string]interface{}{"cty": "application/json"}, wantErr: "collides with a protected header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, cert := signWithHeader(t, nil)
+			var envelope map[string]interface{}
+			if err := json.Unmarshal(sig, &envelope); err != nil {
+				t.Fatal(err)
+			}
+			header := envelope["header"].(map[string]interface{})
+			for k, v := range tt.header {
+				header[k] = v
+			}
+			sig, err := json.Marshal(envelope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := NewVerifier()
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			v.VerifyOptions.Roots = roots
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if result := outcome.Result(notation.CheckIntegrity); result == nil || result.Status != notation.VerificationFailed {
+				t.Errorf("Result(integrity) = %v, want failed", result)
+			}
+		})
+	}
+}
+
+func TestVerify_Clock(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatalf("generateKeyCertPair() error = %v", err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	desc, sOpts := generateSigningContent(nil)
+	now := time.Now()
+	sOpts.Clock = func() time.Time { return now }
+	sig, err := s.Sign(context.Background(), desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		time time.Time
+		want map[notation.VerificationCheck]notation.VerificationStatus
+	}{
+		{
+			name: "before expiry",
+			time: now.Add(30 * time.Minute),
+			want: map[notation.VerificationCheck]notation.VerificationStatus{
+				notation.CheckAuthenticity: notation.VerificationPassed,
+				notation.CheckExpiry:       notation.VerificationPassed,
+			},
+		},
+		{
+			name: "signature expired",
+			time: now.Add(2 * time.Hour),
+			want: map[notation.VerificationCheck]notation.VerificationStatus{
+				notation.CheckAuthenticity: notation.VerificationPassed,
+				notation.CheckExpiry:       notation.VerificationFailed,
+			},
+		},
+		{
+			name: "certificate expired",
+			time: now.Add(48 * time.Hour),
+			want: map[notation.VerificationCheck]notation.VerificationStatus{
+				notation.CheckAuthenticity: notation.VerificationFailed,
+				notation.CheckExpiry:       notation.VerificationFailed,
+			},
+		},
+		{
+			name: "before signing",
+			time: now.Add(-time.Hour),
+			want: map[notation.VerificationCheck]notation.VerificationStatus{
+				notation.CheckAuthenticity: notation.VerificationFailed,
+				notation.CheckExpiry:       notation.VerificationFailed,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			outcome, _ := v.Verify(context.Background(), sig, notation.VerifyOptions{
+				Clock: func() time.Time { return tt.time },
+			})
+			for check, want := range tt.want {
+				if result := outcome.Result(check); result == nil || result.Status != want {
+					t.Errorf("Result(%v) = %v, want %v", check, result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, cert, err := generateKeyCertPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	desc, sOpts := generateSigningContent(nil)
+	sig, err := s.Sign(ctx, desc, sOpts)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	v := NewVerifier()
+	v.VerifyOptions.Roots = x509.NewCertPool()
+	v.VerifyOptions.Roots.AddCert(cert)
+
+	outcome, err := v.VerifySignature(ctx, sig, desc, notation.VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifySignature() error = %v", err)
+	}
+	if !outcome.Descriptor.Equal(desc) || outcome.Descriptor.Annotations["foo"] != "bar" {
+		t.Errorf("VerifySignature() descriptor = %+v, want %+v", outcome.Descriptor, desc)
+	}
+
+	mismatches := map[string]func(d *notation.Descriptor){
+		"digest":     func(d *notation.Descriptor) { d.Digest = digest.FromString("other") },
+		"media type": func(d *notation.Descriptor) { d.MediaType = "application/octet-stream" },
+		"size":       func(d *notation.Descriptor) { d.Size++ },
+	}
+	for name, mismatch := range mismatches {
+		t.Run(name, func(t *testing.T) {
+			other := desc
+			mismatch(&other)
+			outcome, err := v.VerifySignature(ctx, sig, other, notation.VerifyOptions{})
+			if err == nil {
+				t.Fatalf("VerifySignature() error = nil, want mismatch error")
+			}
+			if result := outcome.Result(notation.CheckIntegrity); result.Status != notation.VerificationFailed || result.Error != err {
+				t.Errorf("integrity result = %v, want failed with %v", result, err)
+			}
+		})
+	}
+
+	// the descriptor is validated
+	if _, err := v.VerifySignature(ctx, sig, notation.Descriptor{}, notation.VerifyOptions{}); !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("VerifySignature() error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+
+	// failures of Verify are returned as is
+	if _, err := v.VerifySignature(ctx, []byte("{}"), desc, notation.VerifyOptions{}); !errors.Is(err, notation.ErrMalformedSignature) {
+		t.Errorf("VerifySignature() error = %v, want %v", err, notation.ErrMalformedSignature)
+	}
+}
@@ -0,0 +1,131 @@
+// Package signature inspects signature envelopes of any format supported by
+// notation, without performing trust evaluation.
+package signature
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/dsse"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+// SignatureEnvelopeInfo is the content of a signature envelope, as read by
+// Parse. None of it is verified.
+type SignatureEnvelopeInfo struct {
+	// MediaType is the media type of the envelope, e.g.
+	// notation.MediaTypeJWSEnvelope.
+	MediaType string
+
+	// Descriptor is the descriptor of the signed artifact, including the
+	// signed annotations. It is only set for JWS envelopes.
+	Descriptor notation.Descriptor
+
+	// PayloadType is the type of the payload of DSSE envelopes, e.g.
+	// dsse.PayloadTypeInToto.
+	PayloadType string
+
+	// SignatureAlgorithm is the signature algorithm of the signature, as
+	// declared by JWS envelopes or implied by the signing certificate of
+	// DSSE envelopes. It is empty if the algorithm is not supported.
+	SignatureAlgorithm notation.SignatureAlgorithm
+
+	// SigningScheme is the signing scheme of the signature, for JWS
+	// envelopes.
+	SigningScheme notation.SigningScheme
+
+	// SigningTime is the signing time claimed by the signer, if any.
+	SigningTime time.Time
+
+	// AuthenticSigningTime is the signing time attested by the signing
+	// authority, if any.
+	AuthenticSigningTime time.Time
+
+	// Expiry is the expiry of the signature, if any.
+	Expiry time.Time
+
+	// SignedAttributes are the extended signed attributes of the signature,
+	// sorted by key.
+	SignedAttributes []notation.SignedAttribute
+
+	// CertificateChain is the certificate chain of the signature, starting
+	// with the signing certificate. For DSSE envelopes, it is the chain of
+	// the first signature.
+	CertificateChain []*x509.Certificate
+
+	// HasTimestamp reports whether the signature has a timestamp
+	// countersignature.
+	HasTimestamp bool
+
+	// SigningAgent identifies the software which produced the signature,
+	// if declared.
+	SigningAgent string
+}
+
+// Parse parses the signature envelope data, either a JWS envelope signed by
+// notation or a DSSE envelope, e.g. of an in-toto attestation, without
+// verifying it, for inspection and debugging.
+func Parse(data []byte) (*SignatureEnvelopeInfo, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "invalid signature envelope: %w", err)
+	}
+	switch {
+	case fields["protected"] != nil:
+		return parseJWS(data)
+	case fields["payloadType"] != nil:
+		return parseDSSE(data)
+	}
+	return nil, notation.WrapError(notation.ErrorCodeMalformedSignature, errors.New("unknown signature envelope format"))
+}
+
+// parseJWS parses a JWS envelope.
+func parseJWS(data []byte) (*SignatureEnvelopeInfo, error) {
+	info, err := jws.Inspect(data)
+	if err != nil {
+		return nil, err
+	}
+	return &SignatureEnvelopeInfo{
+		MediaType:            notation.MediaTypeJWSEnvelope,
+		Descriptor:           info.Descriptor,
+		SignatureAlgorithm:   info.SignatureAlgorithm,
+		SigningScheme:        info.SigningScheme,
+		SigningTime:          info.SigningTime,
+		AuthenticSigningTime: info.AuthenticSigningTime,
+		Expiry:               info.Expiry,
+		SignedAttributes:     info.ExtendedSignedAttributes,
+		CertificateChain:     info.CertificateChain,
+		HasTimestamp:         len(info.TimestampToken) > 0,
+		SigningAgent:         info.SigningAgent,
+	}, nil
+}
+
+// parseDSSE parses a DSSE envelope.
+func parseDSSE(data []byte) (*SignatureEnvelopeInfo, error) {
+	envelope, err := dsse.ParseEnvelope(data)
+	if err != nil {
+		return nil, notation.WrapError(notation.ErrorCodeMalformedSignature, err)
+	}
+	info := &SignatureEnvelopeInfo{
+		MediaType:   dsse.MediaTypeEnvelope,
+		PayloadType: envelope.PayloadType,
+	}
+	sig := envelope.Signatures[0]
+	if len(sig.CertificateChain) == 0 {
+		return info, nil
+	}
+	for _, raw := range sig.CertificateChain {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeMalformedSignature, "invalid certificate chain: %w", err)
+		}
+		info.CertificateChain = append(info.CertificateChain, cert)
+	}
+	if keySpec, err := notation.KeySpecFromKey(info.CertificateChain[0].PublicKey); err == nil {
+		info.SignatureAlgorithm = keySpec.SignatureAlgorithm()
+	}
+	return info, nil
+}
@@ -0,0 +1,136 @@
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/dsse"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
+)
+
+var testDescriptor = notation.Descriptor{
+	MediaType: "application/vnd.oci.image.manifest.v1+json",
+	Digest:    digest.FromString("net-monitor"),
+	Size:      11,
+}
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+func TestParse_JWS(t *testing.T) {
+	key, cert := newTestKey(t)
+	s, err := jws.NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingTime := time.Now().Truncate(time.Second)
+	expiry := signingTime.Add(time.Hour)
+	sig, err := s.Sign(context.Background(), testDescriptor, notation.SignOptions{
+		Expiry:                   expiry,
+		Clock:                    func() time.Time { return signingTime },
+		UserMetadata:             map[string]string{"buildId": "42"},
+		ExtendedSignedAttributes: []notation.SignedAttribute{{Key: "io.acme.team", Value: "net-monitor", Critical: true}},
+		SigningAgent:             "notation/test",
+	})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	info, err := Parse(sig)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if info.MediaType != notation.MediaTypeJWSEnvelope {
+		t.Errorf("MediaType = %q, want %q", info.MediaType, notation.MediaTypeJWSEnvelope)
+	}
+	if !info.Descriptor.Equal(testDescriptor) || info.Descriptor.Annotations["buildId"] != "42" {
+		t.Errorf("Descriptor = %+v, want %+v with the user metadata", info.Descriptor, testDescriptor)
+	}
+	if info.SignatureAlgorithm != notation.ECDSA_SHA_256 || info.SigningScheme != notation.SigningSchemeX509 {
+		t.Errorf("SignatureAlgorithm, SigningScheme = %q, %q, want %q, %q", info.SignatureAlgorithm, info.SigningScheme, notation.ECDSA_SHA_256, notation.SigningSchemeX509)
+	}
+	if !info.SigningTime.Equal(signingTime) || !info.Expiry.Equal(expiry) {
+		t.Errorf("SigningTime, Expiry = %v, %v, want %v, %v", info.SigningTime, info.Expiry, signingTime, expiry)
+	}
+	if len(info.SignedAttributes) != 1 || info.SignedAttributes[0].Key != "io.acme.team" || !info.SignedAttributes[0].Critical {
+		t.Errorf("SignedAttributes = %+v, want io.acme.team", info.SignedAttributes)
+	}
+	if len(info.CertificateChain) != 1 || !info.CertificateChain[0].Equal(cert) {
+		t.Errorf("CertificateChain = %v, want the signing certificate", info.CertificateChain)
+	}
+	if info.HasTimestamp || info.SigningAgent != "notation/test" {
+		t.Errorf("HasTimestamp, SigningAgent = %v, %q, want false, notation/test", info.HasTimestamp, info.SigningAgent)
+	}
+}
+
+func TestParse_DSSE(t *testing.T) {
+	key, cert := newTestKey(t)
+	jwsSigner, err := jws.NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := dsse.NewSigner(jwsSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := s.Sign(context.Background(), "application/vnd.example+json", []byte(`{}`), dsse.SignOptions{})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	info, err := Parse(env)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if info.MediaType != dsse.MediaTypeEnvelope || info.PayloadType != "application/vnd.example+json" {
+		t.Errorf("MediaType, PayloadType = %q, %q, want the DSSE envelope", info.MediaType, info.PayloadType)
+	}
+	if info.SignatureAlgorithm != notation.ECDSA_SHA_256 || len(info.CertificateChain) != 1 {
+		t.Errorf("SignatureAlgorithm, CertificateChain = %q, %v, want %q and the signing certificate", info.SignatureAlgorithm, info.CertificateChain, notation.ECDSA_SHA_256)
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	for _, data := range []string{
+		"not json",
+		`{"foo":"bar"}`,
+		`{"protected":"!!","payload":"","signature":"","header":{"x5c":[]}}`,
+		`{"payloadType":"application/vnd.in-toto+json","signatures":[]}`,
+	} {
+		if _, err := Parse([]byte(data)); !errors.Is(err, notation.ErrMalformedSignature) {
+			t.Errorf("Parse(%s) error = %v, want %v", data, err, notation.ErrMalformedSignature)
+		}
+	}
+}
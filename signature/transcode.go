@@ -0,0 +1,93 @@
+package signature
+
+import (
+	"context"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/config"
+	"github.com/notaryproject/notation-go/signature/cose"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+// Envelope formats assembled by Transcode.
+const (
+	// FormatJWS is the JWS envelope format.
+	FormatJWS = config.SignatureFormatJWS
+
+	// FormatCOSE is the COSE_Sign1 envelope format.
+	FormatCOSE = "cose"
+)
+
+// RawSigner produces raw signatures of the signing inputs of envelopes,
+// e.g. the plugin signers of the jws package whose plugin has the
+// SIGNATURE_GENERATOR capability.
+type RawSigner interface {
+	jws.PayloadSigner
+
+	// SignatureAlgorithm returns the signature algorithm of the signatures
+	// of SignPayload, passing pluginConfig as SignPayload does.
+	SignatureAlgorithm(ctx context.Context, pluginConfig map[string]string) (notation.SignatureAlgorithm, error)
+}
+
+// Transcode signs desc with signer into a signature envelope of format,
+// FormatJWS if empty. The envelope is assembled around the raw signature of
+// its format specific signing input, so that a plugin generating raw
+// signatures supports any format without changes. The extended signed
+// attributes of COSE envelopes are limited to integer, boolean and string
+// values, and COSE envelopes are not timestamped.
+//
+// The signature of an existing envelope cannot be re-wrapped into another
+// format, since it only signs the signing input of its own format.
+func Transcode(ctx context.Context, signer RawSigner, format string, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	if signer == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil signer")
+	}
+	switch format {
+	case "", FormatJWS, FormatCOSE:
+	default:
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "signature format %q is not supported", format)
+	}
+	alg, err := signer.SignatureAlgorithm(ctx, opts.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatCOSE {
+		return signCOSE(ctx, signer, alg, desc, opts)
+	}
+	return jws.SignEnvelope(ctx, signer, alg, desc, opts)
+}
+
+// signCOSE signs desc with signer into a COSE envelope, whose signature has
+// the signature algorithm alg.
+func signCOSE(ctx context.Context, signer jws.PayloadSigner, alg notation.SignatureAlgorithm, desc notation.Descriptor, opts notation.SignOptions) ([]byte, error) {
+	if opts.DryRun {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "dry run is not supported by payload signers")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := desc.ValidateDigestAlgorithms(opts.AllowedDigestAlgorithms); err != nil {
+		return nil, err
+	}
+	if opts.SigningScheme == "" {
+		opts.SigningScheme = notation.SigningSchemeX509
+	}
+	desc, err := jws.SignedDescriptor(desc, opts)
+	if err != nil {
+		return nil, err
+	}
+	signingTime := time.Now()
+	if opts.Clock != nil {
+		signingTime = opts.Clock()
+	}
+	msg, err := cose.NewMessage(alg, desc, signingTime, opts)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := jws.SignPayloadWith(ctx, signer, alg, msg.SigningInput(), opts.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Envelope(sig.Signature, sig.CertificateChain)
+}
@@ -0,0 +1,179 @@
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/crypto/timestamp/timestamptest"
+	"github.com/notaryproject/notation-go/signature/cose"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+func newTestRawSigner(t *testing.T, key crypto.Signer) (RawSigner, *x509.Certificate) {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := jws.NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.(RawSigner), cert
+}
+
+func TestTranscode_JWS(t *testing.T) {
+	key, _ := newTestKey(t)
+	s, _ := newTestRawSigner(t, key)
+	sig, err := Transcode(context.Background(), s, FormatJWS, testDescriptor, notation.SignOptions{
+		UserMetadata: map[string]string{"buildId": "42"},
+	})
+	if err != nil {
+		t.Fatalf("Transcode() error = %v", err)
+	}
+	info, err := Parse(sig)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if info.SignatureAlgorithm != notation.ECDSA_SHA_256 {
+		t.Errorf("SignatureAlgorithm = %v, want %v", info.SignatureAlgorithm, notation.ECDSA_SHA_256)
+	}
+	if !info.Descriptor.Equal(testDescriptor) || info.Descriptor.Annotations["buildId"] != "42" {
+		t.Errorf("Descriptor = %+v, want %+v with the user metadata", info.Descriptor, testDescriptor)
+	}
+}
+
+func TestTranscode_COSE(t *testing.T) {
+	ecKey, _ := newTestKey(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingTime := time.Now().Truncate(time.Second)
+	opts := notation.SignOptions{
+		Expiry:                   signingTime.Add(time.Hour),
+		Clock:                    func() time.Time { return signingTime },
+		ExtendedSignedAttributes: []notation.SignedAttribute{{Key: "io.acme.team", Value: "net-monitor", Critical: true}},
+		SigningAgent:             "notation/test",
+	}
+	tests := []struct {
+		key     crypto.Signer
+		sigSize int
+	}{
+		{key: ecKey, sigSize: 64},
+		{key: rsaKey, sigSize: 384},
+		{key: edKey, sigSize: ed25519.SignatureSize},
+	}
+	for _, tt := range tests {
+		s, cert := newTestRawSigner(t, tt.key)
+		alg, err := s.SignatureAlgorithm(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("SignatureAlgorithm() error = %v", err)
+		}
+		t.Run(string(alg), func(t *testing.T) {
+			env, err := Transcode(context.Background(), s, FormatCOSE, testDescriptor, opts)
+			if err != nil {
+				t.Fatalf("Transcode() error = %v", err)
+			}
+
+			// the signature is the last item of the envelope
+			sig := env[len(env)-tt.sigSize:]
+			msgOpts := opts
+			msgOpts.SigningScheme = notation.SigningSchemeX509
+			msg, err := cose.NewMessage(alg, testDescriptor, signingTime, msgOpts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := msg.Envelope(sig, []*x509.Certificate{cert})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(env) != string(want) {
+				t.Errorf("Transcode() = %x, want %x", env, want)
+			}
+			if err := jws.VerifyPayloadSignature(msg.SigningInput(), sig, cert); err != nil {
+				t.Errorf("VerifyPayloadSignature() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTranscode_COSEDeterministic(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := newTestRawSigner(t, edKey)
+	opts := notation.SignOptions{
+		Clock:         func() time.Time { return time.Unix(1660000000, 0) },
+		Deterministic: true,
+		SigningAgent:  "notation/test",
+	}
+	first, err := Transcode(context.Background(), s, FormatCOSE, testDescriptor, opts)
+	if err != nil {
+		t.Fatalf("Transcode() error = %v", err)
+	}
+	second, err := Transcode(context.Background(), s, FormatCOSE, testDescriptor, opts)
+	if err != nil {
+		t.Fatalf("Transcode() error = %v", err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("Transcode() is not deterministic")
+	}
+}
+
+func TestTranscode_Errors(t *testing.T) {
+	key, _ := newTestKey(t)
+	s, _ := newTestRawSigner(t, key)
+	tsa, err := timestamptest.NewTSA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		signer RawSigner
+		format string
+		opts   notation.SignOptions
+	}{
+		{name: "nil signer", format: FormatJWS},
+		{name: "unsupported format", signer: s, format: "x509"},
+		{name: "dry run", signer: s, format: FormatJWS, opts: notation.SignOptions{DryRun: true}},
+		{name: "COSE dry run", signer: s, format: FormatCOSE, opts: notation.SignOptions{DryRun: true}},
+		{name: "COSE timestamp", signer: s, format: FormatCOSE, opts: notation.SignOptions{TSA: tsa}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Transcode(context.Background(), tt.signer, tt.format, testDescriptor, tt.opts)
+			if !errors.Is(err, notation.ErrInvalidArgument) {
+				t.Errorf("Transcode() error = %v, want %v", err, notation.ErrInvalidArgument)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package awskms provides a notation.Signer backed by an asymmetric AWS KMS
+// key, so that services embedding notation can sign without a plugin.
+//
+// The package does not depend on the AWS SDK. The KMS API is accessed
+// through Client, which is implemented by a thin adapter around the KMS
+// client of the SDK, e.g. with github.com/aws/aws-sdk-go-v2/service/kms:
+//
+//	type kmsClient struct{ *kms.Client }
+//
+//	func (c kmsClient) Sign(ctx context.Context, keyID string, digest []byte, alg string) ([]byte, error) {
+//		out, err := c.Client.Sign(ctx, &kms.SignInput{
+//			KeyId:            aws.String(keyID),
+//			Message:          digest,
+//			MessageType:      types.MessageTypeDigest,
+//			SigningAlgorithm: types.SigningAlgorithmSpec(alg),
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return out.Signature, nil
+//	}
+package awskms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+
+	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+)
+
+// Client is the subset of the AWS KMS API used by the signer.
+type Client interface {
+	// Sign signs digest with the key keyID, which is a key ID, key ARN,
+	// alias name or alias ARN, using the KMS signing algorithm alg, e.g.
+	// "RSASSA_PSS_SHA_256". The message type of the request is DIGEST.
+	Sign(ctx context.Context, keyID string, digest []byte, alg string) ([]byte, error)
+}
+
+// Options configures the KMS signer.
+type Options struct {
+	// KeyID identifies the KMS key, e.g. by key ID, key ARN, alias name or
+	// alias ARN.
+	KeyID string
+
+	// CertificateChain is the certificate chain of the KMS key, starting
+	// with the signing certificate.
+	CertificateChain []*x509.Certificate
+
+	// CertificateChainPath is the path of a PEM or DER file holding the
+	// certificate chain of the KMS key. It is read if CertificateChain is
+	// empty.
+	CertificateChainPath string
+}
+
+// New creates a signer signing with the KMS key of opts through client.
+// The key spec of the KMS key is determined by the signing certificate.
+func New(client Client, opts Options) (notation.Signer, error) {
+	if client == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil KMS client")
+	}
+	if opts.KeyID == "" {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing KMS key ID")
+	}
+	certChain := opts.CertificateChain
+	if len(certChain) == 0 && opts.CertificateChainPath != "" {
+		var err error
+		if certChain, err = corex509.ReadCertificateFile(opts.CertificateChainPath); err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "failed to read the certificate chain of KMS key %q: %w", opts.KeyID, err)
+		}
+	}
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing certificate chain of KMS key %q", opts.KeyID)
+	}
+	keySpec, err := notation.KeySpecFromKey(certChain[0].PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if keySpec == notation.ED25519 {
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported by AWS KMS", keySpec)
+	}
+	key := &kmsKey{
+		client: client,
+		keyID:  opts.KeyID,
+		public: certChain[0].PublicKey,
+	}
+	return signer.NewFromCryptoSigner(key, certChain, keySpec)
+}
+
+// kmsKey is a signer.ContextSigner signing with a KMS key.
+type kmsKey struct {
+	client Client
+	keyID  string
+	public crypto.PublicKey
+}
+
+// Public returns the public key of the signing certificate.
+func (k *kmsKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs digest with the KMS key.
+func (k *kmsKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.SignContext(context.Background(), digest, opts)
+}
+
+// SignContext signs digest with the KMS key.
+func (k *kmsKey) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	alg, err := signingAlgorithm(k.public, opts)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := k.client.Sign(ctx, k.keyID, digest, alg)
+	if err != nil {
+		return nil, fmt.Errorf("KMS key %q failed to sign: %w", k.keyID, err)
+	}
+	return sig, nil
+}
+
+// kmsHashNames maps the hash functions to the suffixes of the KMS signing
+// algorithms.
+var kmsHashNames = map[crypto.Hash]string{
+	crypto.SHA256: "SHA_256",
+	crypto.SHA384: "SHA_384",
+	crypto.SHA512: "SHA_512",
+}
+
+// signingAlgorithm returns the KMS signing algorithm of the key public for
+// the signer options opts.
+func signingAlgorithm(public crypto.PublicKey, opts crypto.SignerOpts) (string, error) {
+	hash, ok := kmsHashNames[opts.HashFunc()]
+	if !ok {
+		return "", fmt.Errorf("hash function %v is not supported by AWS KMS", opts.HashFunc())
+	}
+	switch public.(type) {
+	case *rsa.PublicKey:
+		if _, ok := opts.(*rsa.PSSOptions); !ok {
+			return "", errors.New("only RSASSA-PSS signatures are supported for RSA keys")
+		}
+		return "RSASSA_PSS_" + hash, nil
+	case *ecdsa.PublicKey:
+		return "ECDSA_" + hash, nil
+	}
+	return "", fmt.Errorf("public key of type %T is not supported by AWS KMS", public)
+}
@@ -0,0 +1,128 @@
+package awskms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+)
+
+// fakeKMS signs with local keys as AWS KMS does.
+type fakeKMS struct {
+	keys map[string]crypto.Signer
+	algs []string
+}
+
+func (c *fakeKMS) Sign(ctx context.Context, keyID string, digest []byte, alg string) ([]byte, error) {
+	key, ok := c.keys[keyID]
+	if !ok {
+		return nil, errors.New("NotFoundException: key not found")
+	}
+	c.algs = append(c.algs, alg)
+	var opts crypto.SignerOpts
+	switch alg {
+	case "RSASSA_PSS_SHA_256":
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	case "ECDSA_SHA_384":
+		opts = crypto.SHA384
+	default:
+		return nil, errors.New("InvalidKeyUsageException: unsupported signing algorithm " + alg)
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeKMS{keys: map[string]crypto.Signer{
+		"alias/rsa": rsaKey,
+		"alias/ec":  ecKey,
+	}}
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	tests := []struct {
+		keyID   string
+		key     crypto.Signer
+		wantAlg string
+	}{
+		{"alias/rsa", rsaKey, "RSASSA_PSS_SHA_256"},
+		{"alias/ec", ecKey, "ECDSA_SHA_384"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyID, func(t *testing.T) {
+			cert := signertest.GenerateCert(t, tt.key)
+			s, err := New(client, Options{KeyID: tt.keyID, CertificateChain: []*x509.Certificate{cert}})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			client.algs = nil
+			sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if len(client.algs) != 1 || client.algs[0] != tt.wantAlg {
+				t.Errorf("Sign() used KMS signing algorithms %v, want %s", client.algs, tt.wantAlg)
+			}
+
+			v := jws.NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_CertificateChainPath(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := signertest.GenerateCert(t, key)
+	path := filepath.Join(t.TempDir(), "chain.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeKMS{keys: map[string]crypto.Signer{"alias/ec": key}}
+	s, err := New(client, Options{KeyID: "alias/ec", CertificateChainPath: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := s.Sign(context.Background(), notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}, notation.SignOptions{}); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if _, err := New(client, Options{KeyID: "alias/ec"}); err == nil {
+		t.Errorf("New() without certificate chain error = nil, want error")
+	}
+	if _, err := New(client, Options{CertificateChainPath: path}); err == nil {
+		t.Errorf("New() without key ID error = nil, want error")
+	}
+}
@@ -0,0 +1,279 @@
+// Package azurekv provides a notation.Signer backed by a certificate and its
+// key stored in Azure Key Vault, so that services embedding notation can sign
+// without a plugin.
+//
+// The package calls the REST API of Key Vault, authenticated by a managed
+// identity or by a service principal with a client secret, and does not
+// depend on the Azure SDK.
+package azurekv
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+)
+
+// apiVersion is the version of the Key Vault REST API.
+const apiVersion = "7.3"
+
+// maxResponseSize is the maximum size of the responses read.
+const maxResponseSize = 1 << 20
+
+// pemContentType is the content type of the secrets of certificates stored
+// in PEM format.
+const pemContentType = "application/x-pem-file"
+
+// Options configures the Key Vault signer.
+type Options struct {
+	// VaultURL is the URL of the vault, e.g. https://myvault.vault.azure.net.
+	VaultURL string
+
+	// CertificateName is the name of the certificate, whose key signs.
+	CertificateName string
+
+	// CertificateVersion is the version of the certificate.
+	// The latest version is used if empty.
+	CertificateVersion string
+
+	// Credential authenticates the requests to the vault.
+	Credential Credential
+
+	// Client is the HTTP client used to access the vault.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+// New creates a signer signing with the key of the Key Vault certificate of
+// opts. The certificate chain is retrieved from the vault: the full chain is
+// available if the certificate is stored in PEM format, and only the signing
+// certificate otherwise.
+//
+// The returned signer validates the signatures and the certificate chain as
+// plugin signers do.
+func New(ctx context.Context, opts Options) (notation.Signer, error) {
+	if opts.VaultURL == "" || opts.CertificateName == "" {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing vault URL or certificate name")
+	}
+	if opts.Credential == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing Key Vault credential")
+	}
+	c := &client{
+		credential: opts.Credential,
+		httpClient: opts.Client,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	certURL := strings.TrimSuffix(opts.VaultURL, "/") + "/certificates/" + url.PathEscape(opts.CertificateName)
+	if opts.CertificateVersion != "" {
+		certURL += "/" + url.PathEscape(opts.CertificateVersion)
+	}
+	var bundle struct {
+		KeyID    string `json:"kid"`
+		SecretID string `json:"sid"`
+		Cer      []byte `json:"cer"`
+	}
+	if err := c.do(ctx, http.MethodGet, certURL, nil, &bundle); err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(bundle.Cer)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "certificate %q of the vault is malformed: %w", opts.CertificateName, err)
+	}
+	certChain, err := c.certificateChain(ctx, bundle.SecretID)
+	if err != nil {
+		return nil, err
+	}
+	if len(certChain) == 0 || !certChain[0].Equal(leaf) {
+		certChain = []*x509.Certificate{leaf}
+	}
+	keySpec, err := notation.KeySpecFromKey(leaf.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if keySpec == notation.ED25519 {
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported by Azure Key Vault", keySpec)
+	}
+	key := &vaultKey{
+		client: c,
+		keyID:  bundle.KeyID,
+		public: leaf.PublicKey,
+	}
+	return signer.NewFromCryptoSigner(key, certChain, keySpec)
+}
+
+// client calls the Key Vault REST API.
+type client struct {
+	credential Credential
+	httpClient *http.Client
+}
+
+// certificateChain returns the certificate chain of the secret secretID of
+// a certificate, or nil if the secret is not in PEM format.
+func (c *client) certificateChain(ctx context.Context, secretID string) ([]*x509.Certificate, error) {
+	var secret struct {
+		Value       string `json:"value"`
+		ContentType string `json:"contentType"`
+	}
+	if err := c.do(ctx, http.MethodGet, secretID, nil, &secret); err != nil {
+		return nil, err
+	}
+	if secret.ContentType != pemContentType {
+		return nil, nil
+	}
+	var certs []*x509.Certificate
+	rest := []byte(secret.Value)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		// the secret also holds the private key, which is skipped
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "certificate chain of the vault is malformed: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// sign signs digest with the key keyID and the JWS algorithm alg.
+func (c *client) sign(ctx context.Context, keyID, alg string, digest []byte) ([]byte, error) {
+	req := struct {
+		Algorithm string `json:"alg"`
+		Value     string `json:"value"`
+	}{
+		Algorithm: alg,
+		Value:     base64.RawURLEncoding.EncodeToString(digest),
+	}
+	var resp struct {
+		Value string `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodPost, keyID+"/sign", req, &resp); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(resp.Value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature returned by Key Vault: %w", err)
+	}
+	return sig, nil
+}
+
+// do sends an authenticated request to the vault, with the JSON encoding of
+// in as body if not nil, and decodes the JSON response into out.
+func (c *client) do(ctx context.Context, method, endpoint string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"?api-version="+apiVersion, body)
+	if err != nil {
+		return err
+	}
+	token, err := c.credential.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &vaultErr) == nil && vaultErr.Error.Code != "" {
+			return fmt.Errorf("%s %s: %s: %s: %s", method, endpoint, resp.Status, vaultErr.Error.Code, vaultErr.Error.Message)
+		}
+		return fmt.Errorf("%s %s: %s", method, endpoint, resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
+
+// vaultKey is a signer.ContextSigner signing with a Key Vault key.
+type vaultKey struct {
+	client *client
+	keyID  string
+	public crypto.PublicKey
+}
+
+// Public returns the public key of the certificate.
+func (k *vaultKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs digest with the Key Vault key.
+func (k *vaultKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.SignContext(context.Background(), digest, opts)
+}
+
+// SignContext signs digest with the Key Vault key.
+func (k *vaultKey) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	alg, err := signingAlgorithm(k.public, opts)
+	if err != nil {
+		return nil, err
+	}
+	return k.client.sign(ctx, k.keyID, alg, digest)
+}
+
+// hashSizes maps the hash functions to the suffixes of the JWS algorithms.
+var hashSizes = map[crypto.Hash]string{
+	crypto.SHA256: "256",
+	crypto.SHA384: "384",
+	crypto.SHA512: "512",
+}
+
+// signingAlgorithm returns the Key Vault signing algorithm of the key public
+// for the signer options opts.
+func signingAlgorithm(public crypto.PublicKey, opts crypto.SignerOpts) (string, error) {
+	size, ok := hashSizes[opts.HashFunc()]
+	if !ok {
+		return "", fmt.Errorf("hash function %v is not supported by Azure Key Vault", opts.HashFunc())
+	}
+	switch public.(type) {
+	case *rsa.PublicKey:
+		if _, ok := opts.(*rsa.PSSOptions); !ok {
+			return "", errors.New("only RSASSA-PSS signatures are supported for RSA keys")
+		}
+		return "PS" + size, nil
+	case *ecdsa.PublicKey:
+		return "ES" + size, nil
+	}
+	return "", fmt.Errorf("public key of type %T is not supported by Azure Key Vault", public)
+}
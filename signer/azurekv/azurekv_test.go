@@ -0,0 +1,177 @@
+package azurekv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+)
+
+// staticCredential returns a fixed token.
+type staticCredential string
+
+func (c staticCredential) Token(ctx context.Context) (string, error) {
+	return string(c), nil
+}
+
+// newVault serves a certificate and its key as Key Vault does.
+func newVault(t *testing.T, key *ecdsa.PrivateKey, cert *x509.Certificate) *httptest.Server {
+	t.Helper()
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("api-version") != apiVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"code": "Unauthorized", "message": "invalid token"}})
+			return
+		}
+		switch r.URL.Path {
+		case "/certificates/signing":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"kid": server.URL + "/keys/signing/v1",
+				"sid": server.URL + "/secrets/signing/v1",
+				"cer": cert.Raw,
+			})
+		case "/secrets/signing/v1":
+			json.NewEncoder(w).Encode(map[string]string{"value": secret, "contentType": pemContentType})
+		case "/keys/signing/v1/sign":
+			var req struct {
+				Algorithm string `json:"alg"`
+				Value     string `json:"value"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Algorithm != "ES256" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			digest, _ := base64.RawURLEncoding.DecodeString(req.Value)
+			rInt, sInt, err := ecdsa.Sign(rand.Reader, key, digest)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			sig := make([]byte, 64)
+			rInt.FillBytes(sig[:32])
+			sInt.FillBytes(sig[32:])
+			json.NewEncoder(w).Encode(map[string]string{"kid": server.URL + "/keys/signing/v1", "value": base64.RawURLEncoding.EncodeToString(sig)})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := signertest.GenerateCert(t, key)
+	vault := newVault(t, key, cert)
+
+	s, err := New(context.Background(), Options{
+		VaultURL:        vault.URL,
+		CertificateName: "signing",
+		Credential:      staticCredential("token"),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	v := jws.NewVerifier()
+	v.VerifyOptions.Roots = x509.NewCertPool()
+	v.VerifyOptions.Roots.AddCert(cert)
+	if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	_, err = New(context.Background(), Options{
+		VaultURL:        vault.URL,
+		CertificateName: "signing",
+		Credential:      staticCredential("expired"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("New() error = %v, want invalid token", err)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch {
+		case r.URL.Path == "/tenant/oauth2/v2.0/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("client_secret") != "secret" || r.PostForm.Get("scope") != vaultScope+"/.default" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"aad-token","expires_in":3599}`))
+		case r.URL.Path == "/imds" && r.Header.Get("Metadata") == "true":
+			if r.URL.Query().Get("resource") != vaultScope || r.URL.Query().Get("client_id") != "identity" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"imds-token","expires_in":"3599"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	secretCred := NewClientSecretCredential("tenant", "app", "secret")
+	secretCred.AuthorityHost = server.URL
+	identityCred := NewManagedIdentityCredential("identity")
+	identityCred.Endpoint = server.URL + "/imds"
+	tests := []struct {
+		name string
+		cred Credential
+		want string
+	}{
+		{"client secret", secretCred, "aad-token"},
+		{"managed identity", identityCred, "imds-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			for i := 0; i < 2; i++ {
+				token, err := tt.cred.Token(context.Background())
+				if err != nil {
+					t.Fatalf("Token() error = %v", err)
+				}
+				if token != tt.want {
+					t.Errorf("Token() = %q, want %q", token, tt.want)
+				}
+			}
+			if n := atomic.LoadInt32(&requests); n != 1 {
+				t.Errorf("Token() sent %d requests, want 1 with the token cached", n)
+			}
+		})
+	}
+}
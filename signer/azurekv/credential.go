@@ -0,0 +1,193 @@
+package azurekv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultScope is the OAuth scope, or resource, of Azure Key Vault.
+const vaultScope = "https://vault.azure.net"
+
+// DefaultAuthorityHost is the Azure Active Directory host of the public
+// cloud.
+const DefaultAuthorityHost = "https://login.microsoftonline.com"
+
+// DefaultIMDSEndpoint is the token endpoint of the Azure Instance Metadata
+// Service, used by managed identities.
+const DefaultIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// tokenRefreshMargin is the time before expiry at which tokens are renewed.
+const tokenRefreshMargin = 5 * time.Minute
+
+// Credential provides the access tokens to Azure Key Vault.
+type Credential interface {
+	// Token returns a bearer token for Azure Key Vault.
+	Token(ctx context.Context) (string, error)
+}
+
+// ClientSecretCredential authenticates a service principal with a client
+// secret.
+type ClientSecretCredential struct {
+	// TenantID is the Azure Active Directory tenant of the application.
+	TenantID string
+
+	// ClientID is the application (client) ID of the service principal.
+	ClientID string
+
+	// ClientSecret is the client secret of the service principal.
+	ClientSecret string
+
+	// AuthorityHost is the Azure Active Directory host.
+	// DefaultAuthorityHost is used if empty.
+	AuthorityHost string
+
+	// Client is the HTTP client used to request tokens.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+
+	cache tokenCache
+}
+
+// NewClientSecretCredential creates a credential for the service principal
+// clientID of the tenant tenantID, authenticated with secret.
+func NewClientSecretCredential(tenantID, clientID, secret string) *ClientSecretCredential {
+	return &ClientSecretCredential{
+		TenantID:     tenantID,
+		ClientID:     clientID,
+		ClientSecret: secret,
+	}
+}
+
+// Token returns a bearer token for Azure Key Vault, requesting a new one
+// from Azure Active Directory when the cached token is about to expire.
+func (c *ClientSecretCredential) Token(ctx context.Context) (string, error) {
+	return c.cache.get(func() (*http.Request, error) {
+		host := c.AuthorityHost
+		if host == "" {
+			host = DefaultAuthorityHost
+		}
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {c.ClientID},
+			"client_secret": {c.ClientSecret},
+			"scope":         {vaultScope + "/.default"},
+		}
+		endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(host, "/"), url.PathEscape(c.TenantID))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	}, c.Client)
+}
+
+// ManagedIdentityCredential authenticates the managed identity of the Azure
+// resource running the process, through the Instance Metadata Service.
+type ManagedIdentityCredential struct {
+	// ClientID selects a user-assigned managed identity.
+	// The system-assigned managed identity is used if empty.
+	ClientID string
+
+	// Endpoint is the token endpoint of the Instance Metadata Service.
+	// DefaultIMDSEndpoint is used if empty.
+	Endpoint string
+
+	// Client is the HTTP client used to request tokens.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+
+	cache tokenCache
+}
+
+// NewManagedIdentityCredential creates a credential for the managed
+// identity clientID, or for the system-assigned managed identity if clientID
+// is empty.
+func NewManagedIdentityCredential(clientID string) *ManagedIdentityCredential {
+	return &ManagedIdentityCredential{ClientID: clientID}
+}
+
+// Token returns a bearer token for Azure Key Vault, requesting a new one
+// from the Instance Metadata Service when the cached token is about to
+// expire.
+func (c *ManagedIdentityCredential) Token(ctx context.Context) (string, error) {
+	return c.cache.get(func() (*http.Request, error) {
+		endpoint := c.Endpoint
+		if endpoint == "" {
+			endpoint = DefaultIMDSEndpoint
+		}
+		query := url.Values{
+			"api-version": {"2018-02-01"},
+			"resource":    {vaultScope},
+		}
+		if c.ClientID != "" {
+			query.Set("client_id", c.ClientID)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Metadata", "true")
+		return req, nil
+	}, c.Client)
+}
+
+// tokenCache caches an access token until it is about to expire.
+type tokenCache struct {
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// get returns the cached token, or requests a new token with the request
+// created by newRequest.
+func (c *tokenCache) get(newRequest func() (*http.Request, error), client *http.Client) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Add(tokenRefreshMargin).Before(c.expires) {
+		return c.token, nil
+	}
+	req, err := newRequest()
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request an Azure access token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the Azure access token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to request an Azure access token: %s: %s", resp.Status, body)
+	}
+	// Azure Active Directory returns expires_in as a number, while the
+	// Instance Metadata Service returns it as a string.
+	var token struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("malformed Azure access token response")
+	}
+	seconds, err := strconv.ParseInt(token.ExpiresIn.String(), 10, 64)
+	if err != nil {
+		seconds = 0
+	}
+	c.token = token.AccessToken
+	c.expires = time.Now().Add(time.Duration(seconds) * time.Second)
+	return c.token, nil
+}
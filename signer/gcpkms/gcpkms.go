@@ -0,0 +1,139 @@
+// Package gcpkms provides a notation.Signer backed by an asymmetric signing
+// key version of Google Cloud KMS, so that services embedding notation can
+// sign without a plugin.
+//
+// The package does not depend on the Google Cloud SDK. The KMS API is
+// accessed through Client, which is implemented by a thin adapter around the
+// KMS client of the SDK, e.g. with cloud.google.com/go/kms/apiv1:
+//
+//	type kmsClient struct{ *kms.KeyManagementClient }
+//
+//	func (c kmsClient) AsymmetricSign(ctx context.Context, name string, digest []byte, hash crypto.Hash) ([]byte, error) {
+//		d := &kmspb.Digest{}
+//		switch hash {
+//		case crypto.SHA256:
+//			d.Digest = &kmspb.Digest_Sha256{Sha256: digest}
+//		case crypto.SHA384:
+//			d.Digest = &kmspb.Digest_Sha384{Sha384: digest}
+//		case crypto.SHA512:
+//			d.Digest = &kmspb.Digest_Sha512{Sha512: digest}
+//		}
+//		resp, err := c.KeyManagementClient.AsymmetricSign(ctx, &kmspb.AsymmetricSignRequest{Name: name, Digest: d})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return resp.Signature, nil
+//	}
+package gcpkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+
+	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+)
+
+// Client is the subset of the Cloud KMS API used by the signer.
+type Client interface {
+	// AsymmetricSign signs digest, hashed with hash, with the key version
+	// name, e.g. projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1.
+	AsymmetricSign(ctx context.Context, name string, digest []byte, hash crypto.Hash) ([]byte, error)
+}
+
+// Options configures the Cloud KMS signer.
+type Options struct {
+	// KeyVersionName is the resource name of the key version.
+	// Its algorithm must be an RSA PSS or an EC signing algorithm, matching
+	// the key spec of the signing certificate.
+	KeyVersionName string
+
+	// CertificateChain is the certificate chain of the key version, starting
+	// with the signing certificate.
+	CertificateChain []*x509.Certificate
+
+	// CertificateChainPath is the path of a PEM or DER file holding the
+	// certificate chain of the key version. It is read if CertificateChain
+	// is empty.
+	CertificateChainPath string
+}
+
+// New creates a signer signing with the key version of opts through client.
+// The key spec is determined by the signing certificate.
+func New(client Client, opts Options) (notation.Signer, error) {
+	if client == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil Cloud KMS client")
+	}
+	if opts.KeyVersionName == "" {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing Cloud KMS key version name")
+	}
+	certChain := opts.CertificateChain
+	if len(certChain) == 0 && opts.CertificateChainPath != "" {
+		var err error
+		if certChain, err = corex509.ReadCertificateFile(opts.CertificateChainPath); err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "failed to read the certificate chain of key version %q: %w", opts.KeyVersionName, err)
+		}
+	}
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing certificate chain of key version %q", opts.KeyVersionName)
+	}
+	keySpec, err := notation.KeySpecFromKey(certChain[0].PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	switch certChain[0].PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported by Cloud KMS", keySpec)
+	}
+	key := &kmsKey{
+		client: client,
+		name:   opts.KeyVersionName,
+		public: certChain[0].PublicKey,
+	}
+	return signer.NewFromCryptoSigner(key, certChain, keySpec)
+}
+
+// kmsKey is a signer.ContextSigner signing with a Cloud KMS key version.
+type kmsKey struct {
+	client Client
+	name   string
+	public crypto.PublicKey
+}
+
+// Public returns the public key of the signing certificate.
+func (k *kmsKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs digest with the key version.
+func (k *kmsKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.SignContext(context.Background(), digest, opts)
+}
+
+// SignContext signs digest with the key version. The signature algorithm is
+// determined by the key version, so only the hash of opts is passed on.
+func (k *kmsKey) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := k.public.(*rsa.PublicKey); ok {
+		if _, ok := opts.(*rsa.PSSOptions); !ok {
+			return nil, errors.New("only RSASSA-PSS signatures are supported for RSA keys")
+		}
+	}
+	switch hash := opts.HashFunc(); hash {
+	case crypto.SHA256, crypto.SHA384, crypto.SHA512:
+		sig, err := k.client.AsymmetricSign(ctx, k.name, digest, hash)
+		if err != nil {
+			return nil, fmt.Errorf("key version %q failed to sign: %w", k.name, err)
+		}
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("hash function %v is not supported by Cloud KMS", hash)
+	}
+}
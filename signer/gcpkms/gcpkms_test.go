@@ -0,0 +1,117 @@
+package gcpkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+)
+
+// fakeKMS signs with local keys as Cloud KMS does, the signature algorithm
+// being a property of the key version.
+type fakeKMS struct {
+	keys   map[string]crypto.Signer
+	hashes []crypto.Hash
+}
+
+func (c *fakeKMS) AsymmetricSign(ctx context.Context, name string, digest []byte, hash crypto.Hash) ([]byte, error) {
+	key, ok := c.keys[name]
+	if !ok {
+		return nil, errors.New("rpc error: code = NotFound")
+	}
+	c.hashes = append(c.hashes, hash)
+	var opts crypto.SignerOpts = hash
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+	return key.Sign(rand.Reader, digest, opts)
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const keyRing = "projects/p/locations/global/keyRings/r/cryptoKeys/"
+	client := &fakeKMS{keys: map[string]crypto.Signer{
+		keyRing + "rsa/cryptoKeyVersions/1": rsaKey,
+		keyRing + "ec/cryptoKeyVersions/1":  ecKey,
+	}}
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	tests := []struct {
+		name     string
+		key      crypto.Signer
+		wantHash crypto.Hash
+	}{
+		{keyRing + "rsa/cryptoKeyVersions/1", rsaKey, crypto.SHA384},
+		{keyRing + "ec/cryptoKeyVersions/1", ecKey, crypto.SHA256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := signertest.GenerateCert(t, tt.key)
+			s, err := New(client, Options{KeyVersionName: tt.name, CertificateChain: []*x509.Certificate{cert}})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			client.hashes = nil
+			sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if len(client.hashes) != 1 || client.hashes[0] != tt.wantHash {
+				t.Errorf("Sign() sent digests of %v, want %v", client.hashes, tt.wantHash)
+			}
+
+			v := jws.NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := signertest.GenerateCert(t, key)
+	client := &fakeKMS{}
+	tests := []struct {
+		name   string
+		client Client
+		opts   Options
+	}{
+		{"nil client", nil, Options{KeyVersionName: "k", CertificateChain: []*x509.Certificate{cert}}},
+		{"no key version", client, Options{CertificateChain: []*x509.Certificate{cert}}},
+		{"no certificate chain", client, Options{KeyVersionName: "k"}},
+		{"missing chain file", client, Options{KeyVersionName: "k", CertificateChainPath: "missing.pem"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.client, tt.opts); err == nil {
+				t.Errorf("New() error = nil, want error")
+			}
+		})
+	}
+}
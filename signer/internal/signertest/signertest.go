@@ -0,0 +1,66 @@
+// Package signertest provides the certificate fixtures shared by the tests of
+// the signers.
+package signertest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// GenerateCert generates a self-signed code signing certificate of key.
+func GenerateCert(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+	template := codeSigningTemplate("test")
+	return createCert(t, template, key, template, key)
+}
+
+// GenerateCACert generates a self-signed CA certificate of key, named name.
+func GenerateCACert(t *testing.T, name string, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+	template := codeSigningTemplate(name)
+	template.IsCA = true
+	template.KeyUsage = x509.KeyUsageCertSign
+	template.ExtKeyUsage = nil
+	return createCert(t, template, key, template, key)
+}
+
+// GenerateIssuedCert generates a code signing certificate of key, named name,
+// issued by issuer with issuerKey.
+func GenerateIssuedCert(t *testing.T, name string, key crypto.Signer, issuer *x509.Certificate, issuerKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+	return createCert(t, codeSigningTemplate(name), key, issuer, issuerKey)
+}
+
+// codeSigningTemplate returns the template of a code signing certificate
+// named name, valid for an hour.
+func codeSigningTemplate(name string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		BasicConstraintsValid: true,
+	}
+}
+
+// createCert creates the certificate of key from template, issued by issuer
+// with issuerKey.
+func createCert(t *testing.T, template *x509.Certificate, key crypto.Signer, issuer *x509.Certificate, issuerKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -0,0 +1,333 @@
+// Package pkcs11 provides a notation.Signer backed by a key stored in an HSM
+// or a token accessed through a PKCS#11 module. The signatures are performed
+// on the device, and the certificate chain is assembled from the certificate
+// objects of the token.
+//
+// The package does not link a PKCS#11 module. The module is accessed through
+// Session, which is implemented by a thin adapter around a PKCS#11 binding
+// holding a logged in session, e.g. with github.com/miekg/pkcs11:
+//
+//	type session struct {
+//		ctx *pkcs11.Ctx
+//		h   pkcs11.SessionHandle
+//	}
+//
+//	func (s session) FindObjects(template []signerpkcs11.Attribute) ([]signerpkcs11.ObjectHandle, error) {
+//		attrs := make([]*pkcs11.Attribute, len(template))
+//		for i, a := range template {
+//			attrs[i] = pkcs11.NewAttribute(uint(a.Type), a.Value)
+//		}
+//		if err := s.ctx.FindObjectsInit(s.h, attrs); err != nil {
+//			return nil, err
+//		}
+//		defer s.ctx.FindObjectsFinal(s.h)
+//		objs, _, err := s.ctx.FindObjects(s.h, 64)
+//		...
+//	}
+//
+// GetAttributeValue and Sign are adapted similarly, the latter passing
+// Mechanism.PSSParams, if any, as pkcs11.NewPSSParams.
+package pkcs11
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+)
+
+// ObjectHandle is the handle of an object of a token, i.e. CK_OBJECT_HANDLE.
+type ObjectHandle uint
+
+// AttributeType is the type of an object attribute, i.e. CK_ATTRIBUTE_TYPE.
+type AttributeType uint
+
+// Attribute types used by the signer.
+const (
+	AttributeClass AttributeType = 0x000 // CKA_CLASS
+	AttributeLabel AttributeType = 0x003 // CKA_LABEL
+	AttributeValue AttributeType = 0x011 // CKA_VALUE
+	AttributeID    AttributeType = 0x102 // CKA_ID
+)
+
+// Object classes used by the signer, i.e. CK_OBJECT_CLASS.
+const (
+	ClassCertificate uint = 0x1 // CKO_CERTIFICATE
+	ClassPrivateKey  uint = 0x3 // CKO_PRIVATE_KEY
+)
+
+// Attribute is an attribute of a search template, i.e. CK_ATTRIBUTE.
+// Value is either a uint, e.g. an object class, a string or a []byte.
+type Attribute struct {
+	Type  AttributeType
+	Value interface{}
+}
+
+// MechanismType is the type of a mechanism, i.e. CK_MECHANISM_TYPE.
+type MechanismType uint
+
+// Mechanism types used by the signer.
+const (
+	MechanismRSAPKCSPSS MechanismType = 0x00d  // CKM_RSA_PKCS_PSS
+	MechanismECDSA      MechanismType = 0x1041 // CKM_ECDSA
+	MechanismSHA256     MechanismType = 0x250  // CKM_SHA256
+	MechanismSHA384     MechanismType = 0x260  // CKM_SHA384
+	MechanismSHA512     MechanismType = 0x270  // CKM_SHA512
+)
+
+// Mask generation functions of RSA-PSS, i.e. CK_RSA_PKCS_MGF_TYPE.
+const (
+	MGF1SHA256 uint = 0x2 // CKG_MGF1_SHA256
+	MGF1SHA384 uint = 0x3 // CKG_MGF1_SHA384
+	MGF1SHA512 uint = 0x4 // CKG_MGF1_SHA512
+)
+
+// PSSParams are the parameters of MechanismRSAPKCSPSS, i.e.
+// CK_RSA_PKCS_PSS_PARAMS.
+type PSSParams struct {
+	HashAlg    MechanismType
+	MGF        uint
+	SaltLength uint
+}
+
+// Mechanism is a signing mechanism, i.e. CK_MECHANISM.
+type Mechanism struct {
+	Type MechanismType
+
+	// PSSParams are the parameters of MechanismRSAPKCSPSS.
+	PSSParams *PSSParams
+}
+
+// Session is the subset of a logged in PKCS#11 session used by the signer.
+type Session interface {
+	// FindObjects returns the objects matching template, as done by
+	// C_FindObjectsInit, C_FindObjects and C_FindObjectsFinal.
+	FindObjects(template []Attribute) ([]ObjectHandle, error)
+
+	// GetAttributeValue returns the values of the attributes of types of the
+	// object, as done by C_GetAttributeValue. Attributes the object does not
+	// have are omitted.
+	GetAttributeValue(object ObjectHandle, types []AttributeType) (map[AttributeType][]byte, error)
+
+	// Sign signs data with the key and the mechanism, as done by C_SignInit
+	// and C_Sign.
+	Sign(mechanism Mechanism, key ObjectHandle, data []byte) ([]byte, error)
+}
+
+// Options configures the PKCS#11 signer.
+type Options struct {
+	// Session is the logged in session of the token holding the key.
+	Session Session
+
+	// KeyLabel is the label of the private key.
+	KeyLabel string
+
+	// KeyID is the ID of the private key. If both KeyLabel and KeyID are
+	// set, the key must match both.
+	KeyID []byte
+}
+
+// New creates a signer signing with the private key of opts on the token.
+// The signing certificate is the certificate object of the token with the
+// ID of the key, or with its label if the key has no ID, and it is chained
+// with the certificate objects of the token issuing it.
+func New(opts Options) (notation.Signer, error) {
+	if opts.Session == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil PKCS#11 session")
+	}
+	if opts.KeyLabel == "" && len(opts.KeyID) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing PKCS#11 key label or ID")
+	}
+	s := opts.Session
+	name := keyName(opts)
+	template := []Attribute{{Type: AttributeClass, Value: ClassPrivateKey}}
+	if opts.KeyLabel != "" {
+		template = append(template, Attribute{Type: AttributeLabel, Value: opts.KeyLabel})
+	}
+	if len(opts.KeyID) != 0 {
+		template = append(template, Attribute{Type: AttributeID, Value: opts.KeyID})
+	}
+	keys, err := s.FindObjects(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find private key %s: %w", name, err)
+	}
+	switch len(keys) {
+	case 0:
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "private key %s not found", name)
+	case 1:
+	default:
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "private key %s is ambiguous: %d keys found", name, len(keys))
+	}
+
+	// the signing certificate shares the ID, or the label, of the key
+	attrs, err := s.GetAttributeValue(keys[0], []AttributeType{AttributeID, AttributeLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the attributes of private key %s: %w", name, err)
+	}
+	certTemplate := []Attribute{{Type: AttributeClass, Value: ClassCertificate}}
+	if id := attrs[AttributeID]; len(id) != 0 {
+		certTemplate = append(certTemplate, Attribute{Type: AttributeID, Value: id})
+	} else {
+		certTemplate = append(certTemplate, Attribute{Type: AttributeLabel, Value: string(attrs[AttributeLabel])})
+	}
+	leaves, err := certificates(s, certTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if len(leaves) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "no certificate of private key %s found on the token", name)
+	}
+	tokenCerts, err := certificates(s, []Attribute{{Type: AttributeClass, Value: ClassCertificate}})
+	if err != nil {
+		return nil, err
+	}
+	certChain := buildChain(leaves[0], tokenCerts)
+
+	keySpec, err := notation.KeySpecFromKey(certChain[0].PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	switch certChain[0].PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported by the PKCS#11 signer", keySpec)
+	}
+	key := &tokenKey{
+		session: s,
+		handle:  keys[0],
+		public:  certChain[0].PublicKey,
+	}
+	return signer.NewFromCryptoSigner(key, certChain, keySpec)
+}
+
+// keyName describes the key of opts for error messages.
+func keyName(opts Options) string {
+	if opts.KeyLabel != "" {
+		return fmt.Sprintf("%q", opts.KeyLabel)
+	}
+	return fmt.Sprintf("with ID %x", opts.KeyID)
+}
+
+// certificates returns the certificate objects of the token matching
+// template.
+func certificates(s Session, template []Attribute) ([]*x509.Certificate, error) {
+	handles, err := s.FindObjects(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the certificates of the token: %w", err)
+	}
+	certs := make([]*x509.Certificate, 0, len(handles))
+	for _, h := range handles {
+		attrs, err := s.GetAttributeValue(h, []AttributeType{AttributeValue})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the value of a certificate of the token: %w", err)
+		}
+		cert, err := x509.ParseCertificate(attrs[AttributeValue])
+		if err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "certificate of the token is malformed: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// buildChain returns the chain of leaf, following the certificates of certs
+// which signed it up to a self-issued certificate, or up to a certificate
+// whose issuer is not on the token.
+func buildChain(leaf *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	chain := []*x509.Certificate{leaf}
+	for child := leaf; !bytes.Equal(child.RawIssuer, child.RawSubject) && len(chain) <= len(certs); {
+		var parent *x509.Certificate
+		for _, cert := range certs {
+			if bytes.Equal(child.RawIssuer, cert.RawSubject) && child.CheckSignatureFrom(cert) == nil {
+				parent = cert
+				break
+			}
+		}
+		if parent == nil {
+			break
+		}
+		chain = append(chain, parent)
+		child = parent
+	}
+	return chain
+}
+
+// tokenKey is a signer.ContextSigner signing with a private key of a token.
+type tokenKey struct {
+	session Session
+	handle  ObjectHandle
+	public  crypto.PublicKey
+}
+
+// Public returns the public key of the signing certificate.
+func (k *tokenKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// pssHashes maps the hash functions to the hash mechanisms and the mask
+// generation functions of RSA-PSS.
+var pssHashes = map[crypto.Hash]struct {
+	mechanism MechanismType
+	mgf       uint
+}{
+	crypto.SHA256: {MechanismSHA256, MGF1SHA256},
+	crypto.SHA384: {MechanismSHA384, MGF1SHA384},
+	crypto.SHA512: {MechanismSHA512, MGF1SHA512},
+}
+
+// Sign signs digest on the token. ECDSA signatures, returned by the token as
+// the concatenation of r and s, are ASN.1 DER encoded.
+func (k *tokenKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	switch k.public.(type) {
+	case *rsa.PublicKey:
+		pss, ok := opts.(*rsa.PSSOptions)
+		if !ok {
+			return nil, errors.New("only RSASSA-PSS signatures are supported for RSA keys")
+		}
+		hash, ok := pssHashes[pss.HashFunc()]
+		if !ok {
+			return nil, fmt.Errorf("hash function %v is not supported by the PKCS#11 signer", pss.HashFunc())
+		}
+		return k.session.Sign(Mechanism{
+			Type: MechanismRSAPKCSPSS,
+			PSSParams: &PSSParams{
+				HashAlg:    hash.mechanism,
+				MGF:        hash.mgf,
+				SaltLength: uint(pss.HashFunc().Size()),
+			},
+		}, k.handle, digest)
+	case *ecdsa.PublicKey:
+		sig, err := k.session.Sign(Mechanism{Type: MechanismECDSA}, k.handle, digest)
+		if err != nil {
+			return nil, err
+		}
+		if len(sig) == 0 || len(sig)%2 != 0 {
+			return nil, fmt.Errorf("malformed ECDSA signature of %d bytes returned by the token", len(sig))
+		}
+		n := len(sig) / 2
+		return asn1.Marshal(struct{ R, S *big.Int }{
+			R: new(big.Int).SetBytes(sig[:n]),
+			S: new(big.Int).SetBytes(sig[n:]),
+		})
+	}
+	return nil, fmt.Errorf("public key of type %T is not supported by the PKCS#11 signer", k.public)
+}
+
+// SignContext signs digest on the token. PKCS#11 operations cannot be
+// cancelled, so ctx is only checked before signing.
+func (k *tokenKey) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return k.Sign(nil, digest, opts)
+}
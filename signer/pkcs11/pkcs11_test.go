@@ -0,0 +1,202 @@
+package pkcs11
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+)
+
+// tokenObject is an object of fakeToken.
+type tokenObject struct {
+	class uint
+	label string
+	id    []byte
+	value []byte
+	key   crypto.Signer
+}
+
+// fakeToken is a token holding keys and certificates, signing as PKCS#11
+// modules do.
+type fakeToken struct {
+	objects    []tokenObject
+	mechanisms []Mechanism
+}
+
+func (tok *fakeToken) FindObjects(template []Attribute) ([]ObjectHandle, error) {
+	var handles []ObjectHandle
+	for i, obj := range tok.objects {
+		match := true
+		for _, attr := range template {
+			switch attr.Type {
+			case AttributeClass:
+				match = match && attr.Value.(uint) == obj.class
+			case AttributeLabel:
+				match = match && fmt.Sprint(attr.Value) == obj.label
+			case AttributeID:
+				match = match && bytes.Equal(attr.Value.([]byte), obj.id)
+			default:
+				return nil, errors.New("CKR_ATTRIBUTE_TYPE_INVALID")
+			}
+		}
+		if match {
+			handles = append(handles, ObjectHandle(i))
+		}
+	}
+	return handles, nil
+}
+
+func (tok *fakeToken) GetAttributeValue(object ObjectHandle, types []AttributeType) (map[AttributeType][]byte, error) {
+	obj := tok.objects[object]
+	attrs := make(map[AttributeType][]byte)
+	for _, t := range types {
+		switch t {
+		case AttributeLabel:
+			attrs[t] = []byte(obj.label)
+		case AttributeID:
+			if obj.id != nil {
+				attrs[t] = obj.id
+			}
+		case AttributeValue:
+			if obj.class != ClassCertificate {
+				return nil, errors.New("CKR_ATTRIBUTE_SENSITIVE")
+			}
+			attrs[t] = obj.value
+		}
+	}
+	return attrs, nil
+}
+
+func (tok *fakeToken) Sign(mechanism Mechanism, key ObjectHandle, data []byte) ([]byte, error) {
+	tok.mechanisms = append(tok.mechanisms, mechanism)
+	obj := tok.objects[key]
+	switch mechanism.Type {
+	case MechanismRSAPKCSPSS:
+		hash := map[MechanismType]crypto.Hash{
+			MechanismSHA256: crypto.SHA256,
+			MechanismSHA384: crypto.SHA384,
+			MechanismSHA512: crypto.SHA512,
+		}[mechanism.PSSParams.HashAlg]
+		return obj.key.Sign(rand.Reader, data, &rsa.PSSOptions{SaltLength: int(mechanism.PSSParams.SaltLength), Hash: hash})
+	case MechanismECDSA:
+		key := obj.key.(*ecdsa.PrivateKey)
+		r, ss, err := ecdsa.Sign(rand.Reader, key, data)
+		if err != nil {
+			return nil, err
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		raw := make([]byte, 2*size)
+		r.FillBytes(raw[:size])
+		ss.FillBytes(raw[size:])
+		return raw, nil
+	}
+	return nil, errors.New("CKR_MECHANISM_INVALID")
+}
+
+func TestSigner(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := signertest.GenerateCACert(t, "token CA", caKey)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaCert := signertest.GenerateIssuedCert(t, "rsa", rsaKey, ca, caKey)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecCert := signertest.GenerateIssuedCert(t, "ec", ecKey, ca, caKey)
+
+	token := &fakeToken{objects: []tokenObject{
+		{class: ClassCertificate, label: "ca", value: ca.Raw},
+		{class: ClassPrivateKey, label: "rsa", id: []byte{1}, key: rsaKey},
+		{class: ClassCertificate, label: "rsa cert", id: []byte{1}, value: rsaCert.Raw},
+		{class: ClassPrivateKey, label: "ec", key: ecKey},
+		{class: ClassCertificate, label: "ec", value: ecCert.Raw},
+	}}
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	tests := []struct {
+		name          string
+		opts          Options
+		wantMechanism MechanismType
+	}{
+		{"key ID", Options{KeyID: []byte{1}}, MechanismRSAPKCSPSS},
+		{"key label", Options{KeyLabel: "ec"}, MechanismECDSA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Session = token
+			s, err := New(tt.opts)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			token.mechanisms = nil
+			sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if len(token.mechanisms) != 1 || token.mechanisms[0].Type != tt.wantMechanism {
+				t.Errorf("Sign() used mechanisms %v, want %#x", token.mechanisms, tt.wantMechanism)
+			}
+
+			v := jws.NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(ca)
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if chain := outcome.CertificateChain; len(chain) != 2 || !chain[1].Equal(ca) {
+				t.Errorf("Verify() certificate chain has %d certificates, want the signing certificate and the token CA", len(chain))
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := &fakeToken{objects: []tokenObject{
+		{class: ClassPrivateKey, label: "dup", key: key},
+		{class: ClassPrivateKey, label: "dup", key: key},
+		{class: ClassPrivateKey, label: "no cert", id: []byte{2}, key: key},
+	}}
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"nil session", Options{KeyLabel: "dup"}},
+		{"no key label or ID", Options{Session: token}},
+		{"key not found", Options{Session: token, KeyLabel: "missing"}},
+		{"ambiguous key", Options{Session: token, KeyLabel: "dup"}},
+		{"no certificate", Options{Session: token, KeyLabel: "no cert"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil {
+				t.Errorf("New() error = nil, want error")
+			}
+		})
+	}
+}
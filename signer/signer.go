@@ -0,0 +1,170 @@
+// Package signer adapts signing keys which are not directly supported by
+// the JWS signer, such as keys behind a crypto.Signer, to notation.Signer.
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	// register the hash functions of the signature algorithms
+	_ "crypto/sha512"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/plugin"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+// NewFromCryptoSigner creates a signer producing JWS signatures with key,
+// e.g. a key stored in an HSM or a cloud KMS wrapped as a crypto.Signer.
+// certChain is the certificate chain of key, starting with the signing
+// certificate, and keySpec is the key spec of key, which determines the
+// signature algorithm.
+//
+// key is called with the digest of the payload, hashed with the hash of the
+// signature algorithm, and with rsa.PSSOptions for RSA keys. Ed25519 keys are
+// called with the payload itself. ECDSA signatures may be returned ASN.1 DER
+// encoded, as done by crypto/ecdsa. If key is a ContextSigner, the context
+// of the signing operation is passed to it. If key is a MessageSigner, it is
+// called with the payload and hashes it itself.
+func NewFromCryptoSigner(key crypto.Signer, certChain []*x509.Certificate, keySpec notation.KeySpec) (notation.Signer, error) {
+	if key == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil signing key")
+	}
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing signer certificate chain")
+	}
+	if keySpec.SignatureAlgorithm() == "" {
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported", keySpec)
+	}
+	public, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !public.Equal(certChain[0].PublicKey) {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate %q does not match the signing key", certChain[0].Subject)
+	}
+	runner := &cryptoSignerPlugin{
+		key:       key,
+		keySpec:   keySpec,
+		certChain: make([][]byte, len(certChain)),
+	}
+	for i, cert := range certChain {
+		runner.certChain[i] = cert.Raw
+	}
+	fingerprint := sha256.Sum256(certChain[0].Raw)
+	return jws.NewSignerPlugin(runner, hex.EncodeToString(fingerprint[:]), nil)
+}
+
+// ContextSigner is a crypto.Signer whose signing operations, e.g. calls to a
+// remote KMS, honor a context.
+type ContextSigner interface {
+	crypto.Signer
+
+	// SignContext signs digest as crypto.Signer.Sign does, using the
+	// randomness of the key holder.
+	SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// MessageSigner is a crypto.Signer which signs whole messages, hashing them
+// itself, e.g. a key held by ssh-agent.
+type MessageSigner interface {
+	crypto.Signer
+
+	// SignMessage signs message, hashed with the hash of opts.
+	SignMessage(ctx context.Context, message []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// cryptoSignerPlugin is a plugin.Runner supporting the generate-signature
+// workflow with a crypto.Signer.
+type cryptoSignerPlugin struct {
+	key       crypto.Signer
+	keySpec   notation.KeySpec
+	certChain [][]byte
+}
+
+// CertificateChain returns the certificate chain of the key.
+func (p *cryptoSignerPlugin) CertificateChain(_ context.Context, _ string) ([][]byte, error) {
+	return p.certChain, nil
+}
+
+// Run implements the generate-signature workflow.
+func (p *cryptoSignerPlugin) Run(ctx context.Context, req plugin.Request) (interface{}, error) {
+	switch req := req.(type) {
+	case *plugin.GetMetadataRequest:
+		return &plugin.Metadata{
+			Name:                      "crypto-signer",
+			Description:               "Notation signer backed by a crypto.Signer",
+			Version:                   plugin.ContractVersion,
+			URL:                       "https://github.com/notaryproject/notation-go",
+			SupportedContractVersions: []string{plugin.ContractVersion},
+			Capabilities:              []plugin.Capability{plugin.CapabilitySignatureGenerator},
+		}, nil
+	case *plugin.DescribeKeyRequest:
+		return &plugin.DescribeKeyResponse{
+			KeyID:   req.KeyID,
+			KeySpec: p.keySpec,
+		}, nil
+	case *plugin.GenerateSignatureRequest:
+		sig, err := p.sign(ctx, req.Payload, req.Hash)
+		if err != nil {
+			return nil, plugin.RequestError{
+				Code: plugin.ErrorCodeGeneric,
+				Err:  err,
+			}
+		}
+		return &plugin.GenerateSignatureResponse{
+			KeyID:            req.KeyID,
+			Signature:        sig,
+			SigningAlgorithm: p.keySpec.SignatureAlgorithm(),
+			Hash:             req.Hash,
+			CertificateChain: p.certChain,
+		}, nil
+	}
+	return nil, plugin.RequestError{
+		Code: plugin.ErrorCodeGeneric,
+		Err:  fmt.Errorf("command %q is not supported", req.Command()),
+	}
+}
+
+// sign signs payload with the signature algorithm of the key spec.
+func (p *cryptoSignerPlugin) sign(ctx context.Context, payload []byte, hashAlg notation.HashAlgorithm) ([]byte, error) {
+	alg := p.keySpec.SignatureAlgorithm()
+	var opts crypto.SignerOpts = crypto.Hash(0)
+	if alg != notation.EDDSA_ED25519 {
+		if hashAlg != alg.Hash() {
+			return nil, fmt.Errorf("hash algorithm %q does not match signing algorithm %q", hashAlg, alg)
+		}
+		hash := hashAlg.HashFunc()
+		if !hash.Available() {
+			return nil, errors.New("hash algorithm is not available")
+		}
+		opts = hash
+		switch alg {
+		case notation.RSASSA_PSS_SHA_256, notation.RSASSA_PSS_SHA_384, notation.RSASSA_PSS_SHA_512:
+			opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+		}
+	}
+	if key, ok := p.key.(MessageSigner); ok {
+		return key.SignMessage(ctx, payload, opts)
+	}
+	hash := opts.HashFunc()
+	if hash == 0 {
+		// Ed25519 signs the payload itself
+		return p.signDigest(ctx, payload, opts)
+	}
+	h := hash.New()
+	h.Write(payload)
+	return p.signDigest(ctx, h.Sum(nil), opts)
+}
+
+// signDigest signs digest with the key, passing ctx to a ContextSigner.
+func (p *cryptoSignerPlugin) signDigest(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if key, ok := p.key.(ContextSigner); ok {
+		return key.SignContext(ctx, digest, opts)
+	}
+	return p.key.Sign(rand.Reader, digest, opts)
+}
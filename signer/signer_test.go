@@ -0,0 +1,121 @@
+package signer
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"io"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+)
+
+// opaqueSigner hides the concrete type of a key, as HSM or KMS keys do.
+type opaqueSigner struct {
+	key   crypto.Signer
+	calls int
+}
+
+func (s *opaqueSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s *opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	return s.key.Sign(rand, digest, opts)
+}
+
+func TestNewFromCryptoSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		key     crypto.Signer
+		keySpec notation.KeySpec
+	}{
+		{"RSA", rsaKey, notation.RSA_3072},
+		{"EC", ecKey, notation.EC_256},
+		{"Ed25519", edKey, notation.ED25519},
+	}
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := signertest.GenerateCert(t, tt.key)
+			key := &opaqueSigner{key: tt.key}
+			s, err := NewFromCryptoSigner(key, []*x509.Certificate{cert}, tt.keySpec)
+			if err != nil {
+				t.Fatalf("NewFromCryptoSigner() error = %v", err)
+			}
+			sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if key.calls != 1 {
+				t.Errorf("Sign() called the crypto.Signer %d times, want 1", key.calls)
+			}
+
+			v := jws.NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			outcome, err := v.Verify(context.Background(), sig, notation.VerifyOptions{})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !outcome.Descriptor.Equal(desc) {
+				t.Errorf("Verify() Descriptor = %v, want %v", outcome.Descriptor, desc)
+			}
+		})
+	}
+}
+
+func TestNewFromCryptoSigner_Invalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := signertest.GenerateCert(t, key)
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		certChain []*x509.Certificate
+		keySpec   notation.KeySpec
+	}{
+		{"nil key", nil, []*x509.Certificate{cert}, notation.EC_256},
+		{"no certificate", key, nil, notation.EC_256},
+		{"unsupported key spec", key, []*x509.Certificate{cert}, "EC_224"},
+		{"mismatched certificate", other, []*x509.Certificate{cert}, notation.EC_256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFromCryptoSigner(tt.key, tt.certChain, tt.keySpec); err == nil {
+				t.Errorf("NewFromCryptoSigner() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package sshagent provides a notation.Signer backed by a key held by
+// ssh-agent, so that developers can sign with keys which never touch the
+// disk.
+//
+// The agent is typically reached through the socket of SSH_AUTH_SOCK:
+//
+//	conn, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+//	if err != nil {
+//		return err
+//	}
+//	defer conn.Close()
+//	s, err := sshagent.New(sshagent.Options{
+//		Agent:                agent.NewClient(conn),
+//		CertificateChainPath: "signing.crt",
+//	})
+//
+// ECDSA and Ed25519 keys are supported. RSA keys are not, as ssh-agent only
+// produces RSASSA-PKCS1-v1_5 signatures, while notation requires RSASSA-PSS.
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Options configures the ssh-agent signer.
+type Options struct {
+	// Agent is the client of the agent holding the key.
+	Agent agent.Agent
+
+	// CertificateChain is the certificate chain of the key, starting with the
+	// signing certificate. The key is the agent key of the public key of the
+	// signing certificate.
+	CertificateChain []*x509.Certificate
+
+	// CertificateChainPath is the path of a PEM or DER file holding the
+	// certificate chain of the key. It is read if CertificateChain is empty.
+	CertificateChainPath string
+}
+
+// New creates a signer signing with the agent key of the signing certificate
+// of opts.
+func New(opts Options) (notation.Signer, error) {
+	if opts.Agent == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "nil ssh-agent client")
+	}
+	certChain := opts.CertificateChain
+	if len(certChain) == 0 && opts.CertificateChainPath != "" {
+		var err error
+		if certChain, err = corex509.ReadCertificateFile(opts.CertificateChainPath); err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "failed to read the certificate chain of the ssh-agent key: %w", err)
+		}
+	}
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing certificate chain of the ssh-agent key")
+	}
+	public := certChain[0].PublicKey
+	keySpec, err := notation.KeySpecFromKey(public)
+	if err != nil {
+		return nil, err
+	}
+	switch public.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported by ssh-agent, which signs RSA keys with RSASSA-PKCS1-v1_5 only", keySpec)
+	}
+	sshKey, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, notation.Errorf(notation.ErrorCodeUnsupportedKeySpec, "keySpec %q is not supported by ssh-agent: %w", keySpec, err)
+	}
+	keys, err := opts.Agent.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the ssh-agent keys: %w", err)
+	}
+	var agentKey *agent.Key
+	for _, key := range keys {
+		if bytes.Equal(key.Marshal(), sshKey.Marshal()) {
+			agentKey = key
+			break
+		}
+	}
+	if agentKey == nil {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "ssh-agent holds no key %s of signing certificate %q", ssh.FingerprintSHA256(sshKey), certChain[0].Subject)
+	}
+	key := &agentSigner{
+		agent:  opts.Agent,
+		key:    agentKey,
+		public: public,
+	}
+	return signer.NewFromCryptoSigner(key, certChain, keySpec)
+}
+
+// agentSigner is a signer.MessageSigner signing with an agent key.
+type agentSigner struct {
+	agent  agent.Agent
+	key    ssh.PublicKey
+	public crypto.PublicKey
+}
+
+// Public returns the public key of the signing certificate.
+func (s *agentSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign fails, as the agent signs messages rather than digests.
+func (s *agentSigner) Sign(_ io.Reader, _ []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("ssh-agent keys sign messages, not digests")
+}
+
+// SignMessage signs message with the agent key, which hashes it with the
+// hash of its curve, i.e. SHA-256, SHA-384 or SHA-512 for P-256, P-384 and
+// P-521 respectively, matching the JWS algorithm of the key spec. ECDSA
+// signatures are converted from the SSH wire format to ASN.1 DER.
+//
+// The agent protocol cannot be cancelled, so ctx is only checked before
+// signing.
+func (s *agentSigner) SignMessage(ctx context.Context, message []byte, _ crypto.SignerOpts) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sig, err := s.agent.Sign(s.key, message)
+	if err != nil {
+		return nil, fmt.Errorf("ssh-agent failed to sign: %w", err)
+	}
+	if sig.Format != s.key.Type() {
+		return nil, fmt.Errorf("ssh-agent returned a signature of format %q for a key of type %q", sig.Format, s.key.Type())
+	}
+	switch s.public.(type) {
+	case *ecdsa.PublicKey:
+		var ecSig struct {
+			R *big.Int
+			S *big.Int
+		}
+		if err := ssh.Unmarshal(sig.Blob, &ecSig); err != nil {
+			return nil, fmt.Errorf("malformed ECDSA signature returned by ssh-agent: %w", err)
+		}
+		return asn1.Marshal(ecSig)
+	case ed25519.PublicKey:
+		if len(sig.Blob) != ed25519.SignatureSize {
+			return nil, fmt.Errorf("malformed Ed25519 signature of %d bytes returned by ssh-agent", len(sig.Blob))
+		}
+		return sig.Blob, nil
+	}
+	return nil, fmt.Errorf("public key of type %T is not supported by ssh-agent", s.public)
+}
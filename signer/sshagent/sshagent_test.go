@@ -0,0 +1,110 @@
+package sshagent
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestSigner(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	for _, key := range []interface{}{p256, p521, edKey} {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	tests := []struct {
+		name string
+		key  crypto.Signer
+	}{
+		{"P-256", p256},
+		{"P-521", p521},
+		{"Ed25519", edKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := signertest.GenerateCert(t, tt.key)
+			s, err := New(Options{Agent: keyring, CertificateChain: []*x509.Certificate{cert}})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			v := jws.NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	held, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notHeld, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	for _, key := range []interface{}{held, rsaKey} {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"nil agent", Options{CertificateChain: []*x509.Certificate{signertest.GenerateCert(t, held)}}},
+		{"no certificate chain", Options{Agent: keyring}},
+		{"key not held", Options{Agent: keyring, CertificateChain: []*x509.Certificate{signertest.GenerateCert(t, notHeld)}}},
+		{"RSA key", Options{Agent: keyring, CertificateChain: []*x509.Certificate{signertest.GenerateCert(t, rsaKey)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil {
+				t.Errorf("New() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Package vaulttransit provides a notation.Signer backed by a key of the
+// transit secrets engine of HashiCorp Vault, so that services embedding
+// notation can sign without a plugin.
+//
+// The package calls the HTTP API of Vault, authenticated by a Vault token,
+// and does not depend on the Vault client library. As the transit engine does
+// not store certificates, the certificate chain of the key is provided by the
+// caller.
+package vaulttransit
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+)
+
+// defaultMountPath is the path the transit engine is mounted at by default.
+const defaultMountPath = "transit"
+
+// maxResponseSize is the maximum size of the responses read.
+const maxResponseSize = 1 << 20
+
+// signaturePrefix is the prefix of the signatures returned by the transit
+// engine, followed by the key version.
+const signaturePrefix = "vault:v"
+
+// Options configures the Vault transit signer.
+type Options struct {
+	// Address is the address of the Vault server, e.g.
+	// https://vault.example.com:8200.
+	Address string
+
+	// Token is the Vault token authenticating the requests.
+	Token string
+
+	// Namespace is the Vault Enterprise namespace of the transit engine.
+	Namespace string
+
+	// MountPath is the path the transit engine is mounted at.
+	// "transit" is used if empty.
+	MountPath string
+
+	// KeyName is the name of the transit key.
+	KeyName string
+
+	// KeyVersion is the version of the transit key. The latest version is
+	// used if zero.
+	KeyVersion int
+
+	// CertificateChain is the certificate chain of the key, starting with the
+	// signing certificate.
+	CertificateChain []*x509.Certificate
+
+	// CertificateChainPath is the path of a PEM or DER file holding the
+	// certificate chain of the key. It is read if CertificateChain is empty.
+	CertificateChainPath string
+
+	// Client is the HTTP client used to access Vault.
+	// http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+// New creates a signer signing with the transit key of opts.
+// The key spec is determined by the signing certificate.
+func New(opts Options) (notation.Signer, error) {
+	if opts.Address == "" || opts.KeyName == "" {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing Vault address or transit key name")
+	}
+	if opts.Token == "" {
+		return nil, notation.Errorf(notation.ErrorCodeInvalidArgument, "missing Vault token")
+	}
+	certChain := opts.CertificateChain
+	if len(certChain) == 0 && opts.CertificateChainPath != "" {
+		var err error
+		if certChain, err = corex509.ReadCertificateFile(opts.CertificateChainPath); err != nil {
+			return nil, notation.Errorf(notation.ErrorCodeInvalidCertificate, "failed to read the certificate chain of transit key %q: %w", opts.KeyName, err)
+		}
+	}
+	if len(certChain) == 0 {
+		return nil, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "missing certificate chain of transit key %q", opts.KeyName)
+	}
+	keySpec, err := notation.KeySpecFromKey(certChain[0].PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	mountPath := strings.Trim(opts.MountPath, "/")
+	if mountPath == "" {
+		mountPath = defaultMountPath
+	}
+	key := &transitKey{
+		endpoint:  strings.TrimSuffix(opts.Address, "/") + "/v1/" + mountPath + "/sign/" + url.PathEscape(opts.KeyName),
+		token:     opts.Token,
+		namespace: opts.Namespace,
+		version:   opts.KeyVersion,
+		client:    opts.Client,
+		public:    certChain[0].PublicKey,
+	}
+	if key.client == nil {
+		key.client = http.DefaultClient
+	}
+	return signer.NewFromCryptoSigner(key, certChain, keySpec)
+}
+
+// signRequest is the body of the sign requests of the transit engine.
+type signRequest struct {
+	Input               string `json:"input"`
+	KeyVersion          int    `json:"key_version,omitempty"`
+	Prehashed           bool   `json:"prehashed,omitempty"`
+	SignatureAlgorithm  string `json:"signature_algorithm,omitempty"`
+	SaltLength          string `json:"salt_length,omitempty"`
+	MarshalingAlgorithm string `json:"marshaling_algorithm,omitempty"`
+}
+
+// transitKey is a signer.ContextSigner signing with a transit key.
+type transitKey struct {
+	endpoint  string
+	token     string
+	namespace string
+	version   int
+	client    *http.Client
+	public    crypto.PublicKey
+}
+
+// Public returns the public key of the signing certificate.
+func (k *transitKey) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs digest with the transit key.
+func (k *transitKey) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.SignContext(context.Background(), digest, opts)
+}
+
+// hashAlgorithms maps the hash functions to the hash algorithms of the
+// transit engine.
+var hashAlgorithms = map[crypto.Hash]string{
+	crypto.SHA256: "sha2-256",
+	crypto.SHA384: "sha2-384",
+	crypto.SHA512: "sha2-512",
+}
+
+// SignContext signs digest with the transit key. For Ed25519 keys, digest is
+// the message itself.
+func (k *transitKey) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req := signRequest{
+		Input:      base64.StdEncoding.EncodeToString(digest),
+		KeyVersion: k.version,
+	}
+	endpoint := k.endpoint
+	switch k.public.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		hash, ok := hashAlgorithms[opts.HashFunc()]
+		if !ok {
+			return nil, fmt.Errorf("hash function %v is not supported by Vault", opts.HashFunc())
+		}
+		endpoint += "/" + hash
+		req.Prehashed = true
+		if _, ok := k.public.(*rsa.PublicKey); ok {
+			if _, ok := opts.(*rsa.PSSOptions); !ok {
+				return nil, errors.New("only RSASSA-PSS signatures are supported for RSA keys")
+			}
+			req.SignatureAlgorithm = "pss"
+			req.SaltLength = "hash"
+		} else {
+			req.MarshalingAlgorithm = "asn1"
+		}
+	case ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("public key of type %T is not supported by Vault", k.public)
+	}
+
+	var resp struct {
+		Data struct {
+			Signature string `json:"signature"`
+		} `json:"data"`
+	}
+	if err := k.do(ctx, endpoint, req, &resp); err != nil {
+		return nil, err
+	}
+	// the signature is formatted as vault:v{version}:{base64 signature}
+	sig := resp.Data.Signature
+	i := strings.LastIndex(sig, ":")
+	if !strings.HasPrefix(sig, signaturePrefix) || i < len(signaturePrefix) {
+		return nil, fmt.Errorf("malformed signature returned by Vault: %q", sig)
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature returned by Vault: %w", err)
+	}
+	return raw, nil
+}
+
+// do sends an authenticated POST request to Vault with the JSON encoding of
+// in as body, and decodes the JSON response into out.
+func (k *transitKey) do(ctx context.Context, endpoint string, in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", k.token)
+	req.Header.Set("Content-Type", "application/json")
+	if k.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", k.namespace)
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(data, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("POST %s: %s: %s", endpoint, resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("POST %s: %s", endpoint, resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,207 @@
+package vaulttransit
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/signer/internal/signertest"
+	"github.com/opencontainers/go-digest"
+)
+
+// fakeVault serves the sign endpoint of a transit engine mounted at
+// "secrets/transit", signing with local keys.
+type fakeVault struct {
+	keys     map[string]crypto.Signer
+	requests []string
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != "s.token" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	v.requests = append(v.requests, r.URL.Path)
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/secrets/transit/sign/"), "/")
+	key, ok := v.keys[parts[0]]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors":["signing key not found"]}`))
+		return
+	}
+	var req signRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	input, _ := base64.StdEncoding.DecodeString(req.Input)
+	var opts crypto.SignerOpts = crypto.Hash(0)
+	if len(parts) > 1 {
+		hash := map[string]crypto.Hash{"sha2-256": crypto.SHA256, "sha2-384": crypto.SHA384, "sha2-512": crypto.SHA512}[parts[1]]
+		opts = hash
+		if req.SignatureAlgorithm == "pss" {
+			opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+		}
+	}
+	sig, err := key.Sign(rand.Reader, input, opts)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]string{"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(sig)},
+	})
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := &fakeVault{keys: map[string]crypto.Signer{"rsa": rsaKey, "ec": ecKey, "ed": edKey}}
+	ts := httptest.NewServer(vault)
+	defer ts.Close()
+
+	desc := notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}
+	tests := []struct {
+		keyName  string
+		key      crypto.Signer
+		wantPath string
+	}{
+		{"rsa", rsaKey, "/v1/secrets/transit/sign/rsa/sha2-256"},
+		{"ec", ecKey, "/v1/secrets/transit/sign/ec/sha2-512"},
+		{"ed", edKey, "/v1/secrets/transit/sign/ed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyName, func(t *testing.T) {
+			cert := signertest.GenerateCert(t, tt.key)
+			s, err := New(Options{
+				Address:          ts.URL,
+				Token:            "s.token",
+				MountPath:        "/secrets/transit/",
+				KeyName:          tt.keyName,
+				CertificateChain: []*x509.Certificate{cert},
+				Client:           ts.Client(),
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			vault.requests = nil
+			sig, err := s.Sign(context.Background(), desc, notation.SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if len(vault.requests) != 1 || vault.requests[0] != tt.wantPath {
+				t.Errorf("Sign() requested %v, want %s", vault.requests, tt.wantPath)
+			}
+
+			v := jws.NewVerifier()
+			v.VerifyOptions.Roots = x509.NewCertPool()
+			v.VerifyOptions.Roots.AddCert(cert)
+			if _, err := v.Verify(context.Background(), sig, notation.VerifyOptions{}); err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSigner_VaultError(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(&fakeVault{keys: map[string]crypto.Signer{"ec": key}})
+	defer ts.Close()
+	s, err := New(Options{
+		Address:          ts.URL,
+		Token:            "s.expired",
+		MountPath:        "secrets/transit",
+		KeyName:          "ec",
+		CertificateChain: []*x509.Certificate{signertest.GenerateCert(t, key)},
+		Client:           ts.Client(),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err = s.Sign(context.Background(), notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    digest.FromString("manifest"),
+		Size:      8,
+	}, notation.SignOptions{})
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Sign() error = %v, want permission denied", err)
+	}
+}
+
+func TestTransitKey_MalformedSignature(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"signature":"not-a-vault-signature"}}`))
+	}))
+	defer ts.Close()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &transitKey{endpoint: ts.URL, token: "s.token", client: ts.Client(), public: key.Public()}
+	digest := sha256.Sum256([]byte("payload"))
+	if _, err := k.SignContext(context.Background(), digest[:], crypto.SHA256); err == nil {
+		t.Errorf("SignContext() error = nil, want error")
+	}
+	rsaKey := &transitKey{endpoint: ts.URL, token: "s.token", client: ts.Client(), public: &rsa.PublicKey{}}
+	d384 := sha512.Sum384([]byte("payload"))
+	if _, err := rsaKey.SignContext(context.Background(), d384[:], crypto.SHA384); err == nil {
+		t.Errorf("SignContext() with PKCS #1 v1.5 options error = nil, want error")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := []*x509.Certificate{signertest.GenerateCert(t, key)}
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"no address", Options{Token: "t", KeyName: "k", CertificateChain: chain}},
+		{"no key name", Options{Address: "http://vault", Token: "t", CertificateChain: chain}},
+		{"no token", Options{Address: "http://vault", KeyName: "k", CertificateChain: chain}},
+		{"no certificate chain", Options{Address: "http://vault", Token: "t", KeyName: "k"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil {
+				t.Errorf("New() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package notation
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// StreamSigner is a Signer which is also able to sign the payload streamed
+// along with its descriptor, e.g. payload types whose content, rather than
+// just the descriptor, is embedded in the signature.
+//
+// Signers implementing only Signer keep working with SignStream, which
+// falls back to Signer.Sign.
+type StreamSigner interface {
+	Signer
+
+	// SignStream signs the artifact described by desc, and returns the
+	// signature. If payload is not nil, it streams the content described
+	// by desc, which the signer must check against the digest and the size
+	// of desc if it reads it.
+	SignStream(ctx context.Context, desc Descriptor, payload io.Reader, opts SignOptions) ([]byte, error)
+}
+
+// SignStream signs the artifact described by desc with signer.
+// If signer is a StreamSigner, payload is passed to it. Otherwise, payload,
+// if not nil, is checked against the digest and the size of desc before the
+// descriptor is signed with Signer.Sign.
+func SignStream(ctx context.Context, signer Signer, desc Descriptor, payload io.Reader, opts SignOptions) ([]byte, error) {
+	if signer == nil {
+		return nil, errors.New("nil signer")
+	}
+	if s, ok := signer.(StreamSigner); ok {
+		return s.SignStream(ctx, desc, payload, opts)
+	}
+	if payload != nil {
+		dgst, size, err := digestReader(payload, desc.Digest.Algorithm(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if dgst != desc.Digest || size != desc.Size {
+			return nil, Errorf(ErrorCodeInvalidArgument, "payload of digest %s and size %d does not match the descriptor of digest %s and size %d", dgst, size, desc.Digest, desc.Size)
+		}
+	}
+	return signer.Sign(ctx, desc, opts)
+}
@@ -0,0 +1,64 @@
+package notation
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// streamSigner records the payload streamed to it.
+type streamSigner struct {
+	descriptorSigner
+	payload []byte
+}
+
+func (s *streamSigner) SignStream(ctx context.Context, desc Descriptor, payload io.Reader, opts SignOptions) ([]byte, error) {
+	var err error
+	if s.payload, err = io.ReadAll(payload); err != nil {
+		return nil, err
+	}
+	return s.Sign(ctx, desc, opts)
+}
+
+func TestSignStream(t *testing.T) {
+	const content = "release tarball"
+	desc := Descriptor{
+		MediaType: "application/gzip",
+		Digest:    digest.FromString(content),
+		Size:      int64(len(content)),
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		payload io.Reader
+		wantErr bool
+	}{
+		{name: "no payload"},
+		{name: "payload", payload: strings.NewReader(content)},
+		{name: "mismatched payload", payload: strings.NewReader("other"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := SignStream(ctx, descriptorSigner{}, desc, tt.payload, SignOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SignStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(sig) == 0 {
+				t.Errorf("SignStream() returned an empty signature")
+			}
+		})
+	}
+
+	signer := &streamSigner{}
+	if _, err := SignStream(ctx, signer, desc, strings.NewReader(content), SignOptions{}); err != nil {
+		t.Fatalf("SignStream() error = %v", err)
+	}
+	if !bytes.Equal(signer.payload, []byte(content)) {
+		t.Errorf("SignStream() streamed %q, want %q", signer.payload, content)
+	}
+}
@@ -0,0 +1,80 @@
+// Package trace provides the tracing hooks of notation-go.
+//
+// Tracers are injected through the context with WithTracer. The library
+// starts spans for signing, verification, plugin executions and registry
+// operations with the tracer of the context, if any.
+//
+// The interfaces are modeled after OpenTelemetry so that an OpenTelemetry
+// tracer can be adapted in a few lines without notation-go depending on it:
+//
+//	type otelTracer struct{ tracer oteltrace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string, attrs ...trace.Attribute) (context.Context, trace.Span) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		s := otelSpan{span}
+//		s.SetAttributes(attrs...)
+//		return ctx, s
+//	}
+package trace
+
+import "context"
+
+// Attribute is a key/value pair describing a span.
+type Attribute struct {
+	// Key is the name of the attribute.
+	Key string
+
+	// Value is the value of the attribute.
+	Value interface{}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span named name, and returns a context carrying it.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation being traced.
+type Span interface {
+	// SetAttributes sets attributes of the span.
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError records err as the failure of the operation.
+	RecordError(err error)
+
+	// End ends the span.
+	End()
+}
+
+// contextKey is the context key of the tracer.
+type contextKey struct{}
+
+// WithTracer returns a context carrying tracer.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, contextKey{}, tracer)
+}
+
+// StartSpan starts a span with the tracer of ctx.
+// A no-op span is returned if ctx carries no tracer.
+func StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	tracer, ok := ctx.Value(contextKey{}).(Tracer)
+	if !ok || tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name, attrs...)
+}
+
+// EndSpan records err in span if not nil, and ends span.
+func EndSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// noopSpan discards everything.
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
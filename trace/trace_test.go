@@ -0,0 +1,50 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs []Attribute
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) { s.attrs = append(s.attrs, attrs...) }
+func (s *recordedSpan) RecordError(err error)            { s.err = err }
+func (s *recordedSpan) End()                             { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordedSpan{name: name, attrs: attrs}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestStartSpan(t *testing.T) {
+	// no tracer
+	ctx, span := StartSpan(context.Background(), "noop")
+	if _, ok := span.(noopSpan); !ok {
+		t.Errorf("StartSpan() = %T, want noopSpan", span)
+	}
+	EndSpan(span, errors.New("ignored"))
+
+	tracer := &recordingTracer{}
+	ctx = WithTracer(ctx, tracer)
+	_, span = StartSpan(ctx, "notation.Sign", Attribute{Key: "notation.key.id", Value: "key"})
+	failure := errors.New("sign failed")
+	EndSpan(span, failure)
+	if len(tracer.spans) != 1 {
+		t.Fatalf("StartSpan() started %d spans, want 1", len(tracer.spans))
+	}
+	got := tracer.spans[0]
+	if got.name != "notation.Sign" || len(got.attrs) != 1 || got.err != failure || !got.ended {
+		t.Errorf("StartSpan() recorded %+v, want ended span notation.Sign with error", got)
+	}
+}
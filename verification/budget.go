@@ -0,0 +1,88 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+// PhaseBudget apportions the deadline of the context of Verifier.Verify
+// across the phases of the verification, as percentages of the time left
+// when Verify is called, so that a slow phase, e.g. a slow OCSP responder
+// queried by a verification plugin, cannot consume the whole deadline.
+//
+// Each phase must complete by the end of the cumulative share of the phases
+// up to it, and the time left by a phase goes to the next phases. For
+// example, with a budget of 60/20/20, signatures must be fetched within 60%
+// of the time left, and verified, except for revocation, within 80%.
+type PhaseBudget struct {
+	// Fetch is the share of fetching the signatures from the repository.
+	Fetch int
+
+	// Verification is the share of verifying the signatures, including the
+	// checks of the verification plugins other than revocation.
+	Verification int
+
+	// Revocation is the share of the revocation checks of the verification
+	// plugins.
+	Revocation int
+}
+
+// Validate validates the shares are not negative and sum to 100.
+func (b *PhaseBudget) Validate() error {
+	if b.Fetch < 0 || b.Verification < 0 || b.Revocation < 0 {
+		return fmt.Errorf("phase budget %d/%d/%d has a negative share", b.Fetch, b.Verification, b.Revocation)
+	}
+	if sum := b.Fetch + b.Verification + b.Revocation; sum != 100 {
+		return fmt.Errorf("phase budget %d/%d/%d sums to %d%%, not 100%%", b.Fetch, b.Verification, b.Revocation, sum)
+	}
+	return nil
+}
+
+// phaseDeadlines are the deadlines of the phases of a verification.
+// A nil *phaseDeadlines bounds nothing.
+type phaseDeadlines struct {
+	fetch        time.Time
+	verification time.Time
+}
+
+// deadlines apportions the deadline of ctx by the budget. It returns nil if
+// the budget is nil or ctx has no deadline.
+func (b *PhaseBudget) deadlines(ctx context.Context) *phaseDeadlines {
+	if b == nil {
+		return nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	left := deadline.Sub(now)
+	share := func(percent int) time.Time {
+		return now.Add(left * time.Duration(percent) / 100)
+	}
+	return &phaseDeadlines{
+		fetch:        share(b.Fetch),
+		verification: share(b.Fetch + b.Verification),
+	}
+}
+
+// fetchContext returns the context of fetching signatures.
+func (d *phaseDeadlines) fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d == nil {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, d.fetch)
+}
+
+// phaseContext returns the context of the phase of jws.Verifier.Verify,
+// suitable for jws.Verifier.PhaseContext. The revocation phase is bounded
+// by the deadline of ctx.
+func (d *phaseDeadlines) phaseContext(ctx context.Context, phase jws.Phase) (context.Context, context.CancelFunc) {
+	if phase == jws.PhaseVerification {
+		return context.WithDeadline(ctx, d.verification)
+	}
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,102 @@
+package verification
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestPhaseBudget_Validate(t *testing.T) {
+	tests := []struct {
+		budget  PhaseBudget
+		wantErr bool
+	}{
+		{budget: PhaseBudget{Fetch: 60, Verification: 20, Revocation: 20}},
+		{budget: PhaseBudget{Fetch: 50, Verification: 50}},
+		{budget: PhaseBudget{Fetch: 60, Verification: 20, Revocation: 10}, wantErr: true},
+		{budget: PhaseBudget{Fetch: 120, Verification: -20}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.budget.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.budget, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPhaseBudget_Deadlines(t *testing.T) {
+	budget := &PhaseBudget{Fetch: 60, Verification: 20, Revocation: 20}
+	if d := budget.deadlines(context.Background()); d != nil {
+		t.Errorf("deadlines() = %+v, want nil without deadline", d)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	d := budget.deadlines(ctx)
+	if d == nil {
+		t.Fatal("deadlines() = nil")
+	}
+	if left := deadline.Sub(d.fetch); left < 3900*time.Millisecond || left > 4*time.Second {
+		t.Errorf("fetch deadline %v before the deadline, want 4s", left)
+	}
+	if left := deadline.Sub(d.verification); left < 1900*time.Millisecond || left > 2*time.Second {
+		t.Errorf("verification deadline %v before the deadline, want 2s", left)
+	}
+	for phase, want := range map[jws.Phase]time.Time{jws.PhaseVerification: d.verification, jws.PhaseRevocation: deadline} {
+		phaseCtx, cancel := d.phaseContext(ctx, phase)
+		if got, _ := phaseCtx.Deadline(); !got.Equal(want) {
+			t.Errorf("phaseContext(%s) deadline = %v, want %v", phase, got, want)
+		}
+		cancel()
+	}
+}
+
+// blockingRepository blocks fetching signatures until the context is done.
+type blockingRepository struct {
+	mockRepository
+}
+
+func (r *blockingRepository) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestVerify_PhaseBudget(t *testing.T) {
+	trusted := newTestPKI(t)
+	repo := &blockingRepository{}
+	repo.add(trusted.sign(t, pkix.Name{Organization: []string{"Acme"}}, testArtifactDigest, time.Now().Add(time.Hour)))
+	v := NewVerifier(&PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{{
+			Name:                  "test-statement-name",
+			RegistryScopes:        []string{testArtifactPath},
+			SignatureVerification: "strict",
+			TrustStore:            "ca:test-store",
+			TrustedIdentities:     []string{"*"},
+		}},
+	}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+	v.Repository = repo
+	v.PhaseBudget = &PhaseBudget{Fetch: 25, Verification: 50, Revocation: 25}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := v.Verify(ctx, testArtifactPath+"@"+testArtifactDigest.String())
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("Verify() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("Verify() took %v, want the fetch phase bounded to 500ms", time.Since(start))
+	}
+
+	v.PhaseBudget = &PhaseBudget{Fetch: 50}
+	if _, err := v.Verify(ctx, testArtifactPath+"@"+testArtifactDigest.String()); err == nil || !strings.Contains(err.Error(), "sums to 50%") {
+		t.Errorf("Verify() error = %v, want invalid phase budget", err)
+	}
+}
@@ -0,0 +1,233 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
+)
+
+// BundleVersion is the version of the verification bundles created by
+// ExportBundle.
+const BundleVersion = "1.0"
+
+// Bundle is a portable verification bundle, holding everything needed to
+// verify an artifact offline, e.g. in an air-gapped environment.
+type Bundle struct {
+	// Version is the version of the bundle format.
+	Version string `json:"version"`
+
+	// ArtifactURI is the reference of the artifact, including its digest,
+	// e.g. domain.com/my/repository@sha256:digest.
+	ArtifactURI string `json:"artifactUri"`
+
+	// Descriptor is the descriptor of the artifact, as signed by the
+	// signature satisfying the trust policy at export.
+	Descriptor notation.Descriptor `json:"descriptor"`
+
+	// Signatures are the signature envelopes of the artifact.
+	Signatures [][]byte `json:"signatures,omitempty"`
+
+	// CertificateChain is the DER encoded certificate chain of the signature
+	// satisfying the trust policy at export.
+	CertificateChain [][]byte `json:"certificateChain,omitempty"`
+
+	// TrustPolicy is the policy document holding the trust policy applicable
+	// to the artifact at export. It is informational only: Bundle.Verify
+	// verifies against the trust policy of the caller.
+	TrustPolicy *PolicyDocument `json:"trustPolicy"`
+
+	// TrustStores are the trust stores referenced by the trust policy at
+	// export. They are informational only: Bundle.Verify verifies against
+	// the trust stores of the caller.
+	TrustStores []BundleTrustStore `json:"trustStores,omitempty"`
+
+	// Revocation is the snapshot of revocation information the signatures
+	// are checked against.
+	Revocation *RevocationSnapshot `json:"revocation,omitempty"`
+}
+
+// BundleTrustStore is an X.509 trust store of a verification bundle.
+type BundleTrustStore struct {
+	// Name is the name of the trust store.
+	Name string `json:"name"`
+
+	// Certificates are the DER encoded certificates of the trust store.
+	Certificates [][]byte `json:"certificates"`
+}
+
+// ExportBundle verifies the artifact referenced by artifactUri with v, and
+// exports the artifact, all its signatures, the trust policy applicable to
+// it with the trust stores it references, and the revocation snapshot of v
+// into a bundle, so that the artifact can be verified offline with
+// Bundle.Verify. The trust policy and trust stores are bundled for
+// reference only, and are not trusted by Bundle.Verify.
+//
+// Artifacts covered by a trust policy using a system trust store cannot be
+// exported, as the certificate store of the operating system is not
+// portable.
+func ExportBundle(ctx context.Context, v *Verifier, artifactUri string) (*Bundle, error) {
+	trustPolicy, err := v.PolicyDocument.ApplicableTrustPolicy(artifactUri)
+	if err != nil {
+		return nil, err
+	}
+	outcome, err := v.Verify(ctx, artifactUri)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		Version:     BundleVersion,
+		ArtifactURI: artifactUri,
+		Descriptor:  outcome.Descriptor,
+		TrustPolicy: &PolicyDocument{
+			Version:       v.PolicyDocument.Version,
+			TrustPolicies: []TrustPolicy{*trustPolicy},
+		},
+		Revocation: v.Revocation,
+	}
+	if outcome.Skipped {
+		return bundle, nil
+	}
+	for _, cert := range outcome.CertificateChain {
+		bundle.CertificateChain = append(bundle.CertificateChain, cert.Raw)
+	}
+
+	// bundle the trust store
+	storeType, storeName := splitTrustStore(trustPolicy.TrustStore)
+	if storeType == TrustStoreTypeSystem {
+		return nil, fmt.Errorf("trust store %q used by trust policy %q cannot be bundled", trustPolicy.TrustStore, trustPolicy.Name)
+	}
+	certs, _ := v.trustStoreCertificates(storeName)
+	store := BundleTrustStore{Name: storeName}
+	for _, cert := range certs {
+		store.Certificates = append(store.Certificates, cert.Raw)
+	}
+	bundle.TrustStores = []BundleTrustStore{store}
+
+	// bundle all the signatures
+	sigDigests, err := v.Repository.Lookup(ctx, outcome.Descriptor.Digest)
+	if err != nil {
+		return nil, err
+	}
+	for _, sigDigest := range sigDigests {
+		sig, err := v.Repository.Get(ctx, sigDigest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch signature %s: %w", sigDigest, err)
+		}
+		bundle.Signatures = append(bundle.Signatures, sig)
+	}
+	return bundle, nil
+}
+
+// LoadBundle loads the verification bundle at path.
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("malformed verification bundle %q: %w", path, err)
+	}
+	return &bundle, nil
+}
+
+// Save writes the bundle to path.
+func (b *Bundle) Save(path string) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Verify verifies the artifact of the bundle offline, with the signatures
+// and the revocation snapshot of the bundle, against policyDocument and
+// x509TrustStores. The trust policy and trust stores of the bundle are not
+// trusted, since anyone able to craft a bundle could craft them too. The
+// verification plugins declared by the signatures are resolved by
+// pluginManager, which may be nil if they do not declare any.
+func (b *Bundle) Verify(ctx context.Context, policyDocument *PolicyDocument, x509TrustStores []*X509TrustStore, pluginManager jws.PluginManager) (*notation.VerificationOutcome, error) {
+	v, err := b.verifier(policyDocument, x509TrustStores)
+	if err != nil {
+		return nil, err
+	}
+	v.PluginManager = pluginManager
+	return v.Verify(ctx, b.ArtifactURI)
+}
+
+// verifier creates the verifier of the artifact of the bundle against
+// policyDocument and x509TrustStores.
+func (b *Bundle) verifier(policyDocument *PolicyDocument, x509TrustStores []*X509TrustStore) (*Verifier, error) {
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("verification bundle version %q is not supported", b.Version)
+	}
+	if policyDocument == nil {
+		return nil, errors.New("trust policy document is required to verify a verification bundle")
+	}
+	if err := policyDocument.ValidatePolicyDocument(); err != nil {
+		return nil, err
+	}
+	artifactDigest, err := getArtifactDigestFromUri(b.ArtifactURI)
+	if err != nil {
+		return nil, err
+	}
+	if artifactDigest != b.Descriptor.Digest {
+		return nil, fmt.Errorf("verification bundle of artifact %q describes artifact %s", b.ArtifactURI, b.Descriptor.Digest)
+	}
+	v := NewVerifier(policyDocument, x509TrustStores)
+	v.Repository = bundleRepository{artifactDigest: artifactDigest, signatures: b.Signatures}
+	v.Revocation = b.Revocation
+	return v, nil
+}
+
+// bundleRepository is a read-only signature repository serving the
+// signatures of a verification bundle.
+type bundleRepository struct {
+	artifactDigest digest.Digest
+	signatures     [][]byte
+}
+
+var _ registry.SignatureRepository = bundleRepository{}
+
+func (r bundleRepository) Lookup(ctx context.Context, manifestDigest digest.Digest) ([]digest.Digest, error) {
+	if manifestDigest != r.artifactDigest {
+		return nil, nil
+	}
+	digests := make([]digest.Digest, 0, len(r.signatures))
+	for _, sig := range r.signatures {
+		digests = append(digests, digest.FromBytes(sig))
+	}
+	return digests, nil
+}
+
+func (r bundleRepository) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
+	for _, sig := range r.signatures {
+		if digest.FromBytes(sig) == signatureDigest {
+			return sig, nil
+		}
+	}
+	return nil, fmt.Errorf("signature %s is not in the verification bundle", signatureDigest)
+}
+
+func (r bundleRepository) Put(ctx context.Context, signature []byte) (notation.Descriptor, error) {
+	return notation.Descriptor{}, errReadOnlyBundle
+}
+
+func (r bundleRepository) Link(ctx context.Context, manifest, signature notation.Descriptor) (notation.Descriptor, error) {
+	return notation.Descriptor{}, errReadOnlyBundle
+}
+
+func (r bundleRepository) DeleteSignature(ctx context.Context, desc notation.Descriptor) error {
+	return errReadOnlyBundle
+}
+
+// errReadOnlyBundle is returned when modifying the signatures of a
+// verification bundle.
+var errReadOnlyBundle = errors.New("verification bundles are read-only")
@@ -0,0 +1,127 @@
+package verification
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+)
+
+func TestBundle(t *testing.T) {
+	trusted := newTestPKI(t)
+	acme := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}, CommonName: "signer"}
+	repo := &mockRepository{}
+	repo.add(trusted.sign(t, acme, testArtifactDigest, time.Now().Add(time.Hour)))
+	repo.add(newTestPKI(t).sign(t, acme, testArtifactDigest, time.Now().Add(time.Hour)))
+	v := NewVerifier(&PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{
+			{
+				Name:                  "test-statement-name",
+				RegistryScopes:        []string{testArtifactPath},
+				SignatureVerification: "strict",
+				TrustStore:            "ca:test-store",
+				TrustedIdentities:     []string{"x509.subject:C=US,ST=WA,O=Acme"},
+			},
+			{
+				Name:                  "other",
+				RegistryScopes:        []string{"registry.acme-rockets.io/other"},
+				SignatureVerification: "strict",
+				TrustStore:            "ca:other-store",
+				TrustedIdentities:     []string{"*"},
+			},
+		},
+	}, []*X509TrustStore{
+		{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}},
+		{Name: "other-store", Certificates: []*x509.Certificate{newTestPKI(t).caCert}},
+	})
+	v.Repository = repo
+	artifactUri := testArtifactPath + "@" + testArtifactDigest.String()
+
+	exported, err := ExportBundle(context.Background(), v, artifactUri)
+	if err != nil {
+		t.Fatalf("ExportBundle() error = %v", err)
+	}
+	if len(exported.Signatures) != 2 || len(exported.TrustPolicy.TrustPolicies) != 1 || len(exported.TrustStores) != 1 {
+		t.Errorf("ExportBundle() bundled %d signatures, %d trust policies and %d trust stores, want 2, 1 and 1",
+			len(exported.Signatures), len(exported.TrustPolicy.TrustPolicies), len(exported.TrustStores))
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := exported.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	bundle, err := LoadBundle(path)
+	if err != nil {
+		t.Fatalf("LoadBundle() error = %v", err)
+	}
+	outcome, err := bundle.Verify(context.Background(), v.PolicyDocument, v.X509TrustStores, nil)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if outcome.Descriptor.Digest != testArtifactDigest {
+		t.Errorf("Verify() Descriptor.Digest = %v, want %v", outcome.Descriptor.Digest, testArtifactDigest)
+	}
+
+	// the bundled trust stores are not trusted
+	untrusted := newTestPKI(t)
+	bundle.TrustStores = []BundleTrustStore{{Name: "test-store", Certificates: [][]byte{untrusted.caCert.Raw}}}
+	if _, err := bundle.Verify(context.Background(), v.PolicyDocument, []*X509TrustStore{
+		{Name: "test-store", Certificates: []*x509.Certificate{untrusted.caCert}},
+	}, nil); err == nil {
+		t.Errorf("Verify() with untrusted trust stores error = nil, want error")
+	}
+
+	// revoke the signing certificate
+	leaf, err := x509.ParseCertificate(bundle.CertificateChain[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := trusted.caCert.CreateCRL(rand.Reader, trusted.caKey, []pkix.RevokedCertificate{
+		{SerialNumber: leaf.SerialNumber, RevocationTime: time.Now()},
+	}, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.Revocation = &RevocationSnapshot{CRLs: [][]byte{crl}}
+	if _, err := bundle.Verify(context.Background(), v.PolicyDocument, v.X509TrustStores, nil); err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Errorf("Verify() error = %v, want revoked", err)
+	}
+}
+
+func TestBundle_Invalid(t *testing.T) {
+	policy := &PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{{
+			Name:                  "test-statement-name",
+			RegistryScopes:        []string{"*"},
+			SignatureVerification: "strict",
+			TrustStore:            "ca:test-store",
+			TrustedIdentities:     []string{"*"},
+		}},
+	}
+	artifactUri := testArtifactPath + "@" + testArtifactDigest.String()
+	desc := notation.Descriptor{Digest: testArtifactDigest}
+	tests := []struct {
+		name   string
+		bundle Bundle
+		policy *PolicyDocument
+	}{
+		{"unsupported version", Bundle{Version: "0.1", ArtifactURI: artifactUri, Descriptor: desc}, policy},
+		{"no trust policy", Bundle{Version: BundleVersion, ArtifactURI: artifactUri, Descriptor: desc, TrustPolicy: policy}, nil},
+		{"mismatched descriptor", Bundle{Version: BundleVersion, ArtifactURI: artifactUri}, policy},
+		{"no signature", Bundle{Version: BundleVersion, ArtifactURI: artifactUri, Descriptor: desc}, policy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.bundle.Verify(context.Background(), tt.policy, nil, nil); err == nil {
+				t.Errorf("Verify() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package verification
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+// Defaults of NewVerificationCache.
+const (
+	DefaultCacheTTL        = 5 * time.Minute
+	DefaultCacheMaxEntries = 1000
+)
+
+// VerificationCache caches the successful outcomes of Verifier.Verify in
+// memory, keyed by the digest of the artifact and the hash of the applicable
+// trust policy along with its trust store, so that services verifying the
+// same artifacts repeatedly, such as admission webhooks, verify each
+// artifact once per TTL.
+//
+// Failed verifications are not cached, so that a signature pushed after a
+// failure is taken into account. Entries are not invalidated when the
+// signatures of the artifact, the revocation snapshot or the policy
+// evaluator of the verifier change, which only take effect once the entries
+// expire or the cache is purged.
+//
+// A VerificationCache is safe for concurrent use, and can be shared by
+// verifiers.
+type VerificationCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	// now returns the current time, and is overridden by tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[verificationCacheKey]*list.Element
+	lru     *list.List
+}
+
+// verificationCacheKey identifies the verification of an artifact against
+// a trust policy.
+type verificationCacheKey struct {
+	artifactDigest digest.Digest
+	policyHash     digest.Digest
+}
+
+// verificationCacheEntry is a cached verification outcome.
+type verificationCacheEntry struct {
+	key     verificationCacheKey
+	outcome *notation.VerificationOutcome
+	expiry  time.Time
+}
+
+// NewVerificationCache returns an empty cache whose entries expire after
+// ttl, evicting the least recently used entries beyond maxEntries.
+// DefaultCacheTTL and DefaultCacheMaxEntries are used if not positive.
+func NewVerificationCache(ttl time.Duration, maxEntries int) *VerificationCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &VerificationCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[verificationCacheKey]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Len returns the number of cached entries, including the expired entries
+// not evicted yet.
+func (c *VerificationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Purge removes all the entries, e.g. when the trust configuration is
+// reloaded.
+func (c *VerificationCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[verificationCacheKey]*list.Element)
+	c.lru.Init()
+}
+
+// load returns a copy of the cached outcome of key, if not expired.
+// A nil cache caches nothing.
+func (c *VerificationCache) load(key verificationCacheKey) (*notation.VerificationOutcome, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*verificationCacheEntry)
+	if !c.now().Before(entry.expiry) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return copyOutcome(entry.outcome), true
+}
+
+// store caches a copy of outcome as the outcome of key.
+func (c *VerificationCache) store(key verificationCacheKey, outcome *notation.VerificationOutcome) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &verificationCacheEntry{
+		key:     key,
+		outcome: copyOutcome(outcome),
+		expiry:  c.now().Add(c.ttl),
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*verificationCacheEntry).key)
+	}
+}
+
+// cacheKey returns the cache key of the verification of the artifact of
+// artifactDigest against trustPolicy. The key changes with the trust policy,
+// the certificates of its trust store, unless it is a system trust store,
+// and the media type allowlists and the algorithm policy of v.
+func (v *Verifier) cacheKey(artifactDigest digest.Digest, trustPolicy *TrustPolicy) (verificationCacheKey, error) {
+	policyJSON, err := json.Marshal(trustPolicy)
+	if err != nil {
+		return verificationCacheKey{}, err
+	}
+	digester := sha256.New()
+	digester.Write(policyJSON)
+	// the allowlists of the verifier also decide the outcome
+	for _, types := range [][]string{v.AllowedMediaTypes, v.AllowedArtifactTypes, v.AllowedPayloadContentTypes} {
+		typesJSON, err := json.Marshal(types)
+		if err != nil {
+			return verificationCacheKey{}, err
+		}
+		digester.Write(typesJSON)
+	}
+	algorithmPolicyJSON, err := json.Marshal(v.AlgorithmPolicy)
+	if err != nil {
+		return verificationCacheKey{}, err
+	}
+	digester.Write(algorithmPolicyJSON)
+	// the evaluation order decides the signature of the outcome
+	orderJSON, err := json.Marshal(struct {
+		Order      SignatureOrder
+		Algorithms []notation.SignatureAlgorithm
+	}{v.SignatureOrder, v.PreferredSignatureAlgorithms})
+	if err != nil {
+		return verificationCacheKey{}, err
+	}
+	digester.Write(orderJSON)
+	if storeType, storeName := splitTrustStore(trustPolicy.TrustStore); storeType != TrustStoreTypeSystem {
+		certs, _ := v.trustStoreCertificates(storeName)
+		for _, cert := range certs {
+			digester.Write(cert.Raw)
+		}
+	}
+	return verificationCacheKey{
+		artifactDigest: artifactDigest,
+		policyHash:     digest.NewDigest(digest.SHA256, digester),
+	}, nil
+}
+
+// copyOutcome returns a copy of outcome not sharing its results.
+func copyOutcome(outcome *notation.VerificationOutcome) *notation.VerificationOutcome {
+	o := *outcome
+	o.Results = make([]*notation.VerificationResult, len(outcome.Results))
+	for i, result := range outcome.Results {
+		r := *result
+		o.Results[i] = &r
+	}
+	return &o
+}
@@ -0,0 +1,107 @@
+package verification
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/opencontainers/go-digest"
+)
+
+func TestVerify_Cache(t *testing.T) {
+	trusted := newTestPKI(t)
+	acme := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}, CommonName: "signer"}
+	repo := &mockRepository{}
+	repo.add(trusted.sign(t, acme, testArtifactDigest, time.Now().Add(time.Hour)))
+	policy := TrustPolicy{
+		Name:                  "test-statement-name",
+		RegistryScopes:        []string{testArtifactPath},
+		SignatureVerification: "strict",
+		TrustStore:            "ca:test-store",
+		TrustedIdentities:     []string{"x509.subject:C=US,ST=WA,O=Acme"},
+	}
+	cache := NewVerificationCache(time.Minute, 0)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	newVerifier := func(policy TrustPolicy) *Verifier {
+		v := NewVerifier(&PolicyDocument{
+			Version:       "1.0",
+			TrustPolicies: []TrustPolicy{policy},
+		}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+		v.Repository = repo
+		v.Cache = cache
+		return v
+	}
+	verify := func(v *Verifier, wantLookups int) {
+		t.Helper()
+		outcome, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if outcome.Descriptor.Digest != testArtifactDigest || len(outcome.Results) == 0 {
+			t.Errorf("Verify() outcome = %+v, want the outcome of %v", outcome, testArtifactDigest)
+		}
+		if repo.lookups != wantLookups {
+			t.Errorf("Verify() looked up signatures %d times, want %d", repo.lookups, wantLookups)
+		}
+		// outcomes returned do not alter the cache
+		outcome.Results = nil
+	}
+
+	v := newVerifier(policy)
+	verify(v, 1)
+	verify(v, 1)
+	verify(newVerifier(policy), 1)
+
+	// another trust policy verifies again
+	policy.TrustedIdentities = []string{"*"}
+	verify(newVerifier(policy), 2)
+	verify(newVerifier(policy), 2)
+
+	// expired entries verify again
+	now = now.Add(time.Minute)
+	verify(newVerifier(policy), 3)
+
+	cache.Purge()
+	verify(newVerifier(policy), 4)
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+
+	// failures are not cached
+	policy.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=Wabbit"}
+	for i := 0; i < 2; i++ {
+		if _, err := newVerifier(policy).Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String()); err == nil {
+			t.Fatal("Verify() error = nil, want error")
+		}
+	}
+	if repo.lookups != 6 {
+		t.Errorf("Verify() looked up signatures %d times, want 6", repo.lookups)
+	}
+}
+
+func TestVerificationCache_MaxEntries(t *testing.T) {
+	cache := NewVerificationCache(time.Minute, 2)
+	keys := make([]verificationCacheKey, 3)
+	for i := range keys {
+		keys[i] = verificationCacheKey{artifactDigest: digest.FromBytes([]byte{byte(i)})}
+	}
+	cache.store(keys[0], &notation.VerificationOutcome{})
+	cache.store(keys[1], &notation.VerificationOutcome{})
+	// keys[1] is the least recently used
+	if _, ok := cache.load(keys[0]); !ok {
+		t.Fatal("load() = false, want true")
+	}
+	cache.store(keys[2], &notation.VerificationOutcome{})
+	for i, want := range []bool{true, false, true} {
+		if _, ok := cache.load(keys[i]); ok != want {
+			t.Errorf("load(%d) = %v, want %v", i, ok, want)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+}
@@ -0,0 +1,60 @@
+package verification
+
+import (
+	"context"
+
+	"github.com/notaryproject/notation-go"
+)
+
+// PolicyInput is the input of a PolicyEvaluator.
+type PolicyInput struct {
+	// ArtifactUri is the reference of the verified artifact, e.g.
+	// domain.com/my/repository@sha256:digest.
+	ArtifactUri string
+
+	// TrustPolicy is the trust policy applicable to the artifact.
+	TrustPolicy *TrustPolicy
+
+	// Outcome is the outcome of the cryptographic verification of the
+	// signature, including the signing identity, the user metadata and the
+	// signing time. Failures of checks not enforced by the verification
+	// level are reported with their severity.
+	Outcome *notation.VerificationOutcome
+}
+
+// PolicyEvaluator makes the final decision to admit an artifact, once a
+// signature of the artifact satisfies the trust policy, e.g. by evaluating
+// the input against Rego policies or custom rules.
+type PolicyEvaluator interface {
+	// Evaluate returns nil to admit the artifact, and an error explaining
+	// the denial otherwise. Errors evaluating the policy also deny the
+	// artifact.
+	Evaluate(ctx context.Context, input *PolicyInput) error
+}
+
+// PolicyEvaluatorFunc is an adapter to use a function as a PolicyEvaluator.
+type PolicyEvaluatorFunc func(ctx context.Context, input *PolicyInput) error
+
+// Evaluate calls f(ctx, input).
+func (f PolicyEvaluatorFunc) Evaluate(ctx context.Context, input *PolicyInput) error {
+	return f(ctx, input)
+}
+
+// evaluatePolicy records the decision of the policy evaluator of v, if any,
+// on the signature of outcome as the policy check, and returns the denial.
+func (v *Verifier) evaluatePolicy(ctx context.Context, artifactUri string, trustPolicy *TrustPolicy, outcome *notation.VerificationOutcome) error {
+	if v.PolicyEvaluator == nil {
+		return nil
+	}
+	if err := v.PolicyEvaluator.Evaluate(ctx, &PolicyInput{
+		ArtifactUri: artifactUri,
+		TrustPolicy: trustPolicy,
+		Outcome:     outcome,
+	}); err != nil {
+		err = outcome.Fail(notation.CheckPolicy, notation.WrapError(notation.ErrorCodePolicyDenied, err))
+		outcome.Result(notation.CheckPolicy).Severity = notation.SeverityError
+		return err
+	}
+	outcome.Pass(notation.CheckPolicy)
+	return nil
+}
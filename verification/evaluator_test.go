@@ -0,0 +1,133 @@
+package verification
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+)
+
+func TestVerify_PolicyEvaluator(t *testing.T) {
+	trusted := newTestPKI(t)
+	acme := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}, CommonName: "release"}
+	wabbit := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "release"}
+	expiry := time.Now().Add(time.Hour)
+
+	// admits the artifacts signed by the release team of Acme only
+	evaluator := PolicyEvaluatorFunc(func(ctx context.Context, input *PolicyInput) error {
+		if input.ArtifactUri != testArtifactPath+"@"+testArtifactDigest.String() || input.TrustPolicy.Name != "test-statement-name" {
+			return errors.New("unexpected input")
+		}
+		if input.Outcome.Descriptor.Digest != testArtifactDigest {
+			return errors.New("unverified artifact")
+		}
+		if subject := input.Outcome.CertificateChain[0].Subject; len(subject.Organization) == 0 || subject.Organization[0] != "Acme" {
+			return errors.New("not signed by Acme")
+		}
+		return nil
+	})
+	tests := []struct {
+		name       string
+		level      string
+		evaluator  PolicyEvaluator
+		signatures [][]byte
+		wantErr    string
+		wantStatus notation.VerificationStatus
+	}{
+		{
+			name:       "admitted",
+			level:      "strict",
+			evaluator:  evaluator,
+			signatures: [][]byte{trusted.sign(t, acme, testArtifactDigest, expiry)},
+			wantStatus: notation.VerificationPassed,
+		},
+		{
+			name:       "denied",
+			level:      "audit",
+			evaluator:  evaluator,
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry)},
+			wantErr:    "not signed by Acme",
+		},
+		{
+			name:       "one admitted among many",
+			level:      "audit",
+			evaluator:  evaluator,
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry), trusted.sign(t, acme, testArtifactDigest, expiry)},
+			wantStatus: notation.VerificationPassed,
+		},
+		{
+			name:  "not evaluated if the trust policy is not satisfied",
+			level: "strict",
+			evaluator: PolicyEvaluatorFunc(func(ctx context.Context, input *PolicyInput) error {
+				t.Error("Evaluate() called on a signature not satisfying the trust policy")
+				return nil
+			}),
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry)},
+			wantErr:    "does not match the X.509 trusted identities",
+		},
+		{
+			name:       "no evaluator",
+			level:      "strict",
+			signatures: [][]byte{trusted.sign(t, acme, testArtifactDigest, expiry)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			for _, sig := range tt.signatures {
+				repo.add(sig)
+			}
+			v := NewVerifier(&PolicyDocument{
+				Version: "1.0",
+				TrustPolicies: []TrustPolicy{{
+					Name:                  "test-statement-name",
+					RegistryScopes:        []string{testArtifactPath},
+					SignatureVerification: tt.level,
+					TrustStore:            "ca:test-store",
+					TrustedIdentities:     []string{"x509.subject:C=US,ST=WA,O=Acme"},
+				}},
+			}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+			v.Repository = repo
+			v.PolicyEvaluator = tt.evaluator
+
+			outcome, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			result := outcome.Result(notation.CheckPolicy)
+			if tt.wantStatus == "" {
+				if result != nil {
+					t.Errorf("Verify() policy = %v, want not performed", result)
+				}
+			} else if result == nil || result.Status != tt.wantStatus {
+				t.Errorf("Verify() policy = %v, want %s", result, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	v := &Verifier{PolicyEvaluator: PolicyEvaluatorFunc(func(ctx context.Context, input *PolicyInput) error {
+		return errors.New("denied")
+	})}
+	outcome := &notation.VerificationOutcome{}
+	err := v.evaluatePolicy(context.Background(), testArtifactPath, &TrustPolicy{Name: "test"}, outcome)
+	if !errors.Is(err, notation.ErrPolicyDenied) {
+		t.Fatalf("evaluatePolicy() error = %v, want %v", err, notation.ErrPolicyDenied)
+	}
+	result := outcome.Result(notation.CheckPolicy)
+	if result == nil || result.Status != notation.VerificationFailed || result.Severity != notation.SeverityError {
+		t.Errorf("evaluatePolicy() policy = %v, want failed with severity %q", result, notation.SeverityError)
+	}
+}
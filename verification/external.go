@@ -0,0 +1,131 @@
+package verification
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/certpolicy"
+	"github.com/notaryproject/notation-go/log"
+)
+
+// VerifyExternal verifies a signature of the artifact referenced by
+// artifactUri which is not a notation signature, e.g. a cosign signature,
+// against the applicable trust policy.
+//
+// The caller verifies the integrity of the signature, and records it in
+// outcome along with the signed descriptor and the certificate chain of the
+// signature. VerifyExternal then checks the certificate chain against the
+// trust store of the trust policy, the trusted identities and the revocation
+// snapshot of v, enforces the verification level and evaluates the policy
+// evaluator of v as Verify does.
+// If the trust policy skips verification, outcome is marked as Skipped.
+func (v *Verifier) VerifyExternal(ctx context.Context, artifactUri string, outcome *notation.VerificationOutcome) error {
+	if outcome == nil {
+		return errors.New("nil verification outcome")
+	}
+	trustPolicy, err := v.PolicyDocument.ApplicableTrustPolicy(artifactUri)
+	if err != nil {
+		return err
+	}
+	level, err := trustPolicy.VerificationLevel()
+	if err != nil {
+		return err
+	}
+	if level.Name == Skip.Name {
+		log.GetLogger(ctx).Infof("signature verification of %q is skipped by trust policy %q", artifactUri, trustPolicy.Name)
+		outcome.Skipped = true
+		return nil
+	}
+	artifactDigest, err := getArtifactDigestFromUri(artifactUri)
+	if err != nil {
+		return err
+	}
+
+	if result := outcome.Result(notation.CheckIntegrity); result == nil {
+		outcome.Fail(notation.CheckIntegrity, errors.New("integrity of the signature is not verified"))
+	} else if result.Status == notation.VerificationPassed {
+		if outcome.Descriptor.Digest != artifactDigest {
+			outcome.Fail(notation.CheckIntegrity, fmt.Errorf("signature is signed for artifact %s, not %s", outcome.Descriptor.Digest, artifactDigest))
+		} else if chains, expired, err := v.verifyExternalChain(outcome.CertificateChain, trustPolicy); err != nil {
+			outcome.Fail(notation.CheckAuthenticity, err)
+		} else {
+			outcome.VerifiedChains = chains
+			outcome.Pass(notation.CheckAuthenticity)
+			outcome.SelfSigned = notation.IsSelfSigned(outcome.CertificateChain[0])
+			verifySelfSigned(ctx, outcome, trustPolicy)
+			if expired {
+				outcome.Fail(notation.CheckExpiry, notation.Errorf(notation.ErrorCodeSignatureExpired, "signing certificate expired at %s", outcome.CertificateChain[0].NotAfter))
+			}
+			if err := verifyX509TrustedIdentities(outcome.CertificateChain, outcome.VerifiedChains, *trustPolicy); err != nil {
+				outcome.Fail(notation.CheckTrustedIdentity, err)
+			} else {
+				outcome.Pass(notation.CheckTrustedIdentity)
+			}
+			if v.Revocation != nil {
+				var anchors []*x509.Certificate
+				if storeType, storeName := splitTrustStore(trustPolicy.TrustStore); storeType == TrustStoreTypeCA {
+					anchors, _ = v.trustStoreCertificates(storeName)
+				}
+				if checked, err := v.Revocation.check(outcome.CertificateChain, anchors); err != nil {
+					outcome.Fail(notation.CheckRevocation, err)
+				} else if checked {
+					outcome.Pass(notation.CheckRevocation)
+				}
+			}
+		}
+	}
+	err = enforce(outcome, level)
+	logger := log.GetLogger(ctx)
+	for _, result := range outcome.Failures() {
+		if result.Severity == notation.SeverityWarning {
+			logger.Warnf("external signature of %q failed the %s check logged by trust policy %q: %v", artifactUri, result.Check, trustPolicy.Name, result.Error)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return v.evaluatePolicy(ctx, artifactUri, trustPolicy, outcome)
+}
+
+// verifyExternalChain verifies the certificate chain of an external
+// signature, starting with the signing certificate, chains up to the trust
+// store of trustPolicy and meets the default certificate policy. It returns
+// the verified chains built from the signing certificate to a trust anchor.
+//
+// External signatures are not timestamped, so an expired chain is verified
+// at the expiry of the signing certificate and reported as expired, e.g. for
+// the short-lived certificates of keyless signatures.
+func (v *Verifier) verifyExternalChain(certs []*x509.Certificate, trustPolicy *TrustPolicy) (chains [][]*x509.Certificate, expired bool, err error) {
+	if len(certs) == 0 {
+		return nil, false, notation.Errorf(notation.ErrorCodeEmptyCertificateChain, "signer certificates not found")
+	}
+	if err := certpolicy.Default.ValidateChain(certs); err != nil {
+		return nil, false, notation.Errorf(notation.ErrorCodeInvalidCertificate, "signing certificate does not meet the minimum requirements: %w", err)
+	}
+	roots, err := v.trustRoots(trustPolicy)
+	if err != nil {
+		return nil, false, err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	verifyOpts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     certpolicy.Default.RequiredExtKeyUsages(),
+	}
+	chains, err = certs[0].Verify(verifyOpts)
+	if certErr, ok := err.(x509.CertificateInvalidError); ok && certErr.Reason == x509.Expired {
+		expired = true
+		verifyOpts.CurrentTime = certs[0].NotAfter
+		chains, err = certs[0].Verify(verifyOpts)
+	}
+	if err != nil {
+		return nil, false, notation.WrapError(notation.ErrorCodeUntrustedSigner, err)
+	}
+	return chains, expired, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	ldapv3 "github.com/go-ldap/ldap/v3"
+	"github.com/opencontainers/go-digest"
 )
 
 // isPresent is a utility function to check if a string exists in an array
@@ -18,9 +19,22 @@ func isPresent(val string, values []string) bool {
 	return false
 }
 
+// splitTrustStore splits a trust store value of a trust policy, e.g.
+// ca:acme-rabbits, into its type and its name.
+func splitTrustStore(trustStore string) (storeType, name string) {
+	i := strings.Index(trustStore, ":")
+	if i < 0 {
+		return "", trustStore
+	}
+	return trustStore[:i], trustStore[i+1:]
+}
+
 func getArtifactPathFromUri(artifactUri string) (string, error) {
-	// TODO support more types of URI like "domain.com/repository", "domain.com/repository:tag", "domain.com/repository@sha256:digest"
-	i := strings.LastIndex(artifactUri, ":")
+	// TODO support more types of URI like "domain.com/repository", "domain.com/repository:tag"
+	i := strings.LastIndex(artifactUri, "@")
+	if i < 0 {
+		i = strings.LastIndex(artifactUri, ":")
+	}
 	if i < 0 {
 		return "", fmt.Errorf("artifact URI %q could not be parsed, make sure it is the fully qualified OCI artifact URI without the scheme/protocol. e.g domain.com:80/my/repository:digest", artifactUri)
 	}
@@ -32,6 +46,20 @@ func getArtifactPathFromUri(artifactUri string) (string, error) {
 	return artifactPath, nil
 }
 
+// getArtifactDigestFromUri returns the digest of the artifact referenced by
+// an artifact URI such as domain.com/repository@sha256:digest
+func getArtifactDigestFromUri(artifactUri string) (digest.Digest, error) {
+	i := strings.LastIndex(artifactUri, "@")
+	if i < 0 {
+		return "", fmt.Errorf("artifact URI %q does not reference the artifact by digest, e.g domain.com/my/repository@sha256:digest", artifactUri)
+	}
+	d, err := digest.Parse(artifactUri[i+1:])
+	if err != nil {
+		return "", fmt.Errorf("artifact URI %q has an invalid digest: %w", artifactUri, err)
+	}
+	return d, nil
+}
+
 // validateRegistryScopeFormat validates if a scope is following the format defined in distribution spec
 func validateRegistryScopeFormat(scope string) error {
 	// Domain and Repository regexes are adapted from distribution implementation
@@ -71,10 +99,11 @@ func parseDistinguishedName(name string) (map[string]string, error) {
 			return nil, fmt.Errorf("distinguished name (DN) %q has multi-valued RDN attributes, remove multi-valued RDN attributes as they are not supported", name)
 		}
 		for _, attribute := range rdn.Attributes {
-			if attrKeyValue[attribute.Type] == "" {
-				attrKeyValue[attribute.Type] = attribute.Value
+			attrType := canonicalAttributeType(attribute.Type)
+			if attrKeyValue[attrType] == "" {
+				attrKeyValue[attrType] = attribute.Value
 			} else {
-				return nil, fmt.Errorf("distinguished name (DN) %q has duplicate RDN attribute for %q, DN can only have unique RDN attributes", name, attribute.Type)
+				return nil, fmt.Errorf("distinguished name (DN) %q has duplicate RDN attribute for %q, DN can only have unique RDN attributes", name, attrType)
 			}
 		}
 	}
@@ -102,11 +131,53 @@ func validateOverlappingDNs(policyName string, parsedDNs []parsedDN) error {
 }
 
 // isSubsetDN returns true if dn1 is a subset of dn2 i.e. every key/value pair of dn1 has a matching key/value pair in dn2, otherwise returns false
+// Values are compared after normalization, see normalizeDNValue.
 func isSubsetDN(dn1 map[string]string, dn2 map[string]string) bool {
 	for key := range dn1 {
-		if dn1[key] != dn2[key] {
+		value2, ok := dn2[key]
+		if !ok || normalizeDNValue(dn1[key]) != normalizeDNValue(value2) {
 			return false
 		}
 	}
 	return true
 }
+
+// attributeTypeAliases maps the long names and OIDs of the RDN attribute types
+// to their short names, as used by x509 certificate subjects.
+var attributeTypeAliases = map[string]string{
+	"COUNTRYNAME":            "C",
+	"2.5.4.6":                "C",
+	"S":                      "ST",
+	"STATEORPROVINCENAME":    "ST",
+	"2.5.4.8":                "ST",
+	"LOCALITYNAME":           "L",
+	"2.5.4.7":                "L",
+	"ORGANIZATIONNAME":       "O",
+	"2.5.4.10":               "O",
+	"ORGANIZATIONALUNITNAME": "OU",
+	"2.5.4.11":               "OU",
+	"COMMONNAME":             "CN",
+	"2.5.4.3":                "CN",
+	"SERIALNUMBER":           "SERIALNUMBER",
+	"2.5.4.5":                "SERIALNUMBER",
+	"E":                      "1.2.840.113549.1.9.1",
+	"EMAILADDRESS":           "1.2.840.113549.1.9.1",
+}
+
+// canonicalAttributeType returns the canonical form of an RDN attribute type,
+// which is case-insensitive per RFC 4514.
+func canonicalAttributeType(attrType string) string {
+	attrType = strings.ToUpper(strings.TrimSpace(attrType))
+	if alias, ok := attributeTypeAliases[attrType]; ok {
+		return alias
+	}
+	return attrType
+}
+
+// normalizeDNValue normalizes an RDN attribute value for comparison,
+// following the caseIgnoreMatch rule of RFC 4517 and the insignificant
+// space handling of RFC 4518: values are compared case-insensitively,
+// ignoring leading, trailing and repeated spaces.
+func normalizeDNValue(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
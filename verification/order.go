@@ -0,0 +1,98 @@
+package verification
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
+)
+
+// SignatureOrder is the order the signatures of an artifact are evaluated
+// in. Verify returns the outcome of the first signature satisfying the trust
+// policy in this order, so that outcomes are reproducible across runs.
+type SignatureOrder string
+
+// Signature orders.
+const (
+	// SignatureOrderRepository evaluates the signatures in the order the
+	// signature repository lists them.
+	SignatureOrderRepository SignatureOrder = "repository"
+
+	// SignatureOrderNewestFirst evaluates the signatures with the newest
+	// signing time first. The authentic signing time is used if any, and
+	// signatures without signing time, e.g. malformed ones, are last.
+	SignatureOrderNewestFirst SignatureOrder = "newestFirst"
+)
+
+// validateSignatureOrder checks that the signature order of v is supported.
+func (v *Verifier) validateSignatureOrder() error {
+	switch v.SignatureOrder {
+	case "", SignatureOrderRepository, SignatureOrderNewestFirst:
+		return nil
+	}
+	return notation.Errorf(notation.ErrorCodeInvalidArgument, "signature order %q is not supported", v.SignatureOrder)
+}
+
+// ordersByContent reports whether the evaluation order of v depends on the
+// content of the signatures.
+func (v *Verifier) ordersByContent() bool {
+	return v.SignatureOrder == SignatureOrderNewestFirst || len(v.PreferredSignatureAlgorithms) > 0
+}
+
+// signatureCandidate is a signature to order.
+type signatureCandidate struct {
+	digest      digest.Digest
+	signingTime time.Time
+
+	// preference is the index of the signature algorithm in the preferred
+	// algorithms, or their number if not preferred.
+	preference int
+}
+
+// orderSignatures returns sigDigests in the evaluation order of v, i.e. by
+// newest signing time first for SignatureOrderNewestFirst, then by the
+// preference of their signature algorithms, then in repository order.
+// Signatures are fetched with fetcher and inspected if the order depends on
+// their content.
+func (v *Verifier) orderSignatures(ctx context.Context, fetcher *signatureFetcher, sigDigests []digest.Digest) []digest.Digest {
+	if !v.ordersByContent() {
+		return sigDigests
+	}
+	candidates := make([]signatureCandidate, 0, len(sigDigests))
+	for _, sigDigest := range sigDigests {
+		candidate := signatureCandidate{
+			digest:     sigDigest,
+			preference: len(v.PreferredSignatureAlgorithms),
+		}
+		if sig, err := fetcher.get(ctx, sigDigest); err == nil {
+			if info, err := jws.Inspect(sig); err == nil {
+				candidate.signingTime = info.SigningTime
+				if !info.AuthenticSigningTime.IsZero() {
+					candidate.signingTime = info.AuthenticSigningTime
+				}
+				for i, alg := range v.PreferredSignatureAlgorithms {
+					if info.SignatureAlgorithm == alg {
+						candidate.preference = i
+						break
+					}
+				}
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	newestFirst := v.SignatureOrder == SignatureOrderNewestFirst
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if a, b := candidates[i].signingTime, candidates[j].signingTime; newestFirst && !a.Equal(b) {
+			return a.After(b)
+		}
+		return candidates[i].preference < candidates[j].preference
+	})
+	ordered := make([]digest.Digest, 0, len(candidates))
+	for _, candidate := range candidates {
+		ordered = append(ordered, candidate.digest)
+	}
+	return ordered
+}
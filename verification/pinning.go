@@ -0,0 +1,79 @@
+package verification
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Prefixes of the trusted identities pinning a certificate of the verified
+// chain.
+const (
+	// x509SPKI pins the base64 encoded SHA-256 hash of the subject public
+	// key info of a certificate, as the pin-sha256 pins of RFC 7469.
+	x509SPKI = "x509.spki"
+
+	// x509Fingerprint pins the hex encoded SHA-256 fingerprint of a
+	// certificate.
+	x509Fingerprint = "x509.fingerprint"
+)
+
+// certificatePin is a trusted identity pinning a certificate of the verified
+// chain, either the signing certificate or the trust anchor.
+type certificatePin struct {
+	prefix string
+	hash   []byte
+}
+
+// parseCertificatePin parses the value of a trusted identity of prefix
+// x509SPKI or x509Fingerprint.
+func parseCertificatePin(prefix, value string) (certificatePin, error) {
+	var hash []byte
+	var err error
+	switch prefix {
+	case x509SPKI:
+		hash, err = base64.StdEncoding.DecodeString(value)
+	case x509Fingerprint:
+		// fingerprints are commonly displayed as colon separated bytes
+		hash, err = hex.DecodeString(strings.ReplaceAll(value, ":", ""))
+	default:
+		return certificatePin{}, fmt.Errorf("unknown certificate pin prefix %q", prefix)
+	}
+	if err != nil {
+		return certificatePin{}, fmt.Errorf("%s trusted identity %q is not a valid SHA-256 hash: %w", prefix, value, err)
+	}
+	if len(hash) != sha256.Size {
+		return certificatePin{}, fmt.Errorf("%s trusted identity %q is not a valid SHA-256 hash: got %d bytes, want %d", prefix, value, len(hash), sha256.Size)
+	}
+	return certificatePin{prefix: prefix, hash: hash}, nil
+}
+
+// matches reports whether cert is pinned.
+func (p certificatePin) matches(cert *x509.Certificate) bool {
+	var hash [sha256.Size]byte
+	if p.prefix == x509SPKI {
+		hash = sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	} else {
+		hash = sha256.Sum256(cert.Raw)
+	}
+	return bytes.Equal(p.hash, hash[:])
+}
+
+// matchesVerifiedChains reports whether the signing certificate or the trust
+// anchor of any of the verified chains is pinned. Each verified chain starts
+// with the signing certificate and ends with the trust anchor.
+func (p certificatePin) matchesVerifiedChains(chains [][]*x509.Certificate) bool {
+	for _, chain := range chains {
+		if len(chain) == 0 {
+			continue
+		}
+		if p.matches(chain[0]) || p.matches(chain[len(chain)-1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package verification
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+)
+
+func TestVerifyX509TrustedIdentities_Pinning(t *testing.T) {
+	pki := newTestPKI(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafBytes, err := x509.CreateCertificate(rand.Reader, template, pki.caCert, &key.PublicKey, pki.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := []*x509.Certificate{leaf, pki.caCert}
+	spki := func(cert *x509.Certificate) string {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		return "x509.spki:" + base64.StdEncoding.EncodeToString(hash[:])
+	}
+	fingerprint := func(cert *x509.Certificate) string {
+		hash := sha256.Sum256(cert.Raw)
+		return "x509.fingerprint:" + strings.ToUpper(hex.EncodeToString(hash[:]))
+	}
+	other := newTestPKI(t).caCert
+	verified := [][]*x509.Certificate{chain}
+
+	tests := []struct {
+		name       string
+		identities []string
+		certs      []*x509.Certificate
+		verified   [][]*x509.Certificate
+		wantErr    bool
+	}{
+		{name: "leaf SPKI", identities: []string{spki(leaf)}},
+		{name: "root SPKI", identities: []string{spki(pki.caCert)}},
+		{name: "leaf fingerprint", identities: []string{fingerprint(leaf)}},
+		{name: "root fingerprint", identities: []string{fingerprint(pki.caCert)}},
+		{name: "pin or subject", identities: []string{spki(other), "x509.subject:C=US,ST=WA,O=Wabbit"}},
+		{name: "other SPKI", identities: []string{spki(other)}, wantErr: true},
+		{name: "other fingerprint and subject", identities: []string{fingerprint(other), "x509.subject:C=US,ST=WA,O=Acme"}, wantErr: true},
+		{name: "unverified SPKI", identities: []string{spki(other)}, certs: []*x509.Certificate{leaf, pki.caCert, other}, wantErr: true},
+		{name: "leaf SPKI without verified chains", identities: []string{spki(leaf)}, verified: [][]*x509.Certificate{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := TrustPolicy{Name: "test-statement-name", TrustedIdentities: tt.identities}
+			if err := validateTrustedIdentities(policy); err != nil {
+				t.Fatalf("validateTrustedIdentities() error = %v", err)
+			}
+			certs, verifiedChains := chain, verified
+			if tt.certs != nil {
+				certs = tt.certs
+			}
+			if tt.verified != nil {
+				verifiedChains = tt.verified
+			}
+			err := verifyX509TrustedIdentities(certs, verifiedChains, policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyX509TrustedIdentities() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify_PinnedCertificateAppended(t *testing.T) {
+	trusted := newTestPKI(t)
+	pinned := newTestPKI(t).caCert
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	leafBytes, err := x509.CreateCertificate(rand.Reader, template, trusted.caCert, &key.PublicKey, trusted.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pinned certificate is appended to x5c but does not certify the leaf
+	signer, err := jws.NewSigner(key, []*x509.Certificate{leaf, trusted.caCert, pinned})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.Sign(context.Background(), notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    testArtifactDigest,
+		Size:      1,
+	}, notation.SignOptions{Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &mockRepository{}
+	repo.add(sig)
+	hash := sha256.Sum256(pinned.RawSubjectPublicKeyInfo)
+	v := NewVerifier(&PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{{
+			Name:                  "test-statement-name",
+			RegistryScopes:        []string{testArtifactPath},
+			SignatureVerification: "strict",
+			TrustStore:            "ca:test-store",
+			TrustedIdentities:     []string{"x509.spki:" + base64.StdEncoding.EncodeToString(hash[:])},
+		}},
+	}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+	v.Repository = repo
+
+	_, err = v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+	if err == nil || !strings.Contains(err.Error(), "does not match the X.509 trusted identities") {
+		t.Fatalf("Verify() error = %v, want untrusted identity", err)
+	}
+}
+
+func TestValidateTrustedIdentities_InvalidPins(t *testing.T) {
+	for _, identity := range []string{
+		"x509.spki:not base64!",
+		"x509.spki:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"x509.fingerprint:zz",
+		"x509.fingerprint:0123",
+	} {
+		policy := TrustPolicy{Name: "test-statement-name", TrustedIdentities: []string{identity}}
+		if err := validateTrustedIdentities(policy); err == nil {
+			t.Errorf("validateTrustedIdentities(%q) error = nil, want invalid pin", identity)
+		}
+	}
+}
@@ -6,9 +6,15 @@ trust policies and trust stores.
 package verification
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"sort"
 	"strings"
+
+	"github.com/notaryproject/notation-go/dir"
 )
 
 const (
@@ -32,18 +38,80 @@ type TrustPolicy struct {
 	RegistryScopes []string `json:"registryScopes"`
 	// SignatureVerification setting for this policy statement
 	SignatureVerification string `json:"signatureVerification"`
+	// SignatureVerificationOverride overrides the actions of individual verification types of the
+	// signature verification level, e.g. {"revocation": "log"}
+	SignatureVerificationOverride map[string]string `json:"signatureVerificationOverride,omitempty"`
 	// TrustStore this policy statement uses
 	TrustStore string `json:"trustStore,omitempty"`
-	// TrustedIdentities this policy statement pins
+	// TrustedIdentities this policy statement pins, e.g.
+	// x509.subject:<DN>, x509.spki:<base64 SHA-256 of the public key info>
+	// or x509.fingerprint:<hex SHA-256 of the certificate>. Certificate pins
+	// match the signing certificate or the trust anchor of its verified
+	// chain.
 	TrustedIdentities []string `json:"trustedIdentities,omitempty"`
+	// SelfSignedCertificates allows or denies self-signed signing
+	// certificates, e.g. to allow them for development scopes only.
+	// Self-signed signing certificates are allowed if empty.
+	SelfSignedCertificates string `json:"selfSignedCertificates,omitempty"`
 }
 
+// Values of TrustPolicy.SelfSignedCertificates.
+const (
+	SelfSignedCertificatesAllow = "allow"
+	SelfSignedCertificatesDeny  = "deny"
+)
+
 // Internal type to hold raw and parsed Distinguished Names
 type parsedDN struct {
 	RawString string
 	ParsedMap map[string]string
 }
 
+// LoadPolicyDocument loads and validates the trust policy document at path.
+// If path is empty, the trust policy file of notation, as returned by
+// dir.TrustPolicyPath, is loaded.
+func LoadPolicyDocument(path string) (*PolicyDocument, error) {
+	if path == "" {
+		var err error
+		if path, err = dir.TrustPolicyPath(); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePolicyDocument(data, path)
+}
+
+// LoadPolicyDocumentFS loads and validates the trust policy document at path
+// in fsys. If path is empty, the trust policy file of a notation config
+// directory, i.e. dir.TrustPolicyFileName, is loaded, e.g. from the file
+// system returned by dir.ConfigFS.
+func LoadPolicyDocumentFS(fsys fs.FS, path string) (*PolicyDocument, error) {
+	if path == "" {
+		path = dir.TrustPolicyFileName
+	}
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePolicyDocument(data, path)
+}
+
+// parsePolicyDocument parses and validates the trust policy document read
+// from path.
+func parsePolicyDocument(data []byte, path string) (*PolicyDocument, error) {
+	var policyDoc PolicyDocument
+	if err := json.Unmarshal(data, &policyDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse trust policy %q: %w", path, err)
+	}
+	if err := policyDoc.ValidatePolicyDocument(); err != nil {
+		return nil, err
+	}
+	return &policyDoc, nil
+}
+
 // validateRegistryScopes validates if the policy document is following the Notary V2 spec rules for registry scopes
 func validateRegistryScopes(policyDoc *PolicyDocument) error {
 	registryScopeCount := make(map[string]int)
@@ -58,11 +126,11 @@ func validateRegistryScopes(policyDoc *PolicyDocument) error {
 		}
 		for _, scope := range statement.RegistryScopes {
 			if scope != wildcard {
-				if err := validateRegistryScopeFormat(scope); err != nil {
+				if err := validateRegistryScope(scope); err != nil {
 					return err
 				}
 			}
-			registryScopeCount[scope]++
+			registryScopeCount[normalizeRegistryScope(scope)]++
 		}
 	}
 
@@ -101,13 +169,19 @@ func validateTrustedIdentities(statement TrustPolicy) error {
 			identityPrefix := identity[:i]
 			identityValue := identity[i+1:]
 
-			// notation natively supports x509.subject identities only
-			if identityPrefix == x509Subject {
+			// notation natively supports x509.subject identities and
+			// certificate pins only
+			switch identityPrefix {
+			case x509Subject:
 				dn, err := parseDistinguishedName(identityValue)
 				if err != nil {
 					return err
 				}
 				parsedDNs = append(parsedDNs, parsedDN{RawString: identity, ParsedMap: dn})
+			case x509SPKI, x509Fingerprint:
+				if _, err := parseCertificatePin(identityPrefix, identityValue); err != nil {
+					return fmt.Errorf("trust policy statement %q has an invalid trusted identity: %w", statement.Name, err)
+				}
 			}
 		}
 	}
@@ -123,11 +197,11 @@ func validateTrustedIdentities(statement TrustPolicy) error {
 
 // validateTrustStore validates if the policy statement is following the Notary V2 spec rules for truststores
 func validateTrustStore(statement TrustPolicy) error {
-	supportedTrustStorePrefixes := []string{"ca"}
+	supportedTrustStorePrefixes := []string{TrustStoreTypeCA, TrustStoreTypeSystem}
 
-	i := strings.Index(statement.TrustStore, ":")
-	if i < 0 || !isPresent(statement.TrustStore[:i], supportedTrustStorePrefixes) {
-		return fmt.Errorf("trust policy statement %q uses an unsupported trust store type %q in trust store value %q", statement.Name, statement.TrustStore[:i], statement.TrustStore)
+	storeType, _ := splitTrustStore(statement.TrustStore)
+	if !isPresent(storeType, supportedTrustStorePrefixes) {
+		return fmt.Errorf("trust policy statement %q uses an unsupported trust store type %q in trust store value %q", statement.Name, storeType, statement.TrustStore)
 	}
 
 	return nil
@@ -165,6 +239,11 @@ func (policyDoc *PolicyDocument) ValidatePolicyDocument() error {
 			return fmt.Errorf("trust policy statement %q uses unsupported signatureVerification value %q", statement.Name, statement.SignatureVerification)
 		}
 
+		// Verify the overrides of the signature verification level are valid
+		if _, err := statement.VerificationLevel(); err != nil {
+			return err
+		}
+
 		// Any signature verification other than "skip" needs a trust store and trusted identities
 		if statement.SignatureVerification == "skip" {
 			if statement.TrustStore != "" || len(statement.TrustedIdentities) > 0 {
@@ -184,6 +263,12 @@ func (policyDoc *PolicyDocument) ValidatePolicyDocument() error {
 			if err := validateTrustedIdentities(statement); err != nil {
 				return err
 			}
+
+			switch statement.SelfSignedCertificates {
+			case "", SelfSignedCertificatesAllow, SelfSignedCertificatesDeny:
+			default:
+				return fmt.Errorf("trust policy statement %q uses unsupported selfSignedCertificates value %q", statement.Name, statement.SelfSignedCertificates)
+			}
 		}
 
 	}
@@ -204,43 +289,84 @@ func (policyDoc *PolicyDocument) ValidatePolicyDocument() error {
 	return nil
 }
 
-// getApplicableTrustPolicy returns a pointer to the deep copied TrustPolicy statement that applies to the given
-// registry URI. If no applicable trust policy is found, returns an error
+// ApplicableTrustPolicy returns a deep copy of the most specific TrustPolicy statement that applies to the
+// given artifact URI, e.g. domain.com/my/repository@sha256:digest. If no applicable trust policy is found,
+// returns an error.
+//
+// Registry scopes and the artifact URI are compared after normalizing the registry host names, e.g. Docker Hub
+// aliases. A scope naming the repository of the artifact takes precedence over hierarchical scopes such as
+// domain.com/my/*, of which the deepest applies, and over the wildcard (*) scope.
 // see https://github.com/notaryproject/notaryproject/blob/main/trust-store-trust-policy-specification.md#selecting-a-trust-policy-based-on-artifact-uri
-func (policyDoc *PolicyDocument) getApplicableTrustPolicy(artifactUri string) (*TrustPolicy, error) {
-
+func (policyDoc *PolicyDocument) ApplicableTrustPolicy(artifactUri string) (*TrustPolicy, error) {
 	artifactPath, err := getArtifactPathFromUri(artifactUri)
 	if err != nil {
 		return nil, err
 	}
+	repository := normalizeRepository(artifactPath)
 
-	var wildcardPolicy *TrustPolicy
 	var applicablePolicy *TrustPolicy
+	bestSpecificity := -1
 	for _, policyStatement := range policyDoc.TrustPolicies {
-		if isPresent(wildcard, policyStatement.RegistryScopes) {
-			wildcardPolicy = policyStatement.deepCopy() // we need to deep copy because we can't use the loop variable address. see https://stackoverflow.com/a/45967429
-		} else if isPresent(artifactPath, policyStatement.RegistryScopes) {
-			applicablePolicy = policyStatement.deepCopy()
+		for _, scope := range policyStatement.RegistryScopes {
+			specificity, ok := scopeSpecificity(normalizeRegistryScope(scope), repository)
+			if ok && specificity > bestSpecificity {
+				bestSpecificity = specificity
+				applicablePolicy = policyStatement.deepCopy() // we need to deep copy because we can't use the loop variable address. see https://stackoverflow.com/a/45967429
+			}
 		}
 	}
-
-	if applicablePolicy != nil {
-		// a policy with exact match for registry URI takes precedence over a wildcard (*) policy.
-		return applicablePolicy, nil
-	} else if wildcardPolicy != nil {
-		return wildcardPolicy, nil
-	} else {
+	if applicablePolicy == nil {
 		return nil, fmt.Errorf("artifact %q has no applicable trust policy", artifactUri)
 	}
+	return applicablePolicy, nil
 }
 
 // deepCopy returns a pointer to the deeply copied TrustPolicy
 func (t *TrustPolicy) deepCopy() *TrustPolicy {
-	localCopy := t
+	localCopy := *t
 	localCopy.RegistryScopes = make([]string, len(t.RegistryScopes))
 	copy(localCopy.RegistryScopes, t.RegistryScopes)
 
 	localCopy.TrustedIdentities = make([]string, len(t.TrustedIdentities))
 	copy(localCopy.TrustedIdentities, t.TrustedIdentities)
-	return localCopy
+
+	if t.SignatureVerificationOverride != nil {
+		localCopy.SignatureVerificationOverride = make(map[string]string, len(t.SignatureVerificationOverride))
+		for k, v := range t.SignatureVerificationOverride {
+			localCopy.SignatureVerificationOverride[k] = v
+		}
+	}
+	return &localCopy
+}
+
+// VerificationLevel returns the signature verification level of the policy statement,
+// with the actions of SignatureVerificationOverride applied.
+func (t *TrustPolicy) VerificationLevel() (*VerificationLevel, error) {
+	level, err := FindVerificationLevel(t.SignatureVerification)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.SignatureVerificationOverride) == 0 {
+		return level, nil
+	}
+	// apply the overrides in a stable order so that errors are deterministic
+	names := make([]string, 0, len(t.SignatureVerificationOverride))
+	for name := range t.SignatureVerificationOverride {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		verificationType, err := ParseVerificationType(name)
+		if err != nil {
+			return nil, fmt.Errorf("trust policy statement %q has an invalid signatureVerificationOverride: %w", t.Name, err)
+		}
+		action, err := ParseVerificationAction(t.SignatureVerificationOverride[name])
+		if err != nil {
+			return nil, fmt.Errorf("trust policy statement %q has an invalid signatureVerificationOverride for %q: %w", t.Name, name, err)
+		}
+		if level, err = level.Override(verificationType, action); err != nil {
+			return nil, fmt.Errorf("trust policy statement %q has an invalid signatureVerificationOverride: %w", t.Name, err)
+		}
+	}
+	return level, nil
 }
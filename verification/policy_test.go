@@ -1,8 +1,14 @@
 package verification
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/notaryproject/notation-go/dir"
 )
 
 func dummyPolicyStatement() (policyStatement TrustPolicy) {
@@ -281,6 +287,16 @@ func TestValidateInvalidPolicyDocument(t *testing.T) {
 		t.Fatalf("policy statement with invalid SignatureVerification should return error")
 	}
 
+	// Invalid SignatureVerificationOverride
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.SignatureVerificationOverride = map[string]string{"integrity": "log"}
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.ValidatePolicyDocument()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" has an invalid signatureVerificationOverride: verification type \"Integrity\" cannot be overridden" {
+		t.Fatalf("policy statement overriding integrity should return error, got %v", err)
+	}
+
 	// strict SignatureVerification should have a trust store
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -342,6 +358,26 @@ func TestValidateInvalidPolicyDocument(t *testing.T) {
 		t.Fatalf("policy statement with invalid trust store type should return error")
 	}
 
+	// Trust Store without a type
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustStore = "test-trust-store"
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.ValidatePolicyDocument()
+	if err == nil {
+		t.Fatalf("policy statement with a trust store without a type should return error")
+	}
+
+	// System Trust Store
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.TrustStore = "system:default"
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.ValidatePolicyDocument()
+	if err != nil {
+		t.Fatalf("policy statement with the system trust store should not return error: %v", err)
+	}
+
 	// trusted identities with a wildcard
 	policyDoc = dummyPolicyDocument()
 	policyStatement = dummyPolicyStatement()
@@ -352,6 +388,16 @@ func TestValidateInvalidPolicyDocument(t *testing.T) {
 		t.Fatalf("policy statement with more than a wildcard trusted identity should return error")
 	}
 
+	// unsupported self-signed certificates value
+	policyDoc = dummyPolicyDocument()
+	policyStatement = dummyPolicyStatement()
+	policyStatement.SelfSignedCertificates = "warn"
+	policyDoc.TrustPolicies = []TrustPolicy{policyStatement}
+	err = policyDoc.ValidatePolicyDocument()
+	if err == nil || err.Error() != "trust policy statement \"test-statement-name\" uses unsupported selfSignedCertificates value \"warn\"" {
+		t.Fatalf("policy statement with unsupported selfSignedCertificates value should return error")
+	}
+
 	// Policy Document with duplicate policy statement names
 	policyDoc = dummyPolicyDocument()
 	policyStatement1 = dummyPolicyStatement()
@@ -379,15 +425,15 @@ func TestApplicableTrustPolicy(t *testing.T) {
 		policyStatement,
 	}
 	// existing Registry Scope
-	policy, err := policyDoc.getApplicableTrustPolicy(registryUri)
+	policy, err := policyDoc.ApplicableTrustPolicy(registryUri)
 	if policy.Name != policyStatement.Name || err != nil {
-		t.Fatalf("getApplicableTrustPolicy should return %q for registry scope %q", policyStatement.Name, registryScope)
+		t.Fatalf("ApplicableTrustPolicy should return %q for registry scope %q", policyStatement.Name, registryScope)
 	}
 
 	// non-existing Registry Scope
-	policy, err = policyDoc.getApplicableTrustPolicy("non.existing.scope/repo:hash")
+	policy, err = policyDoc.ApplicableTrustPolicy("non.existing.scope/repo:hash")
 	if policy != nil || err == nil || err.Error() != "artifact \"non.existing.scope/repo:hash\" has no applicable trust policy" {
-		t.Fatalf("getApplicableTrustPolicy should return nil for non existing registry scope")
+		t.Fatalf("ApplicableTrustPolicy should return nil for non existing registry scope")
 	}
 
 	// wildcard registry scope
@@ -402,8 +448,40 @@ func TestApplicableTrustPolicy(t *testing.T) {
 		policyStatement,
 		wildcardStatement,
 	}
-	policy, err = policyDoc.getApplicableTrustPolicy("some.registry.that/has.no.policy:hash")
+	policy, err = policyDoc.ApplicableTrustPolicy("some.registry.that/has.no.policy:hash")
 	if policy.Name != wildcardStatement.Name || err != nil {
-		t.Fatalf("getApplicableTrustPolicy should return wildcard policy for registry scope \"some.registry.that/has.no.policy\"")
+		t.Fatalf("ApplicableTrustPolicy should return wildcard policy for registry scope \"some.registry.that/has.no.policy\"")
+	}
+}
+
+// TestLoadPolicyDocument tests loading the trust policy from the config directory
+func TestLoadPolicyDocument(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv(dir.ConfigEnv, configDir)
+	want := dummyPolicyDocument()
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "trustpolicy.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPolicyDocument("")
+	if err != nil {
+		t.Fatalf("LoadPolicyDocument() error = %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("LoadPolicyDocument() = %+v, want %+v", *got, want)
+	}
+
+	invalid := dummyPolicyDocument()
+	invalid.Version = "0.1"
+	data, _ = json.Marshal(invalid)
+	path := filepath.Join(configDir, "invalid.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicyDocument(path); err == nil {
+		t.Errorf("LoadPolicyDocument() should fail for an invalid policy document")
 	}
 }
@@ -0,0 +1,106 @@
+package verification
+
+import (
+	"context"
+	"time"
+
+	"github.com/notaryproject/notation-go/event"
+	"github.com/opencontainers/go-digest"
+)
+
+// signatureFetcher fetches the signature blobs of an artifact, concurrently
+// ahead of their verification if prefetching.
+type signatureFetcher struct {
+	verifier  *Verifier
+	deadlines *phaseDeadlines
+	artifact  digest.Digest
+
+	// sizes are the sizes of the signatures, if looked up with them.
+	sizes map[digest.Digest]int64
+
+	// fetches are the prefetches of the signatures, nil if not prefetching.
+	fetches map[digest.Digest]*signatureFetch
+}
+
+// signatureFetch is the prefetch of a signature blob.
+type signatureFetch struct {
+	done chan struct{}
+	sig  []byte
+	err  error
+}
+
+// newSignatureFetcher creates the fetcher of the signatures sigDigests of
+// the artifact artifactDigest, and starts prefetching them in order with
+// parallelism concurrent fetches until ctx is done. Signatures are fetched
+// when requested if parallelism is negative. Signatures whose size in
+// sigSizes exceeds MaxSignatureSize are not fetched.
+func (v *Verifier) newSignatureFetcher(ctx context.Context, deadlines *phaseDeadlines, artifactDigest digest.Digest, sigDigests []digest.Digest, sigSizes map[digest.Digest]int64, parallelism int) *signatureFetcher {
+	f := &signatureFetcher{
+		verifier:  v,
+		deadlines: deadlines,
+		artifact:  artifactDigest,
+		sizes:     sigSizes,
+	}
+	if parallelism < 0 || len(sigDigests) < 2 {
+		return f
+	}
+	f.fetches = make(map[digest.Digest]*signatureFetch, len(sigDigests))
+	var order []digest.Digest
+	for _, sigDigest := range sigDigests {
+		if _, ok := f.fetches[sigDigest]; !ok {
+			f.fetches[sigDigest] = &signatureFetch{done: make(chan struct{})}
+			order = append(order, sigDigest)
+		}
+	}
+	go func() {
+		sem := make(chan struct{}, parallelism)
+		for _, sigDigest := range order {
+			fetch := f.fetches[sigDigest]
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if err := ctx.Err(); err != nil {
+				fetch.err = err
+				close(fetch.done)
+				continue
+			}
+			go func(sigDigest digest.Digest) {
+				defer func() { <-sem }()
+				fetch.sig, fetch.err = f.fetch(ctx, sigDigest)
+				close(fetch.done)
+			}(sigDigest)
+		}
+	}()
+	return f
+}
+
+// get returns the signature blob sigDigest, waiting for its prefetch if
+// prefetching.
+func (f *signatureFetcher) get(ctx context.Context, sigDigest digest.Digest) ([]byte, error) {
+	fetch, ok := f.fetches[sigDigest]
+	if !ok {
+		return f.fetch(ctx, sigDigest)
+	}
+	select {
+	case <-fetch.done:
+		return fetch.sig, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch fetches the signature blob sigDigest within the fetch deadline.
+func (f *signatureFetcher) fetch(ctx context.Context, sigDigest digest.Digest) ([]byte, error) {
+	if size, ok := f.sizes[sigDigest]; ok {
+		if err := f.verifier.checkSignatureSize(size); err != nil {
+			return nil, err
+		}
+	}
+	fetchCtx, cancel := f.deadlines.fetchContext(ctx)
+	defer cancel()
+	start := time.Now()
+	sig, err := f.verifier.Repository.Get(fetchCtx, sigDigest)
+	event.Emit(ctx, event.Event{Type: event.SignatureFetched, Artifact: f.artifact, Signature: sigDigest, Duration: time.Since(start), Err: err})
+	return sig, err
+}
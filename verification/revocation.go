@@ -0,0 +1,83 @@
+package verification
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// RevocationSnapshot is a snapshot of revocation information, checked
+// offline against the certificate chains of the signatures, e.g. when
+// verifying from a verification bundle in an air-gapped environment.
+type RevocationSnapshot struct {
+	// CRLs are DER encoded certificate revocation lists.
+	CRLs [][]byte `json:"crls,omitempty"`
+
+	// OCSPResponses are DER encoded OCSP responses.
+	OCSPResponses [][]byte `json:"ocspResponses,omitempty"`
+}
+
+// check checks whether the certificates of chain are revoked, with their
+// issuers found among chain and the trust anchors anchors. It reports
+// whether any certificate is covered by the snapshot, i.e. whether the
+// revocation check was performed.
+//
+// The snapshot is taken as current, i.e. the next updates of the CRLs and of
+// the OCSP responses are not enforced.
+func (s *RevocationSnapshot) check(chain, anchors []*x509.Certificate) (bool, error) {
+	crls := make([]*pkix.CertificateList, 0, len(s.CRLs))
+	for _, data := range s.CRLs {
+		crl, err := x509.ParseCRL(data)
+		if err != nil {
+			return false, fmt.Errorf("malformed CRL: %w", err)
+		}
+		crls = append(crls, crl)
+	}
+	candidates := append(append([]*x509.Certificate(nil), chain...), anchors...)
+	var checked bool
+	for _, cert := range chain {
+		issuer := findIssuer(cert, candidates)
+		if issuer == nil {
+			continue
+		}
+		for _, crl := range crls {
+			if issuer.CheckCRLSignature(crl) != nil {
+				continue
+			}
+			checked = true
+			for _, revoked := range crl.TBSCertList.RevokedCertificates {
+				if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return true, fmt.Errorf("certificate with subject %q was revoked at %v", cert.Subject, revoked.RevocationTime)
+				}
+			}
+		}
+		for _, data := range s.OCSPResponses {
+			// responses of other certificates fail to parse for cert
+			resp, err := ocsp.ParseResponseForCert(data, cert, issuer)
+			if err != nil {
+				continue
+			}
+			checked = true
+			if resp.Status == ocsp.Revoked {
+				return true, fmt.Errorf("certificate with subject %q was revoked at %v", cert.Subject, resp.RevokedAt)
+			}
+		}
+	}
+	return checked, nil
+}
+
+// findIssuer returns the issuer of cert among candidates, or nil if cert is
+// self-issued or its issuer is not found.
+func findIssuer(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range candidates {
+		if candidate.Equal(cert) {
+			continue
+		}
+		if cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package verification
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scopeWildcardSuffix is the suffix of hierarchical registry scopes, e.g.
+// registry.acme-rockets.io/software/* covering all the repositories under
+// registry.acme-rockets.io/software.
+const scopeWildcardSuffix = "/*"
+
+// exactScopeSpecificity is the specificity of a registry scope naming the
+// repository of an artifact, which takes precedence over any hierarchical scope.
+const exactScopeSpecificity = 1 << 30
+
+// dockerHubAliases are the host names of Docker Hub normalized to docker.io.
+var dockerHubAliases = []string{"index.docker.io", "registry-1.docker.io", "registry.hub.docker.com"}
+
+// dockerHubRegistry is the normalized host name of Docker Hub.
+const dockerHubRegistry = "docker.io"
+
+// normalizeRepository normalizes a repository path, i.e. host[:port]/repository,
+// so that equivalent paths compare equal: the host is normalized by
+// normalizeHost, and Docker Hub official images are qualified with library/.
+func normalizeRepository(path string) string {
+	host, repository := splitHost(path)
+	host = normalizeHost(host)
+	if host == dockerHubRegistry && repository != "" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return joinHost(host, repository)
+}
+
+// normalizeHost lower-cases host, removes the default HTTPS port, and
+// replaces Docker Hub aliases with docker.io.
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ":443")
+	if isPresent(host, dockerHubAliases) {
+		return dockerHubRegistry
+	}
+	return host
+}
+
+// normalizeRegistryScope normalizes a registry scope, preserving the wildcard
+// of global and hierarchical scopes.
+func normalizeRegistryScope(scope string) string {
+	if scope == wildcard {
+		return scope
+	}
+	if strings.HasSuffix(scope, scopeWildcardSuffix) {
+		// the namespace of a hierarchical scope is not an official image
+		host, namespace := splitHost(strings.TrimSuffix(scope, scopeWildcardSuffix))
+		return joinHost(normalizeHost(host), namespace) + scopeWildcardSuffix
+	}
+	return normalizeRepository(scope)
+}
+
+// splitHost splits path into its host and the rest of the path.
+func splitHost(path string) (host, rest string) {
+	if i := strings.Index(path, "/"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+// joinHost joins host and the rest of a path.
+func joinHost(host, rest string) string {
+	if rest == "" {
+		return host
+	}
+	return host + "/" + rest
+}
+
+// validateRegistryScope validates a non-global registry scope, which is either
+// a repository or a hierarchical scope ending with /*.
+func validateRegistryScope(scope string) error {
+	if !strings.HasSuffix(scope, scopeWildcardSuffix) {
+		return validateRegistryScopeFormat(scope)
+	}
+	base := strings.TrimSuffix(scope, scopeWildcardSuffix)
+	if strings.Contains(base, wildcard) {
+		return fmt.Errorf("registry scope %q is not valid, a wildcard can only be the last path component of a registry scope, e.g. domain.com/my/*", scope)
+	}
+	if !strings.Contains(base, "/") {
+		// a host-wide scope is validated as a repository under the host
+		base += "/repository"
+	}
+	if err := validateRegistryScopeFormat(base); err != nil {
+		return fmt.Errorf("registry scope %q is not valid, make sure it is the fully qualified registry URL without the scheme/protocol, optionally followed by /*. e.g domain.com/my/repository or domain.com/my/*", scope)
+	}
+	return nil
+}
+
+// scopeSpecificity returns how specifically the normalized registry scope
+// covers the normalized repository path, and false if it does not cover it.
+// The global scope has the lowest specificity, hierarchical scopes are more
+// specific the deeper they are, and a scope naming the repository is the most
+// specific.
+func scopeSpecificity(scope, repository string) (int, bool) {
+	switch {
+	case scope == wildcard:
+		return 0, true
+	case strings.HasSuffix(scope, scopeWildcardSuffix):
+		prefix := strings.TrimSuffix(scope, wildcard)
+		if strings.HasPrefix(repository, prefix) {
+			return len(prefix), true
+		}
+		return 0, false
+	case scope == repository:
+		return exactScopeSpecificity, true
+	}
+	return 0, false
+}
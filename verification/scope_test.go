@@ -0,0 +1,80 @@
+package verification
+
+import "testing"
+
+func TestApplicableTrustPolicy_Scopes(t *testing.T) {
+	statement := func(name string, scopes ...string) TrustPolicy {
+		s := dummyPolicyStatement()
+		s.Name = name
+		s.RegistryScopes = scopes
+		return s
+	}
+	policyDoc := PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{
+			statement("global", "*"),
+			statement("registry", "registry.acme-rockets.io/*"),
+			statement("software", "registry.acme-rockets.io/software/*"),
+			statement("net-monitor", "registry.acme-rockets.io/software/net-monitor"),
+			statement("nginx", "docker.io/library/nginx"),
+			statement("wabbit", "index.docker.io/wabbit-networks/*"),
+		},
+	}
+	if err := policyDoc.ValidatePolicyDocument(); err != nil {
+		t.Fatalf("ValidatePolicyDocument() error = %v", err)
+	}
+	tests := []struct {
+		artifact string
+		want     string
+	}{
+		{"registry.acme-rockets.io/software/net-monitor@sha256:abc", "net-monitor"},
+		{"Registry.Acme-Rockets.io:443/software/net-monitor@sha256:abc", "net-monitor"},
+		{"registry.acme-rockets.io/software/net-monitor/plugins@sha256:abc", "software"},
+		{"registry.acme-rockets.io/software/other@sha256:abc", "software"},
+		{"registry.acme-rockets.io/hardware/other@sha256:abc", "registry"},
+		{"registry.acme-rockets.io:5000/software/net-monitor@sha256:abc", "global"},
+		{"docker.io/nginx@sha256:abc", "nginx"},
+		{"registry-1.docker.io/library/nginx@sha256:abc", "nginx"},
+		{"docker.io/wabbit-networks/net-monitor@sha256:abc", "wabbit"},
+		{"example.com/other@sha256:abc", "global"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.artifact, func(t *testing.T) {
+			got, err := policyDoc.ApplicableTrustPolicy(tt.artifact)
+			if err != nil {
+				t.Fatalf("ApplicableTrustPolicy() error = %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("ApplicableTrustPolicy() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryScopes_Hierarchical(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  [][]string
+		wantErr bool
+	}{
+		{name: "host-wide", scopes: [][]string{{"registry.acme-rockets.io/*"}}},
+		{name: "namespace", scopes: [][]string{{"registry.acme-rockets.io/software/*"}}},
+		{name: "wildcard in the middle", scopes: [][]string{{"registry.acme-rockets.io/*/net-monitor"}}, wantErr: true},
+		{name: "partial wildcard", scopes: [][]string{{"registry.acme-rockets.io/soft*"}}, wantErr: true},
+		{name: "equivalent scopes", scopes: [][]string{{"docker.io/library/nginx"}, {"index.docker.io/nginx"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyDoc := PolicyDocument{Version: "1.0"}
+			for i, scopes := range tt.scopes {
+				s := dummyPolicyStatement()
+				s.Name = s.Name + string(rune('a'+i))
+				s.RegistryScopes = scopes
+				policyDoc.TrustPolicies = append(policyDoc.TrustPolicies, s)
+			}
+			if err := policyDoc.ValidatePolicyDocument(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePolicyDocument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package verification
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // VerificationType is an enum for signature verification types such as Integrity, Authenticity, etc.
 type VerificationType string
@@ -103,3 +106,80 @@ func FindVerificationLevel(s string) (*VerificationLevel, error) {
 	}
 	return nil, fmt.Errorf("invalid signature verification level %q", s)
 }
+
+// Override returns a copy of the verification level where the verification
+// type t is set to action. The verification level itself is not modified, so
+// that the presets such as Strict can be shared.
+//
+// Integrity cannot be overridden, and the checks of the skip level cannot be
+// overridden since it skips signature verification altogether.
+func (level *VerificationLevel) Override(t VerificationType, action VerificationAction) (*VerificationLevel, error) {
+	if level.Name == Skip.Name {
+		return nil, fmt.Errorf("verification level %q cannot be overridden", level.Name)
+	}
+	if t == Integrity {
+		return nil, fmt.Errorf("verification type %q cannot be overridden", t)
+	}
+	if !isValidVerificationType(t) {
+		return nil, fmt.Errorf("invalid verification type %q", t)
+	}
+	if !isValidVerificationAction(action) {
+		return nil, fmt.Errorf("invalid verification action %q", action)
+	}
+	overridden := &VerificationLevel{
+		Name:            level.Name,
+		VerificationMap: make(map[VerificationType]VerificationAction, len(level.VerificationMap)),
+	}
+	for k, v := range level.VerificationMap {
+		overridden.VerificationMap[k] = v
+	}
+	overridden.VerificationMap[t] = action
+	return overridden, nil
+}
+
+// verificationActionNames maps the verification actions to their names in
+// the trust policy document.
+var verificationActionNames = map[string]VerificationAction{
+	"enforce": Enforced,
+	"log":     Logged,
+	"skip":    Skipped,
+}
+
+// ParseVerificationType parses the name of a verification type as used in
+// the trust policy document, e.g. "revocation". Names are case-insensitive.
+func ParseVerificationType(s string) (VerificationType, error) {
+	for _, t := range VerificationTypes {
+		if strings.EqualFold(string(t), s) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("invalid verification type %q", s)
+}
+
+// ParseVerificationAction parses the name of a verification action as used
+// in the trust policy document, i.e. "enforce", "log" or "skip".
+// Names are case-insensitive.
+func ParseVerificationAction(s string) (VerificationAction, error) {
+	if action, ok := verificationActionNames[strings.ToLower(s)]; ok {
+		return action, nil
+	}
+	return "", fmt.Errorf("invalid verification action %q, it must be one of \"enforce\", \"log\" or \"skip\"", s)
+}
+
+func isValidVerificationType(t VerificationType) bool {
+	for _, v := range VerificationTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidVerificationAction(action VerificationAction) bool {
+	for _, v := range VerificationActions {
+		if v == action {
+			return true
+		}
+	}
+	return false
+}
@@ -34,3 +34,84 @@ func TestFindVerificationLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestVerificationLevel_Override(t *testing.T) {
+	level, err := Strict.Override(Revocation, Logged)
+	if err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	if level.VerificationMap[Revocation] != Logged || level.VerificationMap[Expiry] != Enforced {
+		t.Errorf("Override() = %v, want revocation logged and expiry enforced", level.VerificationMap)
+	}
+	if Strict.VerificationMap[Revocation] != Enforced {
+		t.Errorf("Override() modified the strict verification level")
+	}
+
+	tests := []struct {
+		name   string
+		level  *VerificationLevel
+		t      VerificationType
+		action VerificationAction
+	}{
+		{"integrity", Strict, Integrity, Logged},
+		{"skip level", Skip, Expiry, Enforced},
+		{"invalid type", Strict, "invalid", Logged},
+		{"invalid action", Strict, Expiry, "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.level.Override(tt.t, tt.action); err == nil {
+				t.Errorf("Override() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestTrustPolicy_VerificationLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		override map[string]string
+		want     map[VerificationType]VerificationAction
+		wantErr  bool
+	}{
+		{
+			name:  "no override",
+			level: "permissive",
+			want:  Permissive.VerificationMap,
+		},
+		{
+			name:     "strict with logged revocation",
+			level:    "strict",
+			override: map[string]string{"revocation": "log"},
+			want:     map[VerificationType]VerificationAction{Integrity: Enforced, Authenticity: Enforced, AuthenticTimestamp: Enforced, Expiry: Enforced, Revocation: Logged},
+		},
+		{
+			name:     "permissive with enforced expiry",
+			level:    "permissive",
+			override: map[string]string{"Expiry": "enforce", "authenticTimestamp": "skip"},
+			want:     map[VerificationType]VerificationAction{Integrity: Enforced, Authenticity: Enforced, AuthenticTimestamp: Skipped, Expiry: Enforced, Revocation: Logged},
+		},
+		{name: "invalid type", level: "strict", override: map[string]string{"freshness": "log"}, wantErr: true},
+		{name: "invalid action", level: "strict", override: map[string]string{"expiry": "warn"}, wantErr: true},
+		{name: "integrity", level: "strict", override: map[string]string{"integrity": "skip"}, wantErr: true},
+		{name: "skip", level: "skip", override: map[string]string{"expiry": "enforce"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := TrustPolicy{Name: "test", SignatureVerification: tt.level, SignatureVerificationOverride: tt.override}
+			level, err := policy.VerificationLevel()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerificationLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for verificationType, action := range tt.want {
+				if got := level.VerificationMap[verificationType]; got != action {
+					t.Errorf("VerificationLevel() %s = %s, want %s", verificationType, got, action)
+				}
+			}
+		})
+	}
+}
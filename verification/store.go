@@ -2,12 +2,29 @@ package verification
 
 import (
 	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 
 	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go/dir"
+)
+
+// Types of the trust stores referenced by trust policies, e.g. ca:acme-rabbits.
+const (
+	// TrustStoreTypeCA is the type of the trust stores holding CA certificates
+	// in the trust store directory of notation.
+	TrustStoreTypeCA = "ca"
+
+	// TrustStoreTypeSystem is the type of the certificate store of the
+	// operating system, i.e. the Windows certificate store, the macOS
+	// keychain, or the ca-certificates bundle on Linux. The name of a system
+	// trust store is informational, e.g. system:default.
+	TrustStoreTypeSystem = "system"
 )
 
 // X509TrustStore provide the members and behavior for a named trust store
@@ -17,6 +34,24 @@ type X509TrustStore struct {
 	Certificates []*x509.Certificate
 }
 
+// LoadNamedX509TrustStore loads the trust store of type storeType, e.g. ca,
+// named name from the trust store directory of notation, as returned by
+// dir.X509TrustStoreDir.
+func LoadNamedX509TrustStore(storeType, name string) (*X509TrustStore, error) {
+	path, err := dir.X509TrustStoreDir(storeType, name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadX509TrustStore(path)
+}
+
+// LoadNamedX509TrustStoreFS loads the trust store of type storeType, e.g.
+// ca, named name from fsys holding a notation config directory, e.g. as
+// returned by dir.ConfigFS.
+func LoadNamedX509TrustStoreFS(fsys fs.FS, storeType, name string) (*X509TrustStore, error) {
+	return LoadX509TrustStoreFS(fsys, dir.X509TrustStoreDirFS(storeType, name))
+}
+
 // LoadX509TrustStore loads a named trust store from a certificates directory,
 // throws error if parsing a certificate from a file fails
 func LoadX509TrustStore(path string) (*X509TrustStore, error) {
@@ -50,15 +85,8 @@ func LoadX509TrustStore(path string) (*X509TrustStore, error) {
 		if err != nil {
 			return nil, fmt.Errorf("Error while reading certificates from %q. Error : %q", joinedPath, err)
 		}
-
-		// to prevent any trust store misconfigurations, ensure there is at least one certificate from each file
-		if len(certs) < 1 {
-			return nil, fmt.Errorf("could not parse a certificate from %q, every file in a trust store must have a PEM or DER certificate in it", joinedPath)
-		}
-		for _, cert := range certs {
-			if !cert.IsCA {
-				return nil, fmt.Errorf("certificate with subject %q from file %q is not a CA certificate, only CA certificates (BasicConstraint CA=True) are allowed", cert.Subject, joinedPath)
-			}
+		if err := validateTrustStoreCertificates(certs, joinedPath); err != nil {
+			return nil, err
 		}
 
 		trustStore.Certificates = append(trustStore.Certificates, certs...)
@@ -73,3 +101,103 @@ func LoadX509TrustStore(path string) (*X509TrustStore, error) {
 
 	return &trustStore, nil
 }
+
+// LoadX509TrustStoreFS loads a named trust store from the certificates
+// directory storePath of fsys, e.g. an embed.FS holding the roots of a service
+// embedded at build time. The trust store is named after the directory, and
+// the same rules as LoadX509TrustStore apply to its files.
+func LoadX509TrustStoreFS(fsys fs.FS, storePath string) (*X509TrustStore, error) {
+	files, err := fs.ReadDir(fsys, storePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var trustStore X509TrustStore
+	for _, file := range files {
+		joinedPath := path.Join(storePath, file.Name())
+		if file.IsDir() || file.Type()&fs.ModeSymlink != 0 {
+			return nil, fmt.Errorf("%q is not a regular file (directories or symlinks are not supported)", joinedPath)
+		}
+		data, err := fs.ReadFile(fsys, joinedPath)
+		if err != nil {
+			return nil, err
+		}
+		certs, err := parseCertificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificates from %q: %w", joinedPath, err)
+		}
+		if err := validateTrustStoreCertificates(certs, joinedPath); err != nil {
+			return nil, err
+		}
+		trustStore.Certificates = append(trustStore.Certificates, certs...)
+	}
+
+	if len(trustStore.Certificates) < 1 {
+		return nil, fmt.Errorf("trust store %q has no x509 certificates", storePath)
+	}
+	trustStore.Name = path.Base(storePath)
+	trustStore.Path = storePath
+	return &trustStore, nil
+}
+
+// ParseX509TrustStore creates the trust store named name from certificates
+// in memory, e.g. embedded at build time. Each element of data holds PEM or
+// DER encoded CA certificates, e.g. a concatenated PEM bundle.
+func ParseX509TrustStore(name string, data ...[]byte) (*X509TrustStore, error) {
+	if name == "" {
+		return nil, errors.New("trust store name cannot be empty")
+	}
+	trustStore := X509TrustStore{Name: name}
+	for i, d := range data {
+		source := fmt.Sprintf("certificate data %d", i)
+		certs, err := parseCertificates(d)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificates from %s: %w", source, err)
+		}
+		if err := validateTrustStoreCertificates(certs, source); err != nil {
+			return nil, err
+		}
+		trustStore.Certificates = append(trustStore.Certificates, certs...)
+	}
+	if len(trustStore.Certificates) < 1 {
+		return nil, fmt.Errorf("trust store %q has no x509 certificates", name)
+	}
+	return &trustStore, nil
+}
+
+// validateTrustStoreCertificates validates the certificates read from
+// source are CA certificates, and that there is at least one.
+func validateTrustStoreCertificates(certs []*x509.Certificate, source string) error {
+	// to prevent any trust store misconfigurations, ensure there is at least one certificate from each file
+	if len(certs) < 1 {
+		return fmt.Errorf("could not parse a certificate from %q, every file in a trust store must have a PEM or DER certificate in it", source)
+	}
+	for _, cert := range certs {
+		if !cert.IsCA {
+			return fmt.Errorf("certificate with subject %q from file %q is not a CA certificate, only CA certificates (BasicConstraint CA=True) are allowed", cert.Subject, source)
+		}
+	}
+	return nil
+}
+
+// parseCertificates parses PEM or DER encoded certificates. Only
+// CERTIFICATE blocks of PEM data are parsed, so that bundles may carry
+// other blocks.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return x509.ParseCertificates(data)
+	}
+	var certs []*x509.Certificate
+	for ; block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
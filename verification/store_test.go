@@ -1,10 +1,13 @@
 package verification
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"testing/fstest"
 )
 
 // TestLoadTrustStore tests a valid trust store
@@ -77,3 +80,86 @@ func TestLoadTrustStoreWithEmptyDir(t *testing.T) {
 		t.Fatalf("empty trust store should throw an error : %q", err)
 	}
 }
+
+func TestLoadX509TrustStoreFS(t *testing.T) {
+	fsys := os.DirFS(filepath.FromSlash("testdata/trust-store"))
+	trustStore, err := LoadX509TrustStoreFS(fsys, "valid-trust-store")
+	if err != nil {
+		t.Fatalf("LoadX509TrustStoreFS() error = %v", err)
+	}
+	if trustStore.Name != "valid-trust-store" || len(trustStore.Certificates) != 2 {
+		t.Errorf("LoadX509TrustStoreFS() = %q with %d certificates, want valid-trust-store with 2", trustStore.Name, len(trustStore.Certificates))
+	}
+	for _, path := range []string{"trust-store-with-directories", "trust-store-with-invalid-certs", "trust-store-with-leaf-certs", "missing"} {
+		if _, err := LoadX509TrustStoreFS(fsys, path); err == nil {
+			t.Errorf("LoadX509TrustStoreFS(%q) error = nil, want error", path)
+		}
+	}
+}
+
+func TestParseX509TrustStore(t *testing.T) {
+	pemData, err := os.ReadFile(filepath.FromSlash("testdata/trust-store/valid-trust-store/GlobalSignRootCA.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	derData, err := os.ReadFile(filepath.FromSlash("testdata/trust-store/valid-trust-store/GlobalSign.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafData, err := os.ReadFile(filepath.FromSlash("testdata/trust-store/trust-store-with-leaf-certs/non-ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a concatenated PEM bundle with a non-certificate block
+	bundle := append(append(append([]byte{}, pemData...), "-----BEGIN COMMENT-----\n-----END COMMENT-----\n"...), pemData...)
+
+	trustStore, err := ParseX509TrustStore("embedded", bundle, derData)
+	if err != nil {
+		t.Fatalf("ParseX509TrustStore() error = %v", err)
+	}
+	if trustStore.Name != "embedded" || len(trustStore.Certificates) != 3 {
+		t.Errorf("ParseX509TrustStore() = %q with %d certificates, want embedded with 3", trustStore.Name, len(trustStore.Certificates))
+	}
+	for _, tt := range []struct {
+		name string
+		data [][]byte
+	}{
+		{name: "", data: [][]byte{pemData}},
+		{name: "leaf", data: [][]byte{pemData, leafData}},
+		{name: "invalid", data: [][]byte{[]byte("invalid")}},
+		{name: "empty"},
+	} {
+		if _, err := ParseX509TrustStore(tt.name, tt.data...); err == nil {
+			t.Errorf("ParseX509TrustStore(%q) error = nil, want error", tt.name)
+		}
+	}
+}
+
+func TestLoadNamedX509TrustStoreFS(t *testing.T) {
+	pemData, err := os.ReadFile(filepath.FromSlash("testdata/trust-store/valid-trust-store/GlobalSignRootCA.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policyData, err := json.Marshal(dummyPolicyDocument())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"truststore/x509/ca/test-store/root.crt": &fstest.MapFile{Data: pemData},
+		"trustpolicy.json":                       &fstest.MapFile{Data: policyData},
+	}
+	trustStore, err := LoadNamedX509TrustStoreFS(fsys, TrustStoreTypeCA, "test-store")
+	if err != nil {
+		t.Fatalf("LoadNamedX509TrustStoreFS() error = %v", err)
+	}
+	if trustStore.Name != "test-store" || len(trustStore.Certificates) != 1 {
+		t.Errorf("LoadNamedX509TrustStoreFS() = %q with %d certificates, want test-store with 1", trustStore.Name, len(trustStore.Certificates))
+	}
+	policyDoc, err := LoadPolicyDocumentFS(fsys, "")
+	if err != nil {
+		t.Fatalf("LoadPolicyDocumentFS() error = %v", err)
+	}
+	if len(policyDoc.TrustPolicies) != 1 || policyDoc.TrustPolicies[0].TrustStore != "ca:test-store" {
+		t.Errorf("LoadPolicyDocumentFS() = %+v, want the trust policy", policyDoc)
+	}
+}
@@ -1,14 +1,118 @@
 package verification
 
 import (
+	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/event"
+	"github.com/notaryproject/notation-go/log"
+	"github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/notaryproject/notation-go/trace"
+	"github.com/opencontainers/go-digest"
 )
 
+// DefaultParallelism is the number of signatures verified concurrently
+// if Verifier.Parallelism is not set.
+const DefaultParallelism = 4
+
 type Verifier struct {
 	PolicyDocument  *PolicyDocument
 	X509TrustStores []*X509TrustStore
+
+	// Repository provides the signatures of the verified artifacts.
+	// It may be a mirror of the repository of the artifacts, e.g. created
+	// with registry.NewMirrorRepositoryClientWithConfig, the trust policy
+	// being selected by the upstream artifact URI passed to Verify.
+	Repository registry.SignatureRepository
+
+	// PluginManager resolves the verification plugins declared by signatures.
+	PluginManager jws.PluginManager
+
+	// Parallelism is the maximum number of signatures verified concurrently.
+	// DefaultParallelism is used if not positive.
+	Parallelism int
+
+	// PrefetchParallelism is the maximum number of signature blobs fetched
+	// concurrently ahead of their verification, so that the blobs of the
+	// last signatures are fetched while the first ones are verified.
+	// The parallelism of the verification is used if zero, and signatures
+	// are fetched when verified if negative, unless the evaluation order
+	// depends on their content.
+	PrefetchParallelism int
+
+	// SignatureOrder is the order the signatures of an artifact are
+	// evaluated in. SignatureOrderRepository is used if empty.
+	SignatureOrder SignatureOrder
+
+	// PreferredSignatureAlgorithms orders the signatures by the preference
+	// of their signature algorithms, the first being preferred, after the
+	// signature order. Signatures of other algorithms are evaluated last.
+	PreferredSignatureAlgorithms []notation.SignatureAlgorithm
+
+	// MaxSignatures is the maximum number of signatures of an artifact,
+	// e.g. to protect against artifacts carrying thousands of junk
+	// signatures. Verify fails with a notation.ErrorCodeLimitExceeded error
+	// without evaluating any signature of an artifact having more.
+	// The number of signatures is not limited if not positive.
+	MaxSignatures int
+
+	// MaxSignatureSize is the maximum size in bytes of a signature
+	// envelope. Larger signatures fail without being verified, and Verify
+	// fails with a notation.ErrorCodeLimitExceeded error if all signatures
+	// of an artifact are larger.
+	// The size of signatures is not limited if not positive.
+	MaxSignatureSize int64
+
+	// SystemRoots are the roots of the trust policies using a system trust
+	// store, e.g. system:default. The certificate store of the operating
+	// system, as returned by x509.SystemCertPool, is used if nil.
+	SystemRoots *x509.CertPool
+
+	// Revocation is a snapshot of revocation information the certificate
+	// chains of the signatures are checked against, e.g. that of a
+	// verification bundle. The revocation check is left to the verification
+	// plugins if nil.
+	Revocation *RevocationSnapshot
+
+	// PolicyEvaluator makes the final decision to admit the artifact once a
+	// signature satisfies the trust policy. Only the trust policy applies if
+	// nil.
+	PolicyEvaluator PolicyEvaluator
+
+	// Cache caches the successful outcomes of Verify. Every artifact is
+	// verified on every call if nil.
+	Cache *VerificationCache
+
+	// PhaseBudget apportions the deadline of the context of Verify across
+	// the phases of the verification. The phases are only bounded by the
+	// deadline of the context if nil.
+	PhaseBudget *PhaseBudget
+
+	// AllowedMediaTypes restricts the media types of the artifacts signed,
+	// e.g. to OCI image manifests. Any media type is allowed if empty.
+	AllowedMediaTypes []string
+
+	// AllowedArtifactTypes restricts the artifact types of the artifacts
+	// signed, as notation.VerifyOptions.AllowedArtifactTypes does. Any
+	// artifact type is allowed if empty.
+	AllowedArtifactTypes []string
+
+	// AlgorithmPolicy rejects the signatures made with algorithms or keys
+	// weaker than its thresholds. No signature is rejected if nil.
+	AlgorithmPolicy *notation.AlgorithmPolicy
+
+	// AllowedPayloadContentTypes are the content types of the signed
+	// payloads accepted. Only notation.MediaTypePayload is accepted if
+	// empty.
+	AllowedPayloadContentTypes []string
 }
 
 func NewVerifier(policyDocument *PolicyDocument, x509TrustStores []*X509TrustStore) *Verifier {
@@ -18,56 +122,435 @@ func NewVerifier(policyDocument *PolicyDocument, x509TrustStores []*X509TrustSto
 	}
 }
 
-func (v *Verifier) Verify(artifactUri string) error {
-	/*
-		[DONE] Find the applicable trust policy, if none, return error
-		If signatureVerification is skip, then return without an error
-		Retrieve signature manifests
-		Return error if no signature manifests
-		For each signature manifest
-			Check the root cert hash is present in trust store hashes, otherwise fail early
-			Retrieve the signature envelope
-			Verify integrity
-				Signing cert produced the signature
-				Chain from signing cert to root cert is valid
-			Verify Authenticity
-				[DONE] Verify root of trust is established
-				[DONE] Verify trusted identites match from the policy
-			Verify expiry time of the signature is in the future
-			(NOT in RC1) Verify timestamping signature if present
-			(NOT in RC1) Verify revocation
-			Invoke plugin for extended verification
-	*/
-
-	// No error
+// Verify verifies the signatures of the artifact referenced by artifactUri,
+// e.g. domain.com/my/repository@sha256:digest, against the applicable
+// trust policy.
+//
+// Signatures are verified concurrently, their blobs being prefetched ahead
+// of their verification, and Verify returns the outcome of the first
+// signature satisfying the trust policy in the evaluation order of v, i.e.
+// by SignatureOrder then PreferredSignatureAlgorithms, without waiting for
+// the signatures after it. Signatures are all fetched before being verified
+// if the order depends on their content. If the trust policy skips verification, no signature is fetched
+// and the outcome is marked as Skipped, so that callers can audit the
+// decision.
+//
+// The failures of the checks logged by the verification level, such as
+// authenticity under the audit level, do not fail Verify. They are reported
+// in the outcome with notation.SeverityWarning, which allows rolling out
+// signature verification gradually.
+//
+// A signature satisfying the trust policy is then evaluated by the policy
+// evaluator of v, if any, whose denial fails the signature with the
+// notation.CheckPolicy check.
+//
+// If the context has a deadline, it is apportioned across the phases of the
+// verification by the phase budget of v, if any.
+//
+// If v has a cache, the cached outcome of the artifact under the same trust
+// policy is returned without fetching any signature.
+func (v *Verifier) Verify(ctx context.Context, artifactUri string) (_ *notation.VerificationOutcome, err error) {
+	ctx, span := trace.StartSpan(ctx, "notation.VerifyArtifact", trace.Attribute{Key: "notation.artifact.uri", Value: artifactUri})
+	defer func() { trace.EndSpan(span, err) }()
+	trustPolicy, err := v.PolicyDocument.ApplicableTrustPolicy(artifactUri)
+	if err != nil {
+		return nil, err
+	}
+	level, err := trustPolicy.VerificationLevel()
+	if err != nil {
+		return nil, err
+	}
+	logger := log.GetLogger(ctx)
+	logger.Debugf("verifying %q with trust policy %q at verification level %q", artifactUri, trustPolicy.Name, level.Name)
+	if level.Name == Skip.Name {
+		logger.Infof("signature verification of %q is skipped by trust policy %q", artifactUri, trustPolicy.Name)
+		outcome := &notation.VerificationOutcome{Skipped: true}
+		if artifactDigest, err := getArtifactDigestFromUri(artifactUri); err == nil {
+			outcome.Descriptor.Digest = artifactDigest
+		}
+		return outcome, nil
+	}
+	artifactDigest, err := getArtifactDigestFromUri(artifactUri)
+	if err != nil {
+		return nil, err
+	}
+	if v.PhaseBudget != nil {
+		if err := v.PhaseBudget.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := v.validateSignatureOrder(); err != nil {
+		return nil, err
+	}
+	deadlines := v.PhaseBudget.deadlines(ctx)
+	var cacheKey verificationCacheKey
+	if v.Cache != nil {
+		if cacheKey, err = v.cacheKey(artifactDigest, trustPolicy); err != nil {
+			return nil, err
+		}
+		if outcome, ok := v.Cache.load(cacheKey); ok {
+			logger.Debugf("found the cached verification outcome of %q under trust policy %q", artifactUri, trustPolicy.Name)
+			return outcome, nil
+		}
+	}
+	sigVerifier, err := v.signatureVerifier(trustPolicy, level)
+	if err != nil {
+		return nil, err
+	}
+	if deadlines != nil {
+		sigVerifier.PhaseContext = deadlines.phaseContext
+	}
+	if v.Repository == nil {
+		return nil, errors.New("no signature repository configured")
+	}
+	fetchCtx, cancelFetch := deadlines.fetchContext(ctx)
+	sigDigests, sigSizes, err := v.lookupSignatures(fetchCtx, artifactUri, artifactDigest)
+	cancelFetch()
+	if err != nil {
+		return nil, err
+	}
+	if len(sigDigests) == 0 {
+		return nil, fmt.Errorf("no signature is associated with %q, make sure the artifact was signed successfully", artifactUri)
+	}
+	logger.Debugf("found %d signatures associated with %q", len(sigDigests), artifactUri)
+
+	type result struct {
+		index   int
+		digest  digest.Digest
+		outcome *notation.VerificationOutcome
+		err     error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	parallelism := v.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	prefetchParallelism := v.PrefetchParallelism
+	if prefetchParallelism == 0 || prefetchParallelism < 0 && v.ordersByContent() {
+		prefetchParallelism = parallelism
+	}
+	fetcher := v.newSignatureFetcher(ctx, deadlines, artifactDigest, sigDigests, sigSizes, prefetchParallelism)
+	sigDigests = v.orderSignatures(ctx, fetcher, sigDigests)
+	results := make(chan result, len(sigDigests))
+	go func() {
+		sem := make(chan struct{}, parallelism)
+		var wg sync.WaitGroup
+		for i, sigDigest := range sigDigests {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			// stop once the outcome is decided
+			if ctx.Err() != nil {
+				break
+			}
+			wg.Add(1)
+			go func(i int, sigDigest digest.Digest) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				outcome, err := v.verifySignature(ctx, sigVerifier, fetcher, artifactUri, artifactDigest, sigDigest, trustPolicy, level)
+				if err == nil {
+					logger.Debugf("signature %s satisfies trust policy %q", sigDigest, trustPolicy.Name)
+				} else {
+					logger.Debugf("signature %s does not satisfy trust policy %q: %v", sigDigest, trustPolicy.Name, err)
+				}
+				results <- result{i, sigDigest, outcome, err}
+			}(i, sigDigest)
+		}
+		wg.Wait()
+		close(results)
+	}()
+
+	// the outcome is the one of the first signature in evaluation order
+	// satisfying the trust policy, decided once the previous ones failed
+	done := make([]*result, len(sigDigests))
+	next := 0
+	var errs []string
+	limitExceeded := true
+	for r := range results {
+		r := r
+		done[r.index] = &r
+		for ; next < len(done) && done[next] != nil; next++ {
+			if r := done[next]; r.err == nil {
+				cancel()
+				v.Cache.store(cacheKey, r.outcome)
+				return r.outcome, nil
+			}
+		}
+		if r.err != nil {
+			errs = append(errs, fmt.Sprintf("signature %s: %v", r.digest, r.err))
+			limitExceeded = limitExceeded && errors.Is(r.err, notation.ErrLimitExceeded)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(errs)
+	if limitExceeded {
+		return nil, notation.Errorf(notation.ErrorCodeLimitExceeded, "no signature associated with %q is within the limits of the verifier: %s", artifactUri, strings.Join(errs, "; "))
+	}
+	return nil, fmt.Errorf("no signature associated with %q satisfies the trust policy %q: %s", artifactUri, trustPolicy.Name, strings.Join(errs, "; "))
+}
+
+// signatureVerifier creates the verifier of the signatures covered by trustPolicy.
+func (v *Verifier) signatureVerifier(trustPolicy *TrustPolicy, level *VerificationLevel) (*jws.Verifier, error) {
+	roots, err := v.trustRoots(trustPolicy)
+	if err != nil {
+		return nil, err
+	}
+	sigVerifier := jws.NewVerifier()
+	sigVerifier.VerifyOptions.Roots = roots
+	sigVerifier.PluginManager = v.PluginManager
+	sigVerifier.AlgorithmPolicy = v.AlgorithmPolicy
+	sigVerifier.TrustedIdentities = trustPolicy.TrustedIdentities
+	sigVerifier.RequireVerificationPlugin = level.Name == Strict.Name
+	return sigVerifier, nil
+}
+
+// trustRoots returns the roots of the trust store of trustPolicy.
+func (v *Verifier) trustRoots(trustPolicy *TrustPolicy) (*x509.CertPool, error) {
+	storeType, storeName := splitTrustStore(trustPolicy.TrustStore)
+	if storeType == TrustStoreTypeSystem {
+		if v.SystemRoots != nil {
+			return v.SystemRoots, nil
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the system trust store used by trust policy %q: %w", trustPolicy.Name, err)
+		}
+		return roots, nil
+	}
+	certs, found := v.trustStoreCertificates(storeName)
+	if !found {
+		return nil, fmt.Errorf("trust store %q used by trust policy %q is not found", trustPolicy.TrustStore, trustPolicy.Name)
+	}
+	roots := x509.NewCertPool()
+	for _, cert := range certs {
+		roots.AddCert(cert)
+	}
+	return roots, nil
+}
+
+// trustStoreCertificates returns the certificates of the X.509 trust stores
+// named name, and whether any is found.
+func (v *Verifier) trustStoreCertificates(name string) ([]*x509.Certificate, bool) {
+	var certs []*x509.Certificate
+	var found bool
+	for _, store := range v.X509TrustStores {
+		if store.Name == name {
+			found = true
+			certs = append(certs, store.Certificates...)
+		}
+	}
+	return certs, found
+}
+
+// lookupSignatures looks up the signatures of the artifact artifactDigest
+// referenced by artifactUri, failing with a notation.ErrorCodeLimitExceeded
+// error if it has more than MaxSignatures. The sizes of the signatures are
+// returned too if the repository is a registry.SignatureLister, so that
+// they are limited before being fetched.
+func (v *Verifier) lookupSignatures(ctx context.Context, artifactUri string, artifactDigest digest.Digest) ([]digest.Digest, map[digest.Digest]int64, error) {
+	if lister, ok := v.Repository.(registry.SignatureLister); ok {
+		descs, err := lister.LookupDescriptors(ctx, artifactDigest, v.MaxSignatures)
+		if err != nil {
+			return nil, nil, err
+		}
+		sigDigests := make([]digest.Digest, 0, len(descs))
+		sigSizes := make(map[digest.Digest]int64, len(descs))
+		for _, desc := range descs {
+			sigDigests = append(sigDigests, desc.Digest)
+			sigSizes[desc.Digest] = desc.Size
+		}
+		return sigDigests, sigSizes, nil
+	}
+	sigDigests, err := v.Repository.Lookup(ctx, artifactDigest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if v.MaxSignatures > 0 && len(sigDigests) > v.MaxSignatures {
+		return nil, nil, notation.Errorf(notation.ErrorCodeLimitExceeded, "%d signatures are associated with %q, more than the maximum of %d", len(sigDigests), artifactUri, v.MaxSignatures)
+	}
+	return sigDigests, nil, nil
+}
+
+// checkSignatureSize fails with a notation.ErrorCodeLimitExceeded error if
+// a signature of size bytes is larger than MaxSignatureSize.
+func (v *Verifier) checkSignatureSize(size int64) error {
+	if v.MaxSignatureSize > 0 && size > v.MaxSignatureSize {
+		return notation.Errorf(notation.ErrorCodeLimitExceeded, "signature of %d bytes is larger than the maximum of %d bytes", size, v.MaxSignatureSize)
+	}
 	return nil
 }
 
-func verifyX509TrustedIdentities(certs []*x509.Certificate, trustPolicy TrustPolicy) error {
+// verifySignature verifies a single signature of the artifact, and returns
+// an error if any check enforced by the verification level failed.
+func (v *Verifier) verifySignature(ctx context.Context, sigVerifier *jws.Verifier, fetcher *signatureFetcher, artifactUri string, artifactDigest, sigDigest digest.Digest, trustPolicy *TrustPolicy, level *VerificationLevel) (*notation.VerificationOutcome, error) {
+	sig, err := fetcher.get(ctx, sigDigest)
+	if err != nil {
+		return nil, err
+	}
+	// the size is known only once fetched if not looked up with the signature
+	if err := v.checkSignatureSize(int64(len(sig))); err != nil {
+		return nil, err
+	}
+	outcome, _ := sigVerifier.Verify(ctx, sig, notation.VerifyOptions{
+		AllowedMediaTypes:          v.AllowedMediaTypes,
+		AllowedArtifactTypes:       v.AllowedArtifactTypes,
+		AllowedPayloadContentTypes: v.AllowedPayloadContentTypes,
+	})
+	outcome.SignatureDigest = sigDigest
+	if result := outcome.Result(notation.CheckIntegrity); result != nil && result.Status == notation.VerificationPassed {
+		verifySelfSigned(ctx, outcome, trustPolicy)
+		if outcome.Descriptor.Digest != artifactDigest {
+			outcome.Fail(notation.CheckIntegrity, fmt.Errorf("signature is signed for artifact %s, not %s", outcome.Descriptor.Digest, artifactDigest))
+		} else if result := outcome.Result(notation.CheckTrustedIdentity); result == nil || result.Status == notation.VerificationSkipped {
+			// the trusted identities are not verified by a verification plugin
+			if err := verifyX509TrustedIdentities(outcome.CertificateChain, outcome.VerifiedChains, *trustPolicy); err != nil {
+				outcome.Fail(notation.CheckTrustedIdentity, err)
+			} else {
+				outcome.Pass(notation.CheckTrustedIdentity)
+			}
+		}
+		if result := outcome.Result(notation.CheckRevocation); v.Revocation != nil && (result == nil || result.Status == notation.VerificationSkipped) {
+			// the revocation is not checked by a verification plugin
+			var anchors []*x509.Certificate
+			if storeType, storeName := splitTrustStore(trustPolicy.TrustStore); storeType == TrustStoreTypeCA {
+				anchors, _ = v.trustStoreCertificates(storeName)
+			}
+			event.Emit(ctx, event.Event{Type: event.RevocationCheckStarted, Artifact: artifactDigest, Signature: sigDigest})
+			start := time.Now()
+			checked, err := v.Revocation.check(outcome.CertificateChain, anchors)
+			if err != nil {
+				outcome.Fail(notation.CheckRevocation, err)
+			} else if checked {
+				outcome.Pass(notation.CheckRevocation)
+			}
+			event.Emit(ctx, event.Event{Type: event.RevocationCheckFinished, Artifact: artifactDigest, Signature: sigDigest, Duration: time.Since(start), Err: err})
+		}
+	}
+	err = enforce(outcome, level)
+	logger := log.GetLogger(ctx)
+	for _, result := range outcome.Failures() {
+		if result.Severity == notation.SeverityWarning {
+			logger.Warnf("signature %s failed the %s check logged by trust policy %q: %v", sigDigest, result.Check, trustPolicy.Name, result.Error)
+		}
+	}
+	if err != nil {
+		return outcome, err
+	}
+	return outcome, v.evaluatePolicy(ctx, artifactUri, trustPolicy, outcome)
+}
+
+// verificationTypes maps the checks of a verification outcome
+// to the verification types of the verification levels.
+var verificationTypes = map[notation.VerificationCheck]VerificationType{
+	notation.CheckIntegrity:       Integrity,
+	notation.CheckAuthenticity:    Authenticity,
+	notation.CheckTrustedIdentity: Authenticity,
+	notation.CheckTimestamp:       AuthenticTimestamp,
+	notation.CheckExpiry:          Expiry,
+	notation.CheckRevocation:      Revocation,
+}
+
+// enforce sets the severity of each failed check according to the
+// verification level, and returns the error of the first failed check
+// enforced by the verification level. Checks without a verification type are
+// always enforced.
+//
+// All the checks are performed regardless of the verification level, so that
+// the outcome reports every failure, e.g. to audit signatures before
+// enforcing signature verification.
+func enforce(outcome *notation.VerificationOutcome, level *VerificationLevel) error {
+	var err error
+	for _, result := range outcome.Failures() {
+		action := Enforced
+		if t, ok := verificationTypes[result.Check]; ok {
+			action = level.VerificationMap[t]
+		}
+		switch action {
+		case Enforced:
+			result.Severity = notation.SeverityError
+			if err == nil {
+				err = result.Error
+			}
+		case Logged:
+			result.Severity = notation.SeverityWarning
+		default:
+			result.Severity = notation.SeverityInfo
+		}
+	}
+	return err
+}
+
+// verifySelfSigned logs whether the signing certificate of the outcome is
+// self-signed or CA-issued, and fails the authenticity check if it is
+// self-signed and trustPolicy denies self-signed signing certificates.
+func verifySelfSigned(ctx context.Context, outcome *notation.VerificationOutcome, trustPolicy *TrustPolicy) {
+	cert := outcome.CertificateChain[0]
+	if !outcome.SelfSigned {
+		log.GetLogger(ctx).Debugf("signing certificate %q is issued by %q", cert.Subject, cert.Issuer)
+		return
+	}
+	log.GetLogger(ctx).Debugf("signing certificate %q is self-signed", cert.Subject)
+	if trustPolicy.SelfSignedCertificates != SelfSignedCertificatesDeny {
+		return
+	}
+	if result := outcome.Result(notation.CheckAuthenticity); result == nil || result.Status != notation.VerificationFailed {
+		outcome.Fail(notation.CheckAuthenticity, notation.Errorf(notation.ErrorCodeUntrustedSigner, "self-signed signing certificate %q is denied by trust policy %q", cert.Subject, trustPolicy.Name))
+	}
+}
+
+// verifyX509TrustedIdentities verifies the signing certificate of certs, or
+// the certificates pinned in verifiedChains, match the trusted identities of
+// trustPolicy.
+func verifyX509TrustedIdentities(certs []*x509.Certificate, verifiedChains [][]*x509.Certificate, trustPolicy TrustPolicy) error {
 	if isPresent(wildcard, trustPolicy.TrustedIdentities) {
 		return nil
 	}
 
 	var trustedX509Identities []map[string]string
+	var rawX509Identities []string
+	var pins []certificatePin
 	for _, identity := range trustPolicy.TrustedIdentities {
 		i := strings.Index(identity, ":")
 
 		identityPrefix := identity[:i]
 		identityValue := identity[i+1:]
 
-		if identityPrefix == x509Subject {
+		switch identityPrefix {
+		case x509Subject:
 			parsedSubject, err := parseDistinguishedName(identityValue)
 			if err != nil {
 				return err
 			}
 			trustedX509Identities = append(trustedX509Identities, parsedSubject)
+			rawX509Identities = append(rawX509Identities, identityValue)
+		case x509SPKI, x509Fingerprint:
+			pin, err := parseCertificatePin(identityPrefix, identityValue)
+			if err != nil {
+				return err
+			}
+			pins = append(pins, pin)
+			rawX509Identities = append(rawX509Identities, identity)
 		}
 	}
 
-	if len(trustedX509Identities) == 0 {
+	if len(rawX509Identities) == 0 {
 		return nil
 	}
 
+	// pins only match verified certificates, as anyone can append a pinned
+	// certificate to the certificate chain of the envelope
+	for _, pin := range pins {
+		if pin.matchesVerifiedChains(verifiedChains) {
+			return nil
+		}
+	}
+
 	leafCert := certs[0]
 
 	leafCertDN, err := parseDistinguishedName(leafCert.Subject.String()) // parse the certificate subject following rfc 4514 DN syntax
@@ -80,5 +563,5 @@ func verifyX509TrustedIdentities(certs []*x509.Certificate, trustPolicy TrustPol
 		}
 	}
 
-	return fmt.Errorf("signing certificate from the digital signature does not match the X.509 trusted identities %q defined in the trust policy %q", trustedX509Identities, trustPolicy.Name)
+	return fmt.Errorf("signing certificate from the digital signature with subject %q does not match the X.509 trusted identities %q defined in the trust policy %q", leafCert.Subject, rawX509Identities, trustPolicy.Name)
 }
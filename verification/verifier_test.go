@@ -1,11 +1,28 @@
 package verification
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	corex509 "github.com/notaryproject/notation-core-go/x509"
+	"github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signature/jws"
+	"github.com/opencontainers/go-digest"
 )
 
 func TestVerifyX509TrustedIdentities(t *testing.T) {
@@ -24,6 +41,12 @@ func TestVerifyX509TrustedIdentities(t *testing.T) {
 		{[]string{"x509.subject:C=IND,O=SomeOrg,ST=TS"}, true},
 		{[]string{"x509.subject:C=IND,O=SomeOrg,ST=TS", "nonX509Prefix:my-custom-identity"}, true},
 		{[]string{"x509.subject:C=IND,O=SomeOrg,ST=TS", "x509.subject:C=LOL,O=LOL,ST=LOL"}, true},
+		// RFC 4514 normalization of attribute types and values
+		{[]string{"x509.subject:c=us,o=someorg,st=wa"}, false},
+		{[]string{"x509.subject:countryName=US,organizationName=SomeOrg,2.5.4.8=WA"}, false},
+		{[]string{"x509.subject:C=US,O=SomeOrg,ST=WA,OU=SOMEOU,CN=  someCN"}, false},
+		{[]string{"x509.subject:C=US,O=Some Org,ST=WA"}, true},
+		{[]string{"x509.subject:C=US,O=SomeOrg,ST=WA,L=Redmond"}, true},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -34,7 +57,7 @@ func TestVerifyX509TrustedIdentities(t *testing.T) {
 				TrustStore:            "ca:test-store",
 				TrustedIdentities:     tt.x509Identities,
 			}
-			err := verifyX509TrustedIdentities(certs, trustPolicy)
+			err := verifyX509TrustedIdentities(certs, nil, trustPolicy)
 
 			if tt.wantErr != (err != nil) {
 				t.Fatalf("TestVerifyX509TrustedIdentities Error: %q WantErr: %v", err, tt.wantErr)
@@ -42,3 +65,604 @@ func TestVerifyX509TrustedIdentities(t *testing.T) {
 		})
 	}
 }
+
+const testArtifactPath = "registry.acme-rockets.io/software/net-monitor"
+
+var testArtifactDigest = digest.FromString("net-monitor")
+
+// mockRepository serves signatures and tracks concurrent downloads.
+type mockRepository struct {
+	signatures map[digest.Digest][]byte
+	order      []digest.Digest
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	lookups     int
+	gets        int
+}
+
+func (r *mockRepository) add(sig []byte) {
+	if r.signatures == nil {
+		r.signatures = make(map[digest.Digest][]byte)
+	}
+	d := digest.FromBytes(sig)
+	r.signatures[d] = sig
+	r.order = append(r.order, d)
+}
+
+func (r *mockRepository) Lookup(ctx context.Context, manifestDigest digest.Digest) ([]digest.Digest, error) {
+	r.mu.Lock()
+	r.lookups++
+	r.mu.Unlock()
+	if manifestDigest != testArtifactDigest {
+		return nil, nil
+	}
+	return r.order, nil
+}
+
+func (r *mockRepository) Get(ctx context.Context, signatureDigest digest.Digest) ([]byte, error) {
+	r.mu.Lock()
+	r.gets++
+	r.inFlight++
+	if r.inFlight > r.maxInFlight {
+		r.maxInFlight = r.inFlight
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	sig, ok := r.signatures[signatureDigest]
+	if !ok {
+		return nil, errors.New("signature not found")
+	}
+	return sig, nil
+}
+
+func (r *mockRepository) Put(ctx context.Context, signature []byte) (notation.Descriptor, error) {
+	return notation.Descriptor{}, errors.New("not implemented")
+}
+
+func (r *mockRepository) Link(ctx context.Context, manifest, signature notation.Descriptor) (notation.Descriptor, error) {
+	return notation.Descriptor{}, errors.New("not implemented")
+}
+
+func (r *mockRepository) DeleteSignature(ctx context.Context, desc notation.Descriptor) error {
+	return errors.New("not implemented")
+}
+
+// listingRepository is a mockRepository looking up the descriptors of the
+// signatures.
+type listingRepository struct {
+	*mockRepository
+}
+
+func (r listingRepository) LookupDescriptors(ctx context.Context, manifestDigest digest.Digest, limit int) ([]notation.Descriptor, error) {
+	sigDigests, err := r.Lookup(ctx, manifestDigest)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(sigDigests) > limit {
+		return nil, notation.Errorf(notation.ErrorCodeLimitExceeded, "too many signatures")
+	}
+	descs := make([]notation.Descriptor, 0, len(sigDigests))
+	for _, sigDigest := range sigDigests {
+		descs = append(descs, notation.Descriptor{Digest: sigDigest, Size: int64(len(r.signatures[sigDigest]))})
+	}
+	return descs, nil
+}
+
+// testPKI is a CA issuing signing certificates.
+type testPKI struct {
+	caKey  *rsa.PrivateKey
+	caCert *x509.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA", Organization: []string{"Acme"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testPKI{caKey: key, caCert: cert}
+}
+
+// sign signs the artifact with a new signing certificate issued for subject.
+func (p *testPKI) sign(t *testing.T, subject pkix.Name, artifactDigest digest.Digest, expiry time.Time) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.signWithKey(t, key, subject, artifactDigest, notation.SignOptions{Expiry: expiry})
+}
+
+// signWithKey signs the artifact with key, certified for subject by the CA.
+func (p *testPKI) signWithKey(t *testing.T, key crypto.Signer, subject pkix.Name, artifactDigest digest.Digest, opts notation.SignOptions) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, p.caCert, key.Public(), p.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jws.NewSigner(key, []*x509.Certificate{cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.Sign(context.Background(), notation.Descriptor{
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    artifactDigest,
+		Size:      1,
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestVerify(t *testing.T) {
+	trusted := newTestPKI(t)
+	untrusted := newTestPKI(t)
+	acme := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}, CommonName: "signer"}
+	wabbit := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"}
+	expiry := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		level      string
+		override   map[string]string
+		signatures [][]byte
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			level:      "strict",
+			signatures: [][]byte{trusted.sign(t, acme, testArtifactDigest, expiry)},
+		},
+		{
+			name:  "one valid among many",
+			level: "strict",
+			signatures: [][]byte{
+				untrusted.sign(t, acme, testArtifactDigest, expiry),
+				untrusted.sign(t, acme, testArtifactDigest, expiry),
+				trusted.sign(t, acme, testArtifactDigest, expiry),
+				untrusted.sign(t, acme, testArtifactDigest, expiry),
+			},
+		},
+		{
+			name:       "untrusted",
+			level:      "strict",
+			signatures: [][]byte{untrusted.sign(t, acme, testArtifactDigest, expiry)},
+			wantErr:    "certificate signed by unknown authority",
+		},
+		{
+			name:       "untrusted identity",
+			level:      "strict",
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry)},
+			wantErr:    "does not match the X.509 trusted identities",
+		},
+		{
+			name:       "untrusted identity audited",
+			level:      "audit",
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry)},
+		},
+		{
+			name:       "untrusted identity with authenticity logged",
+			level:      "strict",
+			override:   map[string]string{"authenticity": "log"},
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry)},
+		},
+		{
+			name:       "untrusted identity with authenticity enforced",
+			level:      "audit",
+			override:   map[string]string{"authenticity": "enforce"},
+			signatures: [][]byte{trusted.sign(t, wabbit, testArtifactDigest, expiry)},
+			wantErr:    "does not match the X.509 trusted identities",
+		},
+		{
+			name:       "other artifact",
+			level:      "strict",
+			signatures: [][]byte{trusted.sign(t, acme, digest.FromString("other"), expiry)},
+			wantErr:    "is signed for artifact",
+		},
+		{
+			name:    "no signature",
+			level:   "strict",
+			wantErr: "no signature is associated",
+		},
+		{
+			name:  "skip",
+			level: "skip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := TrustPolicy{
+				Name:                          "test-statement-name",
+				RegistryScopes:                []string{testArtifactPath},
+				SignatureVerification:         tt.level,
+				SignatureVerificationOverride: tt.override,
+			}
+			if tt.level != "skip" {
+				policy.TrustStore = "ca:test-store"
+				policy.TrustedIdentities = []string{"x509.subject:C=US,ST=WA,O=Acme"}
+			}
+			repo := &mockRepository{}
+			for _, sig := range tt.signatures {
+				repo.add(sig)
+			}
+			v := NewVerifier(&PolicyDocument{
+				Version:       "1.0",
+				TrustPolicies: []TrustPolicy{policy},
+			}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+			v.Repository = repo
+
+			outcome, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if tt.level == "skip" {
+				if !outcome.Skipped || len(outcome.Results) != 0 {
+					t.Errorf("Verify() outcome = %+v, want skipped outcome", outcome)
+				}
+				if repo.lookups != 0 {
+					t.Errorf("Verify() looked up signatures %d times, want none", repo.lookups)
+				}
+			}
+			if outcome.Descriptor.Digest != testArtifactDigest {
+				t.Errorf("Verify() Descriptor.Digest = %v, want %v", outcome.Descriptor.Digest, testArtifactDigest)
+			}
+			if _, ok := repo.signatures[outcome.SignatureDigest]; !ok && tt.level != "skip" {
+				t.Errorf("Verify() SignatureDigest = %v, want the digest of a signature", outcome.SignatureDigest)
+			}
+		})
+	}
+}
+
+func TestVerify_SystemTrustStore(t *testing.T) {
+	trusted := newTestPKI(t)
+	wabbit := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"}
+	repo := &mockRepository{}
+	repo.add(trusted.sign(t, wabbit, testArtifactDigest, time.Now().Add(time.Hour)))
+	systemRoots := x509.NewCertPool()
+	systemRoots.AddCert(trusted.caCert)
+	tests := []struct {
+		name    string
+		roots   *x509.CertPool
+		wantErr bool
+	}{
+		{name: "trusted by the system", roots: systemRoots},
+		{name: "not trusted by the system", roots: x509.NewCertPool(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(&PolicyDocument{
+				Version: "1.0",
+				TrustPolicies: []TrustPolicy{{
+					Name:                  "test-statement-name",
+					RegistryScopes:        []string{testArtifactPath},
+					SignatureVerification: "strict",
+					TrustStore:            "system:default",
+					TrustedIdentities:     []string{"*"},
+				}},
+			}, nil)
+			v.Repository = repo
+			v.SystemRoots = tt.roots
+			_, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify_Audit(t *testing.T) {
+	trusted := newTestPKI(t)
+	untrusted := newTestPKI(t)
+	wabbit := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"}
+	repo := &mockRepository{}
+	repo.add(untrusted.sign(t, wabbit, testArtifactDigest, time.Now().Add(time.Hour)))
+	v := NewVerifier(&PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{{
+			Name:                  "test-statement-name",
+			RegistryScopes:        []string{testArtifactPath},
+			SignatureVerification: "audit",
+			TrustStore:            "ca:test-store",
+			TrustedIdentities:     []string{"x509.subject:C=US,ST=WA,O=Acme"},
+		}},
+	}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+	v.Repository = repo
+
+	outcome, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	want := map[notation.VerificationCheck]bool{
+		notation.CheckAuthenticity:    true,
+		notation.CheckTrustedIdentity: true,
+	}
+	failures := outcome.Failures()
+	for _, result := range failures {
+		if !want[result.Check] {
+			t.Errorf("Verify() unexpected failure %v", result)
+			continue
+		}
+		delete(want, result.Check)
+		if result.Severity != notation.SeverityWarning {
+			t.Errorf("Verify() %s severity = %q, want %q", result.Check, result.Severity, notation.SeverityWarning)
+		}
+	}
+	for check := range want {
+		t.Errorf("Verify() did not report the failure of the %s check, got %v", check, failures)
+	}
+}
+
+func TestVerify_Parallelism(t *testing.T) {
+	trusted := newTestPKI(t)
+	untrusted := newTestPKI(t)
+	subject := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}}
+	repo := &mockRepository{}
+	for i := 0; i < 6; i++ {
+		repo.add(untrusted.sign(t, subject, testArtifactDigest, time.Now().Add(time.Hour)))
+	}
+	v := NewVerifier(&PolicyDocument{
+		Version: "1.0",
+		TrustPolicies: []TrustPolicy{{
+			Name:                  "test-statement-name",
+			RegistryScopes:        []string{"*"},
+			SignatureVerification: "strict",
+			TrustStore:            "ca:test-store",
+			TrustedIdentities:     []string{"*"},
+		}},
+	}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+	v.Repository = repo
+	v.Parallelism = 2
+	if _, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String()); err == nil {
+		t.Fatal("Verify() error = nil, want error")
+	}
+	if repo.maxInFlight > 2 {
+		t.Errorf("Verify() fetched %d signatures concurrently, want at most 2", repo.maxInFlight)
+	}
+	if repo.maxInFlight < 2 {
+		t.Errorf("Verify() fetched %d signatures concurrently, want 2", repo.maxInFlight)
+	}
+}
+
+func TestVerify_Prefetch(t *testing.T) {
+	trusted := newTestPKI(t)
+	untrusted := newTestPKI(t)
+	subject := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Acme"}}
+	tests := []struct {
+		name                string
+		prefetchParallelism int
+		want                int
+	}{
+		{name: "prefetch", prefetchParallelism: 4, want: 4},
+		{name: "disabled", prefetchParallelism: -1, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			for i := 0; i < 6; i++ {
+				repo.add(untrusted.sign(t, subject, testArtifactDigest, time.Now().Add(time.Hour)))
+			}
+			v := NewVerifier(&PolicyDocument{
+				Version: "1.0",
+				TrustPolicies: []TrustPolicy{{
+					Name:                  "test-statement-name",
+					RegistryScopes:        []string{"*"},
+					SignatureVerification: "strict",
+					TrustStore:            "ca:test-store",
+					TrustedIdentities:     []string{"*"},
+				}},
+			}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+			v.Repository = repo
+			v.Parallelism = 1
+			v.PrefetchParallelism = tt.prefetchParallelism
+			if _, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String()); err == nil {
+				t.Fatal("Verify() error = nil, want error")
+			}
+			if repo.maxInFlight != tt.want {
+				t.Errorf("Verify() fetched %d signatures concurrently, want %d", repo.maxInFlight, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify_Limits(t *testing.T) {
+	trusted := newTestPKI(t)
+	wabbit := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"}
+	var sigs [][]byte
+	for i := 0; i < 3; i++ {
+		sigs = append(sigs, trusted.sign(t, wabbit, testArtifactDigest, time.Now().Add(time.Hour)))
+	}
+	tests := []struct {
+		name             string
+		maxSignatures    int
+		maxSignatureSize int64
+		wantErr          bool
+	}{
+		{name: "unlimited"},
+		{name: "signatures within limit", maxSignatures: 3},
+		{name: "too many signatures", maxSignatures: 2, wantErr: true},
+		{name: "size within limit", maxSignatureSize: 1 << 20},
+		{name: "signatures too large", maxSignatureSize: 64, wantErr: true},
+	}
+	for _, listing := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/listing=%v", tt.name, listing), func(t *testing.T) {
+				repo := &mockRepository{}
+				for _, sig := range sigs {
+					repo.add(sig)
+				}
+				v := NewVerifier(&PolicyDocument{
+					Version: "1.0",
+					TrustPolicies: []TrustPolicy{{
+						Name:                  "test-statement-name",
+						RegistryScopes:        []string{"*"},
+						SignatureVerification: "strict",
+						TrustStore:            "ca:test-store",
+						TrustedIdentities:     []string{"*"},
+					}},
+				}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+				v.Repository = repo
+				if listing {
+					v.Repository = listingRepository{repo}
+				}
+				v.MaxSignatures = tt.maxSignatures
+				v.MaxSignatureSize = tt.maxSignatureSize
+				_, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+				if tt.wantErr {
+					if !errors.Is(err, notation.ErrLimitExceeded) {
+						t.Errorf("Verify() error = %v, want %v", err, notation.ErrLimitExceeded)
+					}
+					// signatures are limited before being fetched if looked up
+					// with their sizes
+					if listing && repo.gets != 0 {
+						t.Errorf("Verify() fetched %d signatures, want none", repo.gets)
+					}
+				} else if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestVerify_SignatureOrder(t *testing.T) {
+	trusted := newTestPKI(t)
+	wabbit := pkix.Name{Country: []string{"US"}, Province: []string{"WA"}, Organization: []string{"Wabbit"}, CommonName: "signer"}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	signAt := func(key crypto.Signer, signingTime time.Time) []byte {
+		return trusted.signWithKey(t, key, wabbit, testArtifactDigest, notation.SignOptions{
+			Expiry: now.Add(time.Hour),
+			Clock:  func() time.Time { return signingTime },
+		})
+	}
+	oldest := signAt(rsaKey, now.Add(-3*time.Hour))
+	newest := signAt(rsaKey, now.Add(-time.Hour))
+	ecdsaSig := signAt(ecKey, now.Add(-2*time.Hour))
+	repo := &mockRepository{}
+	for _, sig := range [][]byte{oldest, newest, ecdsaSig} {
+		repo.add(sig)
+	}
+	tests := []struct {
+		name       string
+		order      SignatureOrder
+		algorithms []notation.SignatureAlgorithm
+		want       []byte
+	}{
+		{name: "repository order", want: oldest},
+		{name: "newest first", order: SignatureOrderNewestFirst, want: newest},
+		{name: "preferred algorithm", algorithms: []notation.SignatureAlgorithm{notation.ECDSA_SHA_384}, want: ecdsaSig},
+		{name: "newest first then preferred algorithm", order: SignatureOrderNewestFirst, algorithms: []notation.SignatureAlgorithm{notation.ECDSA_SHA_384}, want: newest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(&PolicyDocument{
+				Version: "1.0",
+				TrustPolicies: []TrustPolicy{{
+					Name:                  "test-statement-name",
+					RegistryScopes:        []string{"*"},
+					SignatureVerification: "strict",
+					TrustStore:            "ca:test-store",
+					TrustedIdentities:     []string{"*"},
+				}},
+			}, []*X509TrustStore{{Name: "test-store", Certificates: []*x509.Certificate{trusted.caCert}}})
+			v.Repository = repo
+			v.SignatureOrder = tt.order
+			v.PreferredSignatureAlgorithms = tt.algorithms
+			// outcomes are reproducible across runs
+			for i := 0; i < 5; i++ {
+				outcome, err := v.Verify(context.Background(), testArtifactPath+"@"+testArtifactDigest.String())
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				if want := digest.FromBytes(tt.want); outcome.SignatureDigest != want {
+					t.Fatalf("Verify() signature = %v, want %v", outcome.SignatureDigest, want)
+				}
+			}
+		})
+	}
+
+	v := NewVerifier(&PolicyDocument{}, nil)
+	v.SignatureOrder = "oldestFirst"
+	if err := v.validateSignatureOrder(); !errors.Is(err, notation.ErrInvalidArgument) {
+		t.Errorf("validateSignatureOrder() error = %v, want %v", err, notation.ErrInvalidArgument)
+	}
+}
+
+func TestVerifySelfSigned(t *testing.T) {
+	pki := newTestPKI(t)
+	for _, tt := range []struct {
+		name       string
+		selfSigned string
+		wantFailed bool
+	}{
+		{name: "allowed by default"},
+		{name: "allowed", selfSigned: SelfSignedCertificatesAllow},
+		{name: "denied", selfSigned: SelfSignedCertificatesDeny, wantFailed: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := &notation.VerificationOutcome{
+				CertificateChain: []*x509.Certificate{pki.caCert},
+				SelfSigned:       notation.IsSelfSigned(pki.caCert),
+			}
+			outcome.Pass(notation.CheckAuthenticity)
+			verifySelfSigned(context.Background(), outcome, &TrustPolicy{Name: "dev", SelfSignedCertificates: tt.selfSigned})
+			result := outcome.Result(notation.CheckAuthenticity)
+			if failed := result.Status == notation.VerificationFailed; failed != tt.wantFailed {
+				t.Errorf("verifySelfSigned() authenticity = %v, want failed %v", result, tt.wantFailed)
+			}
+			if tt.wantFailed && !errors.Is(result.Error, notation.ErrUntrustedSigner) {
+				t.Errorf("verifySelfSigned() error = %v, want %v", result.Error, notation.ErrUntrustedSigner)
+			}
+		})
+	}
+}